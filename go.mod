@@ -66,6 +66,7 @@ require (
 	github.com/testcontainers/testcontainers-go v0.32.0
 	github.com/tidwall/gjson v1.12.1
 	github.com/valyala/fasthttp v1.35.0
+	go.etcd.io/bbolt v1.3.7
 	golang.org/x/sync v0.7.0
 	golang.org/x/text v0.16.0
 	google.golang.org/protobuf v1.33.0
@@ -192,7 +193,6 @@ require (
 	github.com/xanzy/ssh-agent v0.3.3 // indirect
 	github.com/yuin/goldmark v1.4.13 // indirect
 	github.com/yusufpapurcu/wmi v1.2.3 // indirect
-	go.etcd.io/bbolt v1.3.7 // indirect
 	go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp v0.49.0 // indirect
 	go.opentelemetry.io/otel v1.24.0 // indirect
 	go.opentelemetry.io/otel/metric v1.24.0 // indirect
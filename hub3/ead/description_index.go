@@ -20,6 +20,9 @@ import (
 	"io/ioutil"
 	"os"
 	"path"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/delving/hub3/config"
 	"github.com/delving/hub3/ikuzo/service/x/search"
@@ -45,11 +48,32 @@ func NewDescriptionIndex(spec string) *DescriptionIndex {
 	}
 }
 
+// SetLanguage sets the language of the analyzer of the index. It must be set
+// before the index is created.
+func (di *DescriptionIndex) SetLanguage(lang string) error {
+	return di.ti.SetLanguage(lang)
+}
+
+// CreateFrom indexes the text of the items. Besides the full text, the text of
+// each item is indexed in a field per EAD element of the item and its parents,
+// so queries like 'persname:jansen' or 'scopecontent:brieven' can be used.
 func (di *DescriptionIndex) CreateFrom(desc *Description) error {
+	tags := map[string]string{}
+
 	for _, item := range desc.Item {
-		if item.Text != "" {
-			err := di.ti.AppendString(item.Text, int(item.Order))
-			if err != nil {
+		tags[strconv.FormatUint(item.Order, 10)] = item.Tag
+
+		if item.Text == "" {
+			continue
+		}
+
+		err := di.ti.AppendString(item.Text, int(item.Order))
+		if err != nil {
+			return err
+		}
+
+		for _, field := range itemFields(item, tags) {
+			if err := di.ti.AppendField(field, item.Text, int(item.Order)); err != nil {
 				return err
 			}
 		}
@@ -58,6 +82,29 @@ func (di *DescriptionIndex) CreateFrom(desc *Description) error {
 	return nil
 }
 
+// itemFields returns the unique tags of the item and its parents.
+func itemFields(item *DataItem, tags map[string]string) []string {
+	fields := []string{}
+	seen := map[string]bool{}
+
+	add := func(tag string) {
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			fields = append(fields, tag)
+		}
+	}
+
+	add(item.Tag)
+
+	if item.ParentIDS != "" {
+		for _, id := range strings.Split(item.ParentIDS, "~") {
+			add(tags[id])
+		}
+	}
+
+	return fields
+}
+
 func (di *DescriptionIndex) Write() error {
 	err := os.MkdirAll(GetDataPath(di.spec), os.ModePerm)
 	if err != nil {
@@ -88,7 +135,7 @@ func (di *DescriptionIndex) SearchWithString(query string) (*search.Matches, err
 		Str("search.type", "request builder").
 		Logger()
 
-	queryParser, parseErr := search.NewQueryParser()
+	queryParser, parseErr := search.NewQueryParser(search.SetAnalyzer(di.ti.Analyzer()))
 	if parseErr != nil {
 		rlog.Error().Err(parseErr).
 			Str("subquery", "description").
@@ -134,6 +181,27 @@ func (di *DescriptionIndex) HighlightMatches(hits *search.Matches, items []*Data
 	return matches
 }
 
+// SortByScore orders the items by the relevance score of the hits. The best
+// matching items come first. Items without a match keep their document order
+// after the matching items.
+func (di *DescriptionIndex) SortByScore(hits *search.Matches, items []*DataItem) []*DataItem {
+	sorted := make([]*DataItem, len(items))
+	copy(sorted, items)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		iDoc, jDoc := int(sorted[i].Order), int(sorted[j].Order)
+
+		iHit, jHit := hits.HasDocID(iDoc), hits.HasDocID(jDoc)
+		if iHit != jHit {
+			return iHit
+		}
+
+		return hits.Score(iDoc) > hits.Score(jDoc)
+	})
+
+	return sorted
+}
+
 func GetDescriptionIndex(spec string) (*DescriptionIndex, error) {
 	indexPath := getIndexPath(spec)
 	if _, err := os.Stat(indexPath); os.IsNotExist(err) {
@@ -0,0 +1,114 @@
+// Copyright 2017 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ead
+
+import (
+	"testing"
+
+	"github.com/delving/hub3/ikuzo/service/x/search"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDescriptionIndex_SortByScore(t *testing.T) {
+	desc := &Description{
+		Item: []*DataItem{
+			{Order: 1, Text: "inventaris van het archief"},
+			{Order: 2, Text: "het archief van de compagnie en het archief van de kamer"},
+			{Order: 3, Text: "zonder treffer"},
+			{Order: 4, Text: "archief"},
+		},
+	}
+
+	di := NewDescriptionIndex("spec")
+	if err := di.CreateFrom(desc); err != nil {
+		t.Fatalf("CreateFrom() error = %v", err)
+	}
+
+	qp, err := search.NewQueryParser()
+	if err != nil {
+		t.Fatalf("NewQueryParser() error = %v", err)
+	}
+
+	query, err := qp.Parse("archief")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	hits, err := di.Search(query)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+
+	got := []uint64{}
+	for _, item := range di.SortByScore(hits, desc.Item) {
+		got = append(got, item.Order)
+	}
+
+	want := []uint64{4, 1, 2, 3}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("SortByScore() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDescriptionIndex_fields(t *testing.T) {
+	desc := &Description{
+		Item: []*DataItem{
+			{Order: 1, Tag: "scopecontent"},
+			{Order: 2, Tag: "p", ParentIDS: "1", Text: "brieven van Jansen"},
+			{Order: 3, Tag: "persname", ParentIDS: "", Text: "Jansen"},
+		},
+	}
+
+	di := NewDescriptionIndex("spec")
+	if err := di.CreateFrom(desc); err != nil {
+		t.Fatalf("CreateFrom() error = %v", err)
+	}
+
+	tests := []struct {
+		query string
+		want  []int
+	}{
+		{"jansen", []int{3, 2}},
+		{"persname:jansen", []int{3}},
+		{"scopecontent:jansen", []int{2}},
+		{"p:brieven", []int{2}},
+	}
+
+	qp, err := search.NewQueryParser()
+	if err != nil {
+		t.Fatalf("NewQueryParser() error = %v", err)
+	}
+
+	for _, tt := range tests {
+		query, err := qp.Parse(tt.query)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", tt.query, err)
+		}
+
+		hits, err := di.Search(query)
+		if err != nil {
+			t.Fatalf("Search(%q) error = %v", tt.query, err)
+		}
+
+		got := []int{}
+		for _, r := range hits.Ranked() {
+			got = append(got, r.DocID)
+		}
+
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("Search(%q) mismatch (-want +got):\n%s", tt.query, diff)
+		}
+	}
+}
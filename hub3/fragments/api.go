@@ -798,7 +798,7 @@ func (sr *SearchRequest) ElasticQuery() (elastic.Query, error) {
 			rawQuery = strings.Join(all, " ")
 		}
 		if rawQuery != "" {
-			qs := elastic.NewQueryStringQuery(escapeRawQuery(rawQuery))
+			qs := elastic.NewQueryStringQuery(escapeRawQuery(expandRawQuery(sr.GetOrgID(), rawQuery)))
 			qs.DefaultOperator("and")
 
 			qs = qs.
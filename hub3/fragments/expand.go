@@ -0,0 +1,71 @@
+// Copyright © 2017 Delving B.V. <info@delving.eu>
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fragments
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/delving/hub3/ikuzo/service/x/search"
+)
+
+// expanders contains the search.Expander per organization, see SetExpander.
+var expanders sync.Map
+
+// SetExpander sets the Expander that expands the terms of the full-text query
+// of the v2 search for the organization, e.g. with the labels of its SKOS
+// vocabulary. A nil Expander disables the expansion.
+func SetExpander(orgID string, e search.Expander) {
+	if e == nil {
+		expanders.Delete(orgID)
+		return
+	}
+
+	expanders.Store(orgID, e)
+}
+
+// expandRawQuery adds the expansions of the query terms as alternatives, e.g.
+// 'landbouw 1900' becomes '(landbouw OR "akkerbouw" OR "veeteelt") 1900'.
+// Advanced queries and terms with query syntax are not expanded.
+func expandRawQuery(orgID, query string) string {
+	v, ok := expanders.Load(orgID)
+	if !ok || isAdvancedSearch(query) {
+		return query
+	}
+
+	e := v.(search.Expander)
+
+	terms := strings.Fields(query)
+
+	for idx, term := range terms {
+		if strings.ContainsAny(term, `"():*?~^[]{}\/!&|`) {
+			continue
+		}
+
+		expansions := e.Expand(term)
+		if len(expansions) == 0 {
+			continue
+		}
+
+		alternatives := []string{term}
+
+		for _, expansion := range expansions {
+			alternatives = append(alternatives, `"`+strings.ReplaceAll(expansion, `"`, `\"`)+`"`)
+		}
+
+		terms[idx] = "(" + strings.Join(alternatives, " OR ") + ")"
+	}
+
+	return strings.Join(terms, " ")
+}
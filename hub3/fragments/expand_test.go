@@ -0,0 +1,69 @@
+// Copyright © 2017 Delving B.V. <info@delving.eu>
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fragments
+
+import "testing"
+
+type mapExpander map[string][]string
+
+func (e mapExpander) Expand(value string) []string {
+	return e[value]
+}
+
+func Test_expandRawQuery(t *testing.T) {
+	SetExpander("expand-org", mapExpander{"landbouw": {"akkerbouw", "agrarische sector"}})
+	defer SetExpander("expand-org", nil)
+
+	tests := []struct {
+		name  string
+		orgID string
+		query string
+		want  string
+	}{
+		{
+			"narrower terms",
+			"expand-org",
+			"landbouw 1900",
+			`(landbouw OR "akkerbouw" OR "agrarische sector") 1900`,
+		},
+		{
+			"organization without vocabulary",
+			"other-org",
+			"landbouw",
+			"landbouw",
+		},
+		{
+			"advanced query",
+			"expand-org",
+			"landbouw AND 1900",
+			"landbouw AND 1900",
+		},
+		{
+			"term with query syntax",
+			"expand-org",
+			"title:landbouw",
+			"title:landbouw",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expandRawQuery(tt.orgID, tt.query); got != tt.want {
+				t.Errorf("expandRawQuery() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
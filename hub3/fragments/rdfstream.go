@@ -25,30 +25,29 @@ import (
 
 	c "github.com/delving/hub3/config"
 	"github.com/delving/hub3/ikuzo/domain/domainpb"
-	rdf "github.com/kiivihal/gon3"
+	"github.com/delving/hub3/ikuzo/rdf"
+	rdfturtle "github.com/delving/hub3/ikuzo/rdf/formats/turtle"
 	r "github.com/kiivihal/rdf2go"
 )
 
 // parseTurtleFile creates a graph from an uploaded file
 func parseTurtleFile(r io.Reader) (*rdf.Graph, error) {
-	parser := rdf.NewParser("")
-	g, err := parser.Parse(r)
-	return g, err
+	return rdfturtle.Parse(r, nil)
 }
 
 func rdf2term(term rdf.Term) r.Term {
 	switch term := term.(type) {
-	case *rdf.BlankNode:
+	case rdf.BlankNode:
 		return r.NewBlankNode(term.RawValue())
-	case *rdf.Literal:
-		if len(term.LanguageTag) > 0 {
-			return r.NewLiteralWithLanguage(term.LexicalForm, term.LanguageTag)
+	case rdf.Literal:
+		if term.Lang() != "" {
+			return r.NewLiteralWithLanguage(term.RawValue(), term.Lang())
 		}
-		if term.DatatypeIRI != nil && len(term.DatatypeIRI.String()) > 0 {
-			return r.NewLiteralWithDatatype(term.LexicalForm, r.NewResource(debrack(term.DatatypeIRI.String())))
+		if !term.DataType.Equal(rdf.IRI{}) && !term.HasImpliedDataType() {
+			return r.NewLiteralWithDatatype(term.RawValue(), r.NewResource(term.DataType.RawValue()))
 		}
 		return r.NewLiteral(term.RawValue())
-	case *rdf.IRI:
+	case rdf.IRI:
 		return r.NewResource(term.RawValue())
 	}
 	return nil
@@ -68,7 +67,7 @@ type RDFUploader struct {
 func (upl *RDFUploader) createResourceMap(g *rdf.Graph) (*ResourceMap, error) {
 	rm := NewEmptyResourceMap(upl.OrgID)
 	idx := 0
-	for t := range g.IterTriples() {
+	for _, t := range g.Triples() {
 		idx++
 		if t.Predicate.RawValue() == upl.TypeClassURI && t.Object.RawValue() == upl.SubjectClass {
 			upl.subjects = append(upl.subjects, t.Subject.RawValue())
@@ -99,6 +98,13 @@ func (upl *RDFUploader) Parse(r io.Reader) (*ResourceMap, error) {
 	if err != nil {
 		return nil, err
 	}
+
+	return upl.AddGraph(g)
+}
+
+// AddGraph creates the ResourceMap of the uploader from a graph that is
+// already parsed, e.g. for validation.
+func (upl *RDFUploader) AddGraph(g *rdf.Graph) (*ResourceMap, error) {
 	rm, err := upl.createResourceMap(g)
 	if err != nil {
 		return nil, err
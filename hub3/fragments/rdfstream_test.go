@@ -17,7 +17,6 @@ package fragments
 import (
 	"strings"
 
-	rdf "github.com/kiivihal/gon3"
 	r "github.com/kiivihal/rdf2go"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -59,15 +58,41 @@ var _ = Describe("Rdfstream", func() {
 	Describe("when parsing a stream", func() {
 		Context("and given a reader", func() {
 			It("should produces a list of triples", func() {
-				parser := rdf.NewParser("")
 				reader := strings.NewReader(turtle)
-				g, err := parser.Parse(reader)
+				g, err := parseTurtleFile(reader)
 				Expect(err).ToNot(HaveOccurred())
-				for t := range g.IterTriples() {
-					_ = r.NewTriple(rdf2term(t.Subject), rdf2term(t.Predicate), rdf2term(t.Object))
+				Expect(g.Len()).To(Equal(10))
+				for _, t := range g.Triples() {
+					triple := r.NewTriple(rdf2term(t.Subject), rdf2term(t.Predicate), rdf2term(t.Object))
+					Expect(triple.Subject).ToNot(BeNil())
+					Expect(triple.Object).ToNot(BeNil())
 				}
-				// Expect(g.IterTriples()).ToNot(BeEmpty())
 			})
 		})
 	})
+
+	Describe("when uploading a parsed graph", func() {
+		It("should create the same resource map as parsing the reader", func() {
+			g, err := parseTurtleFile(strings.NewReader(turtle))
+			Expect(err).ToNot(HaveOccurred())
+
+			newUploader := func() *RDFUploader {
+				return NewRDFUploader(
+					"hub3", "spec", "http://www.w3.org/2004/02/skos/core#Concept",
+					"http://www.w3.org/1999/02/22-rdf-syntax-ns#type", "/cht/", 1,
+				)
+			}
+
+			parsed := newUploader()
+			want, err := parsed.Parse(strings.NewReader(turtle))
+			Expect(err).ToNot(HaveOccurred())
+
+			upl := newUploader()
+			rm, err := upl.AddGraph(g)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rm.Resources()).To(HaveLen(len(want.Resources())))
+			Expect(upl.subjects).To(Equal(parsed.subjects))
+			Expect(upl.subjects).To(HaveLen(1))
+		})
+	})
 })
@@ -34,6 +34,7 @@ import (
 	"github.com/delving/hub3/hub3/index"
 	"github.com/delving/hub3/ikuzo/rdf"
 	"github.com/delving/hub3/ikuzo/search"
+	"github.com/delving/hub3/ikuzo/service/x/spellcheck"
 	"github.com/delving/hub3/ikuzo/storage/x/memory"
 )
 
@@ -379,7 +380,7 @@ func (fr *FragmentResource) GenerateTriples() []*r.Triple {
 }
 
 func (fr *FragmentResource) AddTo(g *rdf.Graph) error {
-	subject, err := rdf.NewIRI(fr.ID)
+	subject, err := resourceSubject(fr.ID)
 	if err != nil {
 		return err
 	}
@@ -405,6 +406,16 @@ func (fr *FragmentResource) AddTo(g *rdf.Graph) error {
 	return nil
 }
 
+// resourceSubject returns the rdf.Subject for a stored resource ID.
+// The IDs of blank nodes are stored with their '_:' prefix.
+func resourceSubject(id string) (rdf.Subject, error) {
+	if strings.HasPrefix(id, "_:") {
+		return rdf.NewBlankNode(strings.TrimPrefix(id, "_:"))
+	}
+
+	return rdf.NewIRI(id)
+}
+
 // GenerateJSONLD converts a FragmenResource into a JSON-LD entry
 func (fr *FragmentResource) GenerateJSONLD() map[string]interface{} {
 	m := map[string]interface{}{}
@@ -454,17 +465,18 @@ type ProtoBuf struct {
 
 // ScrollResultV4 intermediate non-protobuf search results
 type ScrollResultV4 struct {
-	Pager      *ScrollPager       `json:"pager"`
-	Peek       map[string]int64   `json:"peek,omitempty"`
-	Pagination *search.Paginator  `json:"pagination,omitempty"`
-	Query      *Query             `json:"query"`
-	Items      []*FragmentGraph   `json:"items,omitempty"`
-	Collapsed  []*Collapsed       `json:"collapse,omitempty"`
-	Facets     []*QueryFacet      `json:"facets,omitempty"`
-	TreeHeader *TreeHeader        `json:"treeHeader,omitempty"`
-	Tree       []*Tree            `json:"tree,omitempty"`
-	TreePage   map[string][]*Tree `json:"treePage,omitempty"`
-	ProtoBuf   *ProtoBuf          `json:"-"`
+	Pager      *ScrollPager           `json:"pager"`
+	Peek       map[string]int64       `json:"peek,omitempty"`
+	Pagination *search.Paginator      `json:"pagination,omitempty"`
+	Query      *Query                 `json:"query"`
+	Items      []*FragmentGraph       `json:"items,omitempty"`
+	Collapsed  []*Collapsed           `json:"collapse,omitempty"`
+	Facets     []*QueryFacet          `json:"facets,omitempty"`
+	TreeHeader *TreeHeader            `json:"treeHeader,omitempty"`
+	Tree       []*Tree                `json:"tree,omitempty"`
+	TreePage   map[string][]*Tree     `json:"treePage,omitempty"`
+	DidYouMean *spellcheck.DidYouMean `json:"didYouMean,omitempty"`
+	ProtoBuf   *ProtoBuf              `json:"-"`
 }
 
 // TreeHeader contains rendering hints for the consumer of the TreeView API.
@@ -1103,7 +1115,7 @@ func (re *ResourceEntry) AsTriple(subject rdf.Subject) (*rdf.Triple, error) {
 
 	switch re.EntryType {
 	case bnode:
-		object, err = rdf.NewBlankNode(re.ID)
+		object, err = rdf.NewBlankNode(strings.TrimPrefix(re.ID, "_:"))
 	case resourceType:
 		object, err = rdf.NewIRI(re.ID)
 	case literal:
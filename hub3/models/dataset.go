@@ -612,17 +612,25 @@ func (ds DataSet) deleteAllGraphs() (bool, error) {
 }
 
 // DeleteIndexOrphans deletes all the Orphaned records from the Search Index linked to this dataset
-func (ds DataSet) deleteIndexOrphans(ctx context.Context, wp *wp.WorkerPool) (int, error) {
+// OrphanQuery returns the query for the v2 records of the dataset that are not
+// part of the revision. These records are removed by DropOrphans.
+func OrphanQuery(orgID, spec string, revision int) *elastic.BoolQuery {
 	tags := elastic.NewBoolQuery()
 	for _, tag := range []string{"mdr", "narthex", "ead", "eadDesc"} {
 		tags = tags.Should(elastic.NewTermQuery("meta.tags", tag))
 	}
 
 	v2 := elastic.NewBoolQuery()
-	v2 = v2.MustNot(elastic.NewMatchQuery(c.Config.ElasticSearch.RevisionKey, ds.Revision))
+	v2 = v2.MustNot(elastic.NewMatchQuery(c.Config.ElasticSearch.RevisionKey, revision))
 	v2 = v2.Must(tags)
-	v2 = v2.Must(elastic.NewTermQuery(c.Config.ElasticSearch.SpecKey, ds.Spec))
-	v2 = v2.Must(elastic.NewTermQuery(c.Config.ElasticSearch.OrgIDKey, ds.OrgID))
+	v2 = v2.Must(elastic.NewTermQuery(c.Config.ElasticSearch.SpecKey, spec))
+	v2 = v2.Must(elastic.NewTermQuery(c.Config.ElasticSearch.OrgIDKey, orgID))
+
+	return v2
+}
+
+func (ds DataSet) deleteIndexOrphans(ctx context.Context, wp *wp.WorkerPool) (int, error) {
+	v2 := OrphanQuery(ds.OrgID, ds.Spec, ds.Revision)
 
 	frag := elastic.NewBoolQuery()
 	frag = frag.MustNot(elastic.NewMatchQuery(c.Config.ElasticSearch.RevisionKey, ds.Revision))
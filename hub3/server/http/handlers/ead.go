@@ -254,6 +254,7 @@ func TreeDescriptionAPI(w http.ResponseWriter, r *http.Request) {
 		echo   string
 		err    error
 		filter bool
+		byRank bool
 	)
 
 	for k := range params {
@@ -276,6 +277,8 @@ func TreeDescriptionAPI(w http.ResponseWriter, r *http.Request) {
 			echo = params.Get(k)
 		case "filter":
 			filter = strings.EqualFold(params.Get(k), "true")
+		case "sort":
+			byRank = strings.EqualFold(params.Get(k), "score")
 		}
 	}
 
@@ -307,9 +310,17 @@ func TreeDescriptionAPI(w http.ResponseWriter, r *http.Request) {
 
 		desc.Item = descIndex.HighlightMatches(hits, desc.Item, filter)
 
-		if echo == "hits" {
+		if byRank {
+			desc.Item = descIndex.SortByScore(hits, desc.Item)
+		}
+
+		switch echo {
+		case "hits":
 			render.JSON(w, r, hits.TermFrequency())
 			return
+		case "scores":
+			render.JSON(w, r, hits.Ranked())
+			return
 		}
 
 		// TODO(kiivihal): should we implement search and highlighting for summary
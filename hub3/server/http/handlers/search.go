@@ -33,6 +33,7 @@ import (
 	"github.com/delving/hub3/ikuzo/render"
 	"github.com/delving/hub3/ikuzo/search"
 	"github.com/delving/hub3/ikuzo/service/x/bulk"
+	"github.com/delving/hub3/ikuzo/service/x/spellcheck"
 	"github.com/delving/hub3/ikuzo/storage/x/memory"
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
@@ -49,13 +50,33 @@ type contextKey string
 
 const retryKey contextKey = "retry"
 
+// searchHandler serves the v2 search API.
+type searchHandler struct {
+	// spellCheck returns the didYouMean block for queries with zero or few hits.
+	// It is disabled when nil.
+	spellCheck *spellcheck.Service
+}
+
+// RegisterSearch registers the v2 search API without didYouMean suggestions.
 func RegisterSearch(router chi.Router) {
+	NewSearchRouter(nil)(router)
+}
+
+// NewSearchRouter returns the router function of the v2 search API. The search
+// results contain a didYouMean block when the spellcheck.Service is not nil.
+func NewSearchRouter(spell *spellcheck.Service) func(router chi.Router) {
+	sh := &searchHandler{spellCheck: spell}
+
+	return sh.register
+}
+
+func (sh *searchHandler) register(router chi.Router) {
 	r := chi.NewRouter()
 
 	// throttle queries on elasticsearch
 	r.Use(middleware.Throttle(100))
 
-	r.Get("/v2", GetScrollResult)
+	r.Get("/v2", sh.getScrollResult)
 	r.Get("/v2/{id}", func(w http.ResponseWriter, r *http.Request) {
 		getSearchRecord(w, r)
 		return
@@ -78,7 +99,7 @@ func RegisterSearch(router chi.Router) {
 
 	v2 := chi.NewRouter()
 	v2.Use(middleware.Throttle(100))
-	v2.Get("/search", GetScrollResult)
+	v2.Get("/search", sh.getScrollResult)
 	v2.Get("/search/{id}", func(w http.ResponseWriter, r *http.Request) {
 		getSearchRecord(w, r)
 		return
@@ -88,6 +109,10 @@ func RegisterSearch(router chi.Router) {
 }
 
 func GetScrollResult(w http.ResponseWriter, r *http.Request) {
+	(&searchHandler{}).getScrollResult(w, r)
+}
+
+func (sh *searchHandler) getScrollResult(w http.ResponseWriter, r *http.Request) {
 	orgID := domain.GetOrganizationID(r)
 	searchRequest, err := fragments.NewSearchRequest(orgID.String(), r.URL.Query())
 	if err != nil {
@@ -96,11 +121,14 @@ func GetScrollResult(w http.ResponseWriter, r *http.Request) {
 		render.PlainText(w, r, err.Error())
 		return
 	}
-	ProcessSearchRequest(w, r, searchRequest)
-	return
+	sh.processSearchRequest(w, r, searchRequest)
 }
 
 func ProcessSearchRequest(w http.ResponseWriter, r *http.Request, searchRequest *fragments.SearchRequest) {
+	(&searchHandler{}).processSearchRequest(w, r, searchRequest)
+}
+
+func (sh *searchHandler) processSearchRequest(w http.ResponseWriter, r *http.Request, searchRequest *fragments.SearchRequest) {
 	orgID := domain.GetOrganizationID(r)
 
 	s, fub, err := searchRequest.ElasticSearchService(index.ESClient())
@@ -712,6 +740,10 @@ func ProcessSearchRequest(w http.ResponseWriter, r *http.Request, searchRequest
 		q.Numfound = int32(res.TotalHits())
 		result.Query = q
 
+		if sh.spellCheck != nil && searchRequest.GetQuery() != "" {
+			result.DidYouMean = sh.spellCheck.DidYouMean(orgID.String(), searchRequest.GetQuery(), int(res.TotalHits()))
+		}
+
 		// decode Aggregations
 		aggs, err := searchRequest.DecodeFacets(res, fub)
 		if err != nil {
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"log"
 	"net/url"
+	"sort"
 	"strings"
+	"time"
 )
 
 type OrgConfigRetriever interface {
@@ -21,13 +23,163 @@ type ArchesConfig struct {
 	DSN               string `json:"dsn"` // arches postgresql
 }
 
+// Supported levels of deleted record tracking of the OAI-PMH repository.
+const (
+	DeletedRecordNo         = "no"
+	DeletedRecordTransient  = "transient"
+	DeletedRecordPersistent = "persistent"
+)
+
+// defaultDeletedTTL is how long deleted records are reported with 'transient'
+// tracking when OAIPMHConfig.DeletedTTL is not set.
+const defaultDeletedTTL = 30 * 24 * time.Hour
+
 type OAIPMHConfig struct {
 	Enabled        bool     `json:"enabled"`
 	AdminEmails    []string `json:"adminEmails"`
 	RepositoryName string   `json:"repositoryName"`
+	ResponseSize   int      `json:"responseSize"`
 	Deleted        string   `json:"deleted"` // no, persistent, transient
+	// DeletedTTL is how long deleted records are reported with 'transient'
+	// tracking, parsed with time.ParseDuration. The default is 720h.
+	DeletedTTL string `json:"deletedTTL"`
 	// HarvestPath    string   `json:"harvestPath"`
+	// MetadataFormats are the metadata prefixes that are disseminated. When
+	// empty all formats of the store are available.
+	MetadataFormats []string `json:"metadataFormats"`
+	// SetMetadataFormats replaces MetadataFormats for the sets in the map.
+	SetMetadataFormats map[string][]string `json:"setMetadataFormats"`
+}
+
+// AllowsFormat returns true when the metadata prefix may be disseminated for
+// the set. When setSpec is empty, the prefix is allowed when it is available
+// for any set.
+func (cfg OAIPMHConfig) AllowsFormat(setSpec, prefix string) bool {
+	if setSpec == "" {
+		if allowsPrefix(cfg.MetadataFormats, prefix) {
+			return true
+		}
+
+		for _, prefixes := range cfg.SetMetadataFormats {
+			if allowsPrefix(prefixes, prefix) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	prefixes, ok := cfg.SetMetadataFormats[setSpec]
+	if !ok {
+		prefixes = cfg.MetadataFormats
+	}
+
+	return allowsPrefix(prefixes, prefix)
+}
+
+// FormatSets returns the sets that limit a harvest without a set to the
+// records that may be disseminated with the metadata prefix. When
+// MetadataFormats allows the prefix, the sets of SetMetadataFormats that do
+// not allow it are excluded. Otherwise only the included sets of
+// SetMetadataFormats allow it; included is nil when all sets are harvested.
+func (cfg OAIPMHConfig) FormatSets(prefix string) (included, excluded []string) {
+	if !allowsPrefix(cfg.MetadataFormats, prefix) {
+		included = []string{}
+	}
+
+	for setSpec, prefixes := range cfg.SetMetadataFormats {
+		allowed := allowsPrefix(prefixes, prefix)
+
+		switch {
+		case included != nil && allowed:
+			included = append(included, setSpec)
+		case included == nil && !allowed:
+			excluded = append(excluded, setSpec)
+		}
+	}
+
+	sort.Strings(included)
+	sort.Strings(excluded)
+
+	return included, excluded
+}
+
+// allowsPrefix returns true when the prefixes are empty or contain the prefix.
+func allowsPrefix(prefixes []string, prefix string) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+
+	for _, p := range prefixes {
+		if p == prefix {
+			return true
+		}
+	}
 
+	return false
+}
+
+// DeletedRecord returns the level of deleted record tracking. Unknown values
+// are reported as DeletedRecordNo.
+func (cfg OAIPMHConfig) DeletedRecord() string {
+	switch strings.ToLower(cfg.Deleted) {
+	case DeletedRecordTransient:
+		return DeletedRecordTransient
+	case DeletedRecordPersistent:
+		return DeletedRecordPersistent
+	}
+
+	return DeletedRecordNo
+}
+
+// DeletedExpiry returns how long deleted records are reported. Zero is
+// returned when deleted records do not expire. An invalid DeletedTTL falls
+// back to defaultDeletedTTL.
+func (cfg OAIPMHConfig) DeletedExpiry() time.Duration {
+	if cfg.DeletedRecord() != DeletedRecordTransient {
+		return 0
+	}
+
+	ttl, err := time.ParseDuration(cfg.DeletedTTL)
+	if err != nil || ttl <= 0 {
+		return defaultDeletedTTL
+	}
+
+	return ttl
+}
+
+// TrackDeleted returns true when tombstones of deleted records must be kept.
+func (cfg OAIPMHConfig) TrackDeleted() bool {
+	return cfg.DeletedRecord() != DeletedRecordNo
+}
+
+// HarvestJobConfig configures a scheduled OAI-PMH harvest of an organization.
+type HarvestJobConfig struct {
+	// ID identifies the job within the organization
+	ID      string `json:"id"`
+	Enabled bool   `json:"enabled"`
+	// URL is the base URL of the OAI-PMH endpoint
+	URL            string `json:"url"`
+	Verb           string `json:"verb"` // ListRecords (default) or ListIdentifiers
+	MetadataPrefix string `json:"metadataPrefix"`
+	Set            string `json:"set"`
+	// Schedule is a cron expression, e.g. '*/30 * * * *', or one of the
+	// descriptors @hourly, @daily, @weekly, @monthly or '@every 2h'
+	Schedule string `json:"schedule"`
+	// Processor is the name of the processor of the harvested records, e.g. 'ead' or 'mets'
+	Processor string               `json:"processor"`
+	Backoff   HarvestBackoffConfig `json:"backoff"`
+}
+
+// HarvestBackoffConfig configures the retries of a failed harvest job.
+// The durations are parsed with time.ParseDuration.
+type HarvestBackoffConfig struct {
+	Initial    string  `json:"initial"`    // delay of the first retry (default 1m)
+	Max        string  `json:"max"`        // maximum delay between retries (default 1h)
+	Multiplier float64 `json:"multiplier"` // growth of the delay per retry (default 2)
+	// MaxRetries is the number of retries before the job waits for its next
+	// scheduled run (default 5). A negative value disables retries.
+	MaxRetries int `json:"maxRetries"`
 }
 
 type OrganizationConfig struct {
@@ -92,17 +244,36 @@ type OrganizationConfig struct {
 			MaxLimit int `json:"maxLimit"`
 		} `json:"defaults"`
 	} `json:"elasticSearch,omitempty"`
-	OAIPMH struct {
-		Enabled        bool     `json:"enabled"`
-		AdminEmails    []string `json:"adminEmails"`
-		RepositoryName string   `json:"repositoryName"`
-		ResponseSize   int      `json:"responseSize"`
-	} `json:"oaipmh,omitempty"`
-	RDF struct {
+	// Analyzer configures the text analysis of the full-text search, both for
+	// the elasticsearch mappings and the in-memory indexes.
+	Analyzer struct {
+		// Language selects the stopwords and stemmer: "nl" or "en". When empty
+		// the text is only lowercased and folded to ASCII.
+		Language string `json:"language"`
+	} `json:"analyzer,omitempty"`
+	// Vocabulary configures the SKOS concept schemes that expand the query
+	// terms of the full-text search with alternative labels and narrower
+	// concepts.
+	Vocabulary struct {
+		// Files are N-Triples (.nt) or Turtle (.ttl) files with SKOS concepts
+		Files []string `json:"files"`
+		// NarrowerDepth limits the levels of narrower concepts. default: 0 (all levels)
+		NarrowerDepth int `json:"narrowerDepth"`
+	} `json:"vocabulary,omitempty"`
+	OAIPMH OAIPMHConfig `json:"oaipmh,omitempty"`
+	// Harvest are the OAI-PMH harvest jobs of the organization.
+	Harvest []HarvestJobConfig `json:"harvest,omitempty"`
+	RDF     struct {
 		RDFBaseURL     string `json:"rdfBaseURL"`
 		MintDatasetURL string `json:"mintDatasetURL"`
 		MintOrgIDURL   string `json:"mintOrgIDURL"`
 	} `json:"rdf,omitempty"`
+	// SHACL configures the validation of the records that are received by the bulk service.
+	SHACL struct {
+		SHACLConfig `mapstructure:",squash"`
+		// Datasets overrides the organization SHACL configuration per dataset
+		Datasets map[string]SHACLConfig `json:"datasets,omitempty"`
+	} `json:"shacl,omitempty"`
 	SPARQL struct {
 		Enabled        bool   `json:"enabled"`    // Enable the SPARQL proxy
 		Host           string `json:"host"`       // the base-url to the SPARQL endpoint including the scheme and the port
@@ -114,6 +285,14 @@ type OrganizationConfig struct {
 	} `json:"sparql,omitempty"`
 }
 
+// SHACLConfig configures the SHACL validation of incoming RDF graphs.
+type SHACLConfig struct {
+	// Mode is either "reject" or "warn". Validation is disabled when Mode is empty.
+	Mode string `json:"mode"`
+	// Shapes is the path to a Turtle file with the SHACL shapes
+	Shapes string `json:"shapes"`
+}
+
 func (cfg *OrganizationConfig) OrgID() string {
 	if cfg.CustomID != "" {
 		return cfg.CustomID
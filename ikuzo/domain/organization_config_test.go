@@ -0,0 +1,132 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestOAIPMHConfig_DeletedRecord(t *testing.T) {
+	tests := []struct {
+		name    string
+		deleted string
+		want    string
+		track   bool
+	}{
+		{"not set", "", DeletedRecordNo, false},
+		{"no", "no", DeletedRecordNo, false},
+		{"transient", "transient", DeletedRecordTransient, true},
+		{"persistent mixed case", "Persistent", DeletedRecordPersistent, true},
+		{"unknown value", "always", DeletedRecordNo, false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+
+			cfg := OAIPMHConfig{Deleted: tt.deleted}
+			is.Equal(cfg.DeletedRecord(), tt.want)
+			is.Equal(cfg.TrackDeleted(), tt.track)
+		})
+	}
+}
+
+func TestOAIPMHConfig_DeletedExpiry(t *testing.T) {
+	tests := []struct {
+		name    string
+		deleted string
+		ttl     string
+		want    time.Duration
+	}{
+		{"persistent never expires", "persistent", "1h", 0},
+		{"not tracked", "no", "1h", 0},
+		{"transient", "transient", "48h", 48 * time.Hour},
+		{"transient default", "transient", "", defaultDeletedTTL},
+		{"transient invalid", "transient", "a month", defaultDeletedTTL},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+
+			cfg := OAIPMHConfig{Deleted: tt.deleted, DeletedTTL: tt.ttl}
+			is.Equal(cfg.DeletedExpiry(), tt.want)
+		})
+	}
+}
+
+func TestOAIPMHConfig_AllowsFormat(t *testing.T) {
+	cfg := OAIPMHConfig{
+		MetadataFormats: []string{"edm", "oai_dc"},
+		SetMetadataFormats: map[string][]string{
+			"archive": {"ead"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		cfg     OAIPMHConfig
+		setSpec string
+		prefix  string
+		want    bool
+	}{
+		{"no restrictions", OAIPMHConfig{}, "ds1", "lido", true},
+		{"organization format", cfg, "ds1", "edm", true},
+		{"not an organization format", cfg, "ds1", "lido", false},
+		{"set format", cfg, "archive", "ead", true},
+		{"set replaces organization formats", cfg, "archive", "edm", false},
+		{"repository with organization format", cfg, "", "oai_dc", true},
+		{"repository with set format", cfg, "", "ead", true},
+		{"repository with unknown format", cfg, "", "mods", false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			is.Equal(tt.cfg.AllowsFormat(tt.setSpec, tt.prefix), tt.want)
+		})
+	}
+}
+
+func TestOAIPMHConfig_FormatSets(t *testing.T) {
+	cfg := OAIPMHConfig{
+		MetadataFormats: []string{"edm", "oai_dc"},
+		SetMetadataFormats: map[string][]string{
+			"archive":  {"ead"},
+			"archive2": {"ead", "oai_dc"},
+			"photos":   {"edm"},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		cfg          OAIPMHConfig
+		prefix       string
+		wantIncluded []string
+		wantExcluded []string
+	}{
+		{"no restrictions", OAIPMHConfig{}, "lido", nil, nil},
+		{"organization format", cfg, "oai_dc", nil, []string{"archive", "photos"}},
+		{"set format", cfg, "ead", []string{"archive", "archive2"}, nil},
+		{"unknown format", cfg, "mods", []string{}, nil},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+
+			included, excluded := tt.cfg.FormatSets(tt.prefix)
+			is.Equal(included, tt.wantIncluded)
+			is.Equal(excluded, tt.wantExcluded)
+		})
+	}
+}
@@ -15,6 +15,12 @@ func (in IndexNames) GetSuggestIndexName(orgID string) string {
 	return fmt.Sprintf("%s_suggest", in.GetIndexName(orgID))
 }
 
+// GetTombstoneIndexName returns the name of the index with the tombstones of
+// the records that are deleted from the v2 index.
+func (in IndexNames) GetTombstoneIndexName(orgID string) string {
+	return fmt.Sprintf("%s_tombstones", in.GetIndexName(orgID))
+}
+
 // GetIndexName returns the lowercased indexname.
 // This inforced correct behavior when creating an index in ElasticSearch.
 func (in IndexNames) GetIndexName(orgID string) string {
@@ -0,0 +1,91 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapping
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/delving/hub3/ikuzo/service/x/search"
+)
+
+// stemmerLanguages are the languages of the elasticsearch stemmer filter that
+// implement the same algorithm as the search.Analyzer, when they differ from
+// the name of the language. The 'english' stemmer of elasticsearch is the
+// original Porter stemmer, while search.StemEnglish implements Porter2.
+var stemmerLanguages = map[search.Language]string{
+	search.English: "porter2",
+}
+
+// WithLanguage replaces the default analyzer of the mapping with an analyzer
+// that adds the stopwords and stemmer of the language. It mirrors the
+// search.Analyzer for the language, so the in-memory indexes and elasticsearch
+// produce the same terms. The mapping is returned unchanged for the
+// search.DefaultLanguage.
+func WithLanguage(esMapping string, lang search.Language) (string, error) {
+	if lang == search.DefaultLanguage {
+		return esMapping, nil
+	}
+
+	name := lang.Name()
+	if name == "" {
+		return "", fmt.Errorf("%w: %q", search.ErrUnknownLanguage, lang)
+	}
+
+	var m map[string]interface{}
+	if err := json.Unmarshal([]byte(esMapping), &m); err != nil {
+		return "", fmt.Errorf("unable to parse mapping; %w", err)
+	}
+
+	settings, ok := m["settings"].(map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf("mapping has no settings")
+	}
+
+	stop := name + "_stop"
+	stemmer := name + "_stemmer"
+
+	stemmerLanguage, ok := stemmerLanguages[lang]
+	if !ok {
+		stemmerLanguage = name
+	}
+
+	settings["analysis"] = map[string]interface{}{
+		"filter": map[string]interface{}{
+			stop: map[string]interface{}{
+				"type":      "stop",
+				"stopwords": "_" + name + "_",
+			},
+			stemmer: map[string]interface{}{
+				"type":     "stemmer",
+				"language": stemmerLanguage,
+			},
+		},
+		"analyzer": map[string]interface{}{
+			"default": map[string]interface{}{
+				"tokenizer":   "standard",
+				"char_filter": []string{"html_strip"},
+				"filter":      []string{"lowercase", "asciifolding", stop, stemmer},
+			},
+		},
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		return "", fmt.Errorf("unable to marshal mapping; %w", err)
+	}
+
+	return string(b), nil
+}
@@ -0,0 +1,54 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapping
+
+import (
+	"testing"
+
+	"github.com/delving/hub3/ikuzo/service/x/search"
+	"github.com/matryer/is"
+	"github.com/tidwall/gjson"
+)
+
+func TestWithLanguage(t *testing.T) {
+	is := is.New(t)
+
+	v2 := V2ESMapping(3, 1)
+
+	got, err := WithLanguage(v2, search.DefaultLanguage)
+	is.NoErr(err)
+	is.Equal(got, v2) // the default language does not change the mapping
+
+	got, err = WithLanguage(v2, search.Dutch)
+	is.NoErr(err)
+
+	is.Equal(gjson.Get(got, "settings.index.number_of_shards").Int(), int64(3))
+	is.Equal(gjson.Get(got, "settings.analysis.filter.dutch_stop.stopwords").String(), "_dutch_")
+	is.Equal(gjson.Get(got, "settings.analysis.filter.dutch_stemmer.language").String(), "dutch")
+	is.Equal(
+		gjson.Get(got, "settings.analysis.analyzer.default.filter").String(),
+		`["lowercase","asciifolding","dutch_stop","dutch_stemmer"]`,
+	)
+	// the mappings are not changed
+	is.Equal(gjson.Get(got, "mappings.dynamic").String(), "strict")
+
+	got, err = WithLanguage(v2, search.English)
+	is.NoErr(err)
+	is.Equal(gjson.Get(got, "settings.analysis.filter.english_stop.stopwords").String(), "_english_")
+	is.Equal(gjson.Get(got, "settings.analysis.filter.english_stemmer.language").String(), "porter2")
+
+	_, err = WithLanguage(v2, search.Language("xx"))
+	is.True(err != nil)
+}
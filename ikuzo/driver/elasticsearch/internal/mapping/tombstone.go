@@ -0,0 +1,57 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapping
+
+import "fmt"
+
+func TombstoneMapping(shards, replicas int) string {
+	shards, replicas = setDefaults(shards, replicas)
+
+	return fmt.Sprintf(
+		tombstoneMapping,
+		shards,
+		replicas,
+	)
+}
+
+// tombstoneMapping is the mapping for the tombstones of records that are
+// deleted from the v2 index. Only the fields used for OAI-PMH harvesting are
+// indexed; the rest of the 'meta' of the deleted record is only stored.
+var tombstoneMapping = `{
+	"settings": {
+		"index": {
+			"number_of_shards": %d,
+			"number_of_replicas": %d
+		}
+	},
+	"mappings":{
+		"dynamic": false,
+		"date_detection" : false,
+		"properties": {
+			"meta": {
+				"type": "object",
+				"properties": {
+					"spec": {"type": "keyword"},
+					"orgID": {"type": "keyword"},
+					"hubID": {"type": "keyword"},
+					"revision": {"type": "long"},
+					"tags": {"type": "keyword"},
+					"modified": {"type": "date"}
+				}
+			},
+			"deleted": {"type": "boolean"}
+		}
+	}
+}`
@@ -0,0 +1,38 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapping
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTombstoneMapping(t *testing.T) {
+	mapping := TombstoneMapping(3, 1)
+
+	if !json.Valid([]byte(mapping)) {
+		t.Errorf("TombstoneMapping() = mapping is not valid JSON")
+	}
+
+	if !strings.Contains(mapping, fmt.Sprintf("\"number_of_replicas\": %d", 1)) {
+		t.Errorf("TombstoneMapping() = number_of_replicas not correct")
+	}
+
+	if !strings.Contains(mapping, fmt.Sprintf("\"number_of_shards\": %d", 3)) {
+		t.Errorf("TombstoneMapping() = number_of_shards not correct")
+	}
+}
@@ -0,0 +1,131 @@
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/olivere/elastic/v7"
+
+	"github.com/delving/hub3/hub3/fragments"
+	"github.com/delving/hub3/ikuzo/domain"
+	"github.com/delving/hub3/ikuzo/rdf"
+	"github.com/delving/hub3/ikuzo/service/x/lod"
+)
+
+var _ lod.Resolver = (*LODResolver)(nil)
+
+// LODResolver resolves linked data subjects from the FragmentGraph documents
+// that are stored in the v2 indices.
+type LODResolver struct {
+	c *Client
+	// MaxDocuments is the maximum number of FragmentGraph documents that are
+	// merged into the graph of a subject.
+	MaxDocuments int
+}
+
+func (c *Client) NewLODResolver() (*LODResolver, error) {
+	return &LODResolver{
+		c:            c,
+		MaxDocuments: 10, // default 10
+	}, nil
+}
+
+// Resolve returns the graph of the subject. It contains the triples of the subject
+// and of all the resources that are inlined in the subject in the stored FragmentGraph
+// documents. lod.ErrResourceNotFound is returned when the subject is unknown.
+func (l *LODResolver) Resolve(ctx context.Context, orgID domain.OrganizationID, s rdf.Subject) (*rdf.Graph, error) {
+	query := elastic.NewBoolQuery().
+		Filter(
+			elastic.NewTermQuery("meta.orgID", orgID.String()),
+			elastic.NewTermQuery("meta.docType", fragments.FragmentGraphDocType),
+		).
+		Should(
+			elastic.NewTermQuery("meta.entryURI", s.RawValue()),
+			elastic.NewNestedQuery("resources", elastic.NewTermQuery("resources.id", s.RawValue())),
+		).
+		MinimumNumberShouldMatch(1)
+
+	fsc := elastic.NewFetchSourceContext(true)
+	fsc.Include("meta", "resources")
+
+	res, err := l.c.search.Search().
+		Index(IndexNames{}.GetIndexName(orgID.String())).
+		TrackTotalHits(false).
+		Query(query).
+		Size(l.MaxDocuments).
+		FetchSourceContext(fsc).
+		Do(ctx)
+	if err != nil {
+		l.c.log.Error().Err(err).Str("subject", s.RawValue()).Msg("unable to resolve lod subject")
+		return nil, err
+	}
+
+	fgs := make([]*fragments.FragmentGraph, 0, len(res.Hits.Hits))
+
+	for _, hit := range res.Hits.Hits {
+		fg, err := decodeFragmentGraph(hit.Source)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode fragment graph %q; %w", hit.Id, err)
+		}
+
+		fgs = append(fgs, fg)
+	}
+
+	g, err := subjectGraph(s, fgs...)
+	if err != nil {
+		return nil, err
+	}
+
+	if g.Len() == 0 {
+		return nil, fmt.Errorf("%w: %s", lod.ErrResourceNotFound, s.RawValue())
+	}
+
+	return g, nil
+}
+
+// subjectGraph rebuilds the graph of the subject from the FragmentGraphs.
+// Starting from the subject all resources that are referenced from the
+// subject and are stored in the same FragmentGraph are added to the graph.
+func subjectGraph(s rdf.Subject, fgs ...*fragments.FragmentGraph) (*rdf.Graph, error) {
+	g := rdf.NewGraph()
+
+	for _, fg := range fgs {
+		resources := map[string]*fragments.FragmentResource{}
+		for _, rsc := range fg.Resources {
+			resources[rsc.ID] = rsc
+		}
+
+		seen := map[string]bool{}
+		queue := []string{s.RawValue()}
+
+		for len(queue) > 0 {
+			id := queue[0]
+			queue = queue[1:]
+
+			rsc, ok := resources[id]
+			if !ok || seen[id] {
+				continue
+			}
+
+			seen[id] = true
+
+			if err := rsc.AddTo(g); err != nil {
+				return nil, err
+			}
+
+			for _, entry := range rsc.Entries {
+				if entry.Inline != nil {
+					if _, ok := resources[entry.Inline.ID]; !ok {
+						resources[entry.Inline.ID] = entry.Inline
+					}
+				}
+
+				if entry.ID != "" {
+					queue = append(queue, entry.ID)
+				}
+			}
+		}
+	}
+
+	return g, nil
+}
@@ -0,0 +1,70 @@
+package elasticsearch
+
+import (
+	"os"
+	"testing"
+
+	"github.com/matryer/is"
+
+	"github.com/delving/hub3/ikuzo/rdf"
+)
+
+func TestSubjectGraph(t *testing.T) {
+	is := is.New(t)
+
+	b, err := os.ReadFile("./testdata/lod_graph.json")
+	is.NoErr(err)
+
+	fg, err := decodeFragmentGraph(b)
+	is.NoErr(err)
+
+	t.Run("subject with inlined resources", func(t *testing.T) {
+		is := is.New(t)
+
+		subj, err := rdf.NewIRI("http://example.org/doc/1")
+		is.NoErr(err)
+
+		g, err := subjectGraph(subj, fg)
+		is.NoErr(err)
+
+		// subject: 1 type and 4 entries; blank node: 1 entry; inlined concept: 1 type and 1 entry
+		is.Equal(g.Len(), 8)
+
+		bnode, err := rdf.NewBlankNode("b0")
+		is.NoErr(err)
+
+		var hasBlankNode bool
+
+		for _, triple := range g.Triples() {
+			is.True(triple.Subject.RawValue() != "http://example.org/aggregation/1")
+
+			if triple.Subject.Equal(bnode) {
+				hasBlankNode = true
+			}
+		}
+
+		is.True(hasBlankNode)
+	})
+
+	t.Run("resource that references the subject", func(t *testing.T) {
+		is := is.New(t)
+
+		subj, err := rdf.NewIRI("http://example.org/aggregation/1")
+		is.NoErr(err)
+
+		g, err := subjectGraph(subj, fg)
+		is.NoErr(err)
+		is.Equal(g.Len(), 10)
+	})
+
+	t.Run("unknown subject", func(t *testing.T) {
+		is := is.New(t)
+
+		subj, err := rdf.NewIRI("http://example.org/doc/2")
+		is.NoErr(err)
+
+		g, err := subjectGraph(subj, fg)
+		is.NoErr(err)
+		is.Equal(g.Len(), 0)
+	})
+}
@@ -7,30 +7,35 @@ import (
 
 	"github.com/delving/hub3/ikuzo/domain"
 	"github.com/delving/hub3/ikuzo/driver/elasticsearch/internal/mapping"
+	"github.com/delving/hub3/ikuzo/service/x/search"
 	"github.com/tidwall/gjson"
 )
 
 func (c *Client) createDefaultMappings(orgCfg domain.OrganizationConfig, withAlias, withReset bool) (indexNames []string, err error) {
+	lang, err := search.ParseLanguage(orgCfg.Analyzer.Language)
+	if err != nil {
+		return []string{}, err
+	}
+
 	mappings := map[string]func(shards, replicas int) string{}
+	// analyzed marks the indices that use the language analyzer of the organization
+	analyzed := map[string]bool{}
 
 	for _, indexType := range orgCfg.ElasticSearch.IndexTypes {
-		switch indexType {
-		case "v1":
-			mappings[orgCfg.GetV1IndexName()] = mapping.V1ESMapping
-		case "v2":
-			mappings[orgCfg.GetIndexName()] = mapping.V2ESMapping
-		case "fragments":
-			mappings[orgCfg.GetFragmentsIndexName()] = mapping.FragmentESMapping
-		case "suggest":
-			mappings[orgCfg.GetSuggestIndexName()] = mapping.V2SuggestMapping
-		default:
+		alias, m, isAnalyzed, ok := orgMapping(&orgCfg, indexType)
+		if !ok {
 			c.log.Warn().Msgf("ignoring unknown indexType %s during mapping creation", indexType)
+			continue
 		}
+
+		mappings[alias] = m
+		analyzed[alias] = isAnalyzed
 	}
 
 	if orgCfg.ElasticSearch.DigitalObjectSuffix != "" {
 		indexName := orgCfg.GetIndexName() + "-" + orgCfg.ElasticSearch.DigitalObjectSuffix
 		mappings[indexName] = mapping.V2ESMapping
+		analyzed[indexName] = true
 	}
 
 	indexNames = []string{}
@@ -61,9 +66,18 @@ func (c *Client) createDefaultMappings(orgCfg domain.OrganizationConfig, withAli
 			}
 		}
 
+		esMapping := m(orgCfg.ElasticSearch.Shards, orgCfg.ElasticSearch.Replicas)
+
+		if analyzed[indexName] {
+			esMapping, err = mapping.WithLanguage(esMapping, lang)
+			if err != nil {
+				return []string{}, err
+			}
+		}
+
 		createName, err := indices.Create(
 			indexName,
-			m(orgCfg.ElasticSearch.Shards, orgCfg.ElasticSearch.Replicas),
+			esMapping,
 			withAlias,
 		)
 
@@ -95,6 +109,26 @@ func (c *Client) createDefaultMappings(orgCfg domain.OrganizationConfig, withAli
 	return indexNames, nil
 }
 
+// orgMapping returns the alias and the mapping of the index type of the
+// organization. Analyzed is true when the mapping uses the language analyzer of
+// the organization.
+func orgMapping(orgCfg *domain.OrganizationConfig, indexType string) (
+	alias string, m func(shards, replicas int) string, analyzed, ok bool,
+) {
+	switch indexType {
+	case "v1":
+		return orgCfg.GetV1IndexName(), mapping.V1ESMapping, false, true
+	case "v2":
+		return orgCfg.GetIndexName(), mapping.V2ESMapping, true, true
+	case "fragments":
+		return orgCfg.GetFragmentsIndexName(), mapping.FragmentESMapping, false, true
+	case "suggest":
+		return orgCfg.GetSuggestIndexName(), mapping.V2SuggestMapping, false, true
+	}
+
+	return "", nil, false, false
+}
+
 func (c *Client) isMappingValid(indexName string) (bool, error) {
 	res, err := c.index.Indices.GetMapping(c.index.Indices.GetMapping.WithIndex(indexName))
 	if err != nil {
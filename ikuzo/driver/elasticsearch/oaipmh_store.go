@@ -4,16 +4,15 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 
 	"github.com/olivere/elastic/v7"
+	"github.com/tidwall/gjson"
 
 	"github.com/delving/hub3/hub3/fragments"
-	"github.com/delving/hub3/ikuzo/rdf"
-	"github.com/delving/hub3/ikuzo/rdf/formats/mappingxml"
-	"github.com/delving/hub3/ikuzo/rdf/formats/ntriples"
 	"github.com/delving/hub3/ikuzo/service/x/oaipmh"
+	"github.com/delving/hub3/ikuzo/service/x/oaipmh/crosswalk"
 )
 
 var _ oaipmh.Store = (*OAIPMHStore)(nil)
@@ -21,12 +20,14 @@ var _ oaipmh.Store = (*OAIPMHStore)(nil)
 type OAIPMHStore struct {
 	c            *Client
 	ResponseSize int
+	Crosswalks   *crosswalk.Registry // metadata formats that can be disseminated
 }
 
 func (c *Client) NewOAIPMHStore() (*OAIPMHStore, error) {
 	return &OAIPMHStore{
 		c:            c,
 		ResponseSize: 100, // default 100
+		Crosswalks:   crosswalk.NewDefaultRegistry(),
 	}, nil
 }
 
@@ -95,8 +96,9 @@ func (o *OAIPMHStore) ListSets(ctx context.Context, q *oaipmh.RequestConfig) (re
 }
 
 type recordWrapper struct {
-	HubID string
-	Data  json.RawMessage
+	HubID   string
+	Data    json.RawMessage
+	Deleted bool // Data is a tombstone
 }
 
 type resumableResponse struct {
@@ -105,6 +107,9 @@ type resumableResponse struct {
 	pitPayload string // payload for point in time parsing
 }
 
+// addFilters adds the tag filters and the datestamp range of the request to
+// the query. Invalid datestamps are reported by the callers, so they are
+// ignored here.
 func addFilters(q *oaipmh.RequestConfig, query *elastic.BoolQuery) *elastic.BoolQuery {
 	if len(q.Filters) > 0 {
 		fq := elastic.NewBoolQuery()
@@ -114,36 +119,102 @@ func addFilters(q *oaipmh.RequestConfig, query *elastic.BoolQuery) *elastic.Bool
 		query = query.Must(fq)
 	}
 
+	if dr, err := q.DateRange(); err == nil && !dr.IsZero() {
+		timeRange := elastic.NewRangeQuery("meta.modified").Format("epoch_millis")
+
+		if !dr.From.IsZero() {
+			timeRange = timeRange.Gte(dr.From.UnixMilli())
+		}
+
+		if !dr.Until.IsZero() {
+			timeRange = timeRange.Lte(dr.Until.UnixMilli())
+		}
+
+		query = query.Must(timeRange)
+	}
+
+	return query
+}
+
+// addFormatFilter limits the query to the records that the crosswalk of the
+// requested metadata format can transform.
+func addFormatFilter(cw crosswalk.Crosswalk, query *elastic.BoolQuery) *elastic.BoolQuery {
+	tags := cw.Tags()
+	if len(tags) == 0 {
+		return query
+	}
+
+	fq := elastic.NewBoolQuery()
+	for _, tag := range tags {
+		fq = fq.Should(elastic.NewTermQuery("meta.tags", tag))
+	}
+
+	return query.Must(fq)
+}
+
+// addSetFilter limits a harvest without a set to the sets that may
+// disseminate the requested metadata format.
+func addSetFilter(q *oaipmh.RequestConfig, query *elastic.BoolQuery) *elastic.BoolQuery {
+	terms := func(sets []string) *elastic.TermsQuery {
+		values := make([]interface{}, 0, len(sets))
+		for _, set := range sets {
+			values = append(values, set)
+		}
+
+		return elastic.NewTermsQuery("meta.spec", values...)
+	}
+
+	if q.IncludedSets != nil {
+		query = query.Must(terms(q.IncludedSets))
+	}
+
+	if len(q.ExcludedSets) != 0 {
+		query = query.MustNot(terms(q.ExcludedSets))
+	}
+
 	return query
 }
 
-func (o *OAIPMHStore) getRecords(ctx context.Context, q *oaipmh.RequestConfig, headersOnly bool) (resp resumableResponse, err error) {
+// addExpiryFilter excludes the tombstones that are older than the time from
+// which deleted records are reported.
+func addExpiryFilter(q *oaipmh.RequestConfig, query *elastic.BoolQuery) *elastic.BoolQuery {
+	since := q.DeletedSince()
+	if since.IsZero() {
+		return query
+	}
+
+	expired := elastic.NewBoolQuery().Must(
+		elastic.NewTermQuery("deleted", true),
+		elastic.NewRangeQuery(PathModified).Lt(since.UnixMilli()),
+	)
+
+	return query.MustNot(expired)
+}
+
+func (o *OAIPMHStore) getRecords(ctx context.Context, q *oaipmh.RequestConfig, cw crosswalk.Crosswalk, headersOnly bool) (resp resumableResponse, err error) {
 	query := elastic.NewBoolQuery().
 		Must(elastic.NewTermQuery("meta.orgID", q.OrgID))
 
 	query = addFilters(q, query)
+	query = addFormatFilter(cw, query)
 
 	if q.DatasetID != "" {
 		query = query.Must(elastic.NewTermQuery("meta.spec", q.DatasetID))
 	}
 
-	if q.FirstRequest.From != "" || q.FirstRequest.Until != "" {
+	query = addSetFilter(q, query)
+	query = addExpiryFilter(q, query)
 
-		timeRange := elastic.NewRangeQuery("meta.modified")
-		if q.FirstRequest.From != "" {
-			timeRange = timeRange.Gte(q.FirstRequest.From)
-		}
+	if q.CurrentRequest.HarvestID == "" {
+		indices := []string{IndexNames{}.GetIndexName(q.OrgID)}
 
-		if q.FirstRequest.Until != "" {
-			timeRange = timeRange.Lte(q.FirstRequest.Until)
+		if q.TrackDeleted() {
+			indices = append(indices, IndexNames{}.GetTombstoneIndexName(q.OrgID))
 		}
 
-		query = query.Must(timeRange)
-	}
-
-	if q.CurrentRequest.HarvestID == "" {
-		openResp, openErr := o.c.search.OpenPointInTime(IndexNames{}.GetIndexName(q.OrgID)).
+		openResp, openErr := o.c.search.OpenPointInTime(indices...).
 			KeepAlive("1m").
+			IgnoreUnavailable(true).
 			Pretty(true).
 			Do(context.Background())
 		if openErr != nil {
@@ -161,7 +232,7 @@ func (o *OAIPMHStore) getRecords(ctx context.Context, q *oaipmh.RequestConfig, h
 
 	if headersOnly {
 		fsc := elastic.NewFetchSourceContext(true)
-		fsc.Include("meta")
+		fsc.Include("meta", "deleted")
 		search = search.FetchSourceContext(fsc)
 	}
 
@@ -189,8 +260,9 @@ func (o *OAIPMHStore) getRecords(ctx context.Context, q *oaipmh.RequestConfig, h
 	var hit *elastic.SearchHit
 	for _, hit = range res.Hits.Hits {
 		wrapper := recordWrapper{
-			HubID: hit.Id,
-			Data:  hit.Source,
+			HubID:   hit.Id,
+			Data:    hit.Source,
+			Deleted: gjson.GetBytes(hit.Source, "deleted").Bool(),
 		}
 		resp.records = append(resp.records, wrapper)
 	}
@@ -208,13 +280,24 @@ func (o *OAIPMHStore) getRecords(ctx context.Context, q *oaipmh.RequestConfig, h
 }
 
 func (o *OAIPMHStore) ListIdentifiers(ctx context.Context, q *oaipmh.RequestConfig) (res oaipmh.Resumable, err error) {
-	resp, err := o.getRecords(ctx, q, true)
+	cw, err := o.Crosswalks.Get(q.FirstRequest.MetadataPrefix)
+	if err != nil {
+		res.Errors = append(res.Errors, oaipmh.ErrCannotDisseminateFormat)
+		return res, nil
+	}
+
+	if _, rangeErr := q.DateRange(); rangeErr != nil {
+		res.Errors = append(res.Errors, oaipmh.ErrBadArgument)
+		return res, nil
+	}
+
+	resp, err := o.getRecords(ctx, q, cw, true)
 	if err != nil {
 		return
 	}
 
 	for _, wrapper := range resp.records {
-		rec, getErr := o.getOAIPMHRecord(wrapper, q.FirstRequest.MetadataPrefix, true)
+		rec, getErr := o.getOAIPMHRecord(ctx, wrapper, cw, true)
 		if getErr != nil {
 			return res, getErr
 		}
@@ -229,10 +312,21 @@ func (o *OAIPMHStore) ListIdentifiers(ctx context.Context, q *oaipmh.RequestConf
 }
 
 func (o *OAIPMHStore) ListRecords(ctx context.Context, q *oaipmh.RequestConfig) (res oaipmh.Resumable, err error) {
-	resp, err := o.getRecords(ctx, q, false)
+	cw, err := o.Crosswalks.Get(q.FirstRequest.MetadataPrefix)
+	if err != nil {
+		res.Errors = append(res.Errors, oaipmh.ErrCannotDisseminateFormat)
+		return res, nil
+	}
+
+	if _, rangeErr := q.DateRange(); rangeErr != nil {
+		res.Errors = append(res.Errors, oaipmh.ErrBadArgument)
+		return res, nil
+	}
+
+	resp, err := o.getRecords(ctx, q, cw, false)
 
 	for _, raw := range resp.records {
-		rec, getErr := o.getOAIPMHRecord(raw, q.FirstRequest.MetadataPrefix, true)
+		rec, getErr := o.getOAIPMHRecord(ctx, raw, cw, false)
 		if getErr != nil {
 			return res, getErr
 		}
@@ -246,9 +340,15 @@ func (o *OAIPMHStore) ListRecords(ctx context.Context, q *oaipmh.RequestConfig)
 	return res, err
 }
 
-func (o *OAIPMHStore) GetRecord(ctx context.Context, q *oaipmh.RequestConfig) (record oaipmh.Record, errors []oaipmh.Error, err error) {
+func (o *OAIPMHStore) GetRecord(ctx context.Context, q *oaipmh.RequestConfig) (record oaipmh.Record, pmhErrors []oaipmh.Error, err error) {
 	if q.FirstRequest.Identifier == "" {
-		errors = append(errors, oaipmh.ErrIdDoesNotExist)
+		pmhErrors = append(pmhErrors, oaipmh.ErrIdDoesNotExist)
+		return
+	}
+
+	cw, cwErr := o.Crosswalks.Get(q.FirstRequest.MetadataPrefix)
+	if cwErr != nil {
+		pmhErrors = append(pmhErrors, oaipmh.ErrCannotDisseminateFormat)
 		return
 	}
 
@@ -258,106 +358,143 @@ func (o *OAIPMHStore) GetRecord(ctx context.Context, q *oaipmh.RequestConfig) (r
 
 	res, err := search.Do(ctx)
 	if err != nil {
-		o.c.log.Error().Err(err).Msg("unable to get record")
-		return
+		if !elastic.IsNotFound(err) {
+			o.c.log.Error().Err(err).Msg("unable to get record")
+			return
+		}
+
+		res, err = o.getTombstone(ctx, q)
+		if err != nil {
+			return
+		}
+
+		if res == nil {
+			pmhErrors = append(pmhErrors, oaipmh.ErrIdDoesNotExist)
+			return
+		}
 	}
 
 	wrapper := recordWrapper{
-		HubID: res.Id,
-		Data:  res.Source,
+		HubID:   res.Id,
+		Data:    res.Source,
+		Deleted: gjson.GetBytes(res.Source, "deleted").Bool(),
 	}
 
-	record, err = o.getOAIPMHRecord(wrapper, q.FirstRequest.MetadataPrefix, false)
+	record, err = o.getOAIPMHRecord(ctx, wrapper, cw, false)
 	if err != nil {
+		if errors.Is(err, oaipmh.ErrCannotDisseminateFormat) {
+			return record, append(pmhErrors, oaipmh.ErrCannotDisseminateFormat), nil
+		}
+
 		o.c.log.Error().Err(err).Msg("unable to serialize record")
 		return
 	}
 
-	return record, errors, err
+	return record, pmhErrors, err
 }
 
-func (o *OAIPMHStore) serialize(format string, fg *fragments.FragmentGraph, w io.Writer) error {
-	g, err := fg.Graph()
-	if err != nil {
-		o.c.log.Error().Err(err).Msg("unable to get rdf.Graph")
-		return err
+// getTombstone returns the tombstone of the requested record. When deleted
+// records are not tracked or there is no tombstone that has not expired, nil
+// is returned.
+func (o *OAIPMHStore) getTombstone(ctx context.Context, q *oaipmh.RequestConfig) (*elastic.GetResult, error) {
+	if !q.TrackDeleted() {
+		return nil, nil
 	}
 
-	switch format {
-	case "ntriples":
-		fmt.Fprintln(w, "<!CDATA[")
-
-		if err := ntriples.Serialize(g, w); err != nil {
-			return err
-		}
-
-		fmt.Fprintln(w, "]]>")
-
-		return nil
-	case "rdfxml", "oai_dc":
-		iri, err := rdf.NewIRI(fg.Meta.GetEntryURI())
-		if err != nil {
-			return err
+	res, err := o.c.search.Get().
+		Index(IndexNames{}.GetTombstoneIndexName(q.OrgID)).
+		Id(q.FirstRequest.Identifier).
+		Do(ctx)
+	if err != nil {
+		if elastic.IsNotFound(err) {
+			return nil, nil
 		}
 
-		cfg := &mappingxml.FilterConfig{
-			Subject:         iri,
-			URIPrefixFilter: "urn:private",
-		}
+		o.c.log.Error().Err(err).Msg("unable to get tombstone")
 
-		err = mappingxml.Serialize(g, w, cfg)
-		if err != nil {
-			o.c.log.Error().Err(err).Msg("unable to get serialize mappingxml")
-			return err
-		}
+		return nil, err
+	}
 
-		return nil
+	if since := q.DeletedSince(); !since.IsZero() && gjson.GetBytes(res.Source, PathModified).Int() < since.UnixMilli() {
+		return nil, nil
 	}
 
-	return oaipmh.ErrCannotDisseminateFormat
+	return res, nil
 }
 
-func (o *OAIPMHStore) getOAIPMHRecord(wrapper recordWrapper, format string, onlyHeader bool) (record oaipmh.Record, err error) {
+func (o *OAIPMHStore) getOAIPMHRecord(ctx context.Context, wrapper recordWrapper, cw crosswalk.Crosswalk, onlyHeader bool) (record oaipmh.Record, err error) {
 	fg, err := decodeFragmentGraph(wrapper.Data)
 	if err != nil {
 		o.c.log.Error().Err(err).Str("hubID", wrapper.HubID).RawJSON("rawMessage", wrapper.Data).Msg("unable to get FragmentGraph")
 		return record, fmt.Errorf("unable to decode oai-pmh record %q; %w", wrapper.HubID, err)
 	}
 
+	record.Header.Identifier = fg.Meta.HubID
+	record.Header.DateStamp = fg.Meta.LastModified().UTC().Format(oaipmh.TimeFormat)
+	record.Header.SetSpec = []string{fg.Meta.Spec}
+
+	if wrapper.Deleted {
+		record.Header.Status = "deleted"
+		return record, nil
+	}
+
+	if !crosswalk.Accepts(cw, fg.Meta.Tags) {
+		return record, oaipmh.ErrCannotDisseminateFormat
+	}
+
+	if onlyHeader {
+		return record, nil
+	}
+
 	var buf bytes.Buffer
 
-	if err := o.serialize(format, fg, &buf); err != nil {
+	if err := cw.Transform(ctx, crosswalk.NewRecord(fg), &buf); err != nil {
+		o.c.log.Error().Err(err).Str("hubID", wrapper.HubID).Str("format", cw.Format().MetadataPrefix).Msg("unable to transform record")
 		return record, err
 	}
 
-	record.Header.Identifier = fg.Meta.HubID
-	record.Header.DateStamp = fg.Meta.LastModified().UTC().Format(oaipmh.TimeFormat)
-	record.Header.SetSpec = []string{fg.Meta.Spec}
 	record.Metadata = oaipmh.Metadata{Body: buf.Bytes()}
 
 	return record, nil
 }
 
+// ListMetadataFormats returns the formats of the registered crosswalks. When
+// an identifier is given, only the formats that can be disseminated for the
+// record are returned.
 func (o *OAIPMHStore) ListMetadataFormats(ctx context.Context, q *oaipmh.RequestConfig) (formats []oaipmh.MetadataFormat, err error) {
-	formats = []oaipmh.MetadataFormat{
-		// {
-		// MetadataPrefix:    "ntriples",
-		// Schema:            "",
-		// MetadataNamespace: "http://www.europeana.eu/schemas/edm/",
-		// },
-		{
-			MetadataPrefix:    "rdfxml",
-			Schema:            "",
-			MetadataNamespace: "http://www.w3.org/1999/02/22-rdf-syntax-ns#",
-		},
-		{
-			MetadataPrefix:    "oai_dc",
-			Schema:            "http://www.openarchives.org/OAI/2.0/oai_dc.xsd",
-			MetadataNamespace: "http://www.openarchives.org/OAI/2.0/oai_dc/",
-		},
-	}
-
-	return formats, err
+	if q.FirstRequest == nil || q.FirstRequest.Identifier == "" {
+		return o.Crosswalks.Formats(), nil
+	}
+
+	fsc := elastic.NewFetchSourceContext(true).Include("meta.spec", "meta.tags")
+
+	res, err := o.c.search.Get().
+		Index(IndexNames{}.GetIndexName(q.OrgID)).
+		Id(q.FirstRequest.Identifier).
+		FetchSourceContext(fsc).
+		Do(ctx)
+	if err != nil {
+		if elastic.IsNotFound(err) {
+			return formats, oaipmh.ErrIdDoesNotExist
+		}
+
+		return formats, err
+	}
+
+	spec := gjson.GetBytes(res.Source, "meta.spec").String()
+
+	var tags []string
+	for _, tag := range gjson.GetBytes(res.Source, "meta.tags").Array() {
+		tags = append(tags, tag.String())
+	}
+
+	for _, format := range o.Crosswalks.FormatsFor(tags) {
+		if q.AllowsFormat(spec, format.MetadataPrefix) {
+			formats = append(formats, format)
+		}
+	}
+
+	return formats, nil
 }
 
 func decodeFragmentGraph(hit json.RawMessage) (*fragments.FragmentGraph, error) {
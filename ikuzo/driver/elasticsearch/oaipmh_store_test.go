@@ -1,10 +1,17 @@
 package elasticsearch
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"os"
+	"strings"
 	"testing"
 
+	"github.com/delving/hub3/ikuzo/service/x/oaipmh"
+	"github.com/delving/hub3/ikuzo/service/x/oaipmh/crosswalk"
 	"github.com/matryer/is"
+	"github.com/olivere/elastic/v7"
 )
 
 func ParseFragmentGraph(t *testing.T) {
@@ -15,13 +22,104 @@ func ParseFragmentGraph(t *testing.T) {
 
 	store := OAIPMHStore{}
 
-	record, err := store.getOAIPMHRecord(recordWrapper{
+	record, err := store.getOAIPMHRecord(context.TODO(), recordWrapper{
 		HubID: "123",
 		Data:  f,
 	},
-		"oai_dc",
+		crosswalk.NewOAIDC(),
 		false)
 
 	is.NoErr(err)
 	is.True(len(record.Metadata.Body) > 0)
 }
+
+func TestOAIPMHStore_deletedRecord(t *testing.T) {
+	is := is.New(t)
+
+	store := OAIPMHStore{}
+
+	record, err := store.getOAIPMHRecord(context.TODO(), recordWrapper{
+		HubID:   "hub3_ds1_1",
+		Data:    []byte(`{"meta":{"hubID":"hub3_ds1_1","spec":"ds1","modified":1609459200000},"deleted":true}`),
+		Deleted: true,
+	},
+		crosswalk.NewOAIDC(),
+		false)
+
+	is.NoErr(err)
+	is.Equal(record.Header.Identifier, "hub3_ds1_1")
+	is.Equal(record.Header.Status, "deleted")
+	is.Equal(record.Header.SetSpec, []string{"ds1"})
+	is.Equal(record.Header.DateStamp, "2021-01-01T00:00:00Z")
+	is.Equal(len(record.Metadata.Body), 0)
+}
+
+func TestOAIPMHStore_crosswalkTags(t *testing.T) {
+	is := is.New(t)
+
+	store := OAIPMHStore{}
+	ead := crosswalk.NewEAD(nil)
+	data := []byte(`{"meta":{"hubID":"hub3_ds1_1","spec":"ds1","tags":["mdr"],"modified":1609459200000}}`)
+
+	_, err := store.getOAIPMHRecord(context.TODO(), recordWrapper{HubID: "hub3_ds1_1", Data: data}, ead, false)
+	is.True(errors.Is(err, oaipmh.ErrCannotDisseminateFormat))
+
+	record, err := store.getOAIPMHRecord(context.TODO(), recordWrapper{HubID: "hub3_ds1_1", Data: data}, crosswalk.NewOAIDC(), true)
+	is.NoErr(err)
+	is.Equal(record.Header.Identifier, "hub3_ds1_1")
+	is.Equal(len(record.Metadata.Body), 0) // only the header is returned
+}
+
+func TestAddFilters(t *testing.T) {
+	is := is.New(t)
+
+	q := &oaipmh.RequestConfig{
+		FirstRequest: &oaipmh.Request{From: "2021-01-01", Until: "2021-01-01"},
+		Filters:      []string{"narthex"},
+	}
+
+	src, err := addFilters(q, elastic.NewBoolQuery()).Source()
+	is.NoErr(err)
+
+	b, err := json.Marshal(src)
+	is.NoErr(err)
+
+	is.Equal(
+		string(b),
+		`{"bool":{"must":[{"bool":{"should":{"term":{"meta.tags":"narthex"}}}},`+
+			`{"range":{"meta.modified":{"format":"epoch_millis","from":1609459200000,"include_lower":true,"include_upper":true,"to":1609545599999}}}]}}`,
+	)
+
+	q.FirstRequest = &oaipmh.Request{From: "2021-13-01"}
+
+	src, err = addFilters(q, elastic.NewBoolQuery()).Source()
+	is.NoErr(err)
+
+	b, err = json.Marshal(src)
+	is.NoErr(err)
+	is.True(!strings.Contains(string(b), "range")) // invalid ranges are reported by the callers
+}
+
+func TestAddSetFilter(t *testing.T) {
+	is := is.New(t)
+
+	source := func(q *oaipmh.RequestConfig) string {
+		src, err := addSetFilter(q, elastic.NewBoolQuery()).Source()
+		is.NoErr(err)
+
+		b, err := json.Marshal(src)
+		is.NoErr(err)
+
+		return string(b)
+	}
+
+	is.Equal(source(&oaipmh.RequestConfig{}), `{"bool":{}}`)
+	is.Equal(
+		source(&oaipmh.RequestConfig{ExcludedSets: []string{"archive", "photos"}}),
+		`{"bool":{"must_not":{"terms":{"meta.spec":["archive","photos"]}}}}`,
+	)
+	is.Equal(
+		source(&oaipmh.RequestConfig{IncludedSets: []string{"archive"}}),
+		`{"bool":{"must":{"terms":{"meta.spec":["archive"]}}}}`,
+	)
+}
@@ -5,4 +5,5 @@ const (
 	PathDatasetID string = "meta.spec"
 	PathRevision  string = "meta.revision"
 	PathTags      string = "meta.tags"
+	PathModified  string = "meta.modified"
 )
@@ -37,6 +37,15 @@ func NewQueryBuilder(defaultFields ...QueryField) *QueryBuilder {
 }
 
 func (qb *QueryBuilder) NewElasticQuery(q *search.QueryTerm) elastic.Query {
+	if q.Type() == search.RangeQuery {
+		fields := qb.defaultFields
+		if q.Field != "" {
+			fields = []QueryField{{Field: q.Field}}
+		}
+
+		return buildFieldQueries(q, fields, buildRangeQuery)
+	}
+
 	if !q.IsBoolQuery() && q.Value == "" {
 		return elastic.NewMatchAllQuery()
 	}
@@ -116,3 +125,33 @@ func buildMatchPhraseQuery(q *search.QueryTerm, field QueryField) elastic.Query
 
 	return esq
 }
+
+// buildRangeQuery returns a range query. Unbounded ends are omitted, and date
+// math such as 'now-10y' is passed on to be resolved by elasticsearch.
+func buildRangeQuery(q *search.QueryTerm, field QueryField) elastic.Query {
+	esq := elastic.NewRangeQuery(field.Field)
+
+	if q.Range.Lower != "" {
+		if q.Range.IncludeLower {
+			esq = esq.Gte(q.Range.Lower)
+		} else {
+			esq = esq.Gt(q.Range.Lower)
+		}
+	}
+
+	if q.Range.Upper != "" {
+		if q.Range.IncludeUpper {
+			esq = esq.Lte(q.Range.Upper)
+		} else {
+			esq = esq.Lt(q.Range.Upper)
+		}
+	}
+
+	if q.Boost != 0 {
+		esq = esq.Boost(q.Boost)
+	} else if field.Boost != 0 {
+		esq = esq.Boost(field.Boost)
+	}
+
+	return esq
+}
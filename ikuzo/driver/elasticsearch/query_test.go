@@ -81,6 +81,13 @@ func TestNewBoolQuery(t *testing.T) {
 			false,
 			`{"bool":{"must_not":{"match":{"full_text":{"query":"one"}}}}}`,
 		},
+		{
+			"field range query",
+			fields{[]QueryField{{Field: "full_text"}}},
+			args{q: "date:[1900 TO now-10y}"},
+			false,
+			`{"bool":{"should":{"range":{"date":{"from":"1900","include_lower":true,"include_upper":false,"to":"now-10y"}}}}}`,
+		},
 		{
 			"mix query",
 			fields{[]QueryField{{Field: "full_text"}}},
@@ -208,6 +215,14 @@ func TestNewElasticQuery(t *testing.T) {
 				`{"match":{"subject":{"query":"word"}}}` +
 				`]}}`,
 		},
+		{
+			"open-ended range on default field",
+			fields{[]QueryField{{Field: "year", Boost: 2}}},
+			args{&search.QueryTerm{
+				Range: &search.Range{Lower: "2000", IncludeLower: true},
+			}},
+			`{"range":{"year":{"boost":2,"from":"2000","include_lower":true,"include_upper":true,"to":null}}}`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,448 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/delving/hub3/ikuzo/domain"
+	"github.com/delving/hub3/ikuzo/driver/elasticsearch/internal/mapping"
+	"github.com/delving/hub3/ikuzo/service/x/reindex"
+	"github.com/delving/hub3/ikuzo/service/x/search"
+	"github.com/elastic/go-elasticsearch/v8/esutil"
+	"github.com/olivere/elastic/v7"
+	"github.com/tidwall/gjson"
+)
+
+var _ reindex.Backend = (*ReindexBackend)(nil)
+
+// reindexPollInterval is the interval in which the progress of the
+// elasticsearch reindex task is retrieved.
+const reindexPollInterval = 2 * time.Second
+
+// reindexCatchUpMargin is subtracted from the start of a reindex to select the
+// documents that are caught up after the alias is switched. It covers records
+// that were stamped before the start but indexed after it.
+const reindexCatchUpMargin = 5 * time.Minute
+
+// reindexCatchUpSize is the number of documents that are caught up per bulk
+// request.
+const reindexCatchUpSize = 500
+
+// ReindexBackend implements the reindex.Backend with versioned indices behind
+// an alias.
+type ReindexBackend struct {
+	client *Client
+}
+
+func (c *Client) NewReindexBackend() *ReindexBackend {
+	return &ReindexBackend{
+		client: c,
+	}
+}
+
+func (b *ReindexBackend) CurrentIndex(alias string) (string, error) {
+	a := b.client.Alias()
+
+	indexName, err := a.Get(alias)
+	if errors.Is(err, ErrAliasNotFound) {
+		return "", nil
+	}
+
+	return indexName, err
+}
+
+func (b *ReindexBackend) CreateIndex(alias, esMapping string) (string, error) {
+	indices := b.client.Indices()
+	return indices.Create(alias, esMapping, false)
+}
+
+func (b *ReindexBackend) Count(ctx context.Context, indexName string) (int64, error) {
+	if _, err := b.client.search.Refresh(indexName).Do(ctx); err != nil {
+		return 0, fmt.Errorf("unable to refresh index; %w", err)
+	}
+
+	return b.client.search.Count(indexName).Do(ctx)
+}
+
+func (b *ReindexBackend) SwitchAlias(alias, indexName string) error {
+	oldIndexName, err := b.CurrentIndex(alias)
+	if err != nil {
+		return err
+	}
+
+	a := b.client.Alias()
+
+	if oldIndexName == "" {
+		return a.Create(alias, indexName)
+	}
+
+	_, err = a.Update(alias, indexName)
+
+	return err
+}
+
+func (b *ReindexBackend) DeleteIndex(indexName string) error {
+	indices := b.client.Indices()
+	return indices.Delete(indexName)
+}
+
+// Mapping returns the alias and mapping of the index type. The v2 mapping uses
+// the language analyzer of the organization.
+func (b *ReindexBackend) Mapping(cfg *domain.OrganizationConfig, indexType string) (alias, esMapping string, err error) {
+	alias, m, analyzed, ok := orgMapping(cfg, indexType)
+	if !ok {
+		return "", "", fmt.Errorf("%w: %s", reindex.ErrUnknownIndex, indexType)
+	}
+
+	esMapping = m(cfg.ElasticSearch.Shards, cfg.ElasticSearch.Replicas)
+
+	if analyzed {
+		lang, err := search.ParseLanguage(cfg.Analyzer.Language)
+		if err != nil {
+			return "", "", err
+		}
+
+		esMapping, err = mapping.WithLanguage(esMapping, lang)
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	return alias, esMapping, nil
+}
+
+// CatchUp copies the documents of fromIndex that are modified since the start
+// of the reindex to toIndex. A document is only written when toIndex does not
+// have it or has an older version, and the write is conditional on the
+// sequence number, so concurrent writes via the alias are never overwritten.
+// Documents that are deleted from fromIndex during the reindex are not
+// removed from toIndex.
+func (b *ReindexBackend) CatchUp(ctx context.Context, fromIndex, toIndex string, since time.Time) (int64, error) {
+	// records are stamped with meta.modified before they are bulk indexed
+	since = since.Add(-reindexCatchUpMargin)
+
+	if _, err := b.client.search.Refresh(fromIndex).Do(ctx); err != nil {
+		return 0, fmt.Errorf("unable to refresh index %s; %w", fromIndex, err)
+	}
+
+	scroll := b.client.search.Scroll(fromIndex).
+		Query(elastic.NewRangeQuery("meta.modified").Gte(since.UnixMilli())).
+		Size(reindexCatchUpSize)
+
+	defer func() {
+		if err := scroll.Clear(context.Background()); err != nil {
+			b.client.log.Warn().Err(err).Msg("reindex: unable to clear catch-up scroll")
+		}
+	}()
+
+	var copied int64
+
+	for {
+		res, err := scroll.Do(ctx)
+		if errors.Is(err, io.EOF) {
+			return copied, nil
+		}
+
+		if err != nil {
+			return copied, fmt.Errorf("unable to scroll index %s; %w", fromIndex, err)
+		}
+
+		n, err := b.catchUp(ctx, toIndex, res.Hits.Hits)
+		copied += n
+
+		if err != nil {
+			return copied, err
+		}
+	}
+}
+
+// catchUp writes the hits to the index that are missing or newer than the
+// version in the index.
+func (b *ReindexBackend) catchUp(ctx context.Context, indexName string, hits []*elastic.SearchHit) (int64, error) {
+	if len(hits) == 0 {
+		return 0, nil
+	}
+
+	mget := b.client.search.MultiGet()
+
+	for _, hit := range hits {
+		mget.Add(
+			elastic.NewMultiGetItem().
+				Index(indexName).
+				Id(hit.Id).
+				FetchSource(elastic.NewFetchSourceContext(true).Include("meta.modified")),
+		)
+	}
+
+	current, err := mget.Do(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("unable to get documents from index %s; %w", indexName, err)
+	}
+
+	bulk := b.client.search.Bulk()
+
+	for idx, hit := range hits {
+		req := elastic.NewBulkIndexRequest().Index(indexName).Id(hit.Id).Doc(hit.Source)
+
+		doc := current.Docs[idx]
+
+		switch {
+		case !doc.Found:
+			req.OpType("create")
+		case gjson.GetBytes(doc.Source, "meta.modified").Int() >= gjson.GetBytes(hit.Source, "meta.modified").Int():
+			continue
+		case doc.SeqNo != nil && doc.PrimaryTerm != nil:
+			req.IfSeqNo(*doc.SeqNo).IfPrimaryTerm(*doc.PrimaryTerm)
+		}
+
+		bulk.Add(req)
+	}
+
+	if bulk.NumberOfActions() == 0 {
+		return 0, nil
+	}
+
+	res, err := bulk.Do(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("unable to catch up documents in index %s; %w", indexName, err)
+	}
+
+	// a conflict means the document is written via the alias in the meantime
+	for _, item := range res.Failed() {
+		if item.Status != http.StatusConflict {
+			reason := ""
+			if item.Error != nil {
+				reason = item.Error.Reason
+			}
+
+			return int64(len(res.Succeeded())), fmt.Errorf("unable to catch up document %s: %s", item.Id, reason)
+		}
+	}
+
+	return int64(len(res.Succeeded())), nil
+}
+
+// IndexSource returns a reindex.Source that copies the documents of the index
+// or alias with the elasticsearch reindex API.
+func (b *ReindexBackend) IndexSource(indexName string) reindex.Source {
+	return &indexSource{client: b.client, indexName: indexName}
+}
+
+// DirSource returns a reindex.Source that indexes the JSON search records in
+// the directory and its subdirectories. The document ID is read from
+// 'meta.hubID'.
+func (b *ReindexBackend) DirSource(path string) reindex.Source {
+	return &dirSource{client: b.client, path: path}
+}
+
+type indexSource struct {
+	client    *Client
+	indexName string
+}
+
+// reindexStatus is the status of a running elasticsearch reindex task.
+type reindexStatus struct {
+	Total   int64 `json:"total"`
+	Created int64 `json:"created"`
+	Updated int64 `json:"updated"`
+}
+
+// Populate copies the documents of the source index. The writes that are
+// made to the index behind the alias during the copy are caught up after the
+// alias is switched (see CatchUp).
+func (src *indexSource) Populate(ctx context.Context, indexName string, progress reindex.ProgressFunc) (int64, error) {
+	status, err := src.copy(ctx, indexName, progress)
+	if err != nil {
+		return 0, err
+	}
+
+	return status.Total, nil
+}
+
+// copy runs an elasticsearch reindex task for the documents of the source
+// index and waits for it to complete. The final status of the task is
+// returned.
+func (src *indexSource) copy(ctx context.Context, indexName string, progress reindex.ProgressFunc) (*reindexStatus, error) {
+	source := elastic.NewReindexSource().Index(src.indexName)
+
+	task, err := src.client.search.Reindex().
+		Source(source).
+		DestinationIndex(indexName).
+		WaitForCompletion(false).
+		DoAsync(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to start reindex task; %w", err)
+	}
+
+	ticker := time.NewTicker(reindexPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if _, err := src.client.search.TasksCancel().TaskId(task.TaskId).Do(context.Background()); err != nil {
+				src.client.log.Error().Err(err).Str("taskID", task.TaskId).Msg("unable to cancel reindex task")
+			}
+
+			return nil, ctx.Err()
+		case <-ticker.C:
+			res, err := src.client.search.TasksGetTask().TaskId(task.TaskId).Do(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("unable to get reindex task; %w", err)
+			}
+
+			if res.Error != nil {
+				return nil, fmt.Errorf("reindex task failed: %s", res.Error.Reason)
+			}
+
+			status := &reindexStatus{}
+
+			if res.Task != nil {
+				status, err = decodeReindexStatus(res.Task.Status)
+				if err != nil {
+					return nil, err
+				}
+
+				progress(reindex.Progress{Total: status.Total, Done: status.Created + status.Updated})
+			}
+
+			if res.Completed {
+				if done := status.Created + status.Updated; done != status.Total {
+					return nil, fmt.Errorf("%w: reindex task copied %d of %d documents", reindex.ErrCountMismatch, done, status.Total)
+				}
+
+				return status, nil
+			}
+		}
+	}
+}
+
+func decodeReindexStatus(status interface{}) (*reindexStatus, error) {
+	b, err := json.Marshal(status)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode reindex status; %w", err)
+	}
+
+	var s reindexStatus
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, fmt.Errorf("unable to decode reindex status; %w", err)
+	}
+
+	return &s, nil
+}
+
+type dirSource struct {
+	client *Client
+	path   string
+}
+
+// files returns the JSON files in the directory.
+func (src *dirSource) files() ([]string, error) {
+	files := []string{}
+
+	err := filepath.Walk(src.path, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() || !strings.HasSuffix(info.Name(), ".json") || info.Size() == 0 {
+			return nil
+		}
+
+		files = append(files, path)
+
+		return nil
+	})
+
+	return files, err
+}
+
+func (src *dirSource) Populate(ctx context.Context, indexName string, progress reindex.ProgressFunc) (int64, error) {
+	files, err := src.files()
+	if err != nil {
+		return 0, fmt.Errorf("unable to read record directory %s; %w", src.path, err)
+	}
+
+	bi, err := esutil.NewBulkIndexer(esutil.BulkIndexerConfig{
+		Client:        src.client.index,
+		Index:         indexName,
+		FlushInterval: 5 * time.Second,
+		OnError: func(ctx context.Context, err error) {
+			src.client.log.Error().Err(err).Msg("reindex: bulk indexing error")
+		},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("unable to create bulk indexer; %w", err)
+	}
+
+	total := int64(len(files))
+
+	var (
+		expected int64
+		done     int64
+	)
+
+	for _, path := range files {
+		if ctx.Err() != nil {
+			break
+		}
+
+		b, readErr := os.ReadFile(path)
+		if readErr != nil {
+			err = fmt.Errorf("unable to read record %s; %w", path, readErr)
+			break
+		}
+
+		id := gjson.GetBytes(b, "meta.hubID").String()
+		if id == "" {
+			src.client.log.Warn().Str("path", path).Msg("reindex: skipping record without meta.hubID")
+			atomic.AddInt64(&done, 1)
+
+			continue
+		}
+
+		expected++
+
+		addErr := bi.Add(ctx, esutil.BulkIndexerItem{
+			Action:     "index",
+			DocumentID: id,
+			Body:       bytes.NewReader(b),
+			OnSuccess: func(ctx context.Context, item esutil.BulkIndexerItem, res esutil.BulkIndexerResponseItem) {
+				progress(reindex.Progress{Total: total, Done: atomic.AddInt64(&done, 1)})
+			},
+			OnFailure: func(ctx context.Context, item esutil.BulkIndexerItem, res esutil.BulkIndexerResponseItem, err error) {
+				atomic.AddInt64(&done, 1)
+				src.client.log.Error().Err(err).Str("id", item.DocumentID).
+					Str("reason", res.Error.Reason).Msg("reindex: unable to index record")
+			},
+		})
+		if addErr != nil {
+			err = fmt.Errorf("unable to add record %s; %w", path, addErr)
+			break
+		}
+	}
+
+	if closeErr := bi.Close(context.Background()); closeErr != nil && err == nil {
+		err = fmt.Errorf("unable to flush bulk indexer; %w", closeErr)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	if ctx.Err() != nil {
+		return 0, ctx.Err()
+	}
+
+	return expected, nil
+}
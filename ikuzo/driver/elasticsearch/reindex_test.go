@@ -0,0 +1,63 @@
+package elasticsearch
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/delving/hub3/ikuzo/domain"
+	"github.com/delving/hub3/ikuzo/service/x/reindex"
+	"github.com/matryer/is"
+)
+
+func TestReindexBackend_Mapping(t *testing.T) {
+	is := is.New(t)
+
+	b := (&Client{}).NewReindexBackend()
+
+	cfg := &domain.OrganizationConfig{}
+	cfg.ElasticSearch.IndexName = "Demo"
+	cfg.Analyzer.Language = "nl"
+
+	alias, esMapping, err := b.Mapping(cfg, "v2")
+	is.NoErr(err)
+	is.Equal(alias, "demov2")
+	is.True(esMapping != "")
+
+	alias, _, err = b.Mapping(cfg, "fragments")
+	is.NoErr(err)
+	is.Equal(alias, "demov2_frag")
+
+	_, _, err = b.Mapping(cfg, "v3")
+	is.True(errors.Is(err, reindex.ErrUnknownIndex))
+}
+
+func TestDecodeReindexStatus(t *testing.T) {
+	is := is.New(t)
+
+	status, err := decodeReindexStatus(map[string]interface{}{
+		"total":   float64(10),
+		"created": float64(6),
+		"updated": float64(1),
+		"batches": float64(1),
+	})
+	is.NoErr(err)
+	is.Equal(*status, reindexStatus{Total: 10, Created: 6, Updated: 1})
+}
+
+func TestDirSource_files(t *testing.T) {
+	is := is.New(t)
+
+	dir := t.TempDir()
+	is.NoErr(os.MkdirAll(filepath.Join(dir, "spec"), os.ModePerm))
+	is.NoErr(os.WriteFile(filepath.Join(dir, "spec", "1.json"), []byte(`{"meta":{"hubID":"1"}}`), 0o600))
+	is.NoErr(os.WriteFile(filepath.Join(dir, "spec", "2.json"), []byte{}, 0o600))
+	is.NoErr(os.WriteFile(filepath.Join(dir, "spec", "3.txt"), []byte("3"), 0o600))
+
+	src := &dirSource{path: dir}
+
+	files, err := src.files()
+	is.NoErr(err)
+	is.Equal(files, []string{filepath.Join(dir, "spec", "1.json")})
+}
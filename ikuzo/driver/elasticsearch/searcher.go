@@ -0,0 +1,254 @@
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	isearch "github.com/delving/hub3/ikuzo/search"
+	"github.com/delving/hub3/ikuzo/service/x/search"
+	"github.com/olivere/elastic/v7"
+)
+
+var _ isearch.Searcher = (*Searcher)(nil)
+
+const (
+	// fullTextField contains a copy of all the literal values of the record
+	fullTextField = "full_text"
+	pathEntries   = "resources.entries"
+	// facet sub-aggregation names
+	facetFilterName  = "filter"
+	facetValuesName  = "values"
+	facetRecordsName = "records"
+)
+
+// Searcher executes the search requests of the ikuzo/search package against
+// the v2 index. Query terms are matched against the full-text of the record.
+type Searcher struct {
+	client *Client
+	qb     *QueryBuilder
+}
+
+func (c *Client) NewSearcher() *Searcher {
+	return &Searcher{
+		client: c,
+		qb:     NewQueryBuilder(QueryField{Field: fullTextField}),
+	}
+}
+
+func (s *Searcher) Search(ctx context.Context, req *isearch.Request) (*isearch.Response, error) {
+	ss, err := s.searchSource(req)
+	if err != nil {
+		return nil, err
+	}
+
+	indexName := req.IndexName
+	if indexName == "" {
+		indexName = IndexNames{}.GetIndexName(req.OrgID)
+	}
+
+	res, err := s.client.search.Search(indexName).
+		SearchSource(ss).
+		TrackTotalHits(true).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Error != nil {
+		return nil, fmt.Errorf("%s", res.Error.Reason)
+	}
+
+	return decodeSearchResponse(req, res)
+}
+
+// searchSource returns the elasticsearch request for the search.Request.
+func (s *Searcher) searchSource(req *isearch.Request) (*elastic.SearchSource, error) {
+	query := elastic.NewBoolQuery().Filter(elastic.NewTermQuery(PathOrgID, req.OrgID))
+
+	if strings.TrimSpace(req.Query) != "" {
+		qp, err := search.NewQueryParser(req.QueryOptions...)
+		if err != nil {
+			return nil, err
+		}
+
+		qt, err := qp.Parse(req.Query)
+		if err != nil {
+			return nil, err
+		}
+
+		query = query.Must(s.qb.NewElasticQuery(qt))
+	}
+
+	for _, f := range req.Filters {
+		if f.Exclude {
+			query = query.MustNot(filterQuery(f))
+			continue
+		}
+
+		query = query.Filter(filterQuery(f))
+	}
+
+	ss := elastic.NewSearchSource().
+		Query(query).
+		From(req.Start()).
+		Size(req.Rows)
+
+	for _, ff := range req.Facets {
+		agg, err := facetAggregation(ff)
+		if err != nil {
+			return nil, err
+		}
+
+		ss = ss.Aggregation(ff.Field, agg)
+	}
+
+	return ss, nil
+}
+
+// filterQuery matches the filter value exactly. Filters on a SearchLabel match
+// either the literal value or the URI of the resource entry.
+func filterQuery(f *isearch.Filter) elastic.Query {
+	if f.IsMetaField() {
+		return elastic.NewTermQuery(f.Field, f.Value)
+	}
+
+	return elastic.NewNestedQuery(
+		pathEntries,
+		elastic.NewBoolQuery().
+			Must(elastic.NewTermQuery(pathEntries+".searchLabel", f.Field)).
+			Should(
+				elastic.NewTermQuery(pathEntries+".@value.keyword", f.Value),
+				elastic.NewTermQuery(pathEntries+".@id", f.Value),
+			).
+			MinimumNumberShouldMatch(1),
+	)
+}
+
+// facetAggregation returns a terms aggregation for header facets. Literal
+// facets are aggregated on the entries of the SearchLabel and count the
+// records with a reverse nested aggregation.
+func facetAggregation(ff *isearch.FacetField) (elastic.Aggregation, error) {
+	terms := elastic.NewTermsAggregation().Size(ff.Size)
+
+	// the default order is descending
+	asc := ff.SortAsc()
+	if ff.OrderByKey() {
+		terms = terms.OrderByKey(asc)
+	} else {
+		terms = terms.OrderByCount(asc).OrderByKeyAsc()
+	}
+
+	switch {
+	case ff.IsMetaField():
+		return terms.Field(ff.Path()), nil
+	case ff.IsLiteralField():
+		terms = terms.
+			Field(pathEntries+".@value.keyword").
+			SubAggregation(facetRecordsName, elastic.NewReverseNestedAggregation())
+
+		filter := elastic.NewFilterAggregation().
+			Filter(elastic.NewTermQuery(pathEntries+".searchLabel", ff.Field)).
+			SubAggregation(facetValuesName, terms)
+
+		return elastic.NewNestedAggregation().
+			Path(pathEntries).
+			SubAggregation(facetFilterName, filter), nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", isearch.ErrUnsupportedFacet, ff.Field)
+}
+
+func decodeSearchResponse(req *isearch.Request, res *elastic.SearchResult) (*isearch.Response, error) {
+	resp := &isearch.Response{
+		Total:  int(res.TotalHits()),
+		Hits:   []*isearch.Hit{},
+		Facets: []*isearch.Facet{},
+	}
+
+	if res.Hits != nil {
+		for _, h := range res.Hits.Hits {
+			hit, err := isearch.DecodeHit(h.Source)
+			if err != nil {
+				return nil, err
+			}
+
+			if h.Score != nil {
+				hit.Score = *h.Score
+			}
+
+			resp.Hits = append(resp.Hits, hit)
+		}
+	}
+
+	for _, ff := range req.Facets {
+		resp.Facets = append(resp.Facets, decodeFacet(req, ff, res.Aggregations))
+	}
+
+	return resp, nil
+}
+
+func decodeFacet(req *isearch.Request, ff *isearch.FacetField, aggs elastic.Aggregations) *isearch.Facet {
+	field := ff.Path()
+	if ff.IsLiteralField() {
+		field = ff.Field
+	}
+
+	facet := &isearch.Facet{
+		Name:  ff.Field,
+		Field: field,
+		Links: []*isearch.FacetLink{},
+	}
+
+	terms, ok := facetTerms(ff, aggs)
+	if !ok {
+		return facet
+	}
+
+	facet.OtherDocs = terms.SumOfOtherDocCount
+	facet.Total = terms.SumOfOtherDocCount
+
+	for _, bucket := range terms.Buckets {
+		value := fmt.Sprintf("%v", bucket.Key)
+
+		count := bucket.DocCount
+		if records, ok := bucket.ReverseNested(facetRecordsName); ok {
+			count = records.DocCount
+		}
+
+		link := &isearch.FacetLink{
+			Value:         value,
+			DisplayString: value,
+			Count:         count,
+			IsSelected:    req.IsSelected(field, value),
+		}
+
+		if link.IsSelected {
+			facet.IsSelected = true
+		}
+
+		facet.Total += count
+		facet.Links = append(facet.Links, link)
+	}
+
+	return facet
+}
+
+// facetTerms returns the terms aggregation of the facet.
+func facetTerms(ff *isearch.FacetField, aggs elastic.Aggregations) (*elastic.AggregationBucketKeyItems, bool) {
+	if !ff.IsLiteralField() {
+		return aggs.Terms(ff.Field)
+	}
+
+	nested, ok := aggs.Nested(ff.Field)
+	if !ok {
+		return nil, false
+	}
+
+	filter, ok := nested.Filter(facetFilterName)
+	if !ok {
+		return nil, false
+	}
+
+	return filter.Terms(facetValuesName)
+}
@@ -0,0 +1,170 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"testing"
+
+	isearch "github.com/delving/hub3/ikuzo/search"
+	"github.com/matryer/is"
+	"github.com/olivere/elastic/v7"
+)
+
+func newTestRequest(t *testing.T, query string, filters []string, facets []string) *isearch.Request {
+	is := is.New(t)
+
+	req := &isearch.Request{OrgID: "demo", Query: query, Page: 2, Rows: 10}
+
+	for _, qf := range filters {
+		f, err := isearch.ParseFilter(qf)
+		is.NoErr(err)
+
+		req.Filters = append(req.Filters, f)
+	}
+
+	for _, field := range facets {
+		ff, err := isearch.NewFacetField(field)
+		is.NoErr(err)
+
+		req.Facets = append(req.Facets, ff)
+	}
+
+	return req
+}
+
+func TestSearcher_searchSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		filters []string
+		facets  []string
+		want    string
+		wantErr bool
+	}{
+		{
+			"match all",
+			"",
+			nil,
+			nil,
+			`{"from":10,"query":{"bool":{"filter":{"term":{"meta.orgID":"demo"}}}},"size":10}`,
+			false,
+		},
+		{
+			"query with filters",
+			"amsterdam",
+			[]string{"meta.spec:a", "-dc_creator:Witsen"},
+			nil,
+			`{"from":10,"query":{"bool":{` +
+				`"filter":[{"term":{"meta.orgID":"demo"}},{"term":{"meta.spec":"a"}}],` +
+				`"must":{"bool":{"should":{"match":{"full_text":{"query":"amsterdam"}}}}},` +
+				`"must_not":{"nested":{"path":"resources.entries","query":{"bool":{` +
+				`"minimum_should_match":"1",` +
+				`"must":{"term":{"resources.entries.searchLabel":"dc_creator"}},` +
+				`"should":[{"term":{"resources.entries.@value.keyword":"Witsen"}},{"term":{"resources.entries.@id":"Witsen"}}]}}}}}},` +
+				`"size":10}`,
+			false,
+		},
+		{
+			"facets",
+			"",
+			nil,
+			[]string{"meta.spec~5", "^dc_creator~5@"},
+			`{"aggregations":{` +
+				`"dc_creator":{"aggregations":{"filter":{"aggregations":{"values":{` +
+				`"aggregations":{"records":{"reverse_nested":{}}},` +
+				`"terms":{"field":"resources.entries.@value.keyword","order":[{"_key":"asc"}],"size":5}}},` +
+				`"filter":{"term":{"resources.entries.searchLabel":"dc_creator"}}}},` +
+				`"nested":{"path":"resources.entries"}},` +
+				`"meta.spec":{"terms":{"field":"meta.spec","order":[{"_count":"desc"},{"_key":"asc"}],"size":5}}},` +
+				`"from":10,"query":{"bool":{"filter":{"term":{"meta.orgID":"demo"}}}},"size":10}`,
+			false,
+		},
+		{
+			"unsupported facet",
+			"",
+			nil,
+			[]string{"datehistogram.dc_date"},
+			"",
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+
+			s := (&Client{}).NewSearcher()
+
+			ss, err := s.searchSource(newTestRequest(t, tt.query, tt.filters, tt.facets))
+			if tt.wantErr {
+				is.True(err != nil)
+				return
+			}
+
+			is.NoErr(err)
+
+			src, err := ss.Source()
+			is.NoErr(err)
+
+			got, err := json.Marshal(src)
+			is.NoErr(err)
+			is.Equal(string(got), tt.want)
+		})
+	}
+}
+
+func TestDecodeSearchResponse(t *testing.T) {
+	is := is.New(t)
+
+	var res elastic.SearchResult
+
+	err := json.Unmarshal([]byte(`{
+		"hits": {
+			"total": {"value": 12, "relation": "eq"},
+			"hits": [{
+				"_score": 1.5,
+				"_source": {
+					"meta": {"hubID": "demo_a_1", "orgID": "demo", "spec": "a"},
+					"resources": [{"entries": [{"@value": "Amsterdam", "searchLabel": "dc_title"}]}]
+				}
+			}]
+		},
+		"aggregations": {
+			"meta.spec": {
+				"sum_other_doc_count": 2,
+				"buckets": [{"key": "a", "doc_count": 10}]
+			},
+			"dc_creator": {
+				"doc_count": 30,
+				"filter": {
+					"doc_count": 14,
+					"values": {
+						"sum_other_doc_count": 0,
+						"buckets": [{"key": "Witsen", "doc_count": 8, "records": {"doc_count": 6}}]
+					}
+				}
+			}
+		}
+	}`), &res)
+	is.NoErr(err)
+
+	req := newTestRequest(t, "", []string{"meta.spec:a"}, []string{"meta.spec", "dc_creator"})
+
+	resp, err := decodeSearchResponse(req, &res)
+	is.NoErr(err)
+	is.Equal(resp.Total, 12)
+	is.Equal(len(resp.Hits), 1)
+	is.Equal(resp.Hits[0].ID, "demo_a_1")
+	is.Equal(resp.Hits[0].Score, 1.5)
+	is.Equal(resp.Hits[0].Fields["dc_title"], []string{"Amsterdam"})
+
+	is.Equal(len(resp.Facets), 2)
+	is.Equal(resp.Facets[0].Total, int64(12))
+	is.Equal(resp.Facets[0].OtherDocs, int64(2))
+	is.True(resp.Facets[0].IsSelected)
+	is.Equal(*resp.Facets[0].Links[0], isearch.FacetLink{Value: "a", DisplayString: "a", Count: 10, IsSelected: true})
+
+	// literal facets count the records instead of the entries
+	is.Equal(*resp.Facets[1].Links[0], isearch.FacetLink{Value: "Witsen", DisplayString: "Witsen", Count: 6})
+}
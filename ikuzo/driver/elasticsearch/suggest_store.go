@@ -0,0 +1,157 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/delving/hub3/ikuzo/service/x/suggest"
+	"github.com/olivere/elastic/v7"
+)
+
+var _ suggest.Store = (*SuggestStore)(nil)
+
+const (
+	suggestName = "suggest"
+	// defaultSuggestField is used when the suggest.Query has no field
+	defaultSuggestField = "text"
+	// suggestOverFetch is the factor by which the completion size is increased
+	// when the completions are filtered by dataset afterwards
+	suggestOverFetch = 5
+)
+
+// suggestFields are the fields of the v2 suggest mapping with a completion subfield.
+var suggestFields = map[string]bool{
+	"text":            true,
+	"name":            true,
+	"capacity":        true,
+	"nameWithContext": true,
+}
+
+// SuggestStore retrieves suggestions from the v2 suggest index.
+type SuggestStore struct {
+	client *Client
+}
+
+func (c *Client) NewSuggestStore() *SuggestStore {
+	return &SuggestStore{
+		client: c,
+	}
+}
+
+// Suggest returns the suggestions for the query. Prefix queries use the
+// completion suggester. Infix queries match the words of the field and return
+// the most frequent values of its keyword subfield.
+func (s *SuggestStore) Suggest(ctx context.Context, q *suggest.Query) ([]suggest.Suggestion, error) {
+	field := q.Field
+	if field == "" {
+		field = defaultSuggestField
+	}
+
+	if !suggestFields[field] {
+		return nil, fmt.Errorf("%w: %s", suggest.ErrUnknownField, field)
+	}
+
+	res, err := s.client.search.Search(q.IndexName).
+		SearchSource(suggestSearchSource(q, field)).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Error != nil {
+		return nil, fmt.Errorf("%s", res.Error.Reason)
+	}
+
+	if q.Mode == suggest.Infix {
+		return decodeInfixSuggestions(res), nil
+	}
+
+	return decodeCompletions(res, q)
+}
+
+// suggestSearchSource returns the search request for the suggest.Query.
+func suggestSearchSource(q *suggest.Query, field string) *elastic.SearchSource {
+	ss := elastic.NewSearchSource().Size(0)
+
+	if q.Mode == suggest.Infix {
+		query := elastic.NewBoolQuery().
+			Must(elastic.NewMatchBoolPrefixQuery(field, q.Input).Operator("and"))
+
+		if q.DatasetID != "" {
+			query = query.Filter(elastic.NewTermQuery(PathDatasetID, q.DatasetID))
+		}
+
+		return ss.Query(query).
+			Aggregation(suggestName, elastic.NewTermsAggregation().Field(field+".keyword").Size(q.Size))
+	}
+
+	size := q.Size
+	if q.DatasetID != "" {
+		size *= suggestOverFetch
+	}
+
+	completion := elastic.NewCompletionSuggester(suggestName).
+		Field(field + ".suggest").
+		Prefix(q.Input).
+		SkipDuplicates(true).
+		Size(size)
+
+	fsc := elastic.NewFetchSourceContext(true).Include(PathDatasetID)
+
+	return ss.Suggester(completion).FetchSourceContext(fsc)
+}
+
+func decodeInfixSuggestions(res *elastic.SearchResult) []suggest.Suggestion {
+	suggestions := []suggest.Suggestion{}
+
+	terms, ok := res.Aggregations.Terms(suggestName)
+	if !ok {
+		return suggestions
+	}
+
+	for _, bucket := range terms.Buckets {
+		value, ok := bucket.Key.(string)
+		if !ok {
+			continue
+		}
+
+		suggestions = append(suggestions, suggest.Suggestion{Value: value, Count: int(bucket.DocCount)})
+	}
+
+	return suggestions
+}
+
+// decodeCompletions returns the completions. The completion suggester cannot
+// filter, so the completions of other datasets are removed here.
+func decodeCompletions(res *elastic.SearchResult, q *suggest.Query) ([]suggest.Suggestion, error) {
+	suggestions := []suggest.Suggestion{}
+
+	for _, completion := range res.Suggest[suggestName] {
+		for _, option := range completion.Options {
+			if q.DatasetID != "" {
+				var source struct {
+					Meta struct {
+						Spec string `json:"spec"`
+					} `json:"meta"`
+				}
+
+				if err := json.Unmarshal(option.Source, &source); err != nil {
+					return nil, fmt.Errorf("unable to decode suggest source; %w", err)
+				}
+
+				if source.Meta.Spec != q.DatasetID {
+					continue
+				}
+			}
+
+			suggestions = append(suggestions, suggest.Suggestion{Value: option.Text})
+
+			if len(suggestions) == q.Size {
+				return suggestions, nil
+			}
+		}
+	}
+
+	return suggestions, nil
+}
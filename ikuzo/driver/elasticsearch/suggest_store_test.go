@@ -0,0 +1,79 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/delving/hub3/ikuzo/service/x/suggest"
+	"github.com/matryer/is"
+	"github.com/olivere/elastic/v7"
+)
+
+func TestSuggestSearchSource(t *testing.T) {
+	tests := []struct {
+		name  string
+		query suggest.Query
+		want  string
+	}{
+		{
+			"prefix",
+			suggest.Query{Input: "amst", Mode: suggest.Prefix, Size: 10},
+			`{"_source":{"includes":["meta.spec"]},"size":0,` +
+				`"suggest":{"suggest":{"prefix":"amst","completion":{"field":"name.suggest","size":10,"skip_duplicates":true}}}}`,
+		},
+		{
+			"prefix by dataset",
+			suggest.Query{Input: "amst", Mode: suggest.Prefix, Size: 10, DatasetID: "spec"},
+			`{"_source":{"includes":["meta.spec"]},"size":0,` +
+				`"suggest":{"suggest":{"prefix":"amst","completion":{"field":"name.suggest","size":50,"skip_duplicates":true}}}}`,
+		},
+		{
+			"infix by dataset",
+			suggest.Query{Input: "dam", Mode: suggest.Infix, Size: 5, DatasetID: "spec"},
+			`{"aggregations":{"suggest":{"terms":{"field":"name.keyword","size":5}}},` +
+				`"query":{"bool":{"filter":{"term":{"meta.spec":"spec"}},` +
+				`"must":{"match_bool_prefix":{"name":{"operator":"and","query":"dam"}}}}},"size":0}`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+
+			src, err := suggestSearchSource(&tt.query, "name").Source()
+			is.NoErr(err)
+
+			got, err := json.Marshal(src)
+			is.NoErr(err)
+			is.Equal(string(got), tt.want)
+		})
+	}
+}
+
+func TestDecodeCompletions(t *testing.T) {
+	is := is.New(t)
+
+	res := &elastic.SearchResult{
+		Suggest: elastic.SearchSuggest{
+			suggestName: []elastic.SearchSuggestion{
+				{
+					Options: []elastic.SearchSuggestionOption{
+						{Text: "Amstel", Source: json.RawMessage(`{"meta":{"spec":"other"}}`)},
+						{Text: "Amsterdam", Source: json.RawMessage(`{"meta":{"spec":"spec"}}`)},
+						{Text: "Amstelveen", Source: json.RawMessage(`{"meta":{"spec":"spec"}}`)},
+					},
+				},
+			},
+		},
+	}
+
+	got, err := decodeCompletions(res, &suggest.Query{Size: 10})
+	is.NoErr(err)
+	is.Equal(len(got), 3)
+
+	got, err = decodeCompletions(res, &suggest.Query{Size: 1, DatasetID: "spec"})
+	is.NoErr(err)
+	is.Equal(got, []suggest.Suggestion{{Value: "Amsterdam"}})
+}
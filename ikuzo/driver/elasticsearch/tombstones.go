@@ -0,0 +1,260 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/delving/hub3/ikuzo/driver/elasticsearch/internal/mapping"
+	"github.com/olivere/elastic/v7"
+)
+
+// tombstoneBatchSize is the number of records that is read per scroll request.
+const tombstoneBatchSize = 500
+
+// TombstoneStore keeps a tombstone for each record that is dropped as an
+// orphan from the v2 index, so the OAIPMHStore can report it as deleted.
+//
+// A tombstone contains the 'meta' of the deleted record with the deletion
+// datestamp as 'meta.modified'.
+type TombstoneStore struct {
+	client  *Client
+	indices sync.Map // tombstone indices that are known to exist
+}
+
+func (c *Client) NewTombstoneStore() *TombstoneStore {
+	return &TombstoneStore{client: c}
+}
+
+// ensureIndex creates the tombstone index of the organization when it does
+// not exist yet and returns its alias.
+func (ts *TombstoneStore) ensureIndex(orgID string) (string, error) {
+	alias := IndexNames{}.GetTombstoneIndexName(orgID)
+
+	if _, ok := ts.indices.Load(alias); ok {
+		return alias, nil
+	}
+
+	indices := ts.client.Indices()
+
+	_, err := indices.Create(alias, mapping.TombstoneMapping(0, 0), true)
+	if err != nil && !errors.Is(err, ErrIndexAlreadyCreated) {
+		return "", fmt.Errorf("unable to create tombstone index %s; %w", alias, err)
+	}
+
+	ts.indices.Store(alias, true)
+
+	return alias, nil
+}
+
+// TombstoneIndex returns the alias of the tombstone index of the
+// organization. The index is created when it does not exist yet.
+func (ts *TombstoneStore) TombstoneIndex(orgID string) (string, error) {
+	return ts.ensureIndex(orgID)
+}
+
+// ExpireTombstones removes the tombstones of the records of the organization
+// that were deleted before the given time.
+func (ts *TombstoneStore) ExpireTombstones(ctx context.Context, orgID string, before time.Time) error {
+	alias, err := ts.ensureIndex(orgID)
+	if err != nil {
+		return err
+	}
+
+	res, err := ts.client.search.DeleteByQuery(alias).
+		Query(elastic.NewRangeQuery(PathModified).Lt(before.UnixMilli())).
+		Conflicts("proceed").
+		Do(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to expire tombstones; %w", err)
+	}
+
+	ts.client.log.Info().
+		Str("orgID", orgID).
+		Int64("expired", res.Deleted).
+		Msg("removed expired tombstones")
+
+	return nil
+}
+
+// RecordOrphans stores a tombstone for each record in the index that matches
+// the orphans query. It must be called before the orphans are dropped.
+//
+// Tombstones of records of the dataset that have been indexed again are
+// removed, so a restored record is no longer reported as deleted.
+func (ts *TombstoneStore) RecordOrphans(ctx context.Context, indexName, orgID, datasetID string, orphans elastic.Query) error {
+	alias, err := ts.ensureIndex(orgID)
+	if err != nil {
+		return err
+	}
+
+	if err := ts.removeRestored(ctx, alias, indexName, orgID, datasetID, orphans); err != nil {
+		return fmt.Errorf("unable to remove tombstones of restored records; %w", err)
+	}
+
+	total, err := ts.addTombstones(ctx, alias, indexName, orphans)
+	if err != nil {
+		return fmt.Errorf("unable to store tombstones; %w", err)
+	}
+
+	ts.client.log.Info().
+		Str("orgID", orgID).
+		Str("datasetID", datasetID).
+		Int("tombstones", total).
+		Msg("stored tombstones for orphans")
+
+	return nil
+}
+
+func (ts *TombstoneStore) removeRestored(ctx context.Context, alias, indexName, orgID, datasetID string, orphans elastic.Query) error {
+	query := elastic.NewBoolQuery().Must(
+		elastic.NewTermQuery(PathOrgID, orgID),
+		elastic.NewTermQuery(PathDatasetID, datasetID),
+	)
+
+	scroll := ts.client.search.Scroll(alias).
+		Query(query).
+		FetchSource(false).
+		Size(tombstoneBatchSize)
+
+	defer scroll.Clear(context.Background())
+
+	for {
+		res, err := scroll.Do(ctx)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		ids := make([]string, 0, len(res.Hits.Hits))
+		for _, hit := range res.Hits.Hits {
+			ids = append(ids, hit.Id)
+		}
+
+		restored, err := ts.indexed(ctx, indexName, ids, orphans)
+		if err != nil {
+			return err
+		}
+
+		if len(restored) == 0 {
+			continue
+		}
+
+		_, err = ts.client.search.DeleteByQuery(alias).
+			Query(elastic.NewIdsQuery().Ids(restored...)).
+			Conflicts("proceed").
+			Do(ctx)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// indexed returns the ids of the records that are in the index and are not
+// orphans.
+func (ts *TombstoneStore) indexed(ctx context.Context, indexName string, ids []string, orphans elastic.Query) ([]string, error) {
+	indexed := []string{}
+
+	if len(ids) == 0 {
+		return indexed, nil
+	}
+
+	query := elastic.NewBoolQuery().
+		Must(elastic.NewIdsQuery().Ids(ids...)).
+		MustNot(orphans)
+
+	res, err := ts.client.search.Search(indexName).
+		Query(query).
+		FetchSource(false).
+		Size(len(ids)).
+		Do(ctx)
+	if err != nil {
+		return indexed, err
+	}
+
+	for _, hit := range res.Hits.Hits {
+		indexed = append(indexed, hit.Id)
+	}
+
+	return indexed, nil
+}
+
+func (ts *TombstoneStore) addTombstones(ctx context.Context, alias, indexName string, orphans elastic.Query) (int, error) {
+	scroll := ts.client.search.Scroll(indexName).
+		Query(orphans).
+		FetchSourceContext(elastic.NewFetchSourceContext(true).Include("meta")).
+		Size(tombstoneBatchSize)
+
+	defer scroll.Clear(context.Background())
+
+	var total int
+
+	deleted := time.Now()
+
+	for {
+		res, err := scroll.Do(ctx)
+		if errors.Is(err, io.EOF) {
+			return total, nil
+		}
+
+		if err != nil {
+			return total, err
+		}
+
+		bulk := ts.client.search.Bulk().Index(alias)
+
+		for _, hit := range res.Hits.Hits {
+			doc, err := newTombstone(hit.Source, deleted)
+			if err != nil {
+				return total, fmt.Errorf("unable to create tombstone for %s; %w", hit.Id, err)
+			}
+
+			bulk.Add(elastic.NewBulkIndexRequest().Id(hit.Id).Doc(doc))
+		}
+
+		if bulk.NumberOfActions() == 0 {
+			continue
+		}
+
+		resp, err := bulk.Do(ctx)
+		if err != nil {
+			return total, err
+		}
+
+		if resp.Errors {
+			return total, fmt.Errorf("unable to store %d tombstones", len(resp.Failed()))
+		}
+
+		total += len(resp.Succeeded())
+	}
+}
+
+// newTombstone returns the tombstone document for the source of a deleted
+// record.
+func newTombstone(source json.RawMessage, deleted time.Time) (map[string]interface{}, error) {
+	var record struct {
+		Meta map[string]interface{} `json:"meta"`
+	}
+
+	if err := json.Unmarshal(source, &record); err != nil {
+		return nil, err
+	}
+
+	if record.Meta == nil {
+		record.Meta = map[string]interface{}{}
+	}
+
+	record.Meta["modified"] = deleted.UTC().UnixMilli()
+
+	return map[string]interface{}{
+		"meta":    record.Meta,
+		"deleted": true,
+	}, nil
+}
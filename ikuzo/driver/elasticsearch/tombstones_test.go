@@ -0,0 +1,30 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestNewTombstone(t *testing.T) {
+	is := is.New(t)
+
+	source := json.RawMessage(`{"meta":{"hubID":"hub3_ds1_1","spec":"ds1","orgID":"hub3","revision":2,"modified":1},"resources":[{"id":"x"}]}`)
+	deleted := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tombstone, err := newTombstone(source, deleted)
+	is.NoErr(err)
+
+	b, err := json.Marshal(tombstone)
+	is.NoErr(err)
+
+	is.Equal(
+		string(b),
+		`{"deleted":true,"meta":{"hubID":"hub3_ds1_1","modified":1609459200000,"orgID":"hub3","revision":2,"spec":"ds1"}}`,
+	)
+
+	_, err = newTombstone(json.RawMessage(`not json`), deleted)
+	is.True(err != nil)
+}
@@ -21,13 +21,16 @@ import (
 	"log"
 
 	"github.com/delving/hub3/ikuzo/service/x/bulk"
+	"github.com/delving/hub3/ikuzo/storage/x/file"
 	"github.com/spf13/cobra"
 )
 
 var (
-	requestPath string
-	publishHost string
-	chunkSize   int
+	requestPath  string
+	publishHost  string
+	chunkSize    int
+	storeOrgID   string
+	storeDataset string
 )
 
 // bulkCmd represents the bulk command
@@ -37,8 +40,15 @@ var bulkCmd = &cobra.Command{
 	Long: `Loading bulk.Requests serialized as line delimited json.
 
 	We assume that the structure is 'orgID/datasetID/hubID.jsonl' and that each bulk.Request
-	is stored in a single file.`,
+	is stored in a single file.
+
+	When --orgID is set the dataPath is read as a file record store and the records
+	of its datasets are published instead.`,
 	Run: func(cmd *cobra.Command, args []string) {
+		if storeOrgID != "" {
+			log.Fatal(publishStore(cmd.Context(), publishHost, requestPath))
+		}
+
 		log.Fatal(publish(cmd.Context(), publishHost, requestPath))
 	},
 }
@@ -49,6 +59,8 @@ func init() {
 	bulkCmd.Flags().StringVarP(&requestPath, "dataPath", "p", ".", "Full path to orgIDs for the bulk.Requests.")
 	bulkCmd.Flags().StringVarP(&publishHost, "host", "", "http://localhost:3001", "network host of where target hub3 is running")
 	bulkCmd.Flags().IntVarP(&chunkSize, "chunkSize", "", 500, "size of number of records send per batch")
+	bulkCmd.Flags().StringVarP(&storeOrgID, "orgID", "", "", "publish the datasets of this organization from a file record store")
+	bulkCmd.Flags().StringVarP(&storeDataset, "dataset", "", "", "only publish this dataset from the file record store")
 }
 
 func publish(ctx context.Context, host, dataPath string) error {
@@ -62,3 +74,32 @@ func publish(ctx context.Context, host, dataPath string) error {
 
 	return nil
 }
+
+func publishStore(ctx context.Context, host, dataPath string) error {
+	store, err := file.NewStore(dataPath)
+	if err != nil {
+		return err
+	}
+
+	defer store.Close()
+
+	datasetIDs := []string{storeDataset}
+
+	if storeDataset == "" {
+		datasetIDs, err = store.DatasetIDs(storeOrgID)
+		if err != nil {
+			return fmt.Errorf("unable to list datasets: %w", err)
+		}
+	}
+
+	p := bulk.NewPublisher(host, dataPath)
+	p.BulkSize = chunkSize
+
+	for _, datasetID := range datasetIDs {
+		if err := store.Publish(ctx, storeOrgID, datasetID, p); err != nil {
+			return fmt.Errorf("unable to publish dataset %s: %w", datasetID, err)
+		}
+	}
+
+	return nil
+}
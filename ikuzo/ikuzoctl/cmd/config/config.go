@@ -22,6 +22,7 @@ import (
 	"github.com/delving/hub3/ikuzo/logger"
 	"github.com/delving/hub3/ikuzo/service/organization"
 	"github.com/delving/hub3/ikuzo/service/x/index"
+	"github.com/delving/hub3/ikuzo/service/x/vocabulary"
 	"github.com/pacedotdev/oto/otohttp"
 	"github.com/spf13/viper"
 )
@@ -44,6 +45,7 @@ type Config struct {
 	logger        logger.CustomLogger
 	is            *index.Service
 	orgs          *organization.Service
+	vocabularies  map[string]*vocabulary.Service
 	Organization  `json:"organization"`
 	Org           map[string]domain.OrganizationConfig `json:"org"`
 	Harvest       `json:"harvest"`
@@ -51,7 +53,12 @@ type Config struct {
 	NDE           map[string]NDECfg `json:"nde"`
 	NDERegister   NDE               `json:"-" toml:"-"`
 	RDF           `json:"rdf"`
+	RecordStore   `json:"recordStore"`
+	Reindex       `json:"reindex"`
+	Search        `json:"search"`
 	Sitemap       `json:"sitemap"`
+	SpellCheck    `json:"spellCheck"`
+	Suggest       `json:"suggest"`
 	oto           *otohttp.Server
 }
 
@@ -70,7 +77,11 @@ func (cfg *Config) Options(cfgOptions ...Option) ([]ikuzo.Option, error) {
 			&cfg.Harvest,
 			&cfg.NameSpace,
 			&cfg.NDERegister,
+			&cfg.Reindex,
+			&cfg.Search,
 			&cfg.Sitemap,
+			&cfg.SpellCheck,
+			&cfg.Suggest,
 			&cfg.Logging,
 			&cfg.OAIPMH,
 		}
@@ -136,6 +147,11 @@ func (cfg *Config) GetIndexService() (*index.Service, error) {
 }
 
 func (cfg *Config) defaultOptions() error {
+	// the vocabularies are loaded for the v2 search, also without the v3 search
+	if _, err := cfg.Vocabularies(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -28,9 +28,14 @@ type EAD struct {
 	Workers                 int    `json:"workers"`
 	ProcessDigital          bool   `json:"processDigital"`
 	ProcessDigitalIfMissing bool   `json:"processDigitalIfMissing"`
+	service                 *ead.Service
 }
 
-func (e EAD) NewService(cfg *Config) (*ead.Service, error) {
+func (e *EAD) NewService(cfg *Config) (*ead.Service, error) {
+	if e.service != nil {
+		return e.service, nil
+	}
+
 	is, err := cfg.GetIndexService()
 	if err != nil {
 		return nil, err
@@ -56,6 +61,8 @@ func (e EAD) NewService(cfg *Config) (*ead.Service, error) {
 		expvar.Publish("hub3-ead-service", expvar.Func(func() interface{} { m := svc.Metrics(); return m }))
 	}
 
+	e.service = svc
+
 	return svc, nil
 }
 
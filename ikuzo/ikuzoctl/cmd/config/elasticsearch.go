@@ -118,11 +118,18 @@ func (e *ElasticSearch) AddOptions(cfg *Config) error {
 		return fmt.Errorf("unable to create posthook service; %w", phErr)
 	}
 
-	bulkSvc, bulkErr := bulk.NewService(
+	validations, vErr := cfg.getBulkValidations()
+	if vErr != nil {
+		return fmt.Errorf("unable to create SHACL validation; %w", vErr)
+	}
+
+	bulkOptions := []bulk.Option{
 		bulk.SetIndexService(is),
 		bulk.SetIndexTypes(e.IndexTypes...),
 		bulk.SetPostHookService(postHooks...),
-	)
+	}
+
+	bulkSvc, bulkErr := bulk.NewService(append(bulkOptions, validations...)...)
 	if bulkErr != nil {
 		return fmt.Errorf("unable to create bulk service; %w", isErr)
 	}
@@ -233,11 +240,17 @@ func (e *ElasticSearch) IndexService(cfg *Config, ncfg *index.NatsConfig) (*inde
 			return nil, bulkErr
 		}
 
+		client, clientErr := e.NewCustomClient(&cfg.logger)
+		if clientErr != nil {
+			return nil, clientErr
+		}
+
 		options = append(
 			options,
 			index.SetBulkIndexer(*bi, ncfg == nil),
 			index.SetOrphanWait(e.OrphanWait),
 			index.SetDisableMetrics(!e.Metrics),
+			index.SetOrphanRecorder(client.NewTombstoneStore()),
 		)
 	}
 
@@ -254,6 +267,28 @@ func (e *ElasticSearch) IndexService(cfg *Config, ncfg *index.NatsConfig) (*inde
 		options = append(options, index.SetPostHookService(postHooks...))
 	}
 
+	if cfg.SpellCheck.Enabled {
+		spell, spellErr := cfg.SpellCheck.NewService(cfg)
+		if spellErr != nil {
+			return nil, fmt.Errorf("unable to create spellcheck service; %w", spellErr)
+		}
+
+		options = append(options, index.SetRecordTrainers(spell))
+	}
+
+	if cfg.Suggest.MemoryFallback {
+		options = append(options, index.SetRecordTrainers(cfg.Suggest.MemoryStore()))
+	}
+
+	if cfg.Search.Enabled && cfg.Search.Backend == searchBackendMemory {
+		searcher, searcherErr := cfg.Search.MemorySearcher(cfg)
+		if searcherErr != nil {
+			return nil, searcherErr
+		}
+
+		options = append(options, index.SetRecordTrainers(searcher))
+	}
+
 	e.is, err = index.NewService(options...)
 	if err != nil {
 		return nil, err
@@ -2,9 +2,14 @@ package config
 
 import (
 	"fmt"
+	"path/filepath"
 
+	eadHub3 "github.com/delving/hub3/hub3/ead"
 	"github.com/delving/hub3/ikuzo"
+	"github.com/delving/hub3/ikuzo/service/x/ead"
+	iharvest "github.com/delving/hub3/ikuzo/service/x/harvest"
 	"github.com/delving/hub3/ikuzo/service/x/oaipmh/harvest"
+	"github.com/kiivihal/goharvest/oai"
 )
 
 type Harvest struct {
@@ -17,7 +22,10 @@ type Harvest struct {
 	HarvestPath    string   `json:"harvestPath"`
 	RequireSetSpec bool     `json:"requireSetSpec"`
 	TagFilters     []string `json:"tagFilters"`
-	service        *harvest.Service
+	// StateDir is the directory with the persisted state of the harvest jobs
+	// that are configured per organization. Defaults to 'harvest' in the EAD cacheDir.
+	StateDir string `json:"stateDir"`
+	service  *harvest.Service
 }
 
 func (h *Harvest) NewService(cfg *Config) (*harvest.Service, error) {
@@ -25,13 +33,52 @@ func (h *Harvest) NewService(cfg *Config) (*harvest.Service, error) {
 		return h.service, nil
 	}
 
-	svc, err := harvest.NewService(
+	stateDir := h.StateDir
+	if stateDir == "" {
+		stateDir = filepath.Join(cfg.EAD.CacheDir, "harvest")
+	}
+
+	store, err := iharvest.NewFileStateStore(stateDir)
+	if err != nil {
+		return nil, err
+	}
+
+	options := []harvest.Option{
 		harvest.SetDelay(h.HarvestDelay),
-	)
+		harvest.SetStateStore(store),
+	}
+
+	if h.hasJobs(cfg) {
+		processors, procErr := h.processors(cfg)
+		if procErr != nil {
+			return nil, procErr
+		}
+
+		options = append(options, processors...)
+	}
+
+	svc, err := harvest.NewService(options...)
 	if err != nil {
 		return nil, err
 	}
 
+	for id, org := range cfg.Org {
+		orgID := id
+		if org.CustomID != "" {
+			orgID = org.CustomID
+		}
+
+		for _, job := range org.Harvest {
+			if !job.Enabled {
+				continue
+			}
+
+			if _, err := svc.AddJob(orgID, job); err != nil {
+				return nil, fmt.Errorf("unable to add harvest job for %s; %w", orgID, err)
+			}
+		}
+	}
+
 	if err := svc.StartHarvestSync(); err != nil {
 		return nil, fmt.Errorf("unable to start OAIPMH harvester; %w", err)
 	}
@@ -65,3 +112,61 @@ func (h *Harvest) AddOptions(cfg *Config) error {
 
 	return nil
 }
+
+func (h *Harvest) hasJobs(cfg *Config) bool {
+	for _, org := range cfg.Org {
+		for _, job := range org.Harvest {
+			if job.Enabled {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// processors returns the harvest.Processor options for the harvest jobs that
+// submit EAD files and METS headers of archives to the EAD service.
+func (h *Harvest) processors(cfg *Config) ([]harvest.Option, error) {
+	if !cfg.ElasticSearch.Enabled {
+		cfg.logger.Warn().Msg("ead and mets harvest processors are disabled because elasticsearch is not enabled")
+		return nil, nil
+	}
+
+	svc, err := cfg.EAD.NewService(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	eadHarvester, err := ead.NewEADHarvester(svc)
+	if err != nil {
+		return nil, err
+	}
+
+	is, err := cfg.GetIndexService()
+	if err != nil {
+		return nil, err
+	}
+
+	daoClient := eadHub3.NewDaoClient(is)
+
+	metsHarvester, err := ead.NewMetsHarvest(&daoClient)
+	if err != nil {
+		return nil, err
+	}
+
+	return []harvest.Option{
+		harvest.SetProcessor("ead", func(orgID string, r *oai.Response) error {
+			harvester := eadHarvester
+			harvester.OrgID = orgID
+
+			return harvester.ProcessEadFromOai(r)
+		}),
+		harvest.SetProcessor("mets", func(orgID string, r *oai.Response) error {
+			harvester := metsHarvester
+			harvester.OrgID = orgID
+
+			return harvester.ProcessMetsFromOai(r)
+		}),
+	}, nil
+}
@@ -1,21 +1,58 @@
 package config
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
 	"github.com/delving/hub3/ikuzo"
 	"github.com/delving/hub3/ikuzo/service/x/oaipmh"
+	"github.com/delving/hub3/ikuzo/service/x/oaipmh/crosswalk"
 )
 
 type OAIPMH struct {
+	// store of the records: 'elasticsearch' (default) or 'file'
+	Store   string `json:"store"`
 	service *oaipmh.Service
 }
 
+func (o *OAIPMH) store(cfg *Config) (oaipmh.Store, error) {
+	switch o.Store {
+	case "", storeElastic:
+		store, err := cfg.client.NewOAIPMHStore()
+		if err != nil {
+			return nil, err
+		}
+
+		if cfg.EAD.CacheDir != "" {
+			store.Crosswalks.Register(crosswalk.NewEAD(eadSource(cfg.EAD.CacheDir)))
+		}
+
+		return store, nil
+	case storeFile:
+		return cfg.RecordStore.FileStore()
+	}
+
+	return nil, fmt.Errorf("unknown oai-pmh store: %s", o.Store)
+}
+
+// eadSource opens the EAD that is stored by the EAD service as
+// '{cacheDir}/{datasetID}/{datasetID}.xml'.
+func eadSource(cacheDir string) crosswalk.SourceFunc {
+	return func(ctx context.Context, orgID, datasetID string) (io.ReadCloser, error) {
+		datasetID = filepath.Base(filepath.Clean(datasetID))
+		return os.Open(filepath.Join(cacheDir, datasetID, datasetID+".xml"))
+	}
+}
+
 func (o *OAIPMH) NewService(cfg *Config) (*oaipmh.Service, error) {
 	if o.service != nil {
 		return o.service, nil
 	}
 
-	// TODO(kiivihal): make stores switchable
-	store, err := cfg.client.NewOAIPMHStore()
+	store, err := o.store(cfg)
 	if err != nil {
 		return nil, err
 	}
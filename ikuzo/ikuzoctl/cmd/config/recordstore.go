@@ -0,0 +1,39 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/delving/hub3/ikuzo/storage/x/file"
+)
+
+const (
+	storeElastic = "elasticsearch"
+	storeFile    = "file"
+)
+
+// RecordStore configures the file based record store that can serve OAI-PMH
+// and sitemaps instead of elasticsearch.
+type RecordStore struct {
+	// path of the directory with the stored records per organization
+	Path  string `json:"path"`
+	store *file.Store
+}
+
+func (r *RecordStore) FileStore() (*file.Store, error) {
+	if r.store != nil {
+		return r.store, nil
+	}
+
+	if r.Path == "" {
+		return nil, fmt.Errorf("recordStore.path must be set to use the file record store")
+	}
+
+	store, err := file.NewStore(r.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	r.store = store
+
+	return store, nil
+}
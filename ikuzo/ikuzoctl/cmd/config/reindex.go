@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/delving/hub3/ikuzo"
+	"github.com/delving/hub3/ikuzo/domain"
+	es "github.com/delving/hub3/ikuzo/driver/elasticsearch"
+	"github.com/delving/hub3/ikuzo/service/x/reindex"
+)
+
+type Reindex struct {
+	// enable the /api/index/reindex admin endpoints
+	Enabled bool `json:"enabled"`
+	service *reindex.Service
+}
+
+// Backend returns the elasticsearch reindex.Backend.
+func (r *Reindex) Backend(cfg *Config) (*es.ReindexBackend, error) {
+	if !cfg.ElasticSearch.Enabled {
+		return nil, fmt.Errorf("elasticsearch must be enabled to reindex")
+	}
+
+	client, err := cfg.ElasticSearch.NewCustomClient(&cfg.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.NewReindexBackend(), nil
+}
+
+func (r *Reindex) NewService(cfg *Config) (*reindex.Service, error) {
+	if r.service != nil {
+		return r.service, nil
+	}
+
+	backend, err := r.Backend(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	svc, err := reindex.NewService(reindex.SetBackend(backend))
+	if err != nil {
+		return nil, err
+	}
+
+	r.service = svc
+
+	return svc, nil
+}
+
+func (r *Reindex) AddOptions(cfg *Config) error {
+	if !r.Enabled {
+		return nil
+	}
+
+	svc, err := r.NewService(cfg)
+	if err != nil {
+		return err
+	}
+
+	cfg.options = append(
+		cfg.options,
+		ikuzo.RegisterService(svc),
+	)
+
+	return nil
+}
+
+// GetOrganizationConfig returns the configuration of the organization.
+func (cfg *Config) GetOrganizationConfig(orgID string) (*domain.OrganizationConfig, error) {
+	orgs, err := cfg.getOrganisationService(cfg.Organization.Store)
+	if err != nil {
+		return nil, err
+	}
+
+	orgCfg, ok := orgs.RetrieveConfig(orgID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", domain.ErrOrgNotFound, orgID)
+	}
+
+	return &orgCfg, nil
+}
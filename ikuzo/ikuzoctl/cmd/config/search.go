@@ -0,0 +1,117 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/delving/hub3/ikuzo"
+	"github.com/delving/hub3/ikuzo/domain"
+	"github.com/delving/hub3/ikuzo/search"
+	xsearch "github.com/delving/hub3/ikuzo/service/x/search"
+	"github.com/delving/hub3/ikuzo/storage/x/memory"
+)
+
+const (
+	searchBackendElastic = "elasticsearch"
+	searchBackendMemory  = "memory"
+)
+
+type Search struct {
+	// enable the /api/v3/search endpoint
+	Enabled bool `json:"enabled"`
+	// backend of the search requests: 'elasticsearch' (default) or 'memory'.
+	// The memory backend is filled by the index service.
+	Backend  string `json:"backend"`
+	searcher *memory.Searcher
+	service  *search.Service
+}
+
+// MemorySearcher returns the in-memory searcher. It is only used when the
+// memory backend is configured.
+func (s *Search) MemorySearcher(cfg *Config) (*memory.Searcher, error) {
+	if s.searcher != nil {
+		return s.searcher, nil
+	}
+
+	orgs, err := cfg.getOrganisationService(cfg.Organization.Store)
+	if err != nil {
+		return nil, err
+	}
+
+	s.searcher = memory.NewSearcher(memory.SetOrgConfigRetriever(orgs))
+
+	return s.searcher, nil
+}
+
+func (s *Search) NewService(cfg *Config) (*search.Service, error) {
+	if s.service != nil {
+		return s.service, nil
+	}
+
+	var searcher search.Searcher
+
+	switch s.Backend {
+	case "", searchBackendElastic:
+		if !cfg.ElasticSearch.Enabled {
+			return nil, fmt.Errorf("elasticsearch must be enabled for the elasticsearch search backend")
+		}
+
+		client, err := cfg.ElasticSearch.NewCustomClient(&cfg.logger)
+		if err != nil {
+			return nil, err
+		}
+
+		searcher = client.NewSearcher()
+	case searchBackendMemory:
+		memorySearcher, err := s.MemorySearcher(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		searcher = memorySearcher
+	default:
+		return nil, fmt.Errorf("unknown search backend: %s", s.Backend)
+	}
+
+	vocabularies, err := cfg.Vocabularies()
+	if err != nil {
+		return nil, err
+	}
+
+	svc, err := search.NewService(
+		search.SetSearcher(searcher),
+		search.SetQueryOptions(func(orgCfg *domain.OrganizationConfig) ([]xsearch.QueryOption, error) {
+			options := []xsearch.QueryOption{}
+
+			if v, ok := vocabularies[orgCfg.OrgID()]; ok {
+				options = append(options, xsearch.SetExpander(v))
+			}
+
+			return options, nil
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s.service = svc
+
+	return svc, nil
+}
+
+func (s *Search) AddOptions(cfg *Config) error {
+	if !s.Enabled {
+		return nil
+	}
+
+	svc, err := s.NewService(cfg)
+	if err != nil {
+		return err
+	}
+
+	cfg.options = append(
+		cfg.options,
+		ikuzo.RegisterService(svc),
+	)
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/delving/hub3/ikuzo/domain"
+	"github.com/delving/hub3/ikuzo/rdf/formats/turtle"
+	"github.com/delving/hub3/ikuzo/rdf/shacl"
+	"github.com/delving/hub3/ikuzo/service/x/bulk"
+)
+
+// getBulkValidations returns the bulk options for the SHACL validation that is
+// configured for the organizations and their datasets.
+func (cfg *Config) getBulkValidations() ([]bulk.Option, error) {
+	orgs, err := cfg.getOrganisationService("")
+	if err != nil {
+		return nil, err
+	}
+
+	cfgs, err := orgs.Configs(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+
+	options := []bulk.Option{}
+
+	for _, org := range cfgs {
+		option, err := newBulkValidation(org.OrgID(), "", org.SHACL.SHACLConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		if option != nil {
+			options = append(options, option)
+		}
+
+		for datasetID, dsCfg := range org.SHACL.Datasets {
+			option, err := newBulkValidation(org.OrgID(), datasetID, dsCfg)
+			if err != nil {
+				return nil, err
+			}
+
+			if option != nil {
+				options = append(options, option)
+			}
+		}
+	}
+
+	return options, nil
+}
+
+func newBulkValidation(orgID, datasetID string, shaclCfg domain.SHACLConfig) (bulk.Option, error) {
+	if shaclCfg.Mode == "" {
+		return nil, nil
+	}
+
+	mode, err := bulk.ParseValidationMode(shaclCfg.Mode)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(shaclCfg.Shapes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open SHACL shapes for %s; %w", orgID, err)
+	}
+	defer f.Close()
+
+	g, err := turtle.Parse(f, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse SHACL shapes %s; %w", shaclCfg.Shapes, err)
+	}
+
+	v, err := shacl.NewValidator(g)
+	if err != nil {
+		return nil, err
+	}
+
+	return bulk.SetValidation(orgID, datasetID, v, mode), nil
+}
@@ -1,20 +1,39 @@
 package config
 
 import (
+	"fmt"
+
 	"github.com/delving/hub3/ikuzo"
 	"github.com/delving/hub3/ikuzo/service/x/sitemap"
 )
 
-type Sitemap struct{}
+type Sitemap struct {
+	// store of the records: 'elasticsearch' (default) or 'file'
+	Store string `json:"store"`
+}
+
+func (s *Sitemap) store(cfg *Config) (sitemap.Store, error) {
+	switch s.Store {
+	case "", storeElastic:
+		client, err := cfg.ElasticSearch.NewCustomClient(cfg.log)
+		if err != nil {
+			return nil, err
+		}
+
+		return client.NewSitemapStore(), nil
+	case storeFile:
+		return cfg.RecordStore.FileStore()
+	}
+
+	return nil, fmt.Errorf("unknown sitemap store: %s", s.Store)
+}
 
 func (s *Sitemap) NewService(cfg *Config) (*sitemap.Service, error) {
-	client, err := cfg.ElasticSearch.NewCustomClient(cfg.log)
+	store, err := s.store(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	store := client.NewSitemapStore()
-
 	svc, err := sitemap.NewService(
 		sitemap.SetStore(store),
 	)
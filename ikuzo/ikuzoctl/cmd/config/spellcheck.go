@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/delving/hub3/ikuzo"
+	"github.com/delving/hub3/ikuzo/service/x/search"
+	"github.com/delving/hub3/ikuzo/service/x/spellcheck"
+)
+
+type SpellCheck struct {
+	// enable training of the spelling models and the didYouMean search results
+	Enabled bool `json:"enabled"`
+	// directory where the spelling models are persisted
+	DataDir string `json:"dataDir"`
+	// maximum number of hits for which didYouMean is returned. default: 0
+	MaxHits int `json:"maxHits"`
+	// minimum number of occurrences before a word is suggested. default: 5
+	Threshold int `json:"threshold"`
+	// interval at which the changed models are saved, e.g. '5m'. default: 5m
+	SaveInterval string `json:"saveInterval"`
+	service      *spellcheck.Service
+}
+
+func (s *SpellCheck) NewService(cfg *Config) (*spellcheck.Service, error) {
+	if s.service != nil {
+		return s.service, nil
+	}
+
+	options := []spellcheck.Option{
+		spellcheck.SetDataDir(s.DataDir),
+		spellcheck.SetMaxHits(s.MaxHits),
+	}
+
+	if s.SaveInterval != "" {
+		interval, err := time.ParseDuration(s.SaveInterval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid spellCheck.saveInterval: %w", err)
+		}
+
+		options = append(options, spellcheck.SetSaveInterval(interval))
+	}
+
+	if s.Threshold > 0 {
+		options = append(options, spellcheck.SetSpellCheckOptions(search.SetThreshold(s.Threshold)))
+	}
+
+	svc, err := spellcheck.NewService(options...)
+	if err != nil {
+		return nil, err
+	}
+
+	s.service = svc
+
+	return svc, nil
+}
+
+func (s *SpellCheck) AddOptions(cfg *Config) error {
+	if !s.Enabled {
+		return nil
+	}
+
+	svc, err := s.NewService(cfg)
+	if err != nil {
+		return err
+	}
+
+	cfg.options = append(
+		cfg.options,
+		ikuzo.SetShutdownHook("spellcheck", svc),
+	)
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"github.com/delving/hub3/ikuzo"
+	"github.com/delving/hub3/ikuzo/service/x/suggest"
+)
+
+type Suggest struct {
+	// train the in-memory suggestions with the indexed records. They are used
+	// for organizations without a 'suggest' elasticsearch index.
+	MemoryFallback bool `json:"memoryFallback"`
+	memory         *suggest.MemoryStore
+	service        *suggest.Service
+}
+
+func (s *Suggest) MemoryStore() *suggest.MemoryStore {
+	if s.memory == nil {
+		s.memory = suggest.NewMemoryStore()
+	}
+
+	return s.memory
+}
+
+func (s *Suggest) NewService(cfg *Config) (*suggest.Service, error) {
+	if s.service != nil {
+		return s.service, nil
+	}
+
+	options := []suggest.Option{
+		suggest.SetMemoryStore(s.MemoryStore()),
+	}
+
+	if cfg.ElasticSearch.Enabled {
+		client, err := cfg.ElasticSearch.NewCustomClient(&cfg.logger)
+		if err != nil {
+			return nil, err
+		}
+
+		options = append(options, suggest.SetStore(client.NewSuggestStore()))
+	}
+
+	svc, err := suggest.NewService(options...)
+	if err != nil {
+		return nil, err
+	}
+
+	s.service = svc
+
+	return svc, nil
+}
+
+func (s *Suggest) AddOptions(cfg *Config) error {
+	svc, err := s.NewService(cfg)
+	if err != nil {
+		return err
+	}
+
+	cfg.options = append(
+		cfg.options,
+		ikuzo.RegisterService(svc),
+	)
+
+	return nil
+}
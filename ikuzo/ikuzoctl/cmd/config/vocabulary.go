@@ -0,0 +1,46 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/delving/hub3/hub3/fragments"
+	"github.com/delving/hub3/ikuzo/service/x/vocabulary"
+)
+
+// Vocabularies returns the vocabulary services of the organizations that
+// configure SKOS files in 'org.{id}.vocabulary'. The services are registered
+// as the Expander of the v2 search as well.
+func (cfg *Config) Vocabularies() (map[string]*vocabulary.Service, error) {
+	if cfg.vocabularies != nil {
+		return cfg.vocabularies, nil
+	}
+
+	vocabularies := map[string]*vocabulary.Service{}
+
+	for id, org := range cfg.Org {
+		if len(org.Vocabulary.Files) == 0 {
+			continue
+		}
+
+		orgID := id
+		if org.CustomID != "" {
+			orgID = org.CustomID
+		}
+
+		svc, err := vocabulary.NewService(
+			vocabulary.SetNarrowerDepth(org.Vocabulary.NarrowerDepth),
+			vocabulary.SetFiles(org.Vocabulary.Files...),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load vocabulary of %s; %w", orgID, err)
+		}
+
+		fragments.SetExpander(orgID, svc)
+
+		vocabularies[orgID] = svc
+	}
+
+	cfg.vocabularies = vocabularies
+
+	return vocabularies, nil
+}
@@ -0,0 +1,161 @@
+/*
+Copyright © 2020 Delving B.V. <info@delving.eu>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/delving/hub3/ikuzo/service/x/reindex"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// reindexCmd represents the reindex command
+var reindexCmd = &cobra.Command{
+	Use:   "reindex",
+	Short: "rebuild an index without downtime",
+	Long: `This command creates a new versioned index for an index type of the
+	organization and populates it from the current index, an older versioned
+	index of the same index type or a directory with JSON search records.
+
+	When the document count of the new index matches the source, the alias is
+	switched to the new index. Optionally the old index is deleted.
+
+	This command uses the default hub3 configuration file`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := reindexOrg(); err != nil {
+			log.Fatal().Err(err).Msg("unable to reindex")
+		}
+	},
+}
+
+var (
+	reindexType      string
+	reindexFrom      string
+	reindexPath      string
+	reindexDeleteOld bool
+)
+
+func init() {
+	rootCmd.AddCommand(reindexCmd)
+
+	reindexCmd.Flags().StringVarP(&orgID, "orgID", "", "", "orgID of the index")
+	reindexCmd.Flags().StringVarP(&reindexType, "indexType", "t", "v2", "index type that is reindexed")
+	reindexCmd.Flags().StringVarP(&reindexFrom, "from", "f", "", "versioned index of the index type to copy the documents from (default: the alias)")
+	reindexCmd.Flags().StringVarP(&reindexPath, "path", "p", "", "directory with JSON search records to index instead of an index")
+	reindexCmd.Flags().BoolVarP(&reindexDeleteOld, "deleteOld", "d", false, "delete the old index after the alias is switched")
+}
+
+// progressLogger logs the progress of the reindex at most once per interval.
+type progressLogger struct {
+	mu       sync.Mutex
+	interval time.Duration
+	last     time.Time
+}
+
+func (pl *progressLogger) log(p reindex.Progress) {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+
+	if time.Since(pl.last) < pl.interval && p.Done != p.Total {
+		return
+	}
+
+	pl.last = time.Now()
+
+	log.Info().Int64("done", p.Done).Int64("total", p.Total).Msg("reindex progress")
+}
+
+func reindexOrg() error {
+	if orgID == "" {
+		return fmt.Errorf("orgID is required")
+	}
+
+	orgCfg, err := cfg.GetOrganizationConfig(orgID)
+	if err != nil {
+		return err
+	}
+
+	backend, err := cfg.Reindex.Backend(&cfg)
+	if err != nil {
+		return err
+	}
+
+	svc, err := cfg.Reindex.NewService(&cfg)
+	if err != nil {
+		return err
+	}
+
+	alias, mapping, err := backend.Mapping(orgCfg, reindexType)
+	if err != nil {
+		return err
+	}
+
+	source := backend.IndexSource(alias)
+
+	switch {
+	case reindexPath != "":
+		source = backend.DirSource(reindexPath)
+	case reindexFrom != "":
+		if err := reindex.CheckSource(alias, reindexFrom); err != nil {
+			return err
+		}
+
+		source = backend.IndexSource(reindexFrom)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+
+	go func() {
+		<-sigs
+		log.Info().Msg("caught shutdown signal, cancelling reindex")
+		cancel()
+	}()
+
+	pl := &progressLogger{interval: 3 * time.Second}
+
+	job, err := svc.Reindex(ctx, &reindex.Request{
+		OrgID:     orgID,
+		Alias:     alias,
+		Mapping:   mapping,
+		Source:    source,
+		DeleteOld: reindexDeleteOld,
+		Progress:  pl.log,
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Info().
+		Str("alias", job.Alias).
+		Str("oldIndex", job.OldIndex).
+		Str("newIndex", job.NewIndex).
+		Int64("count", job.Count).
+		Bool("oldIndexDeleted", job.DeleteOld && job.OldIndex != "").
+		Msg("reindex finished and alias switched")
+
+	return nil
+}
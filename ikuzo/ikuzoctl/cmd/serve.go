@@ -21,6 +21,7 @@ import (
 
 	"github.com/delving/hub3/hub3/server/http/handlers"
 	"github.com/delving/hub3/ikuzo"
+	"github.com/delving/hub3/ikuzo/service/x/spellcheck"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 )
@@ -56,6 +57,18 @@ func serve() {
 			Msg("unable to create options")
 	}
 
+	var spell *spellcheck.Service
+
+	if cfg.SpellCheck.Enabled {
+		spell, err = cfg.SpellCheck.NewService(&cfg)
+		if err != nil {
+			log.Fatal().
+				Err(err).
+				Stack().
+				Msg("unable to create spellcheck service")
+		}
+	}
+
 	options = append(
 		options,
 		ikuzo.SetBuildVersionInfo(
@@ -64,7 +77,7 @@ func serve() {
 		ikuzo.SetLegacyRouters(
 			handlers.RegisterDatasets,
 			handlers.RegisterEAD,
-			handlers.RegisterSearch,
+			handlers.NewSearchRouter(spell),
 			handlers.RegisterLinkedDataFragments,
 			handlers.RegisterLOD,
 			handlers.RegisterSparql,
@@ -0,0 +1,141 @@
+package rdf
+
+import (
+	"sync"
+)
+
+// Dataset is a collection of a default Graph and zero or more named Graphs.
+//
+// The order in which the named graphs are added is remembered.
+type Dataset struct {
+	defaultGraph *Graph
+	graphs       map[string]*Graph
+	names        []Context
+	lock         sync.Mutex
+}
+
+// NewDataset returns a Dataset with an empty default graph.
+func NewDataset() *Dataset {
+	return &Dataset{
+		defaultGraph: NewGraph(),
+		graphs:       map[string]*Graph{},
+	}
+}
+
+// DefaultGraph returns the unnamed graph of the Dataset.
+func (ds *Dataset) DefaultGraph() *Graph {
+	return ds.defaultGraph
+}
+
+// Graph returns the named graph. When the graph is not part of the Dataset an
+// empty graph is added. When the Context is nil the default graph is returned.
+func (ds *Dataset) Graph(ctx Context) *Graph {
+	if ctx == nil {
+		return ds.defaultGraph
+	}
+
+	ds.lock.Lock()
+	defer ds.lock.Unlock()
+
+	g, ok := ds.graphs[ctx.String()]
+	if !ok {
+		g = NewGraph()
+		ds.graphs[ctx.String()] = g
+		ds.names = append(ds.names, ctx)
+	}
+
+	return g
+}
+
+// Lookup returns the named graph and if it was present in the Dataset.
+func (ds *Dataset) Lookup(ctx Context) (*Graph, bool) {
+	if ctx == nil {
+		return ds.defaultGraph, true
+	}
+
+	ds.lock.Lock()
+	defer ds.lock.Unlock()
+
+	g, ok := ds.graphs[ctx.String()]
+
+	return g, ok
+}
+
+// Remove removes the named graph from the Dataset.
+func (ds *Dataset) Remove(ctx Context) {
+	if ctx == nil {
+		return
+	}
+
+	ds.lock.Lock()
+	defer ds.lock.Unlock()
+
+	key := ctx.String()
+	if _, ok := ds.graphs[key]; !ok {
+		return
+	}
+
+	delete(ds.graphs, key)
+
+	for i, name := range ds.names {
+		if name.String() == key {
+			ds.names = append(ds.names[:i], ds.names[i+1:]...)
+			break
+		}
+	}
+}
+
+// Names returns the names of the named graphs in insertion order.
+func (ds *Dataset) Names() []Context {
+	ds.lock.Lock()
+	defer ds.lock.Unlock()
+
+	names := make([]Context, len(ds.names))
+	copy(names, ds.names)
+
+	return names
+}
+
+// Add adds the quads to the graph of their Context.
+func (ds *Dataset) Add(quads ...*Quad) {
+	for _, q := range quads {
+		ds.Graph(q.Context()).Add(q.Triple)
+	}
+}
+
+// AddTriple adds the triple to the named graph.
+func (ds *Dataset) AddTriple(ctx Context, t *Triple) {
+	ds.Graph(ctx).Add(t)
+}
+
+// Quads returns all quads in the Dataset. The quads of the default graph are returned
+// first, followed by the named graphs in insertion order.
+func (ds *Dataset) Quads() []*Quad {
+	quads := []*Quad{}
+
+	for _, t := range ds.defaultGraph.Triples() {
+		quads = append(quads, &Quad{Triple: t})
+	}
+
+	for _, name := range ds.Names() {
+		g, _ := ds.Lookup(name)
+
+		for _, t := range g.Triples() {
+			quads = append(quads, &Quad{Triple: t, ctx: name})
+		}
+	}
+
+	return quads
+}
+
+// Len returns the number of quads in the Dataset.
+func (ds *Dataset) Len() int {
+	total := ds.defaultGraph.Len()
+
+	for _, name := range ds.Names() {
+		g, _ := ds.Lookup(name)
+		total += g.Len()
+	}
+
+	return total
+}
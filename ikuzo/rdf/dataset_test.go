@@ -0,0 +1,76 @@
+package rdf_test
+
+import (
+	"testing"
+
+	"github.com/delving/hub3/ikuzo/rdf"
+	"github.com/matryer/is"
+)
+
+func TestDataset(t *testing.T) {
+	is := is.New(t)
+
+	s, err := rdf.NewIRI("urn:s/123")
+	is.NoErr(err)
+
+	p, err := rdf.DC.IRI("subject")
+	is.NoErr(err)
+
+	o, err := rdf.NewLiteralWithLang("some text", "en")
+	is.NoErr(err)
+
+	triple := rdf.NewTriple(s, p, o)
+
+	g1, err := rdf.NewIRI("urn:graph/1")
+	is.NoErr(err)
+
+	g2, err := rdf.NewBlankNode("g2")
+	is.NoErr(err)
+
+	ds := rdf.NewDataset()
+	is.Equal(ds.Len(), 0)
+	is.Equal(len(ds.Names()), 0)
+
+	defaultQuad, err := rdf.NewQuad(triple, nil)
+	is.NoErr(err)
+
+	namedQuad, err := rdf.NewQuad(triple, g1)
+	is.NoErr(err)
+
+	ds.Add(defaultQuad, namedQuad)
+	ds.AddTriple(g2, triple)
+
+	// same triple in the same graph should not be added twice
+	ds.AddTriple(g1, triple)
+
+	is.Equal(ds.Len(), 3)
+	is.Equal(ds.DefaultGraph().Len(), 1)
+	is.Equal(ds.Names(), []rdf.Context{g1, g2})
+
+	_, ok := ds.Lookup(g1)
+	is.True(ok)
+
+	unknown, err := rdf.NewIRI("urn:graph/unknown")
+	is.NoErr(err)
+
+	_, ok = ds.Lookup(unknown)
+	is.True(!ok)
+
+	quads := ds.Quads()
+	is.Equal(len(quads), 3)
+	is.True(quads[0].Context() == nil)
+	is.True(quads[0].Equal(defaultQuad))
+	is.True(quads[1].Equal(namedQuad))
+	is.True(!quads[1].Equal(defaultQuad))
+	is.Equal(quads[2].Context(), rdf.Context(g2))
+
+	is.Equal(quads[0].String(), `<urn:s/123> <http://purl.org/dc/elements/1.1/subject> "some text"@en .`)
+	is.Equal(quads[1].String(), `<urn:s/123> <http://purl.org/dc/elements/1.1/subject> "some text"@en <urn:graph/1> .`)
+
+	ds.Remove(g1)
+	is.Equal(ds.Len(), 2)
+	is.Equal(ds.Names(), []rdf.Context{g2})
+
+	_, err = rdf.NewQuad(nil, g1)
+	is.True(err != nil)
+}
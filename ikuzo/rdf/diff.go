@@ -0,0 +1,246 @@
+package rdf
+
+import (
+	"sort"
+	"strings"
+)
+
+// GraphDiff contains the triples that were added and removed between two
+// versions of a Graph.
+//
+// Removed triples are taken from the old Graph and Added triples from the new
+// Graph, so blank nodes keep the labels of the Graph they originate from.
+type GraphDiff struct {
+	Added   []*Triple
+	Removed []*Triple
+}
+
+// IsEmpty returns true when both graphs are isomorphic.
+func (d *GraphDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0
+}
+
+// Diff computes the triples that are added and removed to turn from into to.
+//
+// Blank nodes are compared by their canonical label instead of their label in
+// the source document, so re-parsing the same data with fresh blank node labels
+// does not produce a diff. A nil Graph is treated as empty.
+func Diff(from, to *Graph) *GraphDiff {
+	oldKeys, oldTriples := canonicalTriples(from)
+	newKeys, newTriples := canonicalTriples(to)
+
+	oldSet := make(map[string]bool, len(oldKeys))
+	for _, k := range oldKeys {
+		oldSet[k] = true
+	}
+
+	newSet := make(map[string]bool, len(newKeys))
+	for _, k := range newKeys {
+		newSet[k] = true
+	}
+
+	diff := &GraphDiff{
+		Added:   []*Triple{},
+		Removed: []*Triple{},
+	}
+
+	for i, k := range oldKeys {
+		if !newSet[k] {
+			diff.Removed = append(diff.Removed, oldTriples[i])
+		}
+	}
+
+	for i, k := range newKeys {
+		if !oldSet[k] {
+			diff.Added = append(diff.Added, newTriples[i])
+		}
+	}
+
+	return diff
+}
+
+// Isomorphic returns true when the graphs contain the same triples, when blank
+// nodes are compared by their canonical label.
+func Isomorphic(a, b *Graph) bool {
+	return Diff(a, b).IsEmpty()
+}
+
+// canonicalTriples returns the triples of g with the canonical key of each
+// triple at the same position.
+func canonicalTriples(g *Graph) ([]string, []*Triple) {
+	if g == nil {
+		return []string{}, []*Triple{}
+	}
+
+	triples := g.Triples()
+	labels := CanonicalLabels(triples)
+
+	keys := make([]string, 0, len(triples))
+
+	for _, t := range triples {
+		keys = append(keys, canonicalKey(t, labels))
+	}
+
+	return keys, triples
+}
+
+func canonicalKey(t *Triple, labels map[string]string) string {
+	term := func(term Term) string {
+		if b, ok := term.(BlankNode); ok {
+			return "_:" + labels[b.RawValue()]
+		}
+
+		return term.String()
+	}
+
+	return term(t.Subject) + " " + term(t.Predicate) + " " + term(t.Object)
+}
+
+// CanonicalLabels returns a canonical label for each blank node in the triples,
+// keyed by the raw label of the blank node.
+//
+// The label is derived from the triples the blank node occurs in. The hashes
+// are refined iteratively with the hashes of the neighbouring blank nodes until
+// the partition of the blank nodes is stable. Blank nodes that cannot be told
+// apart are distinguished one at a time, which gives the same result for
+// automorphic blank nodes, but is not guaranteed to be canonical for every
+// pathological graph.
+func CanonicalLabels(triples []*Triple) map[string]string {
+	incident := map[string][]*Triple{}
+	nodes := []string{}
+
+	addNode := func(term Term, t *Triple) {
+		b, ok := term.(BlankNode)
+		if !ok {
+			return
+		}
+
+		label := b.RawValue()
+		if _, ok := incident[label]; !ok {
+			nodes = append(nodes, label)
+		}
+
+		incident[label] = append(incident[label], t)
+	}
+
+	for _, t := range triples {
+		addNode(t.Subject, t)
+
+		if !t.Subject.Equal(t.Object) {
+			addNode(t.Object, t)
+		}
+	}
+
+	if len(nodes) == 0 {
+		return map[string]string{}
+	}
+
+	hashes := make(map[string]string, len(nodes))
+	for _, n := range nodes {
+		hashes[n] = ""
+	}
+
+	hashes = refineHashes(nodes, incident, hashes)
+
+	for {
+		tied := tiedNode(nodes, hashes)
+		if tied == "" {
+			break
+		}
+
+		hashes[tied] = hash(hashes[tied] + "+")
+		hashes = refineHashes(nodes, incident, hashes)
+	}
+
+	labels := make(map[string]string, len(nodes))
+	for _, n := range nodes {
+		labels[n] = "c" + hashes[n]
+	}
+
+	return labels
+}
+
+// refineHashes rehashes each blank node with the signatures of its triples
+// until the number of distinct hashes no longer increases.
+func refineHashes(nodes []string, incident map[string][]*Triple, hashes map[string]string) map[string]string {
+	partitions := countDistinct(hashes)
+
+	for i := 0; i <= len(nodes); i++ {
+		next := make(map[string]string, len(hashes))
+
+		for _, n := range nodes {
+			signatures := make([]string, 0, len(incident[n]))
+
+			for _, t := range incident[n] {
+				signatures = append(signatures, signature(t, n, hashes))
+			}
+
+			sort.Strings(signatures)
+
+			next[n] = hash(hashes[n] + "|" + strings.Join(signatures, "\n"))
+		}
+
+		hashes = next
+
+		count := countDistinct(hashes)
+		if i > 0 && count == partitions {
+			break
+		}
+
+		partitions = count
+	}
+
+	return hashes
+}
+
+// signature returns the representation of the triple from the perspective of
+// blank node n. Other blank nodes are represented by their current hash.
+func signature(t *Triple, n string, hashes map[string]string) string {
+	term := func(term Term) string {
+		b, ok := term.(BlankNode)
+		if !ok {
+			return term.String()
+		}
+
+		if b.RawValue() == n {
+			return "_:@"
+		}
+
+		return "_:" + hashes[b.RawValue()]
+	}
+
+	return term(t.Subject) + " " + term(t.Predicate) + " " + term(t.Object)
+}
+
+// tiedNode returns a blank node that shares its hash with another blank node.
+// The node with the lowest shared hash is returned; an empty string is returned
+// when all hashes are unique.
+func tiedNode(nodes []string, hashes map[string]string) string {
+	count := map[string]int{}
+	for _, n := range nodes {
+		count[hashes[n]]++
+	}
+
+	var (
+		tied     string
+		tiedHash string
+	)
+
+	for _, n := range nodes {
+		h := hashes[n]
+		if count[h] > 1 && (tied == "" || h < tiedHash) {
+			tied, tiedHash = n, h
+		}
+	}
+
+	return tied
+}
+
+func countDistinct(hashes map[string]string) int {
+	seen := map[string]bool{}
+	for _, h := range hashes {
+		seen[h] = true
+	}
+
+	return len(seen)
+}
@@ -0,0 +1,154 @@
+package rdf_test
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/delving/hub3/ikuzo/rdf"
+	"github.com/delving/hub3/ikuzo/rdf/formats/ntriples"
+	"github.com/google/go-cmp/cmp"
+	"github.com/matryer/is"
+)
+
+func parseNTriples(t *testing.T, data string) *rdf.Graph {
+	t.Helper()
+
+	g, err := ntriples.Parse(strings.NewReader(data), nil)
+	if err != nil {
+		t.Fatalf("unable to parse ntriples: %s", err)
+	}
+
+	return g
+}
+
+func tripleStrings(triples []*rdf.Triple) []string {
+	out := []string{}
+	for _, t := range triples {
+		out = append(out, t.String())
+	}
+
+	sort.Strings(out)
+
+	return out
+}
+
+func TestDiff(t *testing.T) {
+	base := `<urn:s/1> <urn:p/title> "title" .
+<urn:s/1> <urn:p/creator> _:c1 .
+_:c1 <urn:p/name> "Jan" .
+_:c1 <urn:p/role> _:r1 .
+_:r1 <urn:p/label> "painter" .
+`
+
+	tests := []struct {
+		name        string
+		from        string
+		to          string
+		wantAdded   []string
+		wantRemoved []string
+	}{
+		{
+			name:        "identical",
+			from:        base,
+			to:          base,
+			wantAdded:   []string{},
+			wantRemoved: []string{},
+		},
+		{
+			name: "relabelled blank nodes",
+			from: base,
+			to: `_:x <urn:p/label> "painter" .
+_:y <urn:p/role> _:x .
+_:y <urn:p/name> "Jan" .
+<urn:s/1> <urn:p/creator> _:y .
+<urn:s/1> <urn:p/title> "title" .
+`,
+			wantAdded:   []string{},
+			wantRemoved: []string{},
+		},
+		{
+			name: "changed literal",
+			from: base,
+			to:   strings.Replace(base, `"title"`, `"new title"`, 1),
+			wantAdded: []string{
+				`<urn:s/1> <urn:p/title> "new title" .`,
+			},
+			wantRemoved: []string{
+				`<urn:s/1> <urn:p/title> "title" .`,
+			},
+		},
+		{
+			name: "changed nested blank node",
+			from: base,
+			to: `<urn:s/1> <urn:p/title> "title" .
+<urn:s/1> <urn:p/creator> _:a .
+_:a <urn:p/name> "Jan" .
+_:a <urn:p/role> _:b .
+_:b <urn:p/label> "etcher" .
+`,
+			wantAdded: []string{
+				`<urn:s/1> <urn:p/creator> _:a .`,
+				`_:a <urn:p/name> "Jan" .`,
+				`_:a <urn:p/role> _:b .`,
+				`_:b <urn:p/label> "etcher" .`,
+			},
+			wantRemoved: []string{
+				`<urn:s/1> <urn:p/creator> _:c1 .`,
+				`_:c1 <urn:p/name> "Jan" .`,
+				`_:c1 <urn:p/role> _:r1 .`,
+				`_:r1 <urn:p/label> "painter" .`,
+			},
+		},
+		{
+			name: "indistinguishable blank nodes",
+			from: `<urn:s/1> <urn:p/note> _:n1 .
+<urn:s/1> <urn:p/note> _:n2 .
+`,
+			to: `<urn:s/1> <urn:p/note> _:m2 .
+<urn:s/1> <urn:p/note> _:m1 .
+`,
+			wantAdded:   []string{},
+			wantRemoved: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+
+			diff := rdf.Diff(parseNTriples(t, tt.from), parseNTriples(t, tt.to))
+
+			sort.Strings(tt.wantAdded)
+			sort.Strings(tt.wantRemoved)
+
+			if d := cmp.Diff(tt.wantAdded, tripleStrings(diff.Added)); d != "" {
+				t.Errorf("Diff() added mismatch (-want +got):\n%s", d)
+			}
+
+			if d := cmp.Diff(tt.wantRemoved, tripleStrings(diff.Removed)); d != "" {
+				t.Errorf("Diff() removed mismatch (-want +got):\n%s", d)
+			}
+
+			is.Equal(diff.IsEmpty(), len(tt.wantAdded) == 0 && len(tt.wantRemoved) == 0)
+		})
+	}
+}
+
+func TestDiffNilGraph(t *testing.T) {
+	is := is.New(t)
+
+	g := parseNTriples(t, "<urn:s/1> <urn:p/1> <urn:o/1> .\n")
+
+	diff := rdf.Diff(nil, g)
+	is.Equal(len(diff.Added), 1)
+	is.Equal(len(diff.Removed), 0)
+
+	diff = rdf.Diff(g, nil)
+	is.Equal(len(diff.Added), 0)
+	is.Equal(len(diff.Removed), 1)
+
+	is.True(rdf.Isomorphic(g, g))
+}
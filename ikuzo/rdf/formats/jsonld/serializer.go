@@ -0,0 +1,209 @@
+package jsonld
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/piprate/json-gold/ld"
+
+	"github.com/delving/hub3/ikuzo/rdf"
+)
+
+// Form is the JSON-LD document form that is produced by Serialize.
+type Form int
+
+const (
+	// Expanded removes the context and expands all IRIs, types and values.
+	Expanded Form = iota
+	// Compacted applies the context to shorten IRIs into prefixed terms.
+	Compacted
+	// Framed nests the resources under the resources that match the frame.
+	// The result is compacted with the context.
+	Framed
+)
+
+// SerializeConfig determines which JSON-LD form is produced by Serialize.
+type SerializeConfig struct {
+	Form Form
+
+	// Context is used for compaction and framing. When nil a context is
+	// generated from the namespaces that are used in the Graph.
+	Context map[string]interface{}
+
+	// FrameType is the rdf:type of the root resources of a framed document.
+	FrameType rdf.IRI
+
+	// FrameSubject is the root resource of a framed document. It is only used
+	// when FrameType is empty.
+	FrameSubject rdf.Subject
+
+	// Indent pretty prints the JSON output
+	Indent bool
+}
+
+// Serialize writes the Graph as JSON-LD to w. When cfg is nil the
+// Expanded form is written.
+func Serialize(g *rdf.Graph, w io.Writer, cfg *SerializeConfig) error {
+	if cfg == nil {
+		cfg = &SerializeConfig{}
+	}
+
+	doc, err := Document(g, cfg)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+
+	if cfg.Indent {
+		enc.SetIndent("", "  ")
+	}
+
+	return enc.Encode(doc)
+}
+
+// Document returns the Graph as a JSON-LD document in the form
+// that is set in the SerializeConfig.
+func Document(g *rdf.Graph, cfg *SerializeConfig) (interface{}, error) {
+	opts := ld.NewJsonLdOptions("")
+	opts.UseNativeTypes = false
+	opts.UseRdfType = false
+
+	expanded, err := ld.NewJsonLdApi().FromRDF(dataset(g), opts)
+	if err != nil {
+		return nil, fmt.Errorf("unable to expand graph; %w", err)
+	}
+
+	if cfg.Form == Expanded {
+		return expanded, nil
+	}
+
+	ctx := cfg.Context
+	if ctx == nil {
+		ctx, err = Context(g)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	proc := ld.NewJsonLdProcessor()
+
+	switch cfg.Form {
+	case Compacted:
+		return proc.Compact(expanded, map[string]interface{}{"@context": ctx}, opts)
+	case Framed:
+		frame := map[string]interface{}{
+			"@context": ctx,
+		}
+
+		switch {
+		case !cfg.FrameType.Equal(rdf.IRI{}):
+			frame["@type"] = cfg.FrameType.RawValue()
+		case cfg.FrameSubject != nil:
+			frame["@id"] = cfg.FrameSubject.RawValue()
+		default:
+			return nil, fmt.Errorf("jsonld: FrameType or FrameSubject is required for framing")
+		}
+
+		opts.Embed = ld.EmbedAlways
+		opts.OmitGraph = true
+
+		return proc.Frame(expanded, frame, opts)
+	}
+
+	return nil, fmt.Errorf("jsonld: unsupported form %d", cfg.Form)
+}
+
+// Context returns a JSON-LD context with the prefixes of all the namespaces
+// in the Graph that are known by its NamespaceManager.
+func Context(g *rdf.Graph) (map[string]interface{}, error) {
+	nm := g.NamespaceManager
+	if nm == nil {
+		nm = rdf.DefaultNamespaceManager
+	}
+
+	ctx := map[string]interface{}{}
+	if nm == nil {
+		return ctx, nil
+	}
+
+	seen := map[string]bool{}
+
+	addIRI := func(term rdf.Term) {
+		var iri rdf.IRI
+
+		switch t := term.(type) {
+		case rdf.IRI:
+			iri = t
+		case rdf.Literal:
+			if t.DataType.Equal(rdf.IRI{}) || t.HasImpliedDataType() {
+				return
+			}
+
+			iri = t.DataType
+		default:
+			return
+		}
+
+		base, _ := iri.Split()
+		if base == "" || seen[base] {
+			return
+		}
+
+		seen[base] = true
+
+		ns, err := nm.GetWithBase(base)
+		if err != nil || ns.Prefix == "" || ns.Base != base {
+			return
+		}
+
+		ctx[ns.Prefix] = ns.Base
+	}
+
+	for _, t := range g.Triples() {
+		addIRI(t.Subject)
+		addIRI(t.Predicate)
+		addIRI(t.Object)
+	}
+
+	return ctx, nil
+}
+
+// dataset converts the Graph into the default graph of a ld.RDFDataset.
+func dataset(g *rdf.Graph) *ld.RDFDataset {
+	ds := ld.NewRDFDataset()
+
+	triples := g.Triples()
+	quads := make([]*ld.Quad, 0, len(triples))
+
+	for _, t := range triples {
+		quads = append(quads, ld.NewQuad(
+			term2ldnode(t.Subject),
+			term2ldnode(t.Predicate),
+			term2ldnode(t.Object),
+			"@default",
+		))
+	}
+
+	ds.Graphs["@default"] = quads
+
+	return ds
+}
+
+func term2ldnode(term rdf.Term) ld.Node {
+	switch t := term.(type) {
+	case rdf.BlankNode:
+		return ld.NewBlankNode(t.String())
+	case rdf.Literal:
+		dt := t.DataType.RawValue()
+		if dt == "" {
+			dt = ld.XSDString
+		}
+
+		return ld.NewLiteral(t.RawValue(), dt, t.Lang())
+	default:
+		return ld.NewIRI(term.RawValue())
+	}
+}
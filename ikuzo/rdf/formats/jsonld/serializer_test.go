@@ -0,0 +1,107 @@
+package jsonld
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/delving/hub3/ikuzo/rdf"
+	"github.com/matryer/is"
+)
+
+func testGraph() *rdf.Graph {
+	b := rdf.Builder{}
+
+	g := rdf.NewGraph()
+	g.AddTriple(
+		b.IRI("http://example.org/aggregation/1"),
+		rdf.IsA,
+		b.IRI("http://www.openarchives.org/ore/terms/Aggregation"),
+	)
+	g.AddTriple(
+		b.IRI("http://example.org/aggregation/1"),
+		b.IRI("http://www.europeana.eu/schemas/edm/hasView"),
+		b.IRI("http://example.org/media/1.jpg"),
+	)
+	g.AddTriple(
+		b.IRI("http://example.org/media/1.jpg"),
+		rdf.IsA,
+		b.IRI("http://www.europeana.eu/schemas/edm/WebResource"),
+	)
+	g.AddTriple(
+		b.IRI("http://example.org/media/1.jpg"),
+		b.IRI("http://purl.org/dc/elements/1.1/title"),
+		b.LiteralWithLang("voorkant", "nl"),
+	)
+
+	return g
+}
+
+func TestSerialize(t *testing.T) {
+	t.Run("expanded", func(t *testing.T) {
+		is := is.New(t)
+
+		g := testGraph()
+
+		var buf bytes.Buffer
+		err := Serialize(g, &buf, nil)
+		is.NoErr(err)
+
+		var doc []map[string]interface{}
+		is.NoErr(json.Unmarshal(buf.Bytes(), &doc))
+		is.Equal(len(doc), 2)
+
+		roundTrip, err := Parse(&buf, nil)
+		is.NoErr(err)
+		is.Equal(roundTrip.Len(), g.Len())
+	})
+
+	t.Run("compacted", func(t *testing.T) {
+		is := is.New(t)
+
+		var buf bytes.Buffer
+		err := Serialize(testGraph(), &buf, &SerializeConfig{Form: Compacted})
+		is.NoErr(err)
+
+		var doc map[string]interface{}
+		is.NoErr(json.Unmarshal(buf.Bytes(), &doc))
+
+		ctx, ok := doc["@context"].(map[string]interface{})
+		is.True(ok)
+		is.Equal(ctx["edm"], "http://www.europeana.eu/schemas/edm/")
+		is.Equal(ctx["ore"], "http://www.openarchives.org/ore/terms/")
+
+		graph, ok := doc["@graph"].([]interface{})
+		is.True(ok)
+		is.Equal(len(graph), 2)
+	})
+
+	t.Run("framed by type", func(t *testing.T) {
+		is := is.New(t)
+
+		aggregation, err := rdf.NewIRI("http://www.openarchives.org/ore/terms/Aggregation")
+		is.NoErr(err)
+
+		var buf bytes.Buffer
+		err = Serialize(testGraph(), &buf, &SerializeConfig{Form: Framed, FrameType: aggregation})
+		is.NoErr(err)
+
+		var doc map[string]interface{}
+		is.NoErr(json.Unmarshal(buf.Bytes(), &doc))
+
+		is.Equal(doc["@id"], "http://example.org/aggregation/1")
+		is.Equal(doc["@type"], "ore:Aggregation")
+
+		view, ok := doc["edm:hasView"].(map[string]interface{})
+		is.True(ok) // web resource should be nested
+		is.Equal(view["@type"], "edm:WebResource")
+	})
+
+	t.Run("framed without frame", func(t *testing.T) {
+		is := is.New(t)
+
+		var buf bytes.Buffer
+		err := Serialize(testGraph(), &buf, &SerializeConfig{Form: Framed})
+		is.True(err != nil)
+	})
+}
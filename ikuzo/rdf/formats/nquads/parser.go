@@ -0,0 +1,384 @@
+// Package nquads provides tools to parse and serialize an rdf.Dataset in the
+// N-Quads format.
+//
+// For more information about N-Quads, see - https://www.w3.org/TR/n-quads/.
+package nquads
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/delving/hub3/ikuzo/rdf"
+)
+
+// ErrInvalidNQuads is returned when the input is not valid N-Quads.
+var ErrInvalidNQuads = errors.New("invalid N-Quads")
+
+const (
+	rdfLangString = "http://www.w3.org/1999/02/22-rdf-syntax-ns#langString"
+	xsdString     = "http://www.w3.org/2001/XMLSchema#string"
+
+	// maxLineSize is the maximum size of a single quad
+	maxLineSize = 10 * 1024 * 1024
+)
+
+// Parse reads N-Quads from r and adds the quads to the graphs of the Dataset.
+// When ds is nil a new Dataset is created.
+//
+// Quads without a graph label are added to the default graph.
+func Parse(r io.Reader, ds *rdf.Dataset) (*rdf.Dataset, error) {
+	if ds == nil {
+		ds = rdf.NewDataset()
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+
+		q, err := parseLine(scanner.Text())
+		if err != nil {
+			return ds, fmt.Errorf("%w; line %d: %s", ErrInvalidNQuads, lineNumber, err)
+		}
+
+		if q == nil {
+			continue
+		}
+
+		ds.Add(q)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return ds, err
+	}
+
+	return ds, nil
+}
+
+// lexer parses the terms of a single N-Quads line.
+type lexer struct {
+	input string
+	pos   int
+}
+
+// parseLine returns the Quad on the line. It returns nil when the line is
+// empty or only contains a comment.
+func parseLine(line string) (*rdf.Quad, error) {
+	l := &lexer{input: line}
+
+	if l.atEnd() {
+		return nil, nil
+	}
+
+	subject, err := l.subject()
+	if err != nil {
+		return nil, err
+	}
+
+	predicate, err := l.iri()
+	if err != nil {
+		return nil, err
+	}
+
+	object, err := l.object()
+	if err != nil {
+		return nil, err
+	}
+
+	var ctx rdf.Context
+
+	l.skipSpace()
+
+	if l.peek() != '.' {
+		ctx, err = l.subject()
+		if err != nil {
+			return nil, err
+		}
+
+		l.skipSpace()
+	}
+
+	if l.peek() != '.' {
+		return nil, fmt.Errorf("expected '.' at position %d", l.pos)
+	}
+
+	l.pos++
+
+	if !l.atEnd() {
+		return nil, fmt.Errorf("unexpected input after '.' at position %d", l.pos)
+	}
+
+	return rdf.NewQuad(rdf.NewTriple(subject, predicate, object), ctx)
+}
+
+// atEnd returns true when only whitespace or a comment remains.
+func (l *lexer) atEnd() bool {
+	l.skipSpace()
+	return l.pos >= len(l.input) || l.input[l.pos] == '#'
+}
+
+func (l *lexer) skipSpace() {
+	for l.pos < len(l.input) && (l.input[l.pos] == ' ' || l.input[l.pos] == '\t' || l.input[l.pos] == '\r') {
+		l.pos++
+	}
+}
+
+func (l *lexer) peek() byte {
+	if l.pos >= len(l.input) {
+		return 0
+	}
+
+	return l.input[l.pos]
+}
+
+// subject parses an IRI or a blank node. It is also used for graph labels.
+func (l *lexer) subject() (node, error) {
+	l.skipSpace()
+
+	switch l.peek() {
+	case '<':
+		return l.iri()
+	case '_':
+		return l.blankNode()
+	}
+
+	return nil, fmt.Errorf("expected IRI or blank node at position %d", l.pos)
+}
+
+func (l *lexer) object() (rdf.Object, error) {
+	l.skipSpace()
+
+	switch l.peek() {
+	case '<':
+		return l.iri()
+	case '_':
+		return l.blankNode()
+	case '"':
+		return l.literal()
+	}
+
+	return nil, fmt.Errorf("expected IRI, blank node or literal at position %d", l.pos)
+}
+
+// node is a term that is valid as subject, object and graph label.
+type node interface {
+	rdf.Subject
+	ValidAsObject()
+}
+
+func (l *lexer) iri() (rdf.IRI, error) {
+	l.skipSpace()
+
+	if l.peek() != '<' {
+		return rdf.IRI{}, fmt.Errorf("expected IRI at position %d", l.pos)
+	}
+
+	end := strings.IndexByte(l.input[l.pos:], '>')
+	if end == -1 {
+		return rdf.IRI{}, fmt.Errorf("unterminated IRI at position %d", l.pos)
+	}
+
+	raw := l.input[l.pos+1 : l.pos+end]
+	l.pos += end + 1
+
+	value, err := unescape(raw)
+	if err != nil {
+		return rdf.IRI{}, err
+	}
+
+	return rdf.NewIRI(value)
+}
+
+func (l *lexer) blankNode() (rdf.BlankNode, error) {
+	if !strings.HasPrefix(l.input[l.pos:], "_:") {
+		return rdf.BlankNode{}, fmt.Errorf("expected blank node at position %d", l.pos)
+	}
+
+	l.pos += 2
+	start := l.pos
+
+	for l.pos < len(l.input) && !isTermEnd(l.input[l.pos]) {
+		l.pos++
+	}
+
+	// a label cannot end with a '.'
+	for l.pos > start && l.input[l.pos-1] == '.' {
+		l.pos--
+	}
+
+	return rdf.NewBlankNode(l.input[start:l.pos])
+}
+
+func isTermEnd(c byte) bool {
+	switch c {
+	case ' ', '\t', '\r', '<', '"', '#':
+		return true
+	}
+
+	return false
+}
+
+func (l *lexer) literal() (rdf.Literal, error) {
+	start := l.pos
+	l.pos++
+
+	var sb strings.Builder
+
+	for {
+		if l.pos >= len(l.input) {
+			return rdf.Literal{}, fmt.Errorf("unterminated literal at position %d", start)
+		}
+
+		c := l.input[l.pos]
+		if c == '"' {
+			l.pos++
+			break
+		}
+
+		if c != '\\' {
+			sb.WriteByte(c)
+			l.pos++
+
+			continue
+		}
+
+		r, size, err := unescapeAt(l.input, l.pos)
+		if err != nil {
+			return rdf.Literal{}, err
+		}
+
+		sb.WriteRune(r)
+		l.pos += size
+	}
+
+	value := sb.String()
+
+	switch l.peek() {
+	case '@':
+		l.pos++
+		langStart := l.pos
+
+		for l.pos < len(l.input) && !isTermEnd(l.input[l.pos]) {
+			l.pos++
+		}
+
+		// the final '.' can follow the language tag without whitespace
+		for l.pos > langStart && l.input[l.pos-1] == '.' {
+			l.pos--
+		}
+
+		return rdf.NewLiteralWithLang(value, l.input[langStart:l.pos])
+	case '^':
+		if !strings.HasPrefix(l.input[l.pos:], "^^") {
+			return rdf.Literal{}, fmt.Errorf("expected '^^' at position %d", l.pos)
+		}
+
+		l.pos += 2
+
+		dt, err := l.iri()
+		if err != nil {
+			return rdf.Literal{}, err
+		}
+
+		if dt.RawValue() == xsdString {
+			return rdf.NewLiteral(value)
+		}
+
+		if dt.RawValue() == rdfLangString {
+			return rdf.Literal{}, fmt.Errorf("rdf:langString literal without language tag at position %d", start)
+		}
+
+		return rdf.NewLiteralWithType(value, dt)
+	}
+
+	return rdf.NewLiteral(value)
+}
+
+// unescape resolves the escape sequences in s.
+func unescape(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+
+	var sb strings.Builder
+
+	for i := 0; i < len(s); {
+		if s[i] != '\\' {
+			sb.WriteByte(s[i])
+			i++
+
+			continue
+		}
+
+		r, size, err := unescapeAt(s, i)
+		if err != nil {
+			return "", err
+		}
+
+		sb.WriteRune(r)
+		i += size
+	}
+
+	return sb.String(), nil
+}
+
+// unescapeAt decodes the escape sequence at position i in s. It returns the
+// rune and the length of the escape sequence.
+func unescapeAt(s string, i int) (rune, int, error) {
+	if i+1 >= len(s) {
+		return 0, 0, fmt.Errorf("invalid escape sequence at position %d", i)
+	}
+
+	switch s[i+1] {
+	case 't':
+		return '\t', 2, nil
+	case 'b':
+		return '\b', 2, nil
+	case 'n':
+		return '\n', 2, nil
+	case 'r':
+		return '\r', 2, nil
+	case 'f':
+		return '\f', 2, nil
+	case '"':
+		return '"', 2, nil
+	case '\'':
+		return '\'', 2, nil
+	case '\\':
+		return '\\', 2, nil
+	case 'u', 'U':
+		size := 4
+		if s[i+1] == 'U' {
+			size = 8
+		}
+
+		if i+2+size > len(s) {
+			return 0, 0, fmt.Errorf("invalid unicode escape at position %d", i)
+		}
+
+		code, err := strconv.ParseUint(s[i+2:i+2+size], 16, 32)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid unicode escape at position %d", i)
+		}
+
+		return rune(code), 2 + size, nil
+	}
+
+	return 0, 0, fmt.Errorf("invalid escape sequence at position %d", i)
+}
+
+// compile time check of interface
+var _ rdf.DatasetParser = (*p)(nil)
+
+type p struct{}
+
+func (p p) ParseDataset(r io.Reader, ds *rdf.Dataset) (*rdf.Dataset, error) {
+	return Parse(r, ds)
+}
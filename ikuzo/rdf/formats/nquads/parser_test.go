@@ -0,0 +1,117 @@
+package nquads
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/delving/hub3/ikuzo/rdf"
+	"github.com/matryer/is"
+)
+
+func getReader(name string) (io.ReadCloser, error) {
+	f, err := os.Open("./testdata/" + name)
+	if err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// nolint:gocritic
+func TestParse(t *testing.T) {
+	t.Run("parse nquads with dataset", func(t *testing.T) {
+		is := is.New(t)
+
+		ds := rdf.NewDataset()
+		r, err := getReader("dataset.nq")
+		is.NoErr(err)
+		defer r.Close()
+
+		returned, err := Parse(r, ds)
+		is.NoErr(err)
+		is.Equal(ds, returned)
+
+		is.Equal(ds.Len(), 8)
+		is.Equal(ds.DefaultGraph().Len(), 2)
+
+		g1, err := rdf.NewIRI("http://example.org/graph/1")
+		is.NoErr(err)
+
+		g2, err := rdf.NewBlankNode("g1")
+		is.NoErr(err)
+
+		is.Equal(ds.Names(), []rdf.Context{g1, g2})
+
+		g, ok := ds.Lookup(g1)
+		is.True(ok)
+		is.Equal(g.Len(), 4)
+
+		var description rdf.Literal
+
+		for _, t := range g.Triples() {
+			if t.Predicate.RawValue() == "http://purl.org/dc/elements/1.1/description" {
+				description = t.Object.(rdf.Literal)
+			}
+		}
+
+		is.Equal(description.RawValue(), "line one\nline \"two\"\tand é")
+
+		g, ok = ds.Lookup(g2)
+		is.True(ok)
+		is.Equal(g.Len(), 2)
+	})
+
+	t.Run("parse nquads without dataset", func(t *testing.T) {
+		is := is.New(t)
+
+		r, err := getReader("dataset.nq")
+		is.NoErr(err)
+		defer r.Close()
+
+		ds, err := Parse(r, nil)
+		is.NoErr(err)
+		is.Equal(ds.Len(), 8)
+	})
+
+	t.Run("parse ntriples", func(t *testing.T) {
+		is := is.New(t)
+
+		f, err := os.Open("../ntriples/testdata/rdf.nt")
+		is.NoErr(err)
+		defer f.Close()
+
+		ds, err := Parse(f, nil)
+		is.NoErr(err)
+		is.Equal(ds.Len(), 47)
+		is.Equal(ds.DefaultGraph().Len(), 47)
+		is.Equal(len(ds.Names()), 0)
+	})
+
+	t.Run("parse invalid nquads", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			input string
+		}{
+			{"missing object", "<urn:s> <urn:p> ."},
+			{"missing dot", "<urn:s> <urn:p> <urn:o>"},
+			{"literal as graph", `<urn:s> <urn:p> <urn:o> "g" .`},
+			{"unterminated literal", `<urn:s> <urn:p> "o .`},
+			{"invalid escape", `<urn:s> <urn:p> "\x" .`},
+			{"trailing input", "<urn:s> <urn:p> <urn:o> . <urn:x>"},
+		}
+
+		for _, tt := range tests {
+			tt := tt
+
+			t.Run(tt.name, func(t *testing.T) {
+				is := is.New(t)
+
+				_, err := Parse(strings.NewReader(tt.input), nil)
+				is.True(errors.Is(err, ErrInvalidNQuads))
+			})
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package nquads
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/delving/hub3/ikuzo/rdf"
+)
+
+// Serialize writes all the quads of the Dataset as N-Quads to w.
+//
+// The triples of the default graph are written first, followed by the named
+// graphs in the order in which they were added to the Dataset.
+func Serialize(ds *rdf.Dataset, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	for _, q := range ds.Quads() {
+		var sb strings.Builder
+
+		sb.WriteString(term(q.Subject))
+		sb.WriteString(" ")
+		sb.WriteString(term(q.Predicate))
+		sb.WriteString(" ")
+		sb.WriteString(term(q.Object))
+
+		if q.Context() != nil {
+			sb.WriteString(" ")
+			sb.WriteString(term(q.Context()))
+		}
+
+		sb.WriteString(" .\n")
+
+		if _, err := io.WriteString(bw, sb.String()); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// term returns the N-Quads representation of the term.
+func term(term rdf.Term) string {
+	switch t := term.(type) {
+	case rdf.Literal:
+		str := `"` + stringEscaper.Replace(t.RawValue()) + `"`
+
+		if t.Lang() != "" {
+			return str + "@" + strings.TrimPrefix(t.Lang(), "@")
+		}
+
+		if !t.DataType.Equal(rdf.IRI{}) && !t.HasImpliedDataType() {
+			str += "^^" + t.DataType.String()
+		}
+
+		return str
+	default:
+		return term.String()
+	}
+}
+
+var stringEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	"\n", `\n`,
+	"\r", `\r`,
+)
@@ -0,0 +1,60 @@
+package nquads
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/matryer/is"
+)
+
+// nolint:gocritic
+func TestSerialize(t *testing.T) {
+	t.Run("serialize dataset", func(t *testing.T) {
+		is := is.New(t)
+
+		r, err := getReader("dataset.nq")
+		is.NoErr(err)
+		defer r.Close()
+
+		ds, err := Parse(r, nil)
+		is.NoErr(err)
+
+		var buf bytes.Buffer
+		err = Serialize(ds, &buf)
+		is.NoErr(err)
+
+		want, err := os.ReadFile("./testdata/dataset.golden.nq")
+		is.NoErr(err)
+
+		if diff := cmp.Diff(string(want), buf.String()); diff != "" {
+			t.Errorf("Serialize() mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		is := is.New(t)
+
+		r, err := getReader("dataset.nq")
+		is.NoErr(err)
+		defer r.Close()
+
+		ds, err := Parse(r, nil)
+		is.NoErr(err)
+
+		var buf bytes.Buffer
+		err = Serialize(ds, &buf)
+		is.NoErr(err)
+
+		roundTrip, err := Parse(&buf, nil)
+		is.NoErr(err)
+		is.Equal(roundTrip.Len(), ds.Len())
+		is.Equal(roundTrip.Names(), ds.Names())
+
+		quads := ds.Quads()
+		for i, q := range roundTrip.Quads() {
+			is.True(q.Equal(quads[i]))
+		}
+	})
+}
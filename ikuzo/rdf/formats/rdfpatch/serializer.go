@@ -0,0 +1,94 @@
+// Package rdfpatch writes a rdf.GraphDiff in the RDF Patch format.
+//
+// See https://afs.github.io/rdf-patch/ for the specification.
+package rdfpatch
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/delving/hub3/ikuzo/rdf"
+)
+
+// Serialize writes the diff as a single RDF Patch transaction to w.
+//
+// The removed triples are written as D rows before the added triples as A
+// rows. When graph is not nil each row is scoped to that named graph.
+func Serialize(diff *rdf.GraphDiff, graph rdf.Context, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	if _, err := io.WriteString(bw, "TX .\n"); err != nil {
+		return err
+	}
+
+	rows := []struct {
+		op      string
+		triples []*rdf.Triple
+	}{
+		{op: "D", triples: diff.Removed},
+		{op: "A", triples: diff.Added},
+	}
+
+	for _, row := range rows {
+		for _, t := range row.triples {
+			if _, err := io.WriteString(bw, line(row.op, t, graph)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, err := io.WriteString(bw, "TC .\n"); err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+func line(op string, t *rdf.Triple, graph rdf.Context) string {
+	var sb strings.Builder
+
+	sb.WriteString(op)
+	sb.WriteString(" ")
+	sb.WriteString(term(t.Subject))
+	sb.WriteString(" ")
+	sb.WriteString(term(t.Predicate))
+	sb.WriteString(" ")
+	sb.WriteString(term(t.Object))
+
+	if graph != nil {
+		sb.WriteString(" ")
+		sb.WriteString(term(graph))
+	}
+
+	sb.WriteString(" .\n")
+
+	return sb.String()
+}
+
+// term returns the N-Triples representation of the term.
+func term(term rdf.Term) string {
+	switch t := term.(type) {
+	case rdf.Literal:
+		str := `"` + stringEscaper.Replace(t.RawValue()) + `"`
+
+		if t.Lang() != "" {
+			return str + "@" + strings.TrimPrefix(t.Lang(), "@")
+		}
+
+		if !t.DataType.Equal(rdf.IRI{}) && !t.HasImpliedDataType() {
+			str += "^^" + t.DataType.String()
+		}
+
+		return str
+	default:
+		return term.String()
+	}
+}
+
+var stringEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	"\n", `\n`,
+	"\r", `\r`,
+)
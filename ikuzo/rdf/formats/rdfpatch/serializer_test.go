@@ -0,0 +1,42 @@
+package rdfpatch
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/delving/hub3/ikuzo/rdf"
+	"github.com/delving/hub3/ikuzo/rdf/formats/ntriples"
+	"github.com/google/go-cmp/cmp"
+	"github.com/matryer/is"
+)
+
+func TestSerialize(t *testing.T) {
+	is := is.New(t)
+
+	from, err := ntriples.Parse(strings.NewReader(`<urn:s/1> <urn:p/title> "title"@en .
+<urn:s/1> <urn:p/count> "1"^^<http://www.w3.org/2001/XMLSchema#integer> .
+`), nil)
+	is.NoErr(err)
+
+	to, err := ntriples.Parse(strings.NewReader(`<urn:s/1> <urn:p/title> "title"@en .
+<urn:s/1> <urn:p/count> "2"^^<http://www.w3.org/2001/XMLSchema#integer> .
+`), nil)
+	is.NoErr(err)
+
+	graph, err := rdf.NewIRI("urn:graph/1")
+	is.NoErr(err)
+
+	var buf bytes.Buffer
+	err = Serialize(rdf.Diff(from, to), graph, &buf)
+	is.NoErr(err)
+
+	want := `TX .
+D <urn:s/1> <urn:p/count> "1"^^<http://www.w3.org/2001/XMLSchema#integer> <urn:graph/1> .
+A <urn:s/1> <urn:p/count> "2"^^<http://www.w3.org/2001/XMLSchema#integer> <urn:graph/1> .
+TC .
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("Serialize() mismatch (-want +got):\n%s", diff)
+	}
+}
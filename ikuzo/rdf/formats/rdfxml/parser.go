@@ -0,0 +1,656 @@
+// Package rdfxml provides tools to parse and serialize RDF data in the RDF/XML format.
+//
+// For more information about RDF/XML, see - https://www.w3.org/TR/rdf-syntax-grammar/.
+package rdfxml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/delving/hub3/ikuzo/rdf"
+)
+
+const xmlNS = "http://www.w3.org/XML/1998/namespace"
+
+// ErrInvalidRDFXML is returned when the XML does not follow the RDF/XML grammar.
+var ErrInvalidRDFXML = errors.New("invalid RDF/XML")
+
+// Parse parses RDF/XML from the io.Reader and adds the triples to the Graph.
+// When the Graph is nil a new Graph is created.
+//
+// The RDF/XML is decoded as a stream of XML tokens, so the triples are added to the
+// Graph while the document is read. Empty literals are ignored, because they are not
+// valid in a rdf.Graph.
+func Parse(r io.Reader, g *rdf.Graph) (*rdf.Graph, error) {
+	if g == nil {
+		g = rdf.NewGraph()
+	}
+
+	p := &parser{
+		dec:     xml.NewDecoder(r),
+		g:       g,
+		labels:  g.BlankNodeLabels(),
+		nodeIDs: map[string]rdf.BlankNode{},
+	}
+
+	if err := p.parse(); err != nil {
+		return g, err
+	}
+
+	return g, nil
+}
+
+type parser struct {
+	dec    *xml.Decoder
+	g      *rdf.Graph
+	bnodes int
+	// labels contains the blank node labels that are in use in the Graph
+	labels map[string]bool
+	// nodeIDs maps the rdf:nodeID values of the document to their blank node
+	nodeIDs map[string]rdf.BlankNode
+}
+
+// node is a IRI or BlankNode that can be used both as Subject and Object.
+type node interface {
+	rdf.Subject
+	ValidAsObject()
+}
+
+// scope holds the inherited xml:base and xml:lang of an element.
+type scope struct {
+	base string
+	lang string
+}
+
+func (s scope) with(elem xml.StartElement) scope {
+	for _, attr := range elem.Attr {
+		if attr.Name.Space != xmlNS {
+			continue
+		}
+
+		switch attr.Name.Local {
+		case "lang":
+			s.lang = attr.Value
+		case "base":
+			s.base = attr.Value
+		}
+	}
+
+	return s
+}
+
+func (p *parser) parse() error {
+	for {
+		tok, err := p.dec.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return err
+		}
+
+		elem, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		s := scope{}.with(elem)
+
+		if isRDF(elem.Name, "RDF") {
+			return p.nodeElementList(s)
+		}
+
+		// a single node element without the rdf:RDF wrapper
+		_, err = p.nodeElement(elem, s)
+
+		return err
+	}
+}
+
+// nodeElementList parses node elements until the closing element.
+func (p *parser) nodeElementList(s scope) error {
+	for {
+		tok, err := p.dec.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if _, err := p.nodeElement(t, s.with(t)); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// nodeElement parses the node element and its property elements. It returns the
+// subject of the node element.
+func (p *parser) nodeElement(elem xml.StartElement, s scope) (node, error) {
+	subject, err := p.subject(elem, s)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isRDF(elem.Name, "Description") {
+		typeIRI, err := p.resolve(s, elem.Name.Space+elem.Name.Local)
+		if err != nil {
+			return nil, err
+		}
+
+		p.g.AddTriple(subject, rdf.IsA, typeIRI)
+	}
+
+	if err := p.propertyAttrs(subject, elem, s); err != nil {
+		return nil, err
+	}
+
+	if err := p.propertyElementList(subject, s); err != nil {
+		return nil, err
+	}
+
+	return subject, nil
+}
+
+func (p *parser) subject(elem xml.StartElement, s scope) (node, error) {
+	if about, ok := rdfAttr(elem, "about"); ok {
+		return p.resolve(s, about)
+	}
+
+	if id, ok := rdfAttr(elem, "ID"); ok {
+		return p.resolveID(s, id)
+	}
+
+	if nodeID, ok := rdfAttr(elem, "nodeID"); ok {
+		return p.nodeID(nodeID)
+	}
+
+	return p.newBlankNode()
+}
+
+// propertyAttrs adds the triples for the non-syntax attributes of the element.
+func (p *parser) propertyAttrs(subject node, elem xml.StartElement, s scope) error {
+	for _, attr := range elem.Attr {
+		if !isPropertyAttr(attr.Name) {
+			continue
+		}
+
+		predicate, err := rdf.NewIRI(attr.Name.Space + attr.Name.Local)
+		if err != nil {
+			return err
+		}
+
+		if isRDF(attr.Name, "type") {
+			obj, err := p.resolve(s, attr.Value)
+			if err != nil {
+				return err
+			}
+
+			p.g.AddTriple(subject, predicate, obj)
+
+			continue
+		}
+
+		if err := p.addLiteral(subject, predicate, attr.Value, s.lang, ""); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// propertyElementList parses the property elements of subject until the closing element.
+func (p *parser) propertyElementList(subject node, s scope) error {
+	li := 0
+
+	for {
+		tok, err := p.dec.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			name := t.Name.Space + t.Name.Local
+			if isRDF(t.Name, "li") {
+				li++
+				name = rdfNS + "_" + strconv.Itoa(li)
+			}
+
+			predicate, err := rdf.NewIRI(name)
+			if err != nil {
+				return err
+			}
+
+			if err := p.propertyElement(subject, predicate, t, s.with(t)); err != nil {
+				return err
+			}
+		case xml.CharData:
+			if len(bytes.TrimSpace(t)) > 0 {
+				return fmt.Errorf("%w: unexpected text %q in node element", ErrInvalidRDFXML, string(t))
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// propertyElement parses a single property element.
+func (p *parser) propertyElement(subject node, predicate rdf.IRI, elem xml.StartElement, s scope) error {
+	add := func(obj rdf.Object) error {
+		p.g.AddTriple(subject, predicate, obj)
+		return p.reify(subject, predicate, obj, elem, s)
+	}
+
+	if parseType, ok := rdfAttr(elem, "parseType"); ok {
+		switch parseType {
+		case "Resource":
+			obj, err := p.newBlankNode()
+			if err != nil {
+				return err
+			}
+
+			if err := add(obj); err != nil {
+				return err
+			}
+
+			return p.propertyElementList(obj, s)
+		case "Collection":
+			return p.collection(subject, predicate, s)
+		default:
+			literal, err := p.xmlLiteral()
+			if err != nil {
+				return err
+			}
+
+			if literal == "" {
+				return nil
+			}
+
+			obj, err := rdf.NewLiteralWithType(literal, mustIRI(rdfXMLLiteral))
+			if err != nil {
+				return err
+			}
+
+			return add(obj)
+		}
+	}
+
+	var obj node
+
+	if resource, ok := rdfAttr(elem, "resource"); ok {
+		iri, err := p.resolve(s, resource)
+		if err != nil {
+			return err
+		}
+
+		obj = iri
+	} else if nodeID, ok := rdfAttr(elem, "nodeID"); ok {
+		bnode, err := p.nodeID(nodeID)
+		if err != nil {
+			return err
+		}
+
+		obj = bnode
+	}
+
+	if obj != nil || hasPropertyAttrs(elem) {
+		// empty property element
+		if obj == nil {
+			bnode, err := p.newBlankNode()
+			if err != nil {
+				return err
+			}
+
+			obj = bnode
+		}
+
+		if err := add(obj); err != nil {
+			return err
+		}
+
+		if err := p.propertyAttrs(obj, elem, s); err != nil {
+			return err
+		}
+
+		return p.skipEmpty()
+	}
+
+	// Either a literal or a nested node element. More than one nested node element
+	// is not valid RDF/XML, but it is accepted because mappingxml serializes multiple
+	// objects of the same predicate this way.
+	var (
+		text   strings.Builder
+		nested bool
+	)
+
+	for {
+		tok, err := p.dec.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.CharData:
+			text.Write(t)
+		case xml.StartElement:
+			if strings.TrimSpace(text.String()) != "" {
+				return fmt.Errorf("%w: mixed content in property element %s", ErrInvalidRDFXML, predicate)
+			}
+
+			obj, err := p.nodeElement(t, s.with(t))
+			if err != nil {
+				return err
+			}
+
+			if err := add(obj); err != nil {
+				return err
+			}
+
+			nested = true
+
+			text.Reset()
+		case xml.EndElement:
+			if nested {
+				if strings.TrimSpace(text.String()) != "" {
+					return fmt.Errorf("%w: mixed content in property element %s", ErrInvalidRDFXML, predicate)
+				}
+
+				return nil
+			}
+
+			datatype, _ := rdfAttr(elem, "datatype")
+
+			if text.Len() == 0 {
+				return nil
+			}
+
+			lit, err := p.literal(text.String(), s.lang, datatype, s)
+			if err != nil {
+				return err
+			}
+
+			return add(lit)
+		}
+	}
+}
+
+// collection parses the node elements of a rdf:parseType="Collection" into a rdf:List.
+func (p *parser) collection(subject node, predicate rdf.IRI, s scope) error {
+	first := mustIRI(rdfNS + "first")
+	rest := mustIRI(rdfNS + "rest")
+	nilIRI := mustIRI(rdfNS + "nil")
+
+	var (
+		prev node = subject
+		prop      = predicate
+	)
+
+	for {
+		tok, err := p.dec.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			item, err := p.nodeElement(t, s.with(t))
+			if err != nil {
+				return err
+			}
+
+			cell, err := p.newBlankNode()
+			if err != nil {
+				return err
+			}
+
+			p.g.AddTriple(prev, prop, cell)
+			p.g.AddTriple(cell, first, item)
+
+			prev, prop = cell, rest
+		case xml.EndElement:
+			p.g.AddTriple(prev, prop, nilIRI)
+			return nil
+		}
+	}
+}
+
+// xmlLiteral returns the content of the element as a XML string.
+func (p *parser) xmlLiteral() (string, error) {
+	var buf bytes.Buffer
+
+	enc := xml.NewEncoder(&buf)
+	depth := 0
+
+	for {
+		tok, err := p.dec.Token()
+		if err != nil {
+			return "", err
+		}
+
+		switch tok.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			if depth == 0 {
+				if err := enc.Flush(); err != nil {
+					return "", err
+				}
+
+				return buf.String(), nil
+			}
+			depth--
+		}
+
+		if err := enc.EncodeToken(xml.CopyToken(tok)); err != nil {
+			return "", err
+		}
+	}
+}
+
+// reify adds the reification triples when the property element has a rdf:ID.
+func (p *parser) reify(subject node, predicate rdf.IRI, obj rdf.Object, elem xml.StartElement, s scope) error {
+	id, ok := rdfAttr(elem, "ID")
+	if !ok {
+		return nil
+	}
+
+	statement, err := p.resolveID(s, id)
+	if err != nil {
+		return err
+	}
+
+	p.g.AddTriple(statement, rdf.IsA, mustIRI(rdfNS+"Statement"))
+	p.g.AddTriple(statement, mustIRI(rdfNS+"subject"), subject)
+	p.g.AddTriple(statement, mustIRI(rdfNS+"predicate"), predicate)
+	p.g.AddTriple(statement, mustIRI(rdfNS+"object"), obj)
+
+	return nil
+}
+
+// skipEmpty consumes tokens until the closing element. Only whitespace is allowed.
+func (p *parser) skipEmpty() error {
+	for {
+		tok, err := p.dec.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.CharData:
+			if len(bytes.TrimSpace(t)) > 0 {
+				return fmt.Errorf("%w: unexpected text %q", ErrInvalidRDFXML, string(t))
+			}
+		case xml.StartElement:
+			return fmt.Errorf("%w: unexpected element %s", ErrInvalidRDFXML, t.Name.Local)
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+func (p *parser) addLiteral(subject rdf.Subject, predicate rdf.IRI, value, lang, datatype string) error {
+	if value == "" {
+		return nil
+	}
+
+	obj, err := p.literal(value, lang, datatype, scope{})
+	if err != nil {
+		return err
+	}
+
+	p.g.AddTriple(subject, predicate, obj)
+
+	return nil
+}
+
+func (p *parser) literal(value, lang, datatype string, s scope) (rdf.Literal, error) {
+	if datatype != "" {
+		dt, err := p.resolve(s, datatype)
+		if err != nil {
+			return rdf.Literal{}, err
+		}
+
+		return rdf.NewLiteralWithType(value, dt)
+	}
+
+	if lang != "" {
+		return rdf.NewLiteralWithLang(value, lang)
+	}
+
+	return rdf.NewLiteral(value)
+}
+
+// newBlankNode returns a blank node with a label that is not used in the Graph
+// or by another blank node of the document.
+func (p *parser) newBlankNode() (rdf.BlankNode, error) {
+	for {
+		p.bnodes++
+
+		label := fmt.Sprintf("genid%d", p.bnodes)
+		if !p.labels[label] {
+			p.labels[label] = true
+			return rdf.NewBlankNode(label)
+		}
+	}
+}
+
+// nodeID returns the blank node of the rdf:nodeID. The label of the document
+// is kept, unless it is already used in the Graph or by a generated blank node.
+func (p *parser) nodeID(id string) (rdf.BlankNode, error) {
+	if bnode, ok := p.nodeIDs[id]; ok {
+		return bnode, nil
+	}
+
+	bnode, err := rdf.NewBlankNode(id)
+	if err != nil {
+		return rdf.BlankNode{}, err
+	}
+
+	if p.labels[bnode.RawValue()] {
+		bnode, err = p.newBlankNode()
+		if err != nil {
+			return rdf.BlankNode{}, err
+		}
+	}
+
+	p.labels[bnode.RawValue()] = true
+
+	p.nodeIDs[id] = bnode
+
+	return bnode, nil
+}
+
+// resolveID returns the IRI of a rdf:ID, which is only valid with a xml:base.
+func (p *parser) resolveID(s scope, id string) (rdf.IRI, error) {
+	if s.base == "" {
+		return rdf.IRI{}, fmt.Errorf("%w: rdf:ID %q without xml:base", ErrInvalidRDFXML, id)
+	}
+
+	return p.resolve(s, "#"+id)
+}
+
+// resolve returns the IRI resolved against the in-scope xml:base.
+func (p *parser) resolve(s scope, ref string) (rdf.IRI, error) {
+	if s.base == "" {
+		return rdf.NewIRI(ref)
+	}
+
+	base, err := url.Parse(s.base)
+	if err != nil {
+		return rdf.IRI{}, err
+	}
+
+	u, err := url.Parse(ref)
+	if err != nil {
+		return rdf.IRI{}, err
+	}
+
+	return rdf.NewIRI(base.ResolveReference(u).String())
+}
+
+func isRDF(name xml.Name, local string) bool {
+	return name.Space == rdfNS && name.Local == local
+}
+
+func rdfAttr(elem xml.StartElement, local string) (string, bool) {
+	for _, attr := range elem.Attr {
+		if isRDF(attr.Name, local) {
+			return attr.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// isPropertyAttr returns true when the attribute is not a syntax attribute.
+func isPropertyAttr(name xml.Name) bool {
+	switch name.Space {
+	case "", xmlNS, "xmlns":
+		return false
+	case rdfNS:
+		switch name.Local {
+		case "about", "ID", "nodeID", "resource", "datatype", "parseType", "bagID", "aboutEach", "aboutEachPrefix":
+			return false
+		}
+	}
+
+	return !strings.HasPrefix(strings.ToLower(name.Local), "xml")
+}
+
+func hasPropertyAttrs(elem xml.StartElement) bool {
+	for _, attr := range elem.Attr {
+		if isPropertyAttr(attr.Name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func mustIRI(iri string) rdf.IRI {
+	i, err := rdf.NewIRI(iri)
+	if err != nil {
+		panic(err)
+	}
+
+	return i
+}
+
+// compile time check of interface
+var _ rdf.Parser = (*p)(nil)
+
+type p struct{}
+
+func (p p) Parse(r io.Reader, g *rdf.Graph) (*rdf.Graph, error) {
+	return Parse(r, g)
+}
@@ -0,0 +1,165 @@
+package rdfxml
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/delving/hub3/ikuzo/rdf"
+	"github.com/matryer/is"
+)
+
+func getReader(name string) (io.ReadCloser, error) {
+	f, err := os.Open("./testdata/" + name)
+	if err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func hasTriple(g *rdf.Graph, triple string) bool {
+	for _, t := range g.Triples() {
+		if t.String() == triple {
+			return true
+		}
+	}
+
+	return false
+}
+
+// nolint:gocritic
+func TestParse(t *testing.T) {
+	t.Run("parse rdfxml with graph", func(t *testing.T) {
+		is := is.New(t)
+
+		g := rdf.NewGraph()
+		is.Equal(g.Len(), 0)
+		r, err := getReader("record.rdf")
+		is.NoErr(err)
+		defer r.Close()
+		returnedGraph, err := Parse(r, g)
+		is.NoErr(err)
+		is.Equal(g, returnedGraph)
+
+		is.Equal(g.Len(), 48)
+	})
+
+	t.Run("parse rdfxml without graph", func(t *testing.T) {
+		is := is.New(t)
+
+		r, err := getReader("nodeid.rdf")
+		is.NoErr(err)
+		defer r.Close()
+		g, err := Parse(r, nil)
+		is.NoErr(err)
+
+		is.Equal(g.Len(), 28)
+		is.True(hasTriple(g, `<http://klek.si/208B7R> <http://schema.org/contentLocation> _:b2 .`))
+		is.True(hasTriple(g, `_:b2 <http://schema.org/name> "Vitrine 8"@nl .`))
+	})
+
+	t.Run("parse rdfxml features", func(t *testing.T) {
+		is := is.New(t)
+
+		r, err := getReader("features.rdf")
+		is.NoErr(err)
+		defer r.Close()
+		g, err := Parse(r, nil)
+		is.NoErr(err)
+
+		is.Equal(g.Len(), 11)
+
+		// inherited xml:lang
+		is.True(hasTriple(g, `<http://example.org/object/1> <http://purl.org/dc/elements/1.1/title> "Boerderij bij Berlicum"@nl .`))
+		is.True(hasTriple(g, `<http://example.org/object/1> <http://purl.org/dc/elements/1.1/title> "Farm near Berlicum"@en .`))
+		// reset xml:lang
+		is.True(hasTriple(g, `_:view1 <http://purl.org/dc/elements/1.1/format> "image/jpeg" .`))
+		// typed literal
+		is.True(hasTriple(g, `<http://example.org/object/1> <http://purl.org/dc/terms/extent> "12"^^<http://www.w3.org/2001/XMLSchema#integer> .`))
+		// rdf:parseType="Resource"
+		is.True(hasTriple(g, `<http://example.org/object/1> <http://purl.org/dc/terms/spatial> _:genid1 .`))
+		is.True(hasTriple(g, `_:genid1 <http://purl.org/dc/elements/1.1/description> "Gemeente Sint-Michielsgestel"@nl .`))
+		// rdf:nodeID
+		is.True(hasTriple(g, `<http://example.org/object/1> <http://www.europeana.eu/schemas/edm/hasView> _:view1 .`))
+		is.True(hasTriple(g, `_:view1 <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://www.europeana.eu/schemas/edm/WebResource> .`))
+	})
+
+	t.Run("parse rdf:li, xml:base and collections", func(t *testing.T) {
+		is := is.New(t)
+
+		input := `<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns:ex="http://example.org/terms/" xml:base="http://example.org/">
+  <rdf:Seq rdf:about="seq/1">
+    <rdf:li rdf:resource="item/1"/>
+    <rdf:li>second</rdf:li>
+  </rdf:Seq>
+  <rdf:Description rdf:about="list/1" ex:label="list">
+    <ex:members rdf:parseType="Collection">
+      <rdf:Description rdf:about="item/1"/>
+    </ex:members>
+  </rdf:Description>
+</rdf:RDF>`
+
+		g, err := Parse(strings.NewReader(input), nil)
+		is.NoErr(err)
+
+		is.True(hasTriple(g, `<http://example.org/seq/1> <http://www.w3.org/1999/02/22-rdf-syntax-ns#_1> <http://example.org/item/1> .`))
+		is.True(hasTriple(g, `<http://example.org/seq/1> <http://www.w3.org/1999/02/22-rdf-syntax-ns#_2> "second" .`))
+		is.True(hasTriple(g, `<http://example.org/list/1> <http://example.org/terms/label> "list" .`))
+		is.True(hasTriple(g, `_:genid1 <http://www.w3.org/1999/02/22-rdf-syntax-ns#first> <http://example.org/item/1> .`))
+		is.True(hasTriple(g, `_:genid1 <http://www.w3.org/1999/02/22-rdf-syntax-ns#rest> <http://www.w3.org/1999/02/22-rdf-syntax-ns#nil> .`))
+	})
+
+	t.Run("parse blank nodes without label collisions", func(t *testing.T) {
+		is := is.New(t)
+
+		g := rdf.NewGraph()
+		existing, err := rdf.NewBlankNode("genid2")
+		is.NoErr(err)
+		g.AddTriple(existing, rdf.IsA, mustIRI("http://example.org/terms/Existing"))
+
+		input := `<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns:ex="http://example.org/terms/">
+  <rdf:Description rdf:about="urn:1">
+    <ex:first ex:label="generated"/>
+    <ex:second rdf:nodeID="genid1"/>
+    <ex:third rdf:nodeID="genid2"/>
+  </rdf:Description>
+  <rdf:Description rdf:nodeID="genid1" ex:label="document"/>
+</rdf:RDF>`
+
+		_, err = Parse(strings.NewReader(input), g)
+		is.NoErr(err)
+
+		is.True(hasTriple(g, `<urn:1> <http://example.org/terms/first> _:genid1 .`))
+		is.True(hasTriple(g, `_:genid1 <http://example.org/terms/label> "generated" .`))
+		is.True(hasTriple(g, `<urn:1> <http://example.org/terms/second> _:genid3 .`))
+		is.True(hasTriple(g, `_:genid3 <http://example.org/terms/label> "document" .`))
+		is.True(hasTriple(g, `<urn:1> <http://example.org/terms/third> _:genid4 .`))
+	})
+
+	t.Run("parse rdf:ID without xml:base", func(t *testing.T) {
+		is := is.New(t)
+
+		input := `<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns:ex="http://example.org/terms/">
+  <rdf:Description rdf:ID="item1" ex:label="item"/>
+</rdf:RDF>`
+
+		_, err := Parse(strings.NewReader(input), nil)
+		is.True(errors.Is(err, ErrInvalidRDFXML))
+	})
+
+	t.Run("parse invalid rdfxml", func(t *testing.T) {
+		is := is.New(t)
+
+		input := `<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns:ex="http://example.org/terms/">
+  <rdf:Description rdf:about="urn:1">
+    <ex:label rdf:resource="urn:2">text</ex:label>
+  </rdf:Description>
+</rdf:RDF>`
+
+		_, err := Parse(strings.NewReader(input), nil)
+		is.True(errors.Is(err, ErrInvalidRDFXML))
+	})
+}
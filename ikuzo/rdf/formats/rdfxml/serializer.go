@@ -0,0 +1,302 @@
+package rdfxml
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/delving/hub3/ikuzo/rdf"
+)
+
+const (
+	rdfNS         = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	rdfXMLLiteral = rdfNS + "XMLLiteral"
+	indent        = "  "
+)
+
+// Serialize writes the Graph as RDF/XML to w.
+//
+// Each subject is written as a node element in the order in which it was first
+// added to the Graph. The first rdf:type of a subject is used as the name of the node
+// element when it can be written as a qualified name, otherwise rdf:Description is used.
+// Blank nodes are written with rdf:nodeID.
+//
+// The prefixes are resolved with the NamespaceManager of the Graph. Namespaces that
+// are unknown are given a generated prefix. An error is returned when a predicate
+// cannot be written as an XML qualified name.
+func Serialize(g *rdf.Graph, w io.Writer) error {
+	nm := g.NamespaceManager
+	if nm == nil {
+		nm = rdf.DefaultNamespaceManager
+	}
+
+	s := &serializer{
+		nm:       nm,
+		prefixes: map[string]string{rdfNS: "rdf"},
+		used:     map[string]bool{"rdf": true, "xml": true},
+	}
+
+	subjects, err := s.groupBySubject(g.Triples())
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+
+	if err := s.writeHeader(bw); err != nil {
+		return err
+	}
+
+	for _, sg := range subjects {
+		if err := s.writeNode(bw, sg); err != nil {
+			return err
+		}
+	}
+
+	if _, err := io.WriteString(bw, "</rdf:RDF>\n"); err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+type serializer struct {
+	nm rdf.NamespaceManager
+	// prefixes maps the namespace base-URIs to their prefix
+	prefixes map[string]string
+	// used contains all the assigned prefixes
+	used map[string]bool
+}
+
+type subjectGroup struct {
+	subject  rdf.Subject
+	nodeType rdf.IRI
+	triples  []*rdf.Triple
+}
+
+func (s *serializer) groupBySubject(triples []*rdf.Triple) ([]*subjectGroup, error) {
+	subjects := []*subjectGroup{}
+	bySubject := map[string]*subjectGroup{}
+
+	for _, t := range triples {
+		key := t.Subject.String()
+
+		sg, ok := bySubject[key]
+		if !ok {
+			sg = &subjectGroup{subject: t.Subject}
+			bySubject[key] = sg
+			subjects = append(subjects, sg)
+		}
+
+		if t.Predicate.RawValue() == rdf.RDFType && sg.nodeType.Equal(rdf.IRI{}) {
+			if iri, ok := t.Object.(rdf.IRI); ok {
+				if _, _, ok := splitQName(iri.RawValue()); ok {
+					sg.nodeType = iri
+					s.registerNamespace(iri.RawValue())
+
+					continue
+				}
+			}
+		}
+
+		if _, ok := s.registerNamespace(t.Predicate.RawValue()); !ok {
+			return nil, fmt.Errorf("rdfxml: unable to serialize predicate %s as XML qualified name", t.Predicate)
+		}
+
+		sg.triples = append(sg.triples, t)
+	}
+
+	return subjects, nil
+}
+
+// registerNamespace assigns a prefix to the namespace of the IRI.
+func (s *serializer) registerNamespace(iri string) (string, bool) {
+	base, _, ok := splitQName(iri)
+	if !ok {
+		return "", false
+	}
+
+	if prefix, ok := s.prefixes[base]; ok {
+		return prefix, true
+	}
+
+	var prefix string
+
+	if s.nm != nil {
+		ns, err := s.nm.GetWithBase(base)
+		if err == nil && ns.Base == base && isNCName(ns.Prefix) && !s.used[ns.Prefix] {
+			prefix = ns.Prefix
+		}
+	}
+
+	for i := 0; prefix == ""; i++ {
+		candidate := fmt.Sprintf("ns%d", i)
+		if !s.used[candidate] {
+			prefix = candidate
+		}
+	}
+
+	s.prefixes[base] = prefix
+	s.used[prefix] = true
+
+	return prefix, true
+}
+
+func (s *serializer) qname(iri string) string {
+	base, local, _ := splitQName(iri)
+	return s.prefixes[base] + ":" + local
+}
+
+func (s *serializer) writeHeader(w io.Writer) error {
+	type ns struct {
+		prefix string
+		base   string
+	}
+
+	namespaces := make([]ns, 0, len(s.prefixes))
+	for base, prefix := range s.prefixes {
+		namespaces = append(namespaces, ns{prefix: prefix, base: base})
+	}
+
+	sort.Slice(namespaces, func(i, j int) bool {
+		return namespaces[i].prefix < namespaces[j].prefix
+	})
+
+	var sb strings.Builder
+
+	sb.WriteString(xml.Header)
+	sb.WriteString("<rdf:RDF")
+
+	for _, ns := range namespaces {
+		fmt.Fprintf(&sb, " xmlns:%s=\"%s\"", ns.prefix, escape(ns.base))
+	}
+
+	sb.WriteString(">\n")
+
+	_, err := io.WriteString(w, sb.String())
+
+	return err
+}
+
+func (s *serializer) writeNode(w io.Writer, sg *subjectGroup) error {
+	var sb strings.Builder
+
+	name := "rdf:Description"
+	if !sg.nodeType.Equal(rdf.IRI{}) {
+		name = s.qname(sg.nodeType.RawValue())
+	}
+
+	fmt.Fprintf(&sb, "%s<%s %s", indent, name, nodeAttr(sg.subject, "rdf:about"))
+
+	if len(sg.triples) == 0 {
+		sb.WriteString("/>\n")
+
+		_, err := io.WriteString(w, sb.String())
+
+		return err
+	}
+
+	sb.WriteString(">\n")
+
+	for _, t := range sg.triples {
+		s.writeProperty(&sb, t)
+	}
+
+	fmt.Fprintf(&sb, "%s</%s>\n", indent, name)
+
+	_, err := io.WriteString(w, sb.String())
+
+	return err
+}
+
+func (s *serializer) writeProperty(sb *strings.Builder, t *rdf.Triple) {
+	name := s.qname(t.Predicate.RawValue())
+
+	switch o := t.Object.(type) {
+	case rdf.IRI, rdf.BlankNode:
+		fmt.Fprintf(sb, "%s%s<%s %s/>\n", indent, indent, name, nodeAttr(o, "rdf:resource"))
+	case rdf.Literal:
+		fmt.Fprintf(sb, "%s%s<%s", indent, indent, name)
+
+		value := escape(o.RawValue())
+
+		switch {
+		case o.Lang() != "":
+			fmt.Fprintf(sb, " xml:lang=\"%s\"", escape(strings.TrimPrefix(o.Lang(), "@")))
+		case o.DataType.RawValue() == rdfXMLLiteral:
+			sb.WriteString(" rdf:parseType=\"Literal\"")
+
+			value = o.RawValue()
+		case !o.DataType.Equal(rdf.IRI{}) && !o.HasImpliedDataType():
+			fmt.Fprintf(sb, " rdf:datatype=\"%s\"", escape(o.DataType.RawValue()))
+		}
+
+		fmt.Fprintf(sb, ">%s</%s>\n", value, name)
+	}
+}
+
+// nodeAttr returns the rdf:nodeID attribute for blank nodes and the given
+// attribute for IRIs.
+func nodeAttr(term rdf.Term, attr string) string {
+	if term.Type() == rdf.TermBlankNode {
+		attr = "rdf:nodeID"
+	}
+
+	return fmt.Sprintf("%s=\"%s\"", attr, escape(term.RawValue()))
+}
+
+func escape(s string) string {
+	var sb strings.Builder
+
+	_ = xml.EscapeText(&sb, []byte(s))
+
+	return sb.String()
+}
+
+// splitQName splits the IRI into a namespace and a local name that is a valid
+// XML NCName. ok is false when no valid local name can be found.
+func splitQName(iri string) (base, local string, ok bool) {
+	runes := []rune(iri)
+
+	i := len(runes)
+	for i > 0 && isNCNameChar(runes[i-1]) {
+		i--
+	}
+
+	// the local name must start with a letter or underscore
+	for i < len(runes) && !isNCNameStartChar(runes[i]) {
+		i++
+	}
+
+	if i == 0 || i >= len(runes) {
+		return "", "", false
+	}
+
+	return string(runes[:i]), string(runes[i:]), true
+}
+
+func isNCName(s string) bool {
+	for i, r := range s {
+		if i == 0 && !isNCNameStartChar(r) {
+			return false
+		}
+
+		if !isNCNameChar(r) {
+			return false
+		}
+	}
+
+	return s != ""
+}
+
+func isNCNameStartChar(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isNCNameChar(r rune) bool {
+	return isNCNameStartChar(r) || unicode.IsDigit(r) || r == '-' || r == '.'
+}
@@ -0,0 +1,129 @@
+package rdfxml
+
+import (
+	"bytes"
+	"os"
+	"sort"
+	"testing"
+
+	"github.com/delving/hub3/ikuzo/rdf"
+	"github.com/google/go-cmp/cmp"
+	"github.com/matryer/is"
+)
+
+func sortedTriples(g *rdf.Graph) []string {
+	triples := []string{}
+	for _, t := range g.Triples() {
+		triples = append(triples, t.String())
+	}
+
+	sort.Strings(triples)
+
+	return triples
+}
+
+func TestSerialize(t *testing.T) {
+	is := is.New(t)
+
+	r, err := getReader("features.rdf")
+	is.NoErr(err)
+	defer r.Close()
+
+	g, err := Parse(r, nil)
+	is.NoErr(err)
+
+	var buf bytes.Buffer
+	err = Serialize(g, &buf)
+	is.NoErr(err)
+
+	want, err := os.ReadFile("./testdata/features.golden.rdf")
+	is.NoErr(err)
+
+	if diff := cmp.Diff(string(want), buf.String()); diff != "" {
+		t.Errorf("serialize = mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSerializeRoundTrip(t *testing.T) {
+	for _, name := range []string{"record.rdf", "nodeid.rdf", "features.rdf"} {
+		name := name
+
+		t.Run(name, func(t *testing.T) {
+			is := is.New(t)
+
+			r, err := getReader(name)
+			is.NoErr(err)
+			defer r.Close()
+
+			g, err := Parse(r, nil)
+			is.NoErr(err)
+
+			var buf bytes.Buffer
+			err = Serialize(g, &buf)
+			is.NoErr(err)
+
+			roundTrip, err := Parse(&buf, nil)
+			is.NoErr(err)
+
+			if diff := cmp.Diff(sortedTriples(g), sortedTriples(roundTrip)); diff != "" {
+				t.Errorf("round trip = mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSerializeUnknownNamespace(t *testing.T) {
+	is := is.New(t)
+
+	b := rdf.Builder{}
+
+	g := rdf.NewGraph()
+	g.AddTriple(
+		b.IRI("urn:subject"),
+		b.IRI("http://example.org/unknown/label"),
+		b.Literal("a < b & c"),
+	)
+	g.AddTriple(
+		b.IRI("urn:subject"),
+		b.IRI("http://example.org/unknown/parts"),
+		b.LiteralWithDataType("<b>bold</b>", b.IRI("http://www.w3.org/1999/02/22-rdf-syntax-ns#XMLLiteral")),
+	)
+
+	var buf bytes.Buffer
+	err := Serialize(g, &buf)
+	is.NoErr(err)
+
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<rdf:RDF xmlns:ns0="http://example.org/unknown/" xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about="urn:subject">
+    <ns0:label>a &lt; b &amp; c</ns0:label>
+    <ns0:parts rdf:parseType="Literal"><b>bold</b></ns0:parts>
+  </rdf:Description>
+</rdf:RDF>
+`
+
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("serialize = mismatch (-want +got):\n%s", diff)
+	}
+
+	roundTrip, err := Parse(&buf, nil)
+	is.NoErr(err)
+	is.Equal(roundTrip.Len(), 2)
+}
+
+func TestSerializeInvalidPredicate(t *testing.T) {
+	is := is.New(t)
+
+	b := rdf.Builder{}
+
+	g := rdf.NewGraph()
+	g.AddTriple(
+		b.IRI("urn:subject"),
+		b.IRI("http://example.org/123"),
+		b.Literal("value"),
+	)
+
+	var buf bytes.Buffer
+	err := Serialize(g, &buf)
+	is.True(err != nil)
+}
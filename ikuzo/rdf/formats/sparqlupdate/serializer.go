@@ -0,0 +1,196 @@
+// Package sparqlupdate writes a rdf.GraphDiff as a SPARQL 1.1 Update request.
+package sparqlupdate
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/delving/hub3/ikuzo/rdf"
+)
+
+// Serialize writes the diff as a SPARQL Update request to w. When graph is not
+// nil the operations are scoped to that named graph.
+//
+// Removed triples without blank nodes are deleted with DELETE DATA. Blank nodes
+// are not allowed in DELETE DATA, so removed triples with blank nodes are
+// deleted with a DELETE/WHERE operation per group of connected blank nodes,
+// where the blank nodes are replaced by variables. Added triples are inserted
+// with INSERT DATA. Nothing is written when the diff is empty.
+func Serialize(diff *rdf.GraphDiff, graph rdf.Context, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	operations := []string{}
+
+	ground := []*rdf.Triple{}
+	withBlankNodes := []*rdf.Triple{}
+
+	for _, t := range diff.Removed {
+		if hasBlankNode(t) {
+			withBlankNodes = append(withBlankNodes, t)
+			continue
+		}
+
+		ground = append(ground, t)
+	}
+
+	if len(ground) > 0 {
+		operations = append(operations, "DELETE DATA {\n"+block(ground, graph, nil)+"}")
+	}
+
+	for i, group := range connected(withBlankNodes) {
+		vars := map[string]string{}
+
+		for _, t := range group {
+			for _, term := range []rdf.Term{t.Subject, t.Object} {
+				if b, ok := term.(rdf.BlankNode); ok {
+					if _, ok := vars[b.RawValue()]; !ok {
+						vars[b.RawValue()] = fmt.Sprintf("?b%d_%d", i, len(vars))
+					}
+				}
+			}
+		}
+
+		pattern := block(group, graph, vars)
+		operations = append(operations, "DELETE {\n"+pattern+"}\nWHERE {\n"+pattern+"}")
+	}
+
+	if len(diff.Added) > 0 {
+		operations = append(operations, "INSERT DATA {\n"+block(diff.Added, graph, nil)+"}")
+	}
+
+	if len(operations) == 0 {
+		return nil
+	}
+
+	if _, err := io.WriteString(bw, strings.Join(operations, " ;\n")+"\n"); err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+// block returns the triples, optionally wrapped in a GRAPH clause. Blank nodes
+// that have an entry in vars are replaced by the variable.
+func block(triples []*rdf.Triple, graph rdf.Context, vars map[string]string) string {
+	indent := "  "
+
+	var sb strings.Builder
+
+	if graph != nil {
+		sb.WriteString("  GRAPH " + term(graph, nil) + " {\n")
+
+		indent = "    "
+	}
+
+	for _, t := range triples {
+		sb.WriteString(indent)
+		sb.WriteString(term(t.Subject, vars))
+		sb.WriteString(" ")
+		sb.WriteString(term(t.Predicate, vars))
+		sb.WriteString(" ")
+		sb.WriteString(term(t.Object, vars))
+		sb.WriteString(" .\n")
+	}
+
+	if graph != nil {
+		sb.WriteString("  }\n")
+	}
+
+	return sb.String()
+}
+
+func hasBlankNode(t *rdf.Triple) bool {
+	return t.Subject.Type() == rdf.TermBlankNode || t.Object.Type() == rdf.TermBlankNode
+}
+
+// connected groups the triples that share blank nodes.
+func connected(triples []*rdf.Triple) [][]*rdf.Triple {
+	parent := map[string]string{}
+
+	var find func(label string) string
+
+	find = func(label string) string {
+		p, ok := parent[label]
+		if !ok || p == label {
+			parent[label] = label
+			return label
+		}
+
+		root := find(p)
+		parent[label] = root
+
+		return root
+	}
+
+	root := func(t *rdf.Triple) string {
+		for _, term := range []rdf.Term{t.Subject, t.Object} {
+			if b, ok := term.(rdf.BlankNode); ok {
+				return find(b.RawValue())
+			}
+		}
+
+		return ""
+	}
+
+	for _, t := range triples {
+		s, sok := t.Subject.(rdf.BlankNode)
+		o, ook := t.Object.(rdf.BlankNode)
+
+		if sok && ook {
+			parent[find(s.RawValue())] = find(o.RawValue())
+		}
+	}
+
+	groups := [][]*rdf.Triple{}
+	index := map[string]int{}
+
+	for _, t := range triples {
+		r := root(t)
+
+		i, ok := index[r]
+		if !ok {
+			i = len(groups)
+			index[r] = i
+
+			groups = append(groups, []*rdf.Triple{})
+		}
+
+		groups[i] = append(groups[i], t)
+	}
+
+	return groups
+}
+
+// term returns the SPARQL representation of the term.
+func term(term rdf.Term, vars map[string]string) string {
+	switch t := term.(type) {
+	case rdf.BlankNode:
+		if v, ok := vars[t.RawValue()]; ok {
+			return v
+		}
+
+		return t.String()
+	case rdf.Literal:
+		str := `"` + stringEscaper.Replace(t.RawValue()) + `"`
+
+		if t.Lang() != "" {
+			return str + "@" + strings.TrimPrefix(t.Lang(), "@")
+		}
+
+		if !t.DataType.Equal(rdf.IRI{}) && !t.HasImpliedDataType() {
+			str += "^^" + t.DataType.String()
+		}
+
+		return str
+	default:
+		return term.String()
+	}
+}
+
+var stringEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	"\n", `\n`,
+	"\r", `\r`,
+)
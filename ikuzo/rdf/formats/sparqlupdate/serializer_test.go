@@ -0,0 +1,96 @@
+package sparqlupdate
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/delving/hub3/ikuzo/rdf"
+	"github.com/delving/hub3/ikuzo/rdf/formats/ntriples"
+	"github.com/google/go-cmp/cmp"
+	"github.com/matryer/is"
+)
+
+func TestSerialize(t *testing.T) {
+	from, err := ntriples.Parse(strings.NewReader(`<urn:s/1> <urn:p/title> "old" .
+<urn:s/1> <urn:p/creator> _:c .
+_:c <urn:p/name> "Jan" .
+`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	to, err := ntriples.Parse(strings.NewReader(`<urn:s/1> <urn:p/title> "new" .
+`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("named graph", func(t *testing.T) {
+		is := is.New(t)
+
+		graph, err := rdf.NewIRI("urn:graph/1")
+		is.NoErr(err)
+
+		var buf bytes.Buffer
+		err = Serialize(rdf.Diff(from, to), graph, &buf)
+		is.NoErr(err)
+
+		want := `DELETE DATA {
+  GRAPH <urn:graph/1> {
+    <urn:s/1> <urn:p/title> "old" .
+  }
+} ;
+DELETE {
+  GRAPH <urn:graph/1> {
+    <urn:s/1> <urn:p/creator> ?b0_0 .
+    ?b0_0 <urn:p/name> "Jan" .
+  }
+}
+WHERE {
+  GRAPH <urn:graph/1> {
+    <urn:s/1> <urn:p/creator> ?b0_0 .
+    ?b0_0 <urn:p/name> "Jan" .
+  }
+} ;
+INSERT DATA {
+  GRAPH <urn:graph/1> {
+    <urn:s/1> <urn:p/title> "new" .
+  }
+}
+`
+		if diff := cmp.Diff(want, buf.String()); diff != "" {
+			t.Errorf("Serialize() mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("default graph", func(t *testing.T) {
+		is := is.New(t)
+
+		var buf bytes.Buffer
+		err := Serialize(rdf.Diff(to, from), nil, &buf)
+		is.NoErr(err)
+
+		want := `DELETE DATA {
+  <urn:s/1> <urn:p/title> "new" .
+} ;
+INSERT DATA {
+  <urn:s/1> <urn:p/title> "old" .
+  <urn:s/1> <urn:p/creator> _:c .
+  _:c <urn:p/name> "Jan" .
+}
+`
+		if diff := cmp.Diff(want, buf.String()); diff != "" {
+			t.Errorf("Serialize() mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("empty diff", func(t *testing.T) {
+		is := is.New(t)
+
+		var buf bytes.Buffer
+		err := Serialize(rdf.Diff(from, from), nil, &buf)
+		is.NoErr(err)
+		is.Equal(buf.Len(), 0)
+	})
+}
@@ -0,0 +1,532 @@
+// Package trig provides tools to parse and serialize an rdf.Dataset in the
+// TriG format.
+//
+// For more information about TriG, see - https://www.w3.org/TR/trig/.
+package trig
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/delving/hub3/ikuzo/rdf"
+	gonrdf "github.com/kiivihal/gon3"
+)
+
+// ErrInvalidTriG is returned when the input is not valid TriG.
+var ErrInvalidTriG = errors.New("invalid TriG")
+
+// blankNodeRgx matches the blank node labels of the document. Matches inside
+// strings only mark more labels as used.
+var blankNodeRgx = regexp.MustCompile(`_:([A-Za-z0-9_][A-Za-z0-9_.\-]*)`)
+
+// directiveRgx matches the @prefix, @base, PREFIX and BASE directives.
+var directiveRgx = regexp.MustCompile(
+	`(?im)^[ \t]*(@prefix[ \t]+[^ \t]*:[ \t]*<[^>]*>[ \t]*\.|@base[ \t]+<[^>]*>[ \t]*\.|prefix[ \t]+[^ \t]*:[ \t]*<[^>]*>|base[ \t]+<[^>]*>)`,
+)
+
+// Parse reads TriG from r and adds the triples to the graphs of the Dataset.
+// When ds is nil a new Dataset is created.
+//
+// Triples outside a graph block and in an unlabeled graph block are added to the
+// default graph. Blank node labels are shared between all the graphs in the document.
+// Anonymous blank nodes get a generated label that the document does not use.
+func Parse(r io.Reader, ds *rdf.Dataset) (*rdf.Dataset, error) {
+	if ds == nil {
+		ds = rdf.NewDataset()
+	}
+
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return ds, err
+	}
+
+	segments, err := split(string(body))
+	if err != nil {
+		return ds, fmt.Errorf("%w; %s", ErrInvalidTriG, err)
+	}
+
+	tp := &parser{ds: ds, used: map[string]bool{}}
+
+	for _, match := range blankNodeRgx.FindAllStringSubmatch(string(body), -1) {
+		tp.used[strings.TrimRight(match[1], ".")] = true
+	}
+
+	for _, seg := range segments {
+		if err := tp.parseSegment(seg); err != nil {
+			return ds, fmt.Errorf("%w; %s", ErrInvalidTriG, err)
+		}
+	}
+
+	return ds, nil
+}
+
+// segment is a part of the TriG document that is valid Turtle.
+type segment struct {
+	// label is the graph label in Turtle syntax. It is empty for the default graph.
+	label string
+	// text contains the Turtle triples of the segment
+	text string
+	// topLevel is true when the text is outside a graph block.
+	topLevel bool
+}
+
+// split splits the document into top-level segments and graph blocks.
+func split(doc string) ([]segment, error) {
+	segments := []segment{}
+	start := 0
+	blockStart := -1
+
+	// last is the last significant character outside comments
+	var last byte
+
+	for i := 0; i < len(doc); i++ {
+		c := doc[i]
+
+		switch c {
+		case ' ', '\t', '\r', '\n':
+			continue
+		case '<':
+			end := strings.IndexByte(doc[i:], '>')
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated IRI at offset %d", i)
+			}
+
+			i += end
+		case '"', '\'':
+			end, err := skipString(doc, i)
+			if err != nil {
+				return nil, err
+			}
+
+			i = end
+		case '#':
+			end := strings.IndexByte(doc[i:], '\n')
+			if end == -1 {
+				end = len(doc) - i
+			}
+
+			i += end
+
+			continue
+		case '{':
+			if blockStart != -1 {
+				return nil, fmt.Errorf("nested graph block at offset %d", i)
+			}
+
+			text, label := splitLabel(doc[start:i])
+
+			segments = append(segments, segment{text: text, topLevel: true}, segment{label: label})
+			blockStart = i + 1
+		case '}':
+			if blockStart == -1 {
+				return nil, fmt.Errorf("unexpected '}' at offset %d", i)
+			}
+
+			text := doc[blockStart:i]
+
+			// the final '.' in a graph block is optional
+			if last != '.' && last != '{' {
+				text += "\n."
+			}
+
+			segments[len(segments)-1].text = text
+			blockStart = -1
+			start = i + 1
+		}
+
+		last = c
+	}
+
+	if blockStart != -1 {
+		return nil, fmt.Errorf("unterminated graph block at offset %d", blockStart-1)
+	}
+
+	segments = append(segments, segment{text: doc[start:], topLevel: true})
+
+	return segments, nil
+}
+
+// skipString returns the offset of the closing quote of the string that starts at i.
+func skipString(doc string, i int) (int, error) {
+	quote := doc[i : i+1]
+	if strings.HasPrefix(doc[i:], strings.Repeat(quote, 3)) {
+		quote = strings.Repeat(quote, 3)
+	}
+
+	for j := i + len(quote); j < len(doc); j++ {
+		switch {
+		case doc[j] == '\\':
+			j++
+		case strings.HasPrefix(doc[j:], quote):
+			return j + len(quote) - 1, nil
+		case len(quote) == 1 && doc[j] == '\n':
+			return 0, fmt.Errorf("unterminated string at offset %d", i)
+		}
+	}
+
+	return 0, fmt.Errorf("unterminated string at offset %d", i)
+}
+
+const whitespace = " \t\r\n"
+
+// labelPropertyLists replaces the blank node property lists of the Turtle
+// text, e.g. '[ dc:title "x" ]', with a labeled blank node, because gon3
+// gives all property lists in a document the same blank node. The triples of
+// a property list in subject position stay in the statement; the others are
+// added as statements after the statement.
+func labelPropertyLists(text string, newLabel func() string) (string, error) {
+	lr := &listRewriter{text: text, newLabel: newLabel}
+
+	out, err := lr.rewrite(false)
+	if err != nil {
+		return "", err
+	}
+
+	return out + "\n" + lr.statements.String(), nil
+}
+
+type listRewriter struct {
+	text     string
+	i        int
+	newLabel func() string
+	// statements contains the triples of the property lists in object position
+	statements strings.Builder
+}
+
+// rewrite copies the text up to the end, or up to the closing ']' of a nested
+// property list, and replaces the property lists it contains.
+func (lr *listRewriter) rewrite(nested bool) (string, error) {
+	var out strings.Builder
+
+	subject := !nested
+
+	for lr.i < len(lr.text) {
+		start := lr.i
+		c := lr.text[lr.i]
+
+		switch c {
+		case ' ', '\t', '\r', '\n':
+			lr.i++
+			out.WriteByte(c)
+
+			continue
+		case '<':
+			end := strings.IndexByte(lr.text[lr.i:], '>')
+			if end == -1 {
+				return "", fmt.Errorf("unterminated IRI at offset %d", lr.i)
+			}
+
+			lr.i += end + 1
+		case '"', '\'':
+			end, err := skipString(lr.text, lr.i)
+			if err != nil {
+				return "", err
+			}
+
+			lr.i = end + 1
+		case '#':
+			end := strings.IndexByte(lr.text[lr.i:], '\n')
+			if end == -1 {
+				end = len(lr.text) - lr.i
+			}
+
+			lr.i += end
+			out.WriteString(lr.text[start:lr.i])
+
+			continue
+		case '[':
+			lr.i++
+
+			body, err := lr.rewrite(true)
+			if err != nil {
+				return "", err
+			}
+
+			switch {
+			case strings.TrimSpace(body) == "":
+				out.WriteString("[" + body + "]")
+			case subject:
+				out.WriteString("_:" + lr.newLabel() + " " + body)
+
+				if !lr.atTerminator() {
+					out.WriteString(" ;")
+				}
+			default:
+				label := "_:" + lr.newLabel()
+				lr.statements.WriteString(label + " " + body + " .\n")
+				out.WriteString(label)
+			}
+
+			subject = false
+
+			continue
+		case ']':
+			if !nested {
+				return "", fmt.Errorf("unexpected ']' at offset %d", lr.i)
+			}
+
+			lr.i++
+
+			return out.String(), nil
+		case '.':
+			lr.i++
+			subject = !nested && lr.terminates()
+		default:
+			lr.i++
+			subject = false
+		}
+
+		out.WriteString(lr.text[start:lr.i])
+
+		if subject && lr.statements.Len() != 0 {
+			out.WriteString("\n" + lr.statements.String())
+			lr.statements.Reset()
+		}
+	}
+
+	if nested {
+		return "", fmt.Errorf("unterminated blank node property list")
+	}
+
+	return out.String(), nil
+}
+
+// terminates returns true when the '.' before the current offset ends a
+// statement and is not part of a number or a prefixed name.
+func (lr *listRewriter) terminates() bool {
+	if lr.i >= len(lr.text) {
+		return true
+	}
+
+	c := lr.text[lr.i]
+
+	return !(c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c == '-' || c == ':')
+}
+
+// atTerminator returns true when the next significant character ends the
+// statement.
+func (lr *listRewriter) atTerminator() bool {
+	rest := strings.TrimLeft(lr.text[lr.i:], whitespace)
+	if !strings.HasPrefix(rest, ".") {
+		return false
+	}
+
+	next := &listRewriter{text: rest, i: 1}
+
+	return next.terminates()
+}
+
+// splitLabel splits the graph label and the optional GRAPH keyword from the
+// end of the text that precedes a graph block. The label is empty when the
+// block belongs to the default graph.
+func splitLabel(text string) (rest, label string) {
+	rest = strings.TrimRight(text, whitespace)
+
+	// a graph block directly after a statement or a directive has no label
+	lastLine := rest[strings.LastIndexByte(rest, '\n')+1:]
+	if loc := directiveRgx.FindStringIndex(lastLine); loc != nil && strings.TrimSpace(lastLine[loc[1]:]) == "" {
+		return text, ""
+	}
+
+	if rest == "" || strings.HasSuffix(rest, ".") {
+		return text, ""
+	}
+
+	var idx int
+
+	switch {
+	case strings.HasSuffix(rest, ">"):
+		idx = strings.LastIndexByte(rest, '<')
+	case strings.HasSuffix(rest, "]"):
+		idx = strings.LastIndexByte(rest, '[')
+	default:
+		idx = strings.LastIndexAny(rest, whitespace) + 1
+	}
+
+	label = rest[idx:]
+	rest = strings.TrimRight(rest[:idx], whitespace)
+
+	const keyword = "GRAPH"
+
+	if len(rest) >= len(keyword) && strings.EqualFold(rest[len(rest)-len(keyword):], keyword) {
+		before := rest[:len(rest)-len(keyword)]
+		if before == "" || strings.ContainsAny(before[len(before)-1:], whitespace+".") {
+			rest = before
+		}
+	}
+
+	return rest, label
+}
+
+type parser struct {
+	ds *rdf.Dataset
+	// directives contains all the directives of the document parsed so far
+	directives strings.Builder
+	// genID is the counter for the generated blank nodes
+	genID int
+	// used contains the blank node labels of the document
+	used map[string]bool
+}
+
+// newLabel returns a blank node label that is not used by the document.
+func (p *parser) newLabel() string {
+	for {
+		p.genID++
+
+		label := fmt.Sprintf("trig%d", p.genID)
+		if !p.used[label] {
+			return label
+		}
+	}
+}
+
+func (p *parser) parseSegment(seg segment) error {
+	if seg.topLevel {
+		for _, directive := range directiveRgx.FindAllString(seg.text, -1) {
+			p.directives.WriteString(strings.TrimSpace(directive))
+			p.directives.WriteString("\n")
+		}
+	}
+
+	// relabel the generated blank nodes, so they are unique in the Dataset
+	bnodes := map[string]rdf.BlankNode{}
+
+	var ctx rdf.Context
+
+	if seg.label != "" {
+		label, err := p.parseLabel(seg.label, bnodes)
+		if err != nil {
+			return err
+		}
+
+		ctx = label
+	}
+
+	g := p.ds.Graph(ctx)
+
+	text := seg.text
+	if seg.topLevel {
+		// the directives are prepended to each segment
+		text = directiveRgx.ReplaceAllString(text, "")
+	}
+
+	text, err := labelPropertyLists(text, p.newLabel)
+	if err != nil {
+		return err
+	}
+
+	parsed, err := gonrdf.NewParser("").Parse(strings.NewReader(p.directives.String() + text))
+	if err != nil {
+		return err
+	}
+
+	for triple := range parsed.IterTriples() {
+		t, err := p.convert(triple, bnodes)
+		if err != nil {
+			return err
+		}
+
+		g.Add(t)
+	}
+
+	return nil
+}
+
+// parseLabel resolves the graph label with the directives of the document.
+func (p *parser) parseLabel(label string, bnodes map[string]rdf.BlankNode) (rdf.Context, error) {
+	stmt := fmt.Sprintf("%s%s <urn:trig:label> <urn:trig:label> .", p.directives.String(), label)
+
+	parsed, err := gonrdf.NewParser("").Parse(strings.NewReader(stmt))
+	if err != nil {
+		return nil, fmt.Errorf("invalid graph label %s: %w", label, err)
+	}
+
+	for triple := range parsed.IterTriples() {
+		term, err := p.term(triple.Subject, bnodes)
+		if err != nil {
+			return nil, err
+		}
+
+		ctx, ok := term.(rdf.Context)
+		if !ok {
+			return nil, fmt.Errorf("invalid graph label %s", label)
+		}
+
+		return ctx, nil
+	}
+
+	return nil, fmt.Errorf("invalid graph label %s", label)
+}
+
+func (p *parser) convert(triple *gonrdf.Triple, bnodes map[string]rdf.BlankNode) (*rdf.Triple, error) {
+	s, err := p.term(triple.Subject, bnodes)
+	if err != nil {
+		return nil, err
+	}
+
+	pred, err := p.term(triple.Predicate, bnodes)
+	if err != nil {
+		return nil, err
+	}
+
+	o, err := p.term(triple.Object, bnodes)
+	if err != nil {
+		return nil, err
+	}
+
+	return rdf.NewTriple(s.(rdf.Subject), pred.(rdf.Predicate), o.(rdf.Object)), nil
+}
+
+func (p *parser) term(term gonrdf.Term, bnodes map[string]rdf.BlankNode) (rdf.Term, error) {
+	switch term := term.(type) {
+	case *gonrdf.BlankNode:
+		// the labels of generated blank nodes are only unique within a single parse.
+		if term.Id == 0 || term.Label != fmt.Sprintf("a%d", term.Id) {
+			return rdf.NewBlankNode(term.RawValue())
+		}
+
+		if bnode, ok := bnodes[term.Label]; ok {
+			return bnode, nil
+		}
+
+		bnode, err := rdf.NewBlankNode(p.newLabel())
+		if err != nil {
+			return nil, err
+		}
+
+		bnodes[term.Label] = bnode
+
+		return bnode, nil
+	case *gonrdf.Literal:
+		if len(term.LanguageTag) > 0 {
+			return rdf.NewLiteralWithLang(term.LexicalForm, term.LanguageTag)
+		}
+
+		if term.DatatypeIRI != nil && len(term.DatatypeIRI.String()) > 0 {
+			dt, err := rdf.NewIRI(term.DatatypeIRI.RawValue())
+			if err != nil {
+				return nil, err
+			}
+
+			return rdf.NewLiteralWithType(term.LexicalForm, dt)
+		}
+
+		return rdf.NewLiteral(term.RawValue())
+	case *gonrdf.IRI:
+		return rdf.NewIRI(term.RawValue())
+	}
+
+	return nil, fmt.Errorf("unknown RDF term type: %T", term)
+}
+
+// compile time check of interface
+var _ rdf.DatasetParser = (*p)(nil)
+
+type p struct{}
+
+func (p p) ParseDataset(r io.Reader, ds *rdf.Dataset) (*rdf.Dataset, error) {
+	return Parse(r, ds)
+}
@@ -0,0 +1,168 @@
+package trig
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/delving/hub3/ikuzo/rdf"
+	"github.com/matryer/is"
+)
+
+func getReader(name string) (io.ReadCloser, error) {
+	f, err := os.Open("./testdata/" + name)
+	if err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// nolint:gocritic
+func TestParse(t *testing.T) {
+	t.Run("parse trig with dataset", func(t *testing.T) {
+		is := is.New(t)
+
+		ds := rdf.NewDataset()
+		r, err := getReader("dataset.trig")
+		is.NoErr(err)
+		defer r.Close()
+
+		returned, err := Parse(r, ds)
+		is.NoErr(err)
+		is.Equal(ds, returned)
+
+		is.Equal(ds.Len(), 13)
+		is.Equal(ds.DefaultGraph().Len(), 2)
+
+		g1, err := rdf.NewIRI("http://example.org/graph/1")
+		is.NoErr(err)
+
+		g2, err := rdf.NewIRI("http://example.org/graph2")
+		is.NoErr(err)
+
+		g3, err := rdf.NewBlankNode("g1")
+		is.NoErr(err)
+
+		is.Equal(ds.Names(), []rdf.Context{g1, g2, g3})
+
+		g, ok := ds.Lookup(g1)
+		is.True(ok)
+		is.Equal(g.Len(), 6)
+
+		var description string
+
+		for _, t := range g.Triples() {
+			if t.Predicate.RawValue() == "http://purl.org/dc/elements/1.1/description" {
+				description = t.Object.RawValue()
+			}
+		}
+
+		is.Equal(description, "line one\nline \"two\" {with braces}")
+
+		g, ok = ds.Lookup(g2)
+		is.True(ok)
+		is.Equal(g.Len(), 4)
+
+		g, ok = ds.Lookup(g3)
+		is.True(ok)
+		is.Equal(g.Len(), 1)
+	})
+
+	t.Run("blank nodes", func(t *testing.T) {
+		is := is.New(t)
+
+		r, err := getReader("dataset.trig")
+		is.NoErr(err)
+		defer r.Close()
+
+		ds, err := Parse(r, nil)
+		is.NoErr(err)
+
+		subjects := map[string]int{}
+
+		for _, q := range ds.Quads() {
+			if q.Subject.Type() == rdf.TermBlankNode {
+				subjects[q.Subject.String()]++
+			}
+		}
+
+		// labeled blank nodes are shared between graphs, generated blank nodes are unique
+		is.Equal(len(subjects), 3)
+		is.Equal(subjects["_:b0"], 2)
+	})
+
+	t.Run("blank node property lists", func(t *testing.T) {
+		is := is.New(t)
+
+		input := `@prefix dc: <http://purl.org/dc/elements/1.1/> .
+<urn:g> {
+    <urn:doc> dc:subject [ dc:title "first" ], [ dc:title "second" ; ] ;
+        dc:creator _:trig1 .
+    _:trig1 dc:title "user label" .
+    [ dc:title "subject" ; dc:relation [ dc:title "nested" ] ] .
+    [ dc:title "with predicates" ] dc:date "2020" .
+}`
+
+		ds, err := Parse(strings.NewReader(input), nil)
+		is.NoErr(err)
+		is.Equal(ds.Len(), 11)
+
+		titles := map[string]string{}
+
+		for _, q := range ds.Quads() {
+			if q.Predicate.RawValue() == "http://purl.org/dc/elements/1.1/title" {
+				titles[q.Object.RawValue()] = q.Subject.String()
+			}
+		}
+
+		is.Equal(titles["user label"], "_:trig1") // the user label is kept
+
+		labels := map[string]bool{}
+		for _, label := range titles {
+			labels[label] = true
+		}
+
+		is.Equal(len(labels), len(titles)) // each property list is a separate blank node
+	})
+
+	t.Run("parse turtle", func(t *testing.T) {
+		is := is.New(t)
+
+		f, err := os.Open("../turtle/testdata/rdf.ttl")
+		is.NoErr(err)
+		defer f.Close()
+
+		ds, err := Parse(f, nil)
+		is.NoErr(err)
+		is.Equal(ds.DefaultGraph().Len(), 47)
+		is.Equal(len(ds.Names()), 0)
+	})
+
+	t.Run("parse invalid trig", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			input string
+		}{
+			{"unterminated block", "<urn:g> { <urn:s> <urn:p> <urn:o> ."},
+			{"nested block", "<urn:g> { <urn:h> { <urn:s> <urn:p> <urn:o> . } }"},
+			{"unexpected close", "<urn:s> <urn:p> <urn:o> . }"},
+			{"invalid triple", "<urn:g> { <urn:s> <urn:p> . }"},
+			{"unknown prefix", "ex:g { <urn:s> <urn:p> <urn:o> . }"},
+			{"unterminated string", `<urn:g> { <urn:s> <urn:p> "o . }`},
+		}
+
+		for _, tt := range tests {
+			tt := tt
+
+			t.Run(tt.name, func(t *testing.T) {
+				is := is.New(t)
+
+				_, err := Parse(strings.NewReader(tt.input), nil)
+				is.True(errors.Is(err, ErrInvalidTriG))
+			})
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package trig
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/delving/hub3/ikuzo/rdf"
+	"github.com/delving/hub3/ikuzo/rdf/formats/turtle"
+)
+
+const indent = "    "
+
+// Serialize writes the Dataset as TriG to w.
+//
+// The prefixes are shared by all the graphs and declared once at the start of
+// the document. The prefixes are resolved with the NamespaceManager of the
+// default graph. The default graph is written first as an unlabeled graph
+// block, followed by the named graphs in the order in which they were added
+// to the Dataset. Empty graphs are omitted.
+func Serialize(ds *rdf.Dataset, w io.Writer) error {
+	s := turtle.NewSerializer(ds.DefaultGraph().NamespaceManager)
+
+	type graph struct {
+		name    rdf.Context
+		triples []*rdf.Triple
+	}
+
+	graphs := []graph{{triples: ds.DefaultGraph().Triples()}}
+
+	for _, name := range ds.Names() {
+		g, _ := ds.Lookup(name)
+		graphs = append(graphs, graph{name: name, triples: g.Triples()})
+	}
+
+	for _, g := range graphs {
+		s.Register(g.triples)
+	}
+
+	bw := bufio.NewWriter(w)
+
+	if err := s.WritePrefixes(bw); err != nil {
+		return err
+	}
+
+	first := true
+
+	for _, g := range graphs {
+		if len(g.triples) == 0 {
+			continue
+		}
+
+		if !first {
+			if _, err := io.WriteString(bw, "\n"); err != nil {
+				return err
+			}
+		}
+
+		first = false
+
+		label := "{\n"
+		if g.name != nil {
+			label = fmt.Sprintf("%s {\n", s.Term(g.name))
+		}
+
+		if _, err := io.WriteString(bw, label); err != nil {
+			return err
+		}
+
+		if err := s.WriteTriples(bw, g.triples, indent); err != nil {
+			return err
+		}
+
+		if _, err := io.WriteString(bw, "}\n"); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
@@ -0,0 +1,66 @@
+package trig
+
+import (
+	"bytes"
+	"os"
+	"sort"
+	"testing"
+
+	"github.com/delving/hub3/ikuzo/rdf"
+	"github.com/google/go-cmp/cmp"
+	"github.com/matryer/is"
+)
+
+// nolint:gocritic
+func TestSerialize(t *testing.T) {
+	t.Run("serialize dataset", func(t *testing.T) {
+		is := is.New(t)
+
+		r, err := getReader("dataset.trig")
+		is.NoErr(err)
+		defer r.Close()
+
+		ds, err := Parse(r, nil)
+		is.NoErr(err)
+
+		var buf bytes.Buffer
+		err = Serialize(ds, &buf)
+		is.NoErr(err)
+
+		want, err := os.ReadFile("./testdata/dataset.golden.trig")
+		is.NoErr(err)
+
+		if diff := cmp.Diff(string(want), buf.String()); diff != "" {
+			t.Errorf("Serialize() mismatch (-want +got):\n%s", diff)
+		}
+
+		// round trip
+		roundTrip, err := Parse(&buf, nil)
+		is.NoErr(err)
+		is.Equal(roundTrip.Names(), ds.Names())
+
+		if diff := cmp.Diff(sortedQuads(ds), sortedQuads(roundTrip)); diff != "" {
+			t.Errorf("round trip = mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("empty dataset", func(t *testing.T) {
+		is := is.New(t)
+
+		var buf bytes.Buffer
+		err := Serialize(rdf.NewDataset(), &buf)
+		is.NoErr(err)
+		is.Equal(buf.String(), "")
+	})
+}
+
+func sortedQuads(ds *rdf.Dataset) []string {
+	quads := []string{}
+	for _, q := range ds.Quads() {
+		quads = append(quads, q.String())
+	}
+
+	sort.Strings(quads)
+
+	return quads
+}
@@ -0,0 +1,57 @@
+package turtle
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/delving/hub3/ikuzo/rdf"
+	"github.com/matryer/is"
+)
+
+func getReader(name string) (io.ReadCloser, error) {
+	f, err := os.Open("./testdata/" + name)
+	if err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// nolint:gocritic
+func TestParse(t *testing.T) {
+	t.Run("parse turtle with graph", func(t *testing.T) {
+		is := is.New(t)
+
+		g := rdf.NewGraph()
+		is.Equal(g.Len(), 0)
+		r, err := getReader("rdf.ttl")
+		is.NoErr(err)
+		defer r.Close()
+		returnedGraph, err := Parse(r, g)
+		is.NoErr(err)
+		is.Equal(g, returnedGraph)
+
+		is.Equal(g.Len(), 47)
+	})
+
+	t.Run("parse turtle without graph", func(t *testing.T) {
+		is := is.New(t)
+
+		r, err := getReader("rdf.ttl")
+		is.NoErr(err)
+		defer r.Close()
+		returnedGraph, err := Parse(r, nil)
+		is.NoErr(err)
+
+		is.Equal(returnedGraph.Len(), 47)
+	})
+
+	t.Run("parse invalid turtle", func(t *testing.T) {
+		is := is.New(t)
+
+		_, err := Parse(strings.NewReader("<urn:s> <urn:p> ."), nil)
+		is.True(err != nil)
+	})
+}
@@ -0,0 +1,320 @@
+package turtle
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/delving/hub3/ikuzo/rdf"
+)
+
+// Serialize writes the Graph as Turtle to w.
+//
+// Triples are grouped by subject in the order in which the subjects were first
+// added to the Graph. IRIs are written in their prefixed form when their base-URI
+// is known to the NamespaceManager of the Graph; only the prefixes that are
+// used are declared.
+func Serialize(g *rdf.Graph, w io.Writer) error {
+	s := NewSerializer(g.NamespaceManager)
+
+	triples := g.Triples()
+	s.Register(triples)
+
+	bw := bufio.NewWriter(w)
+
+	if err := s.WritePrefixes(bw); err != nil {
+		return err
+	}
+
+	if err := s.WriteTriples(bw, triples, ""); err != nil {
+		return err
+	}
+
+	if len(triples) > 0 {
+		if _, err := io.WriteString(bw, "\n"); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// Serializer writes triples in the Turtle syntax. The prefixes of all the
+// registered triples are shared, so it can be used to write multiple sets of
+// triples in a single document, e.g. the graphs of a TriG document.
+type Serializer struct {
+	nm rdf.NamespaceManager
+	// prefixes maps the used base-URIs to their prefix
+	prefixes map[string]string
+}
+
+// NewSerializer returns a Serializer that resolves prefixes with the
+// NamespaceManager. When nm is nil the rdf.DefaultNamespaceManager is used.
+func NewSerializer(nm rdf.NamespaceManager) *Serializer {
+	if nm == nil {
+		nm = rdf.DefaultNamespaceManager
+	}
+
+	return &Serializer{
+		nm:       nm,
+		prefixes: map[string]string{},
+	}
+}
+
+// Register records the prefixes of all the IRIs in the triples. Only
+// registered prefixes are used when writing the triples.
+func (s *Serializer) Register(triples []*rdf.Triple) {
+	for _, t := range triples {
+		s.register(t.Subject)
+		s.register(t.Predicate)
+		s.register(t.Object)
+	}
+}
+
+// WriteTriples writes the triples grouped by subject. Each line is prefixed
+// with indent. The subject blocks are separated by an empty line.
+func (s *Serializer) WriteTriples(w io.Writer, triples []*rdf.Triple, indent string) error {
+	for i, subj := range groupBySubject(triples) {
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+
+		if err := s.writeSubject(w, subj, indent); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type subjectGroup struct {
+	subject    rdf.Subject
+	predicates []*predicateGroup
+}
+
+type predicateGroup struct {
+	predicate rdf.Predicate
+	objects   []rdf.Object
+}
+
+// groupBySubject groups the triples per subject and predicate, while preserving
+// the insertion order. rdf:type is always serialized as the first predicate.
+func groupBySubject(triples []*rdf.Triple) []*subjectGroup {
+	subjects := []*subjectGroup{}
+	bySubject := map[string]*subjectGroup{}
+	byPredicate := map[string]*predicateGroup{}
+
+	for _, t := range triples {
+		sKey := t.Subject.String()
+
+		sg, ok := bySubject[sKey]
+		if !ok {
+			sg = &subjectGroup{subject: t.Subject}
+			bySubject[sKey] = sg
+			subjects = append(subjects, sg)
+		}
+
+		pKey := sKey + t.Predicate.String()
+
+		pg, ok := byPredicate[pKey]
+		if !ok {
+			pg = &predicateGroup{predicate: t.Predicate}
+			byPredicate[pKey] = pg
+			sg.predicates = append(sg.predicates, pg)
+		}
+
+		pg.objects = append(pg.objects, t.Object)
+	}
+
+	for _, sg := range subjects {
+		sort.SliceStable(sg.predicates, func(i, j int) bool {
+			return sg.predicates[i].predicate.RawValue() == rdf.RDFType &&
+				sg.predicates[j].predicate.RawValue() != rdf.RDFType
+		})
+	}
+
+	return subjects
+}
+
+// register records the prefix of the IRI when it is known by the NamespaceManager.
+func (s *Serializer) register(term rdf.Term) {
+	switch t := term.(type) {
+	case rdf.IRI:
+		s.registerIRI(t)
+	case rdf.Literal:
+		if !t.DataType.Equal(rdf.IRI{}) && !t.HasImpliedDataType() {
+			s.registerIRI(t.DataType)
+		}
+	}
+}
+
+func (s *Serializer) registerIRI(iri rdf.IRI) {
+	base, local := iri.Split()
+	if base == "" || !isValidLocalName(local) {
+		return
+	}
+
+	if _, ok := s.prefixes[base]; ok {
+		return
+	}
+
+	if s.nm == nil {
+		return
+	}
+
+	ns, err := s.nm.GetWithBase(base)
+	if err != nil || ns.Prefix == "" {
+		return
+	}
+
+	// the base of the returned namespace can differ, e.g. http vs https
+	if ns.Base != base {
+		return
+	}
+
+	s.prefixes[base] = ns.Prefix
+}
+
+// WritePrefixes writes the @prefix declarations of the registered prefixes.
+func (s *Serializer) WritePrefixes(w io.Writer) error {
+	type prefix struct {
+		prefix string
+		base   string
+	}
+
+	prefixes := make([]prefix, 0, len(s.prefixes))
+	for base, p := range s.prefixes {
+		prefixes = append(prefixes, prefix{prefix: p, base: base})
+	}
+
+	sort.Slice(prefixes, func(i, j int) bool {
+		return prefixes[i].prefix < prefixes[j].prefix
+	})
+
+	for _, p := range prefixes {
+		if _, err := fmt.Fprintf(w, "@prefix %s: <%s> .\n", p.prefix, p.base); err != nil {
+			return err
+		}
+	}
+
+	if len(prefixes) > 0 {
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Serializer) writeSubject(w io.Writer, sg *subjectGroup, indent string) error {
+	var sb strings.Builder
+
+	sb.WriteString(indent)
+	sb.WriteString(s.Term(sg.subject))
+
+	for i, pg := range sg.predicates {
+		if i == 0 {
+			sb.WriteString(" ")
+		} else {
+			sb.WriteString(" ;\n" + indent + "    ")
+		}
+
+		if pg.predicate.RawValue() == rdf.RDFType {
+			sb.WriteString("a")
+		} else {
+			sb.WriteString(s.Term(pg.predicate))
+		}
+
+		for j, o := range pg.objects {
+			if j == 0 {
+				sb.WriteString(" ")
+			} else {
+				sb.WriteString(" ,\n" + indent + "        ")
+			}
+
+			sb.WriteString(s.Term(o))
+		}
+	}
+
+	sb.WriteString(" .\n")
+
+	_, err := io.WriteString(w, sb.String())
+
+	return err
+}
+
+// Term returns the Turtle representation of the term.
+func (s *Serializer) Term(term rdf.Term) string {
+	switch t := term.(type) {
+	case rdf.IRI:
+		return s.iri(t)
+	case rdf.Literal:
+		return s.literal(t)
+	default:
+		return term.String()
+	}
+}
+
+func (s *Serializer) iri(iri rdf.IRI) string {
+	base, local := iri.Split()
+
+	if prefix, ok := s.prefixes[base]; ok && isValidLocalName(local) {
+		return prefix + ":" + local
+	}
+
+	return iri.String()
+}
+
+func (s *Serializer) literal(l rdf.Literal) string {
+	str := `"` + escapeString(l.RawValue()) + `"`
+
+	if l.Lang() != "" {
+		return str + "@" + strings.TrimPrefix(l.Lang(), "@")
+	}
+
+	if !l.DataType.Equal(rdf.IRI{}) && !l.HasImpliedDataType() {
+		str += "^^" + s.iri(l.DataType)
+	}
+
+	return str
+}
+
+var stringEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	"\n", `\n`,
+	"\r", `\r`,
+	"\t", `\t`,
+)
+
+func escapeString(str string) string {
+	return stringEscaper.Replace(str)
+}
+
+// isValidLocalName is a conservative check if the local name can be written
+// as the local part of a prefixed name without escaping.
+func isValidLocalName(local string) bool {
+	if local == "" {
+		return false
+	}
+
+	for i, r := range local {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '_':
+			continue
+		case r == '-' && i > 0:
+			continue
+		case r == '.' && i > 0 && i < len(local)-1:
+			continue
+		default:
+			return false
+		}
+	}
+
+	return true
+}
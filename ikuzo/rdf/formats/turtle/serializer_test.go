@@ -0,0 +1,89 @@
+package turtle
+
+import (
+	"bytes"
+	"os"
+	"sort"
+	"testing"
+
+	"github.com/delving/hub3/ikuzo/rdf"
+	"github.com/delving/hub3/ikuzo/rdf/formats/ntriples"
+	"github.com/google/go-cmp/cmp"
+	"github.com/matryer/is"
+)
+
+func TestSerialize(t *testing.T) {
+	is := is.New(t)
+
+	r, err := getReader("rdf.nt")
+	is.NoErr(err)
+	defer r.Close()
+
+	g, err := ntriples.Parse(r, nil)
+	is.NoErr(err)
+
+	var buf bytes.Buffer
+	err = Serialize(g, &buf)
+	is.NoErr(err)
+
+	want, err := os.ReadFile("./testdata/rdf.ttl")
+	is.NoErr(err)
+
+	if diff := cmp.Diff(string(want), buf.String()); diff != "" {
+		t.Errorf("serialize = mismatch (-want +got):\n%s", diff)
+	}
+
+	// round trip
+	roundTrip, err := Parse(&buf, nil)
+	is.NoErr(err)
+	is.Equal(roundTrip.Len(), g.Len())
+
+	if diff := cmp.Diff(sortedTriples(g), sortedTriples(roundTrip)); diff != "" {
+		t.Errorf("round trip = mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func sortedTriples(g *rdf.Graph) []string {
+	triples := []string{}
+	for _, t := range g.Triples() {
+		triples = append(triples, t.String())
+	}
+
+	sort.Strings(triples)
+
+	return triples
+}
+
+func TestSerializeEscaping(t *testing.T) {
+	is := is.New(t)
+
+	b := rdf.Builder{}
+
+	g := rdf.NewGraph()
+	g.AddTriple(
+		b.IRI("urn:subject"),
+		b.IRI("http://purl.org/dc/elements/1.1/title"),
+		b.Literal("a \"quoted\"\nvalue"),
+	)
+	g.AddTriple(
+		b.IRI("urn:subject"),
+		b.IRI("http://purl.org/dc/elements/1.1/subject"),
+		b.IRI("http://purl.org/dc/elements/1.1/not-a.local."),
+	)
+
+	var buf bytes.Buffer
+	err := Serialize(g, &buf)
+	is.NoErr(err)
+
+	want := "@prefix dc: <http://purl.org/dc/elements/1.1/> .\n\n" +
+		"<urn:subject> dc:title \"a \\\"quoted\\\"\\nvalue\" ;\n" +
+		"    dc:subject <http://purl.org/dc/elements/1.1/not-a.local.> .\n\n"
+
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("serialize = mismatch (-want +got):\n%s", diff)
+	}
+
+	g2, err := Parse(&buf, nil)
+	is.NoErr(err)
+	is.Equal(g2.Len(), 2)
+}
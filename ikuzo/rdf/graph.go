@@ -103,6 +103,28 @@ func (g *Graph) Len() int {
 	return len(g.triples)
 }
 
+// BlankNodeLabels returns the labels of the blank nodes in the Graph. Unlike
+// Triples, it does not mark the Graph as read.
+func (g *Graph) BlankNodeLabels() map[string]bool {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	labels := map[string]bool{}
+
+	for _, t := range g.triples {
+		for _, term := range []Term{t.Subject, t.Object} {
+			switch bnode := term.(type) {
+			case BlankNode:
+				labels[bnode.RawValue()] = true
+			case *BlankNode:
+				labels[bnode.RawValue()] = true
+			}
+		}
+	}
+
+	return labels
+}
+
 // Triples returns an list based on insertion order of the triples in Graph.
 func (g *Graph) Triples() []*Triple {
 	g.export = true
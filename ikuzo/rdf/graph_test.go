@@ -55,6 +55,30 @@ func TestGraph(t *testing.T) {
 		is.Equal(stats.Languages, 1)
 	})
 
+	t.Run("BlankNodeLabels", func(t *testing.T) {
+		is := is.New(t)
+
+		g := rdf.NewGraph()
+
+		s, err := rdf.NewBlankNode("b1")
+		is.NoErr(err)
+
+		o, err := rdf.NewBlankNode("b2")
+		is.NoErr(err)
+
+		p, err := rdf.DC.IRI("relation")
+		is.NoErr(err)
+
+		g.AddTriple(s, p, o)
+
+		is.Equal(g.BlankNodeLabels(), map[string]bool{"b1": true, "b2": true})
+
+		// reading the labels does not mark the graph as read
+		g.AddTriple(o, p, s)
+		_, err = g.TriplesOnce()
+		is.NoErr(err)
+	})
+
 	t.Run("test with index", func(t *testing.T) {
 		is := is.New(t)
 
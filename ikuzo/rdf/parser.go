@@ -8,3 +8,11 @@ import "io"
 type Parser interface {
 	Parse(r io.Reader, g *Graph) (*Graph, error)
 }
+
+// DatasetParser is an interface for parsing RDF with named graphs in io.Reader
+// and storing it into the Dataset.
+//
+// This should be implemented by RDF parsing packages that support quads.
+type DatasetParser interface {
+	ParseDataset(r io.Reader, ds *Dataset) (*Dataset, error)
+}
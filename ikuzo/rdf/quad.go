@@ -1,5 +1,7 @@
 package rdf
 
+import "fmt"
+
 // Context is the IRI namespace for the Quad
 type Context interface {
 	Term
@@ -12,13 +14,28 @@ type Quad struct {
 	ctx Context
 }
 
+// NewQuad returns a new Quad. When the Context is nil the Quad is part of the
+// default graph.
 func NewQuad(triple *Triple, ctx Context) (*Quad, error) {
+	if triple == nil {
+		return nil, fmt.Errorf("triple cannot be nil")
+	}
+
 	return &Quad{Triple: triple, ctx: ctx}, nil
 }
 
+// Context returns the named graph of the Quad. It returns nil when the Quad
+// is part of the default graph.
+func (q Quad) Context() Context {
+	return q.ctx
+}
+
 // Equal tests if other Quad is identical.
 func (q Quad) Equal(other *Quad) bool {
-	if !q.ctx.Equal(other.ctx) {
+	switch {
+	case q.ctx == nil && other.ctx != nil, q.ctx != nil && other.ctx == nil:
+		return false
+	case q.ctx != nil && !q.ctx.Equal(other.ctx):
 		return false
 	}
 
@@ -28,3 +45,25 @@ func (q Quad) Equal(other *Quad) bool {
 
 	return true
 }
+
+// String returns the NQuads representation of this quad.
+func (q Quad) String() string {
+	if q.ctx == nil {
+		return q.Triple.String()
+	}
+
+	var subj, pred, obj string
+	if q.Subject != nil {
+		subj = q.Subject.String()
+	}
+
+	if q.Predicate != nil {
+		pred = q.Predicate.String()
+	}
+
+	if q.Object != nil {
+		obj = q.Object.String()
+	}
+
+	return fmt.Sprintf("%s %s %s %s .", subj, pred, obj, q.ctx.String())
+}
@@ -0,0 +1,57 @@
+package shacl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Report is the result of validating a data graph.
+type Report struct {
+	// Conforms is false when the report contains results with the Violation severity.
+	Conforms bool     `json:"conforms"`
+	Results  []Result `json:"results,omitempty"`
+}
+
+// Result is a single validation result.
+type Result struct {
+	FocusNode  string   `json:"focusNode"`
+	Path       string   `json:"path,omitempty"`
+	Value      string   `json:"value,omitempty"`
+	Shape      string   `json:"shape"`
+	Constraint string   `json:"constraint"`
+	Severity   Severity `json:"severity"`
+	Message    string   `json:"message"`
+}
+
+func (r *Report) add(result Result) {
+	if result.Severity == Violation {
+		r.Conforms = false
+	}
+
+	r.Results = append(r.Results, result)
+}
+
+// String returns a short summary of the results.
+func (r *Report) String() string {
+	if len(r.Results) == 0 {
+		return "conforms"
+	}
+
+	var sb strings.Builder
+
+	for i, result := range r.Results {
+		if i > 0 {
+			sb.WriteString("; ")
+		}
+
+		fmt.Fprintf(&sb, "%s %s", result.Severity, result.FocusNode)
+
+		if result.Path != "" {
+			fmt.Fprintf(&sb, " <%s>", result.Path)
+		}
+
+		fmt.Fprintf(&sb, ": %s", result.Message)
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,264 @@
+// Package shacl provides a validator for rdf.Graph that supports a subset of
+// the SHACL Core constraints.
+//
+// The supported targets are sh:targetClass, sh:targetNode, sh:targetSubjectsOf
+// and sh:targetObjectsOf. Node shapes and property shapes can use sh:minCount,
+// sh:maxCount, sh:datatype, sh:class, sh:nodeKind and sh:pattern. Property shapes
+// only support predicate paths. The sh:property of a property shape is validated
+// against its value nodes. Recursive shapes are not supported.
+//
+// For more information about SHACL, see - https://www.w3.org/TR/shacl/.
+package shacl
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/delving/hub3/ikuzo/rdf"
+)
+
+const (
+	shNS   = "http://www.w3.org/ns/shacl#"
+	rdfNS  = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	rdfsNS = "http://www.w3.org/2000/01/rdf-schema#"
+)
+
+// ErrInvalidShape is returned when the shapes graph contains an invalid or
+// unsupported shape definition.
+var ErrInvalidShape = errors.New("invalid SHACL shape")
+
+// Severity is the severity of a validation result.
+type Severity string
+
+const (
+	Violation Severity = "Violation"
+	Warning   Severity = "Warning"
+	Info      Severity = "Info"
+)
+
+// Shape is a SHACL node shape or property shape.
+type Shape struct {
+	// ID is the IRI or blank node label of the shape.
+	ID string
+	// Path is the predicate of a property shape. It is empty for node shapes.
+	Path string
+
+	Severity    Severity
+	Message     string
+	Deactivated bool
+
+	targetClasses    []string
+	targetNodes      []rdf.Term
+	targetSubjectsOf []string
+	targetObjectsOf  []string
+
+	minCount *int
+	maxCount *int
+	datatype string
+	classes  []string
+	nodeKind string
+	pattern  *regexp.Regexp
+
+	properties []*Shape
+}
+
+// IsPropertyShape returns true when the shape has a sh:path.
+func (s *Shape) IsPropertyShape() bool {
+	return s.Path != ""
+}
+
+// shapesGraph is a helper for reading the shapes from a rdf.Graph.
+type shapesGraph struct {
+	bySubject map[string][]*rdf.Triple
+}
+
+func newShapesGraph(g *rdf.Graph) *shapesGraph {
+	sg := &shapesGraph{bySubject: map[string][]*rdf.Triple{}}
+
+	for _, t := range g.Triples() {
+		key := t.Subject.String()
+		sg.bySubject[key] = append(sg.bySubject[key], t)
+	}
+
+	return sg
+}
+
+func (sg *shapesGraph) objects(subject rdf.Term, predicate string) []rdf.Object {
+	objects := []rdf.Object{}
+
+	for _, t := range sg.bySubject[subject.String()] {
+		if t.Predicate.RawValue() == predicate {
+			objects = append(objects, t.Object)
+		}
+	}
+
+	return objects
+}
+
+func (sg *shapesGraph) object(subject rdf.Term, predicate string) (rdf.Object, bool) {
+	objects := sg.objects(subject, predicate)
+	if len(objects) == 0 {
+		return nil, false
+	}
+
+	return objects[0], true
+}
+
+// parseShapes returns all the node shapes in the graph. A node shape is a
+// subject with rdf:type sh:NodeShape or with one of the target predicates.
+func parseShapes(g *rdf.Graph) ([]*Shape, error) {
+	sg := newShapesGraph(g)
+
+	shapes := []*Shape{}
+	seen := map[string]bool{}
+
+	for _, t := range g.Triples() {
+		key := t.Subject.String()
+		if seen[key] {
+			continue
+		}
+
+		isNodeShape := false
+
+		switch t.Predicate.RawValue() {
+		case rdf.RDFType:
+			isNodeShape = t.Object.RawValue() == shNS+"NodeShape"
+		case shNS + "targetClass", shNS + "targetNode", shNS + "targetSubjectsOf", shNS + "targetObjectsOf":
+			isNodeShape = true
+		}
+
+		if !isNodeShape {
+			continue
+		}
+
+		seen[key] = true
+
+		shape, err := sg.parseShape(t.Subject, map[string]bool{})
+		if err != nil {
+			return nil, err
+		}
+
+		shapes = append(shapes, shape)
+	}
+
+	return shapes, nil
+}
+
+// parseShape parses the shape and its property shapes. visiting contains the
+// shapes that are being parsed, so recursive shapes are rejected.
+func (sg *shapesGraph) parseShape(id rdf.Subject, visiting map[string]bool) (*Shape, error) {
+	shape := &Shape{
+		ID:       id.RawValue(),
+		Severity: Violation,
+	}
+
+	key := id.String()
+	if visiting[key] {
+		return nil, fmt.Errorf("%w: %s recursive shapes are not supported", ErrInvalidShape, shape.ID)
+	}
+
+	visiting[key] = true
+	defer delete(visiting, key)
+
+	for _, t := range sg.bySubject[id.String()] {
+		value := t.Object.RawValue()
+
+		switch strings.TrimPrefix(t.Predicate.RawValue(), shNS) {
+		case "targetClass":
+			shape.targetClasses = append(shape.targetClasses, value)
+		case "targetNode":
+			shape.targetNodes = append(shape.targetNodes, t.Object)
+		case "targetSubjectsOf":
+			shape.targetSubjectsOf = append(shape.targetSubjectsOf, value)
+		case "targetObjectsOf":
+			shape.targetObjectsOf = append(shape.targetObjectsOf, value)
+		case "path":
+			if t.Object.Type() != rdf.TermIRI {
+				return nil, fmt.Errorf("%w: %s only predicate paths are supported", ErrInvalidShape, shape.ID)
+			}
+
+			shape.Path = value
+		case "minCount":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s invalid sh:minCount %q", ErrInvalidShape, shape.ID, value)
+			}
+
+			shape.minCount = &n
+		case "maxCount":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s invalid sh:maxCount %q", ErrInvalidShape, shape.ID, value)
+			}
+
+			shape.maxCount = &n
+		case "datatype":
+			shape.datatype = value
+		case "class":
+			shape.classes = append(shape.classes, value)
+		case "nodeKind":
+			shape.nodeKind = strings.TrimPrefix(value, shNS)
+		case "pattern":
+			flags := ""
+			if f, ok := sg.object(id, shNS+"flags"); ok {
+				flags = f.RawValue()
+			}
+
+			rgx, err := compilePattern(value, flags)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s invalid sh:pattern %q; %s", ErrInvalidShape, shape.ID, value, err)
+			}
+
+			shape.pattern = rgx
+		case "severity":
+			shape.Severity = Severity(strings.TrimPrefix(value, shNS))
+		case "message":
+			shape.Message = value
+		case "deactivated":
+			shape.Deactivated = value == "true"
+		case "property":
+			subj, ok := t.Object.(rdf.Subject)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s sh:property must be a IRI or blank node", ErrInvalidShape, shape.ID)
+			}
+
+			property, err := sg.parseShape(subj, visiting)
+			if err != nil {
+				return nil, err
+			}
+
+			if !property.IsPropertyShape() {
+				return nil, fmt.Errorf("%w: property shape %s has no sh:path", ErrInvalidShape, property.ID)
+			}
+
+			shape.properties = append(shape.properties, property)
+		}
+	}
+
+	return shape, nil
+}
+
+// compilePattern compiles the SHACL pattern with the supported sh:flags.
+func compilePattern(pattern, flags string) (*regexp.Regexp, error) {
+	var goFlags string
+
+	for _, f := range flags {
+		switch f {
+		case 'i', 'm', 's':
+			goFlags += string(f)
+		case 'x':
+			// not supported by the regexp package, so it is ignored
+		default:
+			return nil, fmt.Errorf("unsupported flag %q", f)
+		}
+	}
+
+	if goFlags != "" {
+		pattern = "(?" + goFlags + ")" + pattern
+	}
+
+	return regexp.Compile(pattern)
+}
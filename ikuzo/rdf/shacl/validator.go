@@ -0,0 +1,280 @@
+package shacl
+
+import (
+	"fmt"
+
+	"github.com/delving/hub3/ikuzo/rdf"
+)
+
+const (
+	xsdString     = "http://www.w3.org/2001/XMLSchema#string"
+	rdfLangString = rdfNS + "langString"
+)
+
+// Validator validates data graphs against the shapes from a shapes graph.
+type Validator struct {
+	shapes []*Shape
+}
+
+// NewValidator returns a Validator for the shapes in the shapes graph.
+func NewValidator(shapes *rdf.Graph) (*Validator, error) {
+	parsed, err := parseShapes(shapes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Validator{shapes: parsed}, nil
+}
+
+// Shapes returns the node shapes of the Validator.
+func (v *Validator) Shapes() []*Shape {
+	return v.shapes
+}
+
+// dataGraph is an index of the data graph that is used during validation.
+type dataGraph struct {
+	bySubject   map[string][]*rdf.Triple
+	byObject    map[string][]*rdf.Triple
+	byPredicate map[string][]*rdf.Triple
+	// subClasses maps classes to their direct rdfs:subClassOf subclasses
+	subClasses map[string][]string
+}
+
+func newDataGraph(g *rdf.Graph) *dataGraph {
+	dg := &dataGraph{
+		bySubject:   map[string][]*rdf.Triple{},
+		byObject:    map[string][]*rdf.Triple{},
+		byPredicate: map[string][]*rdf.Triple{},
+		subClasses:  map[string][]string{},
+	}
+
+	for _, t := range g.Triples() {
+		dg.bySubject[t.Subject.String()] = append(dg.bySubject[t.Subject.String()], t)
+		dg.byObject[t.Object.String()] = append(dg.byObject[t.Object.String()], t)
+		dg.byPredicate[t.Predicate.RawValue()] = append(dg.byPredicate[t.Predicate.RawValue()], t)
+
+		if t.Predicate.RawValue() == rdfsNS+"subClassOf" {
+			dg.subClasses[t.Object.RawValue()] = append(dg.subClasses[t.Object.RawValue()], t.Subject.RawValue())
+		}
+	}
+
+	return dg
+}
+
+func (dg *dataGraph) values(focus rdf.Term, path string) []rdf.Object {
+	values := []rdf.Object{}
+
+	for _, t := range dg.bySubject[focus.String()] {
+		if t.Predicate.RawValue() == path {
+			values = append(values, t.Object)
+		}
+	}
+
+	return values
+}
+
+// classes returns the class and all its subclasses in the data graph.
+func (dg *dataGraph) classes(class string) map[string]bool {
+	classes := map[string]bool{}
+	queue := []string{class}
+
+	for len(queue) > 0 {
+		c := queue[0]
+		queue = queue[1:]
+
+		if classes[c] {
+			continue
+		}
+
+		classes[c] = true
+		queue = append(queue, dg.subClasses[c]...)
+	}
+
+	return classes
+}
+
+// isInstanceOf returns true when the term is a SHACL instance of the class.
+func (dg *dataGraph) isInstanceOf(term rdf.Term, class string) bool {
+	classes := dg.classes(class)
+
+	for _, t := range dg.bySubject[term.String()] {
+		if t.Predicate.RawValue() == rdf.RDFType && classes[t.Object.RawValue()] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// focusNodes returns the target nodes of the shape in the data graph.
+func (dg *dataGraph) focusNodes(shape *Shape) []rdf.Term {
+	nodes := []rdf.Term{}
+	seen := map[string]bool{}
+
+	add := func(term rdf.Term) {
+		if !seen[term.String()] {
+			seen[term.String()] = true
+			nodes = append(nodes, term)
+		}
+	}
+
+	for _, node := range shape.targetNodes {
+		add(node)
+	}
+
+	for _, class := range shape.targetClasses {
+		classes := dg.classes(class)
+
+		for _, t := range dg.byPredicate[rdf.RDFType] {
+			if classes[t.Object.RawValue()] {
+				add(t.Subject)
+			}
+		}
+	}
+
+	for _, p := range shape.targetSubjectsOf {
+		for _, t := range dg.byPredicate[p] {
+			add(t.Subject)
+		}
+	}
+
+	for _, p := range shape.targetObjectsOf {
+		for _, t := range dg.byPredicate[p] {
+			add(t.Object)
+		}
+	}
+
+	return nodes
+}
+
+// Validate validates the data graph against all the shapes of the Validator.
+func (v *Validator) Validate(g *rdf.Graph) *Report {
+	dg := newDataGraph(g)
+	report := &Report{Conforms: true, Results: []Result{}}
+
+	for _, shape := range v.shapes {
+		if shape.Deactivated {
+			continue
+		}
+
+		for _, focus := range dg.focusNodes(shape) {
+			v.validateNode(dg, report, shape, focus)
+		}
+	}
+
+	return report
+}
+
+// validateNode validates the focus node against a node shape and its property shapes.
+func (v *Validator) validateNode(dg *dataGraph, report *Report, shape *Shape, focus rdf.Term) {
+	// the value node of a node shape is the focus node itself
+	validateValues(dg, report, shape, focus, []rdf.Object{focus.(rdf.Object)})
+
+	v.validateProperties(dg, report, shape.properties, focus)
+}
+
+// validateProperties validates the focus node against the property shapes. The
+// value nodes of a property shape are the focus nodes of its nested property shapes.
+func (v *Validator) validateProperties(dg *dataGraph, report *Report, properties []*Shape, focus rdf.Term) {
+	for _, property := range properties {
+		if property.Deactivated {
+			continue
+		}
+
+		values := dg.values(focus, property.Path)
+		validateValues(dg, report, property, focus, values)
+
+		for _, value := range values {
+			v.validateProperties(dg, report, property.properties, value)
+		}
+	}
+}
+
+// validateValues checks all the constraints of the shape against the value nodes.
+func validateValues(dg *dataGraph, report *Report, shape *Shape, focus rdf.Term, values []rdf.Object) {
+	add := func(component string, value rdf.Term, msg string) {
+		result := Result{
+			FocusNode:  focus.String(),
+			Path:       shape.Path,
+			Shape:      shape.ID,
+			Constraint: shNS + component,
+			Severity:   shape.Severity,
+			Message:    msg,
+		}
+
+		if value != nil {
+			result.Value = value.String()
+		}
+
+		if shape.Message != "" {
+			result.Message = shape.Message
+		}
+
+		report.add(result)
+	}
+
+	if shape.minCount != nil && len(values) < *shape.minCount {
+		add("MinCountConstraintComponent", nil,
+			fmt.Sprintf("expected at least %d values for %s, got %d", *shape.minCount, shape.Path, len(values)))
+	}
+
+	if shape.maxCount != nil && len(values) > *shape.maxCount {
+		add("MaxCountConstraintComponent", nil,
+			fmt.Sprintf("expected at most %d values for %s, got %d", *shape.maxCount, shape.Path, len(values)))
+	}
+
+	for _, value := range values {
+		if shape.datatype != "" && !hasDatatype(value, shape.datatype) {
+			add("DatatypeConstraintComponent", value,
+				fmt.Sprintf("value does not have datatype %s", shape.datatype))
+		}
+
+		for _, class := range shape.classes {
+			if !dg.isInstanceOf(value, class) {
+				add("ClassConstraintComponent", value,
+					fmt.Sprintf("value is not an instance of %s", class))
+			}
+		}
+
+		if shape.nodeKind != "" && !hasNodeKind(value, shape.nodeKind) {
+			add("NodeKindConstraintComponent", value,
+				fmt.Sprintf("value does not have node kind sh:%s", shape.nodeKind))
+		}
+
+		if shape.pattern != nil {
+			if value.Type() == rdf.TermBlankNode || !shape.pattern.MatchString(value.RawValue()) {
+				add("PatternConstraintComponent", value,
+					fmt.Sprintf("value does not match pattern %q", shape.pattern.String()))
+			}
+		}
+	}
+}
+
+func hasDatatype(value rdf.Term, datatype string) bool {
+	l, ok := value.(rdf.Literal)
+	if !ok {
+		return false
+	}
+
+	switch {
+	case l.Lang() != "":
+		return datatype == rdfLangString
+	case l.DataType.Equal(rdf.IRI{}):
+		return datatype == xsdString
+	}
+
+	return l.DataType.RawValue() == datatype
+}
+
+func hasNodeKind(value rdf.Term, kind string) bool {
+	switch value.Type() {
+	case rdf.TermIRI:
+		return kind == "IRI" || kind == "BlankNodeOrIRI" || kind == "IRIOrLiteral"
+	case rdf.TermBlankNode:
+		return kind == "BlankNode" || kind == "BlankNodeOrIRI" || kind == "BlankNodeOrLiteral"
+	case rdf.TermLiteral:
+		return kind == "Literal" || kind == "BlankNodeOrLiteral" || kind == "IRIOrLiteral"
+	}
+
+	return false
+}
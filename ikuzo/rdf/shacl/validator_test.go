@@ -0,0 +1,201 @@
+package shacl
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+
+	"github.com/delving/hub3/ikuzo/rdf"
+	"github.com/delving/hub3/ikuzo/rdf/formats/turtle"
+)
+
+func parseFile(t *testing.T, name string) *rdf.Graph {
+	t.Helper()
+
+	f, err := os.Open("./testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	g, err := turtle.Parse(f, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return g
+}
+
+func parseString(t *testing.T, s string) *rdf.Graph {
+	t.Helper()
+
+	g, err := turtle.Parse(strings.NewReader(s), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return g
+}
+
+func TestNewValidator(t *testing.T) {
+	is := is.New(t)
+
+	v, err := NewValidator(parseFile(t, "shapes.ttl"))
+	is.NoErr(err)
+	is.Equal(len(v.Shapes()), 1)
+
+	shape := v.Shapes()[0]
+	is.Equal(shape.ID, "http://example.org/ProvidedCHOShape")
+	is.True(!shape.IsPropertyShape())
+	is.Equal(len(shape.properties), 4)
+}
+
+func TestNewValidatorInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		shapes string
+	}{
+		{
+			"property without path",
+			`<urn:shape> <http://www.w3.org/ns/shacl#targetClass> <urn:class> ;
+				<http://www.w3.org/ns/shacl#property> [ <http://www.w3.org/ns/shacl#minCount> 1 ] .`,
+		},
+		{
+			"invalid pattern",
+			`<urn:shape> <http://www.w3.org/ns/shacl#targetNode> <urn:node> ;
+				<http://www.w3.org/ns/shacl#pattern> "[a-" .`,
+		},
+		{
+			"recursive property shape",
+			`<urn:shape> <http://www.w3.org/ns/shacl#targetClass> <urn:class> ;
+				<http://www.w3.org/ns/shacl#property> <urn:property> .
+			<urn:property> <http://www.w3.org/ns/shacl#path> <urn:p> ;
+				<http://www.w3.org/ns/shacl#property> <urn:property> .`,
+		},
+		{
+			"invalid minCount",
+			`<urn:shape> <http://www.w3.org/ns/shacl#targetNode> <urn:node> ;
+				<http://www.w3.org/ns/shacl#minCount> "many" .`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+
+			_, err := NewValidator(parseString(t, tt.shapes))
+			is.True(err != nil)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	is := is.New(t)
+
+	v, err := NewValidator(parseFile(t, "shapes.ttl"))
+	is.NoErr(err)
+
+	report := v.Validate(parseFile(t, "data.ttl"))
+	is.True(!report.Conforms)
+
+	type result struct {
+		focus      string
+		constraint string
+		severity   Severity
+	}
+
+	got := []result{}
+
+	for _, r := range report.Results {
+		is.Equal(r.FocusNode, "<http://example.org/invalid>")
+
+		got = append(got, result{
+			focus:      r.FocusNode,
+			constraint: strings.TrimPrefix(r.Constraint, shNS),
+			severity:   r.Severity,
+		})
+	}
+
+	want := []result{
+		{"<http://example.org/invalid>", "MaxCountConstraintComponent", Violation},
+		{"<http://example.org/invalid>", "DatatypeConstraintComponent", Violation},
+		{"<http://example.org/invalid>", "PatternConstraintComponent", Violation},
+		{"<http://example.org/invalid>", "ClassConstraintComponent", Violation},
+		{"<http://example.org/invalid>", "DatatypeConstraintComponent", Warning},
+	}
+
+	is.Equal(got, want)
+	is.Equal(report.Results[4].Message, "date should be a xsd:dateTime")
+}
+
+func TestValidateTargets(t *testing.T) {
+	data := `
+		<urn:a> <urn:p> <urn:b> .
+		<urn:b> <urn:q> "b" .
+		_:c <urn:p> "c" .
+	`
+
+	tests := []struct {
+		name    string
+		shapes  string
+		results int
+	}{
+		{
+			"targetNode minCount",
+			`<urn:shape> <http://www.w3.org/ns/shacl#targetNode> <urn:a>, <urn:b> ;
+				<http://www.w3.org/ns/shacl#property> [
+					<http://www.w3.org/ns/shacl#path> <urn:p> ;
+					<http://www.w3.org/ns/shacl#minCount> 1 
+				] .`,
+			1,
+		},
+		{
+			"targetSubjectsOf nodeKind",
+			`<urn:shape> <http://www.w3.org/ns/shacl#targetSubjectsOf> <urn:p> ;
+				<http://www.w3.org/ns/shacl#nodeKind> <http://www.w3.org/ns/shacl#IRI> .`,
+			1,
+		},
+		{
+			"targetObjectsOf nodeKind",
+			`<urn:shape> <http://www.w3.org/ns/shacl#targetObjectsOf> <urn:p> ;
+				<http://www.w3.org/ns/shacl#nodeKind> <http://www.w3.org/ns/shacl#BlankNodeOrIRI> .`,
+			1,
+		},
+		{
+			"nested property shapes",
+			`<urn:shape> <http://www.w3.org/ns/shacl#targetNode> <urn:a> ;
+				<http://www.w3.org/ns/shacl#property> <urn:p-shape> .
+			<urn:p-shape> <http://www.w3.org/ns/shacl#path> <urn:p> ;
+				<http://www.w3.org/ns/shacl#property> <urn:q-shape> .
+			<urn:q-shape> <http://www.w3.org/ns/shacl#path> <urn:q> ;
+				<http://www.w3.org/ns/shacl#minCount> 2 .`,
+			1,
+		},
+		{
+			"deactivated",
+			`<urn:shape> <http://www.w3.org/ns/shacl#targetObjectsOf> <urn:p> ;
+				<http://www.w3.org/ns/shacl#deactivated> true ;
+				<http://www.w3.org/ns/shacl#nodeKind> <http://www.w3.org/ns/shacl#BlankNodeOrIRI> .`,
+			0,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+
+			v, err := NewValidator(parseString(t, tt.shapes))
+			is.NoErr(err)
+
+			report := v.Validate(parseString(t, data))
+			is.Equal(len(report.Results), tt.results)
+			is.Equal(report.Conforms, tt.results == 0)
+		})
+	}
+}
@@ -41,7 +41,7 @@ type FacetField struct {
 	orderByKey      bool
 }
 
-// newFacetField parses a string and returns a *FacetField.
+// NewFacetField parses a string and returns a *FacetField.
 //
 // The input field is a shorthand representation of the FacetField options,
 // designed to be used in configuration or URL Query parameters.
@@ -97,7 +97,7 @@ type FacetField struct {
 // aggregation the RDF resource URI instead of the literal value.
 //
 // Empty values are not allowed and will return an error.
-func newFacetField(field string) (*FacetField, error) {
+func NewFacetField(field string) (*FacetField, error) {
 	if field == "" {
 		return nil, fmt.Errorf("empty input is not allowed: %s", field)
 	}
@@ -183,3 +183,31 @@ func newFacetField(field string) (*FacetField, error) {
 
 	return &ff, nil
 }
+
+// IsMetaField returns true when the facet aggregates a field of the record
+// header, e.g. 'meta.spec' or 'meta.tags'.
+func (ff *FacetField) IsMetaField() bool {
+	return strings.HasPrefix(ff.path, "meta.")
+}
+
+// IsLiteralField returns true when the facet aggregates the literal values of
+// the resource entries with the SearchLabel in Field.
+func (ff *FacetField) IsLiteralField() bool {
+	return ff.nestedField == literalField && ff.aggregationType == ""
+}
+
+// Path returns the field path of the facet in the search record.
+func (ff *FacetField) Path() string {
+	return ff.path
+}
+
+// SortAsc returns true when the facet values are returned in ascending order.
+func (ff *FacetField) SortAsc() bool {
+	return ff.sortAsc
+}
+
+// OrderByKey returns true when the facet values are sorted by their value
+// instead of their count.
+func (ff *FacetField) OrderByKey() bool {
+	return ff.orderByKey
+}
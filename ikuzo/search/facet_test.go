@@ -21,7 +21,7 @@ import (
 )
 
 // nolint:funlen // table driven test
-func Test_newFacetField(t *testing.T) {
+func TestNewFacetField(t *testing.T) {
 	type args struct {
 		field string
 	}
@@ -207,14 +207,14 @@ func Test_newFacetField(t *testing.T) {
 		tt := tt
 
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := newFacetField(tt.args.field)
+			got, err := NewFacetField(tt.args.field)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("newFacetField() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("NewFacetField() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			opt := cmp.AllowUnexported(FacetField{})
 			if diff := cmp.Diff(tt.want, got, opt); diff != "" {
-				t.Errorf("newFacetField() %s mismatch (-want +got):\n%s", tt.name, diff)
+				t.Errorf("NewFacetField() %s mismatch (-want +got):\n%s", tt.name, diff)
 			}
 		})
 	}
@@ -14,6 +14,14 @@
 
 package search
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidFilter = errors.New("invalid filter")
+
 // Filter is used to limit the results of a SearchRequest.
 //
 // It supports both first level objects such as 'Meta' and 'Tree' and nested
@@ -24,11 +32,40 @@ type Filter struct {
 	// SearchLabel is a short namespaced version of a URI.
 	Field string `json:"searchLabel,omitempty"`
 	Value string `json:"value,omitempty"`
-	// Exclude     bool          `json:"exclude,omitempty"`
+	// Exclude removes the records that match the filter from the results.
+	Exclude bool `json:"exclude,omitempty"`
+	// NestedFilter is used to filter in the nested RDF structure of the RecordGraph.
 	// Nested      *NestedFilter `json:"nested,omitempty"`
 }
 
-// NestedFilter is used to filter in the nested RDF structure of the RecordGraph.
+// ParseFilter parses a filter from the 'qf' query parameter. The shorthand is
+// 'field:value'. The field is either a header field, like 'meta.spec', or a
+// SearchLabel. A '-' prefix excludes the matching records, e.g. '-meta.spec:spec'.
+func ParseFilter(filter string) (*Filter, error) {
+	f := &Filter{}
+
+	if strings.HasPrefix(filter, "-") {
+		f.Exclude = true
+		filter = strings.TrimPrefix(filter, "-")
+	}
+
+	parts := strings.SplitN(filter, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("%w: %q must be formatted as 'field:value'", ErrInvalidFilter, filter)
+	}
+
+	f.Field = parts[0]
+	f.Value = parts[1]
+
+	return f, nil
+}
+
+// IsMetaField returns true when the filter applies to a field of the record
+// header, e.g. 'meta.spec' or 'meta.tags'.
+func (f *Filter) IsMetaField() bool {
+	return strings.HasPrefix(f.Field, "meta.")
+}
+
 type NestedFilter struct {
 	// SearchLabel string              `json:"searchLabel,omitempty"`
 	// Value       string              `json:"value,omitempty"`
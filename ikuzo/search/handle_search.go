@@ -0,0 +1,55 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/delving/hub3/ikuzo/domain"
+	"github.com/delving/hub3/ikuzo/render"
+)
+
+func (s *Service) handleSearch(w http.ResponseWriter, r *http.Request) {
+	org, ok := domain.GetOrganization(r)
+	if !ok {
+		http.Error(w, domain.ErrOrgNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	req, err := NewRequest(org.ID.String(), r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp, err := s.Search(r.Context(), &org.Config, req)
+	if err != nil {
+		if errors.Is(err, ErrInvalidPage) || errors.Is(err, ErrUnsupportedFacet) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		render.Error(w, r, err, &render.ErrorConfig{
+			StatusCode: http.StatusInternalServerError,
+			Message:    "unable to execute search request",
+			Log:        &s.log,
+		})
+
+		return
+	}
+
+	render.JSON(w, r, resp)
+}
@@ -0,0 +1,123 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/delving/hub3/ikuzo/domain"
+	"github.com/matryer/is"
+)
+
+// mockSearcher returns total hits and records the last request.
+type mockSearcher struct {
+	total int
+	req   *Request
+}
+
+func (m *mockSearcher) Search(ctx context.Context, req *Request) (*Response, error) {
+	m.req = req
+
+	for _, ff := range req.Facets {
+		if !ff.IsMetaField() && !ff.IsLiteralField() {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedFacet, ff.Field)
+		}
+	}
+
+	resp := &Response{Total: m.total, Hits: []*Hit{}}
+
+	for i := req.Start(); i < m.total && i < req.Start()+req.Rows; i++ {
+		resp.Hits = append(resp.Hits, &Hit{ID: fmt.Sprintf("%s_%d", req.OrgID, i), OrgID: req.OrgID})
+	}
+
+	return resp, nil
+}
+
+func TestService_handleSearch(t *testing.T) {
+	searcher := &mockSearcher{total: 25}
+
+	svc, err := NewService(SetSearcher(searcher), ResponseSize(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	orgID, err := domain.NewOrganizationID("demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	org := domain.Organization{ID: orgID}
+	org.Config.ElasticSearch.IndexName = "Demo"
+
+	tests := []struct {
+		name      string
+		query     string
+		status    int
+		wantHits  int
+		wantPage  int
+		wantIndex string
+	}{
+		{"default rows", "q=amsterdam", http.StatusOK, 10, 1, "demov2"},
+		{"last page", "q=amsterdam&page=3", http.StatusOK, 5, 3, "demov2"},
+		{"rows", "rows=25&facet.field=meta.spec", http.StatusOK, 25, 1, "demov2"},
+		{"page out of range", "page=4", http.StatusBadRequest, 0, 0, ""},
+		{"invalid filter", "qf=amsterdam", http.StatusBadRequest, 0, 0, ""},
+		{"unsupported facet", "facet.field=datehistogram.dc_date", http.StatusBadRequest, 0, 0, ""},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+
+			r := httptest.NewRequest(http.MethodGet, "/api/v3/search?"+tt.query, nil)
+			r = domain.SetOrganization(r, &org)
+
+			w := httptest.NewRecorder()
+			svc.ServeHTTP(w, r)
+
+			is.Equal(w.Code, tt.status)
+
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			is.Equal(searcher.req.IndexName, tt.wantIndex)
+
+			var got Response
+			is.NoErr(json.NewDecoder(w.Body).Decode(&got))
+			is.Equal(got.Total, 25)
+			is.Equal(len(got.Hits), tt.wantHits)
+			is.Equal(got.Pager.CurrentPage, tt.wantPage)
+			is.Equal(got.Pager.NumFound, 25)
+		})
+	}
+}
+
+func TestService_SearchWithoutSearcher(t *testing.T) {
+	is := is.New(t)
+
+	svc, err := NewService()
+	is.NoErr(err)
+
+	_, err = svc.Search(context.TODO(), nil, &Request{OrgID: "demo"})
+	is.Equal(err, ErrSearcherNotSet)
+}
@@ -0,0 +1,104 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Hit is a single record in the Response.
+type Hit struct {
+	ID        string   `json:"id"`
+	OrgID     string   `json:"orgID"`
+	DatasetID string   `json:"datasetID"`
+	Tags      []string `json:"tags,omitempty"`
+	Score     float64  `json:"score,omitempty"`
+	// Fields contains the values per SearchLabel. Resource references without
+	// a label are added with their URI.
+	Fields map[string][]string `json:"fields"`
+}
+
+// record is the part of the v2 search record that is decoded into a Hit.
+type record struct {
+	Meta struct {
+		HubID string   `json:"hubID"`
+		OrgID string   `json:"orgID"`
+		Spec  string   `json:"spec"`
+		Tags  []string `json:"tags"`
+	} `json:"meta"`
+	Resources []struct {
+		Entries []struct {
+			ID          string `json:"@id"`
+			Value       string `json:"@value"`
+			SearchLabel string `json:"searchLabel"`
+		} `json:"entries"`
+	} `json:"resources"`
+}
+
+// DecodeHit decodes a v2 search record, as it is stored by the index service,
+// into a Hit.
+func DecodeHit(source []byte) (*Hit, error) {
+	var rec record
+	if err := json.Unmarshal(source, &rec); err != nil {
+		return nil, fmt.Errorf("unable to decode search record; %w", err)
+	}
+
+	hit := &Hit{
+		ID:        rec.Meta.HubID,
+		OrgID:     rec.Meta.OrgID,
+		DatasetID: rec.Meta.Spec,
+		Tags:      rec.Meta.Tags,
+		Fields:    map[string][]string{},
+	}
+
+	for _, rsc := range rec.Resources {
+		for _, entry := range rsc.Entries {
+			if entry.SearchLabel == "" {
+				continue
+			}
+
+			value := entry.Value
+			if value == "" {
+				value = entry.ID
+			}
+
+			if value == "" {
+				continue
+			}
+
+			hit.Fields[entry.SearchLabel] = append(hit.Fields[entry.SearchLabel], value)
+		}
+	}
+
+	return hit, nil
+}
+
+// Values returns the values of the filter or facet field. Header fields, like
+// 'meta.spec' and 'meta.tags', are returned from the header of the Hit.
+func (h *Hit) Values(field string) []string {
+	switch field {
+	case "meta.spec":
+		return []string{h.DatasetID}
+	case "meta.orgID":
+		return []string{h.OrgID}
+	case "meta.hubID":
+		return []string{h.ID}
+	case "meta.tags":
+		return h.Tags
+	}
+
+	return h.Fields[field]
+}
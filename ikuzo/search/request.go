@@ -14,4 +14,106 @@
 
 package search
 
-type Request struct{}
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/delving/hub3/ikuzo/domain"
+	xsearch "github.com/delving/hub3/ikuzo/service/x/search"
+)
+
+// Request is a backend-agnostic search request. It is executed by a Searcher.
+type Request struct {
+	OrgID string `json:"orgID"`
+	// IndexName is the index of the organization. It is set by the Service.
+	IndexName string `json:"-"`
+	// Query is the query string, see the QueryParser in service/x/search.
+	Query   string        `json:"query,omitempty"`
+	Filters []*Filter     `json:"filters,omitempty"`
+	Facets  []*FacetField `json:"-"`
+	// Page is 1-based.
+	Page int `json:"page"`
+	Rows int `json:"rows"`
+	// QueryOptions configure the QueryParser of the Searcher for the
+	// organization. They are set by the Service.
+	QueryOptions []xsearch.QueryOption `json:"-"`
+}
+
+// NewRequest creates a Request from the URL query parameters:
+//
+// q is the query string.
+//
+// qf or qf[] is a filter, see ParseFilter. It can be repeated.
+//
+// facet.field is a facet, see NewFacetField. It can be repeated.
+//
+// page is the 1-based page of the results.
+//
+// rows is the number of hits per page. When it is absent the response size of
+// the Service is used.
+func NewRequest(orgID string, params url.Values) (*Request, error) {
+	req := &Request{
+		OrgID: orgID,
+		Query: params.Get("q"),
+		Page:  1,
+	}
+
+	for _, key := range []string{"qf", "qf[]"} {
+		for _, qf := range params[key] {
+			f, err := ParseFilter(qf)
+			if err != nil {
+				return nil, err
+			}
+
+			req.Filters = append(req.Filters, f)
+		}
+	}
+
+	for _, field := range params["facet.field"] {
+		ff, err := NewFacetField(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid facet.field %q; %w", domain.LogUserInput(field), err)
+		}
+
+		req.Facets = append(req.Facets, ff)
+	}
+
+	var err error
+
+	if page := params.Get("page"); page != "" {
+		req.Page, err = strconv.Atoi(page)
+		if err != nil || req.Page < 1 {
+			return nil, fmt.Errorf("%w: page must be a positive number: %q", ErrInvalidPage, domain.LogUserInput(page))
+		}
+	}
+
+	if rows := params.Get("rows"); rows != "" {
+		req.Rows, err = strconv.Atoi(rows)
+		if err != nil || req.Rows < 1 {
+			return nil, fmt.Errorf("rows must be a positive number: %q", domain.LogUserInput(rows))
+		}
+	}
+
+	return req, nil
+}
+
+// Start returns the zero-based offset of the first hit of the requested page.
+func (req *Request) Start() int {
+	if req.Page < 1 {
+		return 0
+	}
+
+	return (req.Page - 1) * req.Rows
+}
+
+// IsSelected returns true when the request has a filter for the value of the field.
+func (req *Request) IsSelected(field, value string) bool {
+	for _, f := range req.Filters {
+		if !f.Exclude && f.Field == field && f.Value == value {
+			return true
+		}
+	}
+
+	return false
+}
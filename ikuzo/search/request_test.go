@@ -0,0 +1,97 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/matryer/is"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  string
+		want    *Filter
+		wantErr bool
+	}{
+		{"meta field", "meta.spec:spec", &Filter{Field: "meta.spec", Value: "spec"}, false},
+		{"exclude", "-dc_subject:boat", &Filter{Field: "dc_subject", Value: "boat", Exclude: true}, false},
+		{"value with colon", "dc_spatial:http://example.org/1", &Filter{Field: "dc_spatial", Value: "http://example.org/1"}, false},
+		{"no value", "dc_subject:", nil, true},
+		{"no field", ":boat", nil, true},
+		{"no separator", "boat", nil, true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFilter(tt.filter)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseFilter() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err != nil && !errors.Is(err, ErrInvalidFilter) {
+				t.Errorf("ParseFilter() error = %v, want ErrInvalidFilter", err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("ParseFilter() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNewRequest(t *testing.T) {
+	is := is.New(t)
+
+	params := url.Values{
+		"q":           []string{"amsterdam"},
+		"qf":          []string{"meta.spec:spec"},
+		"qf[]":        []string{"-dc_subject:boat"},
+		"facet.field": []string{"meta.spec", "dc_creator~5"},
+		"page":        []string{"3"},
+		"rows":        []string{"20"},
+	}
+
+	req, err := NewRequest("demo", params)
+	is.NoErr(err)
+	is.Equal(req.OrgID, "demo")
+	is.Equal(req.Query, "amsterdam")
+	is.Equal(req.Filters, []*Filter{
+		{Field: "meta.spec", Value: "spec"},
+		{Field: "dc_subject", Value: "boat", Exclude: true},
+	})
+	is.Equal(len(req.Facets), 2)
+	is.Equal(req.Facets[1].Size, 5)
+	is.Equal(req.Start(), 40)
+	is.True(req.IsSelected("meta.spec", "spec"))
+	is.True(!req.IsSelected("dc_subject", "boat"))
+
+	for _, invalid := range []url.Values{
+		{"page": []string{"0"}},
+		{"rows": []string{"many"}},
+		{"qf": []string{"spec"}},
+		{"facet.field": []string{"~"}},
+	} {
+		_, err = NewRequest("demo", invalid)
+		is.True(err != nil)
+	}
+}
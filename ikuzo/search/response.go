@@ -38,15 +38,11 @@ type FacetLink struct {
 	Count         int64  `json:"count"`
 }
 
+// Response is the backend-agnostic result of a search Request.
 type Response struct {
-	// Pager      *ScrollPager       `json:"pager"`
-	// Query      *Query             `json:"query"`
-	// Items      []*FragmentGraph   `json:"items,omitempty"`
-	// Collapsed  []*Collapsed       `json:"collapse,omitempty"`
-	// Peek       map[string]int64   `json:"peek,omitempty"`
-	// Facets     []*QueryFacet      `json:"facets,omitempty"`
-	// TreeHeader *TreeHeader        `json:"treeHeader,omitempty"`
-	// Tree       []*Tree            `json:"tree,omitempty"`
-	// TreePage   map[string][]*Tree `json:"treePage,omitempty"`
-	// ProtoBuf   *ProtoBuf          `json:"protobuf,omitempty"`
+	// Total is the number of records that match the request.
+	Total  int        `json:"total"`
+	Pager  *Paginator `json:"pager,omitempty"`
+	Hits   []*Hit     `json:"items"`
+	Facets []*Facet   `json:"facets,omitempty"`
 }
@@ -13,3 +13,45 @@
 // limitations under the License.
 
 package search
+
+import (
+	"context"
+	"errors"
+	"sort"
+)
+
+var (
+	ErrSearcherNotSet   = errors.New("no searcher is configured")
+	ErrUnsupportedFacet = errors.New("facet type is not supported by the searcher")
+)
+
+// Searcher executes a search Request against a storage backend.
+//
+// Implementations only support header facets, like 'meta.spec', and the
+// literal term facets of a SearchLabel. Other facets return ErrUnsupportedFacet.
+// The Pager of the Response is set by the Service.
+type Searcher interface {
+	Search(ctx context.Context, req *Request) (*Response, error)
+}
+
+// SortFacetLinks sorts the links in the order requested by the FacetField.
+// Links are sorted by descending count by default. Links with the same count
+// are sorted by value.
+func SortFacetLinks(ff *FacetField, links []*FacetLink) {
+	sort.SliceStable(links, func(i, j int) bool {
+		a, b := links[i], links[j]
+		if ff.SortAsc() {
+			a, b = b, a
+		}
+
+		if ff.OrderByKey() {
+			return a.Value > b.Value
+		}
+
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+
+		return links[i].Value < links[j].Value
+	})
+}
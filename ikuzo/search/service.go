@@ -14,12 +14,27 @@
 
 package search
 
+import (
+	"context"
+	"net/http"
+
+	"github.com/delving/hub3/ikuzo/domain"
+	xsearch "github.com/delving/hub3/ikuzo/service/x/search"
+	"github.com/go-chi/chi"
+	"github.com/rs/zerolog"
+)
+
+var _ domain.Service = (*Service)(nil)
+
 // Service is the central search service that should be initialized once and
 // shared between requests. It is safe for concurrent use by multiple goroutines.
 type Service struct {
 	responseSize    int
 	maxResponseSize int
 	facetSize       int
+	searcher        Searcher
+	queryOptions    QueryOptionsFunc
+	log             zerolog.Logger
 }
 
 // OptionFunc is a function that configures a Service.
@@ -70,3 +85,102 @@ func ResponseSize(size int) OptionFunc {
 		return nil
 	}
 }
+
+// SetSearcher sets the backend that executes the search requests.
+func SetSearcher(searcher Searcher) OptionFunc {
+	return func(s *Service) error {
+		s.searcher = searcher
+		return nil
+	}
+}
+
+// QueryOptionsFunc returns the options of the QueryParser for the organization,
+// e.g. the Expander of its vocabulary.
+type QueryOptionsFunc func(cfg *domain.OrganizationConfig) ([]xsearch.QueryOption, error)
+
+// SetQueryOptions sets the function that returns the options of the
+// QueryParser per organization. The options are passed to the Searcher with
+// the Request.
+func SetQueryOptions(fn QueryOptionsFunc) OptionFunc {
+	return func(s *Service) error {
+		s.queryOptions = fn
+		return nil
+	}
+}
+
+// Search executes the Request with the Searcher of the Service. The default
+// response and facet size are applied and the Pager is added to the Response.
+// The IndexName and QueryOptions of the Request are set from the configuration
+// of the organization.
+func (s *Service) Search(ctx context.Context, cfg *domain.OrganizationConfig, req *Request) (*Response, error) {
+	if s.searcher == nil {
+		return nil, ErrSearcherNotSet
+	}
+
+	if req.Page < 1 {
+		req.Page = 1
+	}
+
+	if req.Rows == 0 {
+		req.Rows = s.responseSize
+	}
+
+	if req.Rows > s.maxResponseSize {
+		req.Rows = s.maxResponseSize
+	}
+
+	for _, ff := range req.Facets {
+		if ff.Size == 0 {
+			ff.Size = s.facetSize
+		}
+	}
+
+	if cfg != nil {
+		req.IndexName = cfg.GetIndexName()
+
+		if s.queryOptions != nil {
+			options, err := s.queryOptions(cfg)
+			if err != nil {
+				return nil, err
+			}
+
+			req.QueryOptions = options
+		}
+	}
+
+	resp, err := s.searcher.Search(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	pager, err := NewPaginator(resp.Total, req.Rows, req.Page, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := pager.AddPageLinks(); err != nil {
+		return nil, err
+	}
+
+	resp.Pager = pager
+
+	return resp, nil
+}
+
+func (s *Service) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	router := chi.NewRouter()
+	s.Routes("", router)
+	router.ServeHTTP(w, r)
+}
+
+func (s *Service) Routes(pattern string, router chi.Router) {
+	router.Get("/api/v3/search", s.handleSearch)
+}
+
+func (s *Service) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+func (s *Service) SetServiceBuilder(b *domain.ServiceBuilder) {
+	s.log = b.Logger.With().Str("svc", "search").Logger()
+}
@@ -19,6 +19,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 )
 
 func TestNewService(t *testing.T) {
@@ -85,7 +86,8 @@ func TestNewService(t *testing.T) {
 				return
 			}
 			opt := cmp.AllowUnexported(Service{})
-			if diff := cmp.Diff(tt.want, got, opt); diff != "" {
+			ignoreLog := cmpopts.IgnoreFields(Service{}, "log")
+			if diff := cmp.Diff(tt.want, got, opt, ignoreLog); diff != "" {
 				t.Errorf("NewService() mismatch (-want +got):\n%s", diff)
 			}
 		})
@@ -55,6 +55,7 @@ func (s *Service) NewParser() *Parser {
 		indexTypes:    s.indexTypes,
 		bi:            s.index,
 		sparqlUpdates: []fragments.SparqlUpdate{},
+		validations:   s.validations,
 	}
 
 	if len(s.postHooks) != 0 {
@@ -2,6 +2,7 @@ package bulk
 
 import (
 	"github.com/delving/hub3/ikuzo/domain"
+	"github.com/delving/hub3/ikuzo/rdf/shacl"
 	"github.com/delving/hub3/ikuzo/service/x/index"
 )
 
@@ -30,3 +31,18 @@ func SetPostHookService(hooks ...domain.PostHookService) Option {
 		return nil
 	}
 }
+
+// SetValidation validates the records of the organization with the SHACL validator.
+// When datasetID is not empty, the validation only applies to that dataset and
+// overrides the validation of the organization.
+func SetValidation(orgID, datasetID string, v *shacl.Validator, mode ValidationMode) Option {
+	return func(s *Service) error {
+		if _, err := ParseValidationMode(string(mode)); err != nil {
+			return err
+		}
+
+		s.validations[validationKey(orgID, datasetID)] = &validation{validator: v, mode: mode}
+
+		return nil
+	}
+}
@@ -49,6 +49,7 @@ type Parser struct {
 	// TODO(kiivihal): find better solution for this
 	sparqlUpdates []fragments.SparqlUpdate // store all the triples here for bulk insert
 	postHooks     []*domain.PostHookItem
+	validations   map[string]*validation
 	m             sync.RWMutex
 }
 
@@ -103,6 +104,10 @@ func (p *Parser) Parse(ctx context.Context, r io.Reader) error {
 				a := a
 
 				if err := p.process(ctx, &a); err != nil {
+					if errors.Is(err, ErrRecordRejected) {
+						continue
+					}
+
 					log.Error().Err(err).Msg("unable to process action")
 					return err
 				}
@@ -234,6 +239,11 @@ func (p *Parser) process(ctx context.Context, req *Request) error {
 	switch req.Action {
 	case "index":
 		if err := p.Publish(ctx, req); err != nil {
+			if errors.Is(err, ErrRecordRejected) {
+				subLogger.Warn().Str("hubID", req.HubID).Msg("record rejected by SHACL validation")
+				return err
+			}
+
 			subLogger.Error().Err(err).Msg("unable to publish bulk index request")
 
 			return err
@@ -314,6 +324,10 @@ func (p *Parser) Publish(ctx context.Context, req *Request) error {
 		return err
 	}
 
+	if err := p.validate(req, fb.Graph); err != nil {
+		return err
+	}
+
 	_, err = fb.ResourceMap()
 	if err != nil {
 		log.Error().Err(err).Str("datasetID", req.DatasetID).Msg("unable to build resource map")
@@ -385,6 +399,31 @@ func (p *Parser) Publish(ctx context.Context, req *Request) error {
 	return nil
 }
 
+// validate applies the SHACL validation of the dataset to the graph and adds
+// the report to the Stats. ErrRecordRejected is returned when the record must
+// not be indexed.
+func (p *Parser) validate(req *Request, g *rdf.Graph) error {
+	v := lookupValidation(p.validations, req.OrgID, req.DatasetID)
+	if v == nil {
+		return nil
+	}
+
+	report := v.validateRDF2Go(req.HubID, g)
+	if report == nil {
+		return nil
+	}
+
+	p.m.Lock()
+	p.stats.addValidationReport(report)
+	p.m.Unlock()
+
+	if report.Rejected {
+		return ErrRecordRejected
+	}
+
+	return nil
+}
+
 // AppendRDFBulkRequest gathers all the triples from an BulkAction to be inserted in bulk.
 func (p *Parser) AppendRDFBulkRequest(req *Request, g *rdf.Graph) error {
 	var b bytes.Buffer
@@ -414,6 +453,13 @@ type Stats struct {
 	JSONErrors         uint64 `json:"jsonErrors"`
 	TriplesStored      uint64 `json:"triplesStored"`
 	PostHooksSubmitted uint64 `json:"postHooksSubmitted"`
+	RecordsRejected    uint64 `json:"recordsRejected"`    // records not stored because of SHACL violations
+	ValidationWarnings uint64 `json:"validationWarnings"` // stored records with SHACL validation results
+	// ValidationReports contains the SHACL validation report of each record with validation results
+	ValidationReports []*RecordReport `json:"validationReports,omitempty"`
+	// ValidationReportsOmitted is the number of reports that are not added to
+	// ValidationReports, because it is limited to maxValidationReports
+	ValidationReportsOmitted uint64 `json:"validationReportsOmitted,omitempty"`
 	// ContentHashMatches uint64    `json:"contentHashMatches"` // originally json was content_hash_matches
 }
 
@@ -130,7 +130,7 @@ func (p *Publisher) Do(ctx context.Context) error {
 				return fmt.Errorf("unable to find path: %w", err)
 			}
 
-			if err := p.incrementRevision(orgID.Name(), datasetID.Name()); err != nil {
+			if err := p.IncrementRevision(orgID.Name(), datasetID.Name()); err != nil {
 				return err
 			}
 
@@ -160,7 +160,7 @@ func (p *Publisher) Do(ctx context.Context) error {
 				}
 			}
 
-			if err := p.dropOrphans(orgID.Name(), datasetID.Name()); err != nil {
+			if err := p.DropOrphans(orgID.Name(), datasetID.Name()); err != nil {
 				return err
 			}
 		}
@@ -209,7 +209,8 @@ func (p *Publisher) post(body []byte) (*http.Response, error) {
 	return p.client.Post(p.endpoint(), "text/plain", body)
 }
 
-func (p *Publisher) incrementRevision(orgID, datasetID string) error {
+// IncrementRevision submits the request to start a new revision of the dataset.
+func (p *Publisher) IncrementRevision(orgID, datasetID string) error {
 	req := Request{
 		OrgID:     orgID,
 		DatasetID: datasetID,
@@ -224,7 +225,9 @@ func (p *Publisher) incrementRevision(orgID, datasetID string) error {
 	return p.send()
 }
 
-func (p *Publisher) dropOrphans(orgID, datasetID string) error {
+// DropOrphans submits the pending requests and then the request to drop the
+// records that are not part of the current revision of the dataset.
+func (p *Publisher) DropOrphans(orgID, datasetID string) error {
 	req := Request{
 		OrgID:     orgID,
 		DatasetID: datasetID,
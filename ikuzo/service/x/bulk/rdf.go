@@ -1,14 +1,18 @@
 package bulk
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 
 	"github.com/delving/hub3/hub3/fragments"
 	"github.com/delving/hub3/hub3/models"
 	"github.com/delving/hub3/ikuzo/domain"
 	"github.com/delving/hub3/ikuzo/domain/domainpb"
+	"github.com/delving/hub3/ikuzo/rdf"
+	"github.com/delving/hub3/ikuzo/rdf/formats/turtle"
 	"github.com/go-chi/render"
 	"github.com/gorilla/schema"
 	"github.com/rs/zerolog/log"
@@ -51,6 +55,7 @@ func (s *Service) HandleRDF(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	defer in.Close()
 
 	var form rdfUploadForm
 	err = decoder.Decode(&form, r.PostForm)
@@ -68,6 +73,48 @@ func (s *Service) HandleRDF(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// the multipart file is removed when the request is done, so it is read
+	// before the fragments are indexed in the background.
+	b, err := ioutil.ReadAll(in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stats := &Stats{
+		OrgID:     orgID.String(),
+		DatasetID: form.Spec,
+		Spec:      form.Spec,
+	}
+
+	// the graph is only parsed before the response for validation, and is
+	// then passed to the uploader, so the upload is parsed once.
+	var g *rdf.Graph
+
+	v := lookupValidation(s.validations, orgID.String(), form.Spec)
+	if v != nil {
+		g, err = turtle.Parse(bytes.NewReader(b), nil)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		b = nil
+
+		if report := v.validate(form.Spec, g); report != nil {
+			stats.addValidationReport(report)
+
+			if report.Rejected {
+				render.Status(r, http.StatusUnprocessableEntity)
+				render.JSON(w, r, stats)
+
+				return
+			}
+
+			log.Warn().Str("datasetID", form.Spec).Msgf("SHACL validation results: %s", report.Report)
+		}
+	}
+
 	// todo handle when no form.Spec is given
 	ds, created, err := models.GetOrCreateDataSet(orgID.String(), form.Spec)
 	if err != nil {
@@ -94,6 +141,8 @@ func (s *Service) HandleRDF(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	stats.SpecRevision = uint64(ds.Revision)
+
 	upl := fragments.NewRDFUploader(
 		orgID.String(),
 		form.Spec,
@@ -104,10 +153,16 @@ func (s *Service) HandleRDF(w http.ResponseWriter, r *http.Request) {
 	)
 
 	go func() {
-		defer in.Close()
 		log.Print("Start creating resource map")
 
-		_, err := upl.Parse(in)
+		var err error
+
+		if g != nil {
+			_, err = upl.AddGraph(g)
+		} else {
+			_, err = upl.Parse(bytes.NewReader(b))
+		}
+
 		if err != nil {
 			log.Printf("Can't read turtle file: %v", err)
 			return
@@ -139,6 +194,12 @@ func (s *Service) HandleRDF(w http.ResponseWriter, r *http.Request) {
 	}()
 
 	render.Status(r, http.StatusCreated)
+
+	// with SHACL validation the Stats report the validation results
+	if v != nil {
+		render.JSON(w, r, stats)
+		return
+	}
+
 	render.PlainText(w, r, "ok")
-	return
 }
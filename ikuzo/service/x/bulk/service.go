@@ -32,12 +32,15 @@ type Service struct {
 	postHooks  map[string][]domain.PostHookService
 	log        zerolog.Logger
 	orgs       domain.OrgConfigRetriever
+	// validations are keyed by orgID or orgID/datasetID
+	validations map[string]*validation
 }
 
 func NewService(options ...Option) (*Service, error) {
 	s := &Service{
-		indexTypes: []string{"v2"},
-		postHooks:  map[string][]domain.PostHookService{},
+		indexTypes:  []string{"v2"},
+		postHooks:   map[string][]domain.PostHookService{},
+		validations: map[string]*validation{},
 	}
 
 	// apply options
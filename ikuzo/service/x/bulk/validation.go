@@ -0,0 +1,187 @@
+package bulk
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/kiivihal/rdf2go"
+
+	"github.com/delving/hub3/ikuzo/rdf"
+	"github.com/delving/hub3/ikuzo/rdf/shacl"
+)
+
+// ErrRecordRejected is returned when a record is not indexed because it does
+// not conform to the SHACL shapes of its dataset.
+var ErrRecordRejected = errors.New("record rejected by SHACL validation")
+
+// ValidationMode determines what happens with records that do not conform to
+// the SHACL shapes.
+type ValidationMode string
+
+const (
+	// ValidationWarn indexes the records and reports the validation results.
+	ValidationWarn ValidationMode = "warn"
+	// ValidationReject does not index the records and reports the validation results.
+	ValidationReject ValidationMode = "reject"
+)
+
+// ParseValidationMode returns the ValidationMode for its string representation.
+func ParseValidationMode(mode string) (ValidationMode, error) {
+	switch ValidationMode(mode) {
+	case ValidationWarn, ValidationReject:
+		return ValidationMode(mode), nil
+	}
+
+	return "", fmt.Errorf("unknown SHACL validation mode: %q", mode)
+}
+
+type validation struct {
+	validator *shacl.Validator
+	mode      ValidationMode
+}
+
+// maxValidationReports is the maximum number of RecordReports that are kept in
+// the Stats of a single request.
+const maxValidationReports = 100
+
+// RecordReport is the SHACL validation report of a single record.
+type RecordReport struct {
+	HubID    string        `json:"hubID"`
+	Rejected bool          `json:"rejected"`
+	Error    string        `json:"error,omitempty"`
+	Report   *shacl.Report `json:"report,omitempty"`
+}
+
+// addValidationReport adds the report to the Stats and counts it as rejected
+// or as a warning. When the Stats already contain maxValidationReports reports,
+// the report is only counted in ValidationReportsOmitted.
+func (s *Stats) addValidationReport(report *RecordReport) {
+	if report.Rejected {
+		atomic.AddUint64(&s.RecordsRejected, 1)
+	} else {
+		atomic.AddUint64(&s.ValidationWarnings, 1)
+	}
+
+	if len(s.ValidationReports) >= maxValidationReports {
+		atomic.AddUint64(&s.ValidationReportsOmitted, 1)
+		return
+	}
+
+	s.ValidationReports = append(s.ValidationReports, report)
+}
+
+func validationKey(orgID, datasetID string) string {
+	if datasetID == "" {
+		return orgID
+	}
+
+	return orgID + "/" + datasetID
+}
+
+// lookupValidation returns the validation for the dataset. When no dataset
+// validation is configured, the validation of the organization is returned.
+func lookupValidation(validations map[string]*validation, orgID, datasetID string) *validation {
+	if v, ok := validations[validationKey(orgID, datasetID)]; ok {
+		return v
+	}
+
+	return validations[validationKey(orgID, "")]
+}
+
+// validate validates the graph and returns a RecordReport when the graph
+// does not conform or could not be validated. nil is returned when the
+// graph is valid.
+func (v *validation) validate(hubID string, g *rdf.Graph) *RecordReport {
+	report := v.validator.Validate(g)
+	if len(report.Results) == 0 {
+		return nil
+	}
+
+	return &RecordReport{
+		HubID:    hubID,
+		Rejected: !report.Conforms && v.mode == ValidationReject,
+		Report:   report,
+	}
+}
+
+// validateRDF2Go converts the rdf2go.Graph before validating it.
+func (v *validation) validateRDF2Go(hubID string, g *rdf2go.Graph) *RecordReport {
+	converted, err := convertGraph(g)
+	if err != nil {
+		return &RecordReport{
+			HubID:    hubID,
+			Rejected: v.mode == ValidationReject,
+			Error:    err.Error(),
+		}
+	}
+
+	return v.validate(hubID, converted)
+}
+
+// convertGraph converts a rdf2go.Graph into a rdf.Graph.
+func convertGraph(g *rdf2go.Graph) (*rdf.Graph, error) {
+	converted := rdf.NewGraph()
+
+	for t := range g.IterTriplesOrdered() {
+		s, err := convertTerm(t.Subject)
+		if err != nil {
+			return nil, err
+		}
+
+		subject, ok := s.(rdf.Subject)
+		if !ok {
+			return nil, fmt.Errorf("invalid subject %s", t.Subject)
+		}
+
+		p, err := convertTerm(t.Predicate)
+		if err != nil {
+			return nil, err
+		}
+
+		predicate, ok := p.(rdf.Predicate)
+		if !ok {
+			return nil, fmt.Errorf("invalid predicate %s", t.Predicate)
+		}
+
+		o, err := convertTerm(t.Object)
+		if err != nil {
+			return nil, err
+		}
+
+		object, ok := o.(rdf.Object)
+		if !ok {
+			return nil, fmt.Errorf("invalid object %s", t.Object)
+		}
+
+		converted.Add(rdf.NewTriple(subject, predicate, object))
+	}
+
+	return converted, nil
+}
+
+func convertTerm(term rdf2go.Term) (rdf.Term, error) {
+	switch t := term.(type) {
+	case *rdf2go.Resource:
+		return rdf.NewIRI(t.URI)
+	case *rdf2go.BlankNode:
+		return rdf.NewBlankNode(t.ID)
+	case *rdf2go.Literal:
+		if t.Language != "" {
+			return rdf.NewLiteralWithLang(t.Value, t.Language)
+		}
+
+		if t.Datatype != nil && t.Datatype.RawValue() != "" {
+			dt, err := rdf.NewIRI(t.Datatype.RawValue())
+			if err != nil {
+				return nil, err
+			}
+
+			return rdf.NewLiteralWithType(t.Value, dt)
+		}
+
+		return rdf.NewLiteral(t.Value)
+	}
+
+	return nil, fmt.Errorf("unknown rdf2go term type: %T", term)
+}
@@ -0,0 +1,166 @@
+package bulk
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kiivihal/rdf2go"
+	"github.com/matryer/is"
+
+	"github.com/delving/hub3/ikuzo/rdf/formats/turtle"
+	"github.com/delving/hub3/ikuzo/rdf/shacl"
+)
+
+const testShapes = `
+@prefix sh: <http://www.w3.org/ns/shacl#> .
+@prefix xsd: <http://www.w3.org/2001/XMLSchema#> .
+
+<urn:shape> sh:targetSubjectsOf <http://purl.org/dc/elements/1.1/subject> ;
+	sh:property _:title .
+
+_:title sh:path <http://purl.org/dc/elements/1.1/title> ;
+	sh:minCount 1 ;
+	sh:datatype xsd:string .
+`
+
+func newTestValidator(t *testing.T) *shacl.Validator {
+	t.Helper()
+
+	g, err := turtle.Parse(strings.NewReader(testShapes), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := shacl.NewValidator(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return v
+}
+
+func newTestGraph(withTitle bool) *rdf2go.Graph {
+	g := rdf2go.NewGraph("")
+	g.AddTriple(
+		rdf2go.NewResource("urn:subject"),
+		rdf2go.NewResource("http://purl.org/dc/elements/1.1/subject"),
+		rdf2go.NewLiteralWithLanguage("hello", "en"),
+	)
+	g.AddTriple(
+		rdf2go.NewResource("urn:subject"),
+		rdf2go.NewResource("http://www.europeana.eu/schemas/edm/hasView"),
+		rdf2go.NewBlankNode("view"),
+	)
+
+	if withTitle {
+		g.AddTriple(
+			rdf2go.NewResource("urn:subject"),
+			rdf2go.NewResource("http://purl.org/dc/elements/1.1/title"),
+			rdf2go.NewLiteral("title"),
+		)
+	}
+
+	return g
+}
+
+func TestConvertGraph(t *testing.T) {
+	is := is.New(t)
+
+	g, err := convertGraph(newTestGraph(true))
+	is.NoErr(err)
+	is.Equal(g.Len(), 3)
+
+	got := map[string]bool{}
+	for _, t := range g.Triples() {
+		got[t.Object.String()] = true
+	}
+
+	is.True(got[`"hello"@en`])
+	is.True(got["_:view"])
+	is.True(got[`"title"`])
+}
+
+func TestLookupValidation(t *testing.T) {
+	is := is.New(t)
+
+	org := &validation{mode: ValidationWarn}
+	ds := &validation{mode: ValidationReject}
+
+	validations := map[string]*validation{
+		validationKey("hub3", ""):      org,
+		validationKey("hub3", "spec1"): ds,
+	}
+
+	is.Equal(lookupValidation(validations, "hub3", "spec1"), ds)
+	is.Equal(lookupValidation(validations, "hub3", "spec2"), org)
+	is.Equal(lookupValidation(validations, "other", "spec1"), nil)
+}
+
+func TestParseValidationMode(t *testing.T) {
+	is := is.New(t)
+
+	mode, err := ParseValidationMode("reject")
+	is.NoErr(err)
+	is.Equal(mode, ValidationReject)
+
+	_, err = ParseValidationMode("ignore")
+	is.True(err != nil)
+}
+
+func TestParserValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      ValidationMode
+		withTitle bool
+		wantErr   error
+		rejected  uint64
+		warnings  uint64
+		reports   int
+	}{
+		{"valid record", ValidationReject, true, nil, 0, 0, 0},
+		{"reject invalid record", ValidationReject, false, ErrRecordRejected, 1, 0, 1},
+		{"warn invalid record", ValidationWarn, false, nil, 0, 1, 1},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+
+			svc, err := NewService(SetValidation("hub3", "spec", newTestValidator(t), tt.mode))
+			is.NoErr(err)
+
+			p := svc.NewParser()
+			req := &Request{HubID: "hub3_spec_1", OrgID: "hub3", DatasetID: "spec"}
+
+			err = p.validate(req, newTestGraph(tt.withTitle))
+			is.True(errors.Is(err, tt.wantErr))
+			is.Equal(p.stats.RecordsRejected, tt.rejected)
+			is.Equal(p.stats.ValidationWarnings, tt.warnings)
+			is.Equal(len(p.stats.ValidationReports), tt.reports)
+
+			if tt.reports > 0 {
+				report := p.stats.ValidationReports[0]
+				is.Equal(report.HubID, "hub3_spec_1")
+				is.Equal(len(report.Report.Results), 1)
+			}
+		})
+	}
+}
+
+func TestStats_addValidationReport(t *testing.T) {
+	is := is.New(t)
+
+	stats := &Stats{}
+
+	for i := 0; i < maxValidationReports+5; i++ {
+		stats.addValidationReport(&RecordReport{HubID: "hub3_spec_1", Rejected: i%2 == 0})
+	}
+
+	is.Equal(len(stats.ValidationReports), maxValidationReports)
+	is.Equal(stats.ValidationReportsOmitted, uint64(5))
+	is.Equal(stats.RecordsRejected, uint64(53))
+	is.Equal(stats.ValidationWarnings, uint64(52))
+}
@@ -12,6 +12,8 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+var ErrVerbNotSupported = errors.New("verb is not supported")
+
 type EADHarvester struct {
 	s     *Service
 	OrgID string
@@ -25,12 +27,16 @@ func NewEADHarvester(s *Service) (EADHarvester, error) {
 	return EADHarvester{s: s}, nil
 }
 
-func (e *EADHarvester) ProcessEadFromOai(r *oai.Response) {
+// ProcessEadFromOai submits the EAD records of the page for indexing. All
+// records are processed; the errors of the records that could not be
+// processed are returned together.
+func (e *EADHarvester) ProcessEadFromOai(r *oai.Response) error {
 	if r.Request.Verb != harvest.VerbListRecords {
-		log.Warn().Str("verb", r.Request.Verb).Msg("verb is not supported for getting ead records")
-		return
+		return fmt.Errorf("%w: %s for getting ead records", ErrVerbNotSupported, r.Request.Verb)
 	}
 
+	var errs []error
+
 	for _, record := range r.ListRecords.Records {
 		record := record
 
@@ -46,8 +52,12 @@ func (e *EADHarvester) ProcessEadFromOai(r *oai.Response) {
 				Str("identifier", record.Header.Identifier).
 				Err(err).
 				Msg("unable to process ead record")
+
+			errs = append(errs, fmt.Errorf("unable to process ead record %s; %w", record.Header.Identifier, err))
 		}
 	}
+
+	return errors.Join(errs...)
 }
 
 func (e *EADHarvester) processRecord(record *oai.Record) error {
@@ -74,7 +84,11 @@ func (e *EADHarvester) processRecord(record *oai.Record) error {
 
 	t, err := e.s.NewTask(&meta)
 	if err != nil {
-		e.s.M.IncAlreadyQueued()
+		if errors.Is(err, ErrTaskAlreadySubmitted) {
+			e.s.M.IncAlreadyQueued()
+			return nil
+		}
+
 		return err
 	}
 
@@ -102,12 +116,16 @@ func NewMetsHarvest(c *ead.DaoClient) (MetsHarvester, error) {
 	}, nil
 }
 
-func (m *MetsHarvester) ProcessMetsFromOai(r *oai.Response) {
+// ProcessMetsFromOai publishes the finding aids of the METS headers of the
+// page. All headers are processed; the errors of the headers that could not
+// be processed are returned together.
+func (m *MetsHarvester) ProcessMetsFromOai(r *oai.Response) error {
 	if r.Request.Verb != harvest.VerbListIdentifiers {
-		log.Warn().Str("verb", r.Request.Verb).Msg("verb is not supported for getting mets headers")
-		return
+		return fmt.Errorf("%w: %s for getting mets headers", ErrVerbNotSupported, r.Request.Verb)
 	}
 
+	var errs []error
+
 	for _, header := range r.ListIdentifiers.Headers {
 		log.Info().
 			Str("identifier", header.Identifier).
@@ -121,8 +139,12 @@ func (m *MetsHarvester) ProcessMetsFromOai(r *oai.Response) {
 				Str("identifier", header.Identifier).
 				Err(err).
 				Msg("unable to process mets header")
+
+			errs = append(errs, fmt.Errorf("unable to process mets header %s; %w", header.Identifier, err))
 		}
 	}
+
+	return errors.Join(errs...)
 }
 
 func (m MetsHarvester) processHeader(header oai.Header) error {
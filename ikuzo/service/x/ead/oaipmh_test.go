@@ -1,6 +1,40 @@
 package ead
 
-import "testing"
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/delving/hub3/hub3/ead"
+	"github.com/delving/hub3/ikuzo/service/x/oaipmh/harvest"
+	"github.com/kiivihal/goharvest/oai"
+	"github.com/matryer/is"
+)
+
+func TestProcessFromOai_errors(t *testing.T) {
+	is := is.New(t)
+
+	eadHarvester := EADHarvester{}
+	err := eadHarvester.ProcessEadFromOai(&oai.Response{Request: oai.RequestNode{Verb: harvest.VerbListIdentifiers}})
+	is.True(errors.Is(err, ErrVerbNotSupported))
+
+	metsHarvester, err := NewMetsHarvest(&ead.DaoClient{})
+	is.NoErr(err)
+
+	err = metsHarvester.ProcessMetsFromOai(&oai.Response{Request: oai.RequestNode{Verb: harvest.VerbListRecords}})
+	is.True(errors.Is(err, ErrVerbNotSupported))
+
+	r := &oai.Response{Request: oai.RequestNode{Verb: harvest.VerbListIdentifiers}}
+	r.ListIdentifiers.Headers = []oai.Header{
+		{Identifier: "uuid-1", SetSpec: []string{"INV:5ED"}},
+		{Identifier: "uuid-2"},
+	}
+
+	err = metsHarvester.ProcessMetsFromOai(r)
+	is.True(err != nil) // the headers without an archiveID fail the page
+	is.True(strings.Contains(err.Error(), "uuid-1"))
+	is.True(strings.Contains(err.Error(), "uuid-2"))
+}
 
 func Test_extractSpecs(t *testing.T) {
 	type args struct {
@@ -223,6 +223,14 @@ func (s *Service) saveDescription(cfg *eadHub3.NodeConfig, t *Task, ead *eadHub3
 
 	descIndex := eadHub3.NewDescriptionIndex(t.Meta.DatasetID)
 
+	if s.orgs != nil {
+		if orgCfg, ok := s.orgs.RetrieveConfig(t.Meta.OrgID); ok {
+			if err := descIndex.SetLanguage(orgCfg.Analyzer.Language); err != nil {
+				return fmt.Errorf("unable to set DescriptionIndex language; %w", err)
+			}
+		}
+	}
+
 	err = descIndex.CreateFrom(desc)
 	if err != nil {
 		return fmt.Errorf("unable to create DescriptionIndex; %w", err)
@@ -0,0 +1,37 @@
+package harvest
+
+// Diff classifies the changes of a source between two syncs.
+type Diff string
+
+const (
+	DiffNoChange           Diff = "NoChange"
+	DiffNew                Diff = "New"
+	DiffModified           Diff = "Modified"
+	DiffModifiedNew        Diff = "ModifiedNew"
+	DiffDeleted            Diff = "Deleted"
+	DiffDeletedNew         Diff = "DeletedNew"
+	DiffDeletedModified    Diff = "DeletedModified"
+	DiffDeletedModifiedNew Diff = "DeletedModifiedNew"
+)
+
+// Classify returns the Diff for the number of new, modified and deleted items.
+func Classify(added, modified, deleted int) Diff {
+	switch {
+	case deleted == 0 && modified == 0 && added == 0:
+		return DiffNoChange
+	case deleted == 0 && modified == 0:
+		return DiffNew
+	case deleted == 0 && added == 0:
+		return DiffModified
+	case deleted == 0:
+		return DiffModifiedNew
+	case modified == 0 && added == 0:
+		return DiffDeleted
+	case modified == 0:
+		return DiffDeletedNew
+	case added == 0:
+		return DiffDeletedModified
+	default:
+		return DiffDeletedModifiedNew
+	}
+}
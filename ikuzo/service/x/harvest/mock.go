@@ -10,8 +10,9 @@ import (
 
 // make sure item satisfies harvest.Item interface.
 var (
-	_ Item   = (*mockItem)(nil)
-	_ Syncer = (*mockService)(nil)
+	_ Item      = (*mockItem)(nil)
+	_ Deletable = (*mockItem)(nil)
+	_ Syncer    = (*mockService)(nil)
 )
 
 type mockItem struct {
@@ -32,6 +33,10 @@ func (m mockItem) GetData() io.Reader {
 	return strings.NewReader(fmt.Sprintf("doc %s", m.id))
 }
 
+func (m mockItem) IsDeleted() bool {
+	return m.deleted
+}
+
 func newMockItems(start, max int, seedTime time.Time) []*mockItem {
 	var items []*mockItem
 
@@ -76,11 +81,14 @@ type mockService struct {
 	completeListSize int
 	currentPage      int
 	cursor           int
-	items            []Item
+	items            []Item // all items of the source
+	results          []Item // items of the current query
 	maxItems         int
 	pageSize         int
 	query            Query
 	seedTime         time.Time
+	// unknownSize leaves the complete list size out of the pages
+	unknownSize bool
 }
 
 func newMockService(maxItems int) *mockService {
@@ -99,15 +107,21 @@ func (ms *mockService) First(q Query) (Page, error) {
 	ms.currentPage = 1
 	ms.cursor = 1
 
+	if ms.items == nil {
+		for _, val := range newMockItems(1, ms.maxItems, ms.seedTime) {
+			ms.items = append(ms.items, Item(val))
+		}
+	}
+
 	res := []Item{}
 
-	for _, val := range newMockItems(1, ms.maxItems, ms.seedTime) {
+	for _, val := range ms.items {
 		if ms.query.Valid(val.GetLastModified()) {
-			res = append(res, Item(val))
+			res = append(res, val)
 		}
 	}
 
-	ms.items = res
+	ms.results = res
 	ms.completeListSize = len(res)
 
 	end := ms.pageSize
@@ -116,12 +130,20 @@ func (ms *mockService) First(q Query) (Page, error) {
 	}
 
 	return mockPage{
-		items:            ms.items[:end],
-		completeListSize: ms.completeListSize,
+		items:            ms.results[:end],
+		completeListSize: ms.listSize(),
 		cursor:           ms.cursor,
 	}, nil
 }
 
+func (ms *mockService) listSize() int {
+	if ms.unknownSize {
+		return 0
+	}
+
+	return ms.completeListSize
+}
+
 func (ms *mockService) Next() (Page, error) {
 	start := ms.currentPage * ms.pageSize
 
@@ -135,24 +157,60 @@ func (ms *mockService) Next() (Page, error) {
 		end = ms.completeListSize
 	}
 
+	if start > end {
+		start = end
+	}
+
 	return mockPage{
-		items:            ms.items[start:end],
-		completeListSize: ms.completeListSize,
+		items:            ms.results[start:end],
+		completeListSize: ms.listSize(),
 		cursor:           ms.cursor,
 	}, nil
 }
 
+// addNew adds items that are modified after all other items.
 func (ms *mockService) addNew(nr int) {
-	items := newMockItems(ms.completeListSize+1, nr, ms.seedTime)
+	var last time.Time
+	if len(ms.items) > 0 {
+		last = ms.items[len(ms.items)-1].GetLastModified()
+	}
+
+	items := newMockItems(ms.maxItems+1, nr, ms.seedTime)
+	ms.maxItems += nr
 	ms.completeListSize += nr
 
-	for _, item := range items {
+	for idx, item := range items {
+		if !item.lastModified.After(last) {
+			item.lastModified = last.Add(time.Duration(idx+1) * time.Second)
+		}
+
 		ms.items = append(ms.items, Item(item))
 	}
 
 	sortItems(ms.items)
 }
 
+// remove deletes the items from the source without a trace.
+func (ms *mockService) remove(ids ...string) {
+	items := []Item{}
+
+	for _, item := range ms.items {
+		removed := false
+
+		for _, id := range ids {
+			if item.GetIdentifier() == id {
+				removed = true
+			}
+		}
+
+		if !removed {
+			items = append(items, item)
+		}
+	}
+
+	ms.items = items
+}
+
 func (ms *mockService) modify(ids []string, deleted bool) {
 	last := ms.items[len(ms.items)-1]
 
@@ -195,8 +195,8 @@ func Test_mockService_First(t *testing.T) {
 			},
 			args{
 				Query{
-					From:  time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC).Add(10 * time.Second),
-					Until: time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC).Add(41 * time.Second),
+					From:  time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC).Add(11 * time.Second),
+					Until: time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC).Add(40 * time.Second),
 				},
 			},
 			mockPage{
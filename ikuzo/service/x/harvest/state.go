@@ -0,0 +1,212 @@
+package harvest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	ErrStateNotFound    = errors.New("harvest state not found")
+	ErrInvalidStateName = errors.New("invalid harvest state name")
+)
+
+// State is what is known about a source after the last Sync.
+type State struct {
+	// Watermark is the latest modification date of the synced items. The
+	// next Sync harvests the items that are modified at or after it.
+	Watermark        time.Time `json:"watermark"`
+	CompleteListSize int       `json:"completeListSize"`
+	LastSync         time.Time `json:"lastSync"`
+	// Items contains the last modification date by identifier.
+	Items map[string]time.Time `json:"items"`
+	// Deleted contains the items that are still listed by the source as
+	// deleted (see Deletable).
+	Deleted map[string]time.Time `json:"deleted,omitempty"`
+}
+
+// NewState returns an empty State.
+func NewState() *State {
+	return &State{
+		Items:   map[string]time.Time{},
+		Deleted: map[string]time.Time{},
+	}
+}
+
+// IsEmpty returns true when nothing has been synced yet.
+func (s *State) IsEmpty() bool {
+	return len(s.Items) == 0 && len(s.Deleted) == 0 && s.Watermark.IsZero()
+}
+
+// KnownItem is an identifier with its last modification date in the State.
+type KnownItem struct {
+	Identifier   string
+	LastModified time.Time
+	Deleted      bool
+}
+
+// sorted returns the known items, including the deleted items, sorted from
+// first to last modified, excluding the identifiers in skip.
+func (s *State) sorted(skip map[string]bool) []KnownItem {
+	items := make([]KnownItem, 0, len(s.Items)+len(s.Deleted))
+
+	for id, modified := range s.Items {
+		if !skip[id] {
+			items = append(items, KnownItem{Identifier: id, LastModified: modified})
+		}
+	}
+
+	for id, modified := range s.Deleted {
+		if !skip[id] {
+			items = append(items, KnownItem{Identifier: id, LastModified: modified, Deleted: true})
+		}
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].LastModified.Equal(items[j].LastModified) {
+			return items[i].Identifier < items[j].Identifier
+		}
+
+		return items[i].LastModified.Before(items[j].LastModified)
+	})
+
+	return items
+}
+
+// StateStore persists the state of a harvest as JSON by name. A name can
+// contain slashes to group the states, e.g. by organization.
+type StateStore interface {
+	// Get decodes the state of the name into v. ErrStateNotFound is returned
+	// when no state is stored for the name.
+	Get(name string, v interface{}) error
+	Put(name string, v interface{}) error
+}
+
+// MemoryStateStore is a StateStore that does not persist the state.
+type MemoryStateStore struct {
+	rw     sync.RWMutex
+	states map[string][]byte
+}
+
+func NewMemoryStateStore() *MemoryStateStore {
+	return &MemoryStateStore{states: map[string][]byte{}}
+}
+
+func (ms *MemoryStateStore) Get(name string, v interface{}) error {
+	ms.rw.RLock()
+	defer ms.rw.RUnlock()
+
+	b, ok := ms.states[name]
+	if !ok {
+		return ErrStateNotFound
+	}
+
+	return decodeState(name, b, v)
+}
+
+func (ms *MemoryStateStore) Put(name string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	ms.rw.Lock()
+	defer ms.rw.Unlock()
+
+	ms.states[name] = b
+
+	return nil
+}
+
+// FileStateStore stores each state as a JSON file. The slashes of the name
+// are subdirectories.
+type FileStateStore struct {
+	dir string
+	rw  sync.Mutex
+}
+
+// NewFileStateStore returns a FileStateStore that writes to dir. The
+// directory is created when it does not exist.
+func NewFileStateStore(dir string) (*FileStateStore, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("directory of the harvest state store cannot be empty")
+	}
+
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("unable to create harvest state directory; %w", err)
+	}
+
+	return &FileStateStore{dir: dir}, nil
+}
+
+func (fs *FileStateStore) path(name string) (string, error) {
+	path := filepath.FromSlash(name)
+	if !filepath.IsLocal(path) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidStateName, name)
+	}
+
+	return filepath.Join(fs.dir, path+".json"), nil
+}
+
+func (fs *FileStateStore) Get(name string, v interface{}) error {
+	path, err := fs.path(name)
+	if err != nil {
+		return err
+	}
+
+	fs.rw.Lock()
+	defer fs.rw.Unlock()
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ErrStateNotFound
+		}
+
+		return err
+	}
+
+	return decodeState(name, b, v)
+}
+
+// Put writes the state to a temporary file that replaces the previous state,
+// so a crash never leaves a partially written state behind.
+func (fs *FileStateStore) Put(name string, v interface{}) error {
+	path, err := fs.path(name)
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	fs.rw.Lock()
+	defer fs.rw.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+func decodeState(name string, b []byte, v interface{}) error {
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("unable to decode harvest state %s; %w", name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package harvest
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestFileStateStore(t *testing.T) {
+	is := is.New(t)
+
+	dir := t.TempDir()
+
+	store, err := NewFileStateStore(dir)
+	is.NoErr(err)
+
+	type jobState struct {
+		ID string `json:"id"`
+	}
+
+	var got jobState
+
+	err = store.Get("org1/job1", &got)
+	is.True(errors.Is(err, ErrStateNotFound))
+
+	is.NoErr(store.Put("org1/job1", jobState{ID: "job1"}))
+
+	_, err = os.Stat(filepath.Join(dir, "org1", "job1.json"))
+	is.NoErr(err)
+
+	is.NoErr(store.Get("org1/job1", &got))
+	is.Equal(got.ID, "job1")
+
+	for _, name := range []string{"../job1", "/etc/job1", ""} {
+		err = store.Put(name, jobState{})
+		is.True(errors.Is(err, ErrInvalidStateName)) // name must stay in the directory
+
+		err = store.Get(name, &got)
+		is.True(errors.Is(err, ErrInvalidStateName))
+	}
+}
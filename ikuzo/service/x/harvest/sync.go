@@ -0,0 +1,458 @@
+package harvest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// defaultScanSize is the number of known items below which a range is
+// harvested instead of split by the binary search.
+const defaultScanSize = 100
+
+// defaultGranularity is the granularity of the datestamps of OAI-PMH sources.
+const defaultGranularity = time.Second
+
+// EventType is the type of change of an Event.
+type EventType string
+
+const (
+	EventAdd    EventType = "add"
+	EventUpdate EventType = "update"
+	EventDelete EventType = "delete"
+)
+
+// Event is a change of an item of the source. Item is nil for EventDelete.
+type Event struct {
+	Type         EventType
+	Identifier   string
+	LastModified time.Time
+	Item         Item
+}
+
+// EventHandler processes the events of a Sync. An error aborts the Sync.
+type EventHandler func(ctx context.Context, event Event) error
+
+// Result reports the changes that are found by a Sync.
+type Result struct {
+	Diff             Diff
+	New              int
+	Modified         int
+	Deleted          int
+	CompleteListSize int
+	// Requests is the number of pages that are requested from the Syncer.
+	Requests int
+}
+
+// Driver synchronizes a source via its Syncer and emits the changes since
+// the previous Sync as events.
+//
+// New and modified items are harvested from the watermark of the State. The
+// bounds of a Query are inclusive and truncated to the granularity of the
+// source, so the known items that are modified at the watermark are listed
+// again and are only reported when they are removed.
+// Deleted items that the source keeps in its list are reported by items that
+// implement Deletable. Items that are silently removed from the source are
+// detected by comparing the count of the items up to the watermark with the
+// State. The removed items are then located with a binary search over the
+// known items, where each step compares the count of a date range with the
+// source, so only the ranges that contain removed items are harvested.
+// Sources that leave out the optional complete list size are counted by
+// listing the range.
+//
+// An unknown item with an old modification date that is added while another
+// old item is removed does not change the count and is not detected.
+type Driver struct {
+	name        string
+	syncer      Syncer
+	store       StateStore
+	scanSize    int
+	granularity time.Duration
+	requests    int
+}
+
+// DriverOption configures a Driver.
+type DriverOption func(d *Driver)
+
+// SetScanSize sets the number of known items below which the binary search
+// harvests the range instead of splitting it further.
+func SetScanSize(size int) DriverOption {
+	return func(d *Driver) {
+		d.scanSize = size
+	}
+}
+
+// SetGranularity sets the precision of the modification dates of the source,
+// e.g. 24 * time.Hour for an OAI-PMH endpoint with day granularity. The
+// default is time.Second.
+func SetGranularity(granularity time.Duration) DriverOption {
+	return func(d *Driver) {
+		d.granularity = granularity
+	}
+}
+
+// NewDriver returns a Driver that stores the State of the source by name.
+func NewDriver(name string, syncer Syncer, store StateStore, options ...DriverOption) *Driver {
+	d := &Driver{
+		name:        name,
+		syncer:      syncer,
+		store:       store,
+		scanSize:    defaultScanSize,
+		granularity: defaultGranularity,
+	}
+
+	for _, option := range options {
+		option(d)
+	}
+
+	return d
+}
+
+// Sync emits the changes of the source since the previous Sync to the
+// handler. The State is only stored when all events are handled, so after an
+// error the same events are emitted again by the next Sync.
+func (d *Driver) Sync(ctx context.Context, handler EventHandler) (*Result, error) {
+	state := NewState()
+
+	if err := d.store.Get(d.name, state); err != nil {
+		if !errors.Is(err, ErrStateNotFound) {
+			return nil, err
+		}
+	}
+
+	if state.Items == nil {
+		state.Items = map[string]time.Time{}
+	}
+
+	if state.Deleted == nil {
+		state.Deleted = map[string]time.Time{}
+	}
+
+	d.requests = 0
+
+	res, err := d.sync(ctx, state, handler)
+	if err != nil {
+		return nil, err
+	}
+
+	res.Requests = d.requests
+	state.LastSync = time.Now()
+
+	if err := d.store.Put(d.name, state); err != nil {
+		return nil, fmt.Errorf("unable to store sync state of %s; %w", d.name, err)
+	}
+
+	return res, nil
+}
+
+func (d *Driver) sync(ctx context.Context, state *State, handler EventHandler) (*Result, error) {
+	res := &Result{}
+
+	// the listed items are no longer in the range before the previous watermark
+	listed := map[string]bool{}
+	watermark := state.Watermark
+	isFirst := state.IsEmpty()
+
+	size, err := d.harvest(ctx, Query{From: state.Watermark}, func(item Item) error {
+		id := item.GetIdentifier()
+		modified := d.truncate(item.GetLastModified())
+
+		if modified.After(watermark) {
+			watermark = modified
+		}
+
+		previous, known := state.Items[id]
+		listed[id] = true
+
+		if !isDeleted(item) {
+			delete(state.Deleted, id)
+		}
+
+		switch {
+		case isDeleted(item):
+			state.Deleted[id] = modified
+
+			if !known {
+				return nil
+			}
+
+			res.Deleted++
+
+			return d.emit(ctx, state, handler, Event{Type: EventDelete, Identifier: id, LastModified: modified})
+		case known && previous.Equal(modified):
+			// listed again, because the from of the query is inclusive
+			return nil
+		case known:
+			res.Modified++
+
+			return d.emit(ctx, state, handler, Event{Type: EventUpdate, Identifier: id, LastModified: modified, Item: item})
+		default:
+			res.New++
+
+			return d.emit(ctx, state, handler, Event{Type: EventAdd, Identifier: id, LastModified: modified, Item: item})
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res.CompleteListSize = size
+
+	if !isFirst {
+		until := state.Watermark.Add(-d.granularity)
+
+		old, err := d.count(ctx, Query{Until: until})
+		if err != nil {
+			return nil, err
+		}
+
+		res.CompleteListSize += old
+
+		candidates := state.sorted(listed)
+
+		// the known items at the watermark are within the harvested range, so
+		// the ones that are not listed are removed
+		split := sort.Search(len(candidates), func(i int) bool {
+			return !candidates[i].LastModified.Before(state.Watermark)
+		})
+
+		added, removed, err := d.locate(ctx, candidates[:split], time.Time{}, until, split-old)
+		if err != nil {
+			return nil, err
+		}
+
+		removed = append(removed, candidates[split:]...)
+
+		for _, item := range added {
+			if isDeleted(item) {
+				// keeps the State in step with the count of the source
+				state.Deleted[item.GetIdentifier()] = d.truncate(item.GetLastModified())
+				continue
+			}
+
+			res.New++
+
+			event := Event{Type: EventAdd, Identifier: item.GetIdentifier(), LastModified: d.truncate(item.GetLastModified()), Item: item}
+			if err := d.emit(ctx, state, handler, event); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, known := range removed {
+			if known.Deleted {
+				delete(state.Deleted, known.Identifier)
+				continue
+			}
+
+			res.Deleted++
+
+			event := Event{Type: EventDelete, Identifier: known.Identifier, LastModified: known.LastModified}
+			if err := d.emit(ctx, state, handler, event); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	state.Watermark = watermark
+	state.CompleteListSize = res.CompleteListSize
+	res.Diff = Classify(res.New, res.Modified, res.Deleted)
+
+	return res, nil
+}
+
+// emit handles the event and applies it to the State.
+func (d *Driver) emit(ctx context.Context, state *State, handler EventHandler, event Event) error {
+	if err := handler(ctx, event); err != nil {
+		return err
+	}
+
+	switch event.Type {
+	case EventDelete:
+		delete(state.Items, event.Identifier)
+	default:
+		state.Items[event.Identifier] = event.LastModified
+	}
+
+	return nil
+}
+
+// locate returns the items that are added to or removed from the date range
+// [from, until] with the sorted candidates. missing is the number of
+// candidates minus the count of the items of the source in the range. The
+// added items include the unknown deleted items.
+func (d *Driver) locate(
+	ctx context.Context, candidates []KnownItem, from, until time.Time, missing int,
+) (added []Item, removed []KnownItem, err error) {
+	switch {
+	case missing == 0:
+		return nil, nil, nil
+	case missing == len(candidates):
+		return nil, candidates, nil
+	case missing < 0 || len(candidates) <= d.scanSize:
+		return d.scan(ctx, candidates, from, until)
+	}
+
+	// split by date, so items with the same modification date stay together
+	middle := candidates[len(candidates)/2].LastModified
+	split := sort.Search(len(candidates), func(i int) bool {
+		return !candidates[i].LastModified.Before(middle)
+	})
+
+	if split == 0 {
+		return d.scan(ctx, candidates, from, until)
+	}
+
+	left, right := candidates[:split], candidates[split:]
+
+	// the left candidates are modified before the middle, so at least one
+	// granularity step earlier
+	leftUntil := middle.Add(-d.granularity)
+
+	count, err := d.count(ctx, Query{From: from, Until: leftUntil})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	leftMissing := len(left) - count
+
+	added, removed, err = d.locate(ctx, left, from, leftUntil, leftMissing)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rightAdded, rightRemoved, err := d.locate(ctx, right, middle, until, missing-leftMissing)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return append(added, rightAdded...), append(removed, rightRemoved...), nil
+}
+
+// scan harvests the date range [from, until] and compares the identifiers
+// with the candidates.
+func (d *Driver) scan(
+	ctx context.Context, candidates []KnownItem, from, until time.Time,
+) (added []Item, removed []KnownItem, err error) {
+	expected := map[string]bool{}
+	for _, known := range candidates {
+		expected[known.Identifier] = true
+	}
+
+	found := map[string]bool{}
+
+	_, err = d.harvest(ctx, Query{From: from, Until: until}, func(item Item) error {
+		id := item.GetIdentifier()
+
+		if expected[id] {
+			found[id] = true
+		} else {
+			added = append(added, item)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, known := range candidates {
+		if !found[known.Identifier] {
+			removed = append(removed, known)
+		}
+	}
+
+	return added, removed, nil
+}
+
+// truncate returns the modification date at the granularity of the source.
+func (d *Driver) truncate(t time.Time) time.Time {
+	return t.Truncate(d.granularity)
+}
+
+// harvest calls fn for all items of the query and returns the number of items.
+func (d *Driver) harvest(ctx context.Context, q Query, fn func(item Item) error) (int, error) {
+	page, err := d.first(q)
+	if err != nil || page == nil {
+		return 0, err
+	}
+
+	return d.walk(ctx, page, fn)
+}
+
+// walk calls fn for the items of the page and of the next pages, until an
+// empty page or ErrNoMatch, and returns the number of items. The complete
+// list size is not used, because it is optional.
+func (d *Driver) walk(ctx context.Context, page Page, fn func(item Item) error) (int, error) {
+	seen := 0
+
+	for {
+		items := page.GetItems()
+		if len(items) == 0 {
+			return seen, nil
+		}
+
+		for _, item := range items {
+			if err := fn(item); err != nil {
+				return 0, err
+			}
+		}
+
+		seen += len(items)
+
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+
+		d.requests++
+
+		next, err := d.syncer.Next()
+		if err != nil {
+			if errors.Is(err, ErrNoMatch) {
+				return seen, nil
+			}
+
+			return 0, err
+		}
+
+		page = next
+	}
+}
+
+// count returns the number of items of the query. When the source does not
+// report the complete list size, the items are counted by listing them.
+func (d *Driver) count(ctx context.Context, q Query) (int, error) {
+	page, err := d.first(q)
+	if err != nil || page == nil {
+		return 0, err
+	}
+
+	if size := page.GetCompleteListSize(); size > 0 || len(page.GetItems()) == 0 {
+		return size, nil
+	}
+
+	return d.walk(ctx, page, func(item Item) error { return nil })
+}
+
+// first returns the first page of the query. A nil Page is returned when the
+// Syncer returns ErrNoMatch.
+func (d *Driver) first(q Query) (Page, error) {
+	d.requests++
+
+	page, err := d.syncer.First(q)
+	if err != nil {
+		if errors.Is(err, ErrNoMatch) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return page, nil
+}
+
+func isDeleted(item Item) bool {
+	d, ok := item.(Deletable)
+	return ok && d.IsDeleted()
+}
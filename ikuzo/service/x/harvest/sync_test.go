@@ -0,0 +1,390 @@
+package harvest
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/matryer/is"
+)
+
+// eventRecorder collects the identifiers of the events by type.
+type eventRecorder map[EventType][]string
+
+func (er eventRecorder) handle(ctx context.Context, event Event) error {
+	er[event.Type] = append(er[event.Type], event.Identifier)
+	return nil
+}
+
+func (er eventRecorder) sorted(t EventType) []string {
+	ids := append([]string{}, er[t]...)
+	sort.Strings(ids)
+
+	return ids
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		added, modified, deleted int
+		want                     Diff
+	}{
+		{0, 0, 0, DiffNoChange},
+		{2, 0, 0, DiffNew},
+		{0, 2, 0, DiffModified},
+		{2, 2, 0, DiffModifiedNew},
+		{0, 0, 2, DiffDeleted},
+		{2, 0, 2, DiffDeletedNew},
+		{0, 2, 2, DiffDeletedModified},
+		{2, 2, 2, DiffDeletedModifiedNew},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(string(tt.want), func(t *testing.T) {
+			if got := Classify(tt.added, tt.modified, tt.deleted); got != tt.want {
+				t.Errorf("Classify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// nolint:funlen // table tests can have longer function length
+func TestDriver_Sync(t *testing.T) {
+	type want struct {
+		diff    Diff
+		added   []string
+		updated []string
+		deleted []string
+		size    int
+	}
+
+	// the steps are applied in order to the same source
+	steps := []struct {
+		name        string
+		change      func(ms *mockService)
+		want        want
+		maxRequests int
+	}{
+		{
+			"first sync",
+			func(ms *mockService) {},
+			want{diff: DiffNew, size: 1000},
+			21, // the list ends with an empty page
+		},
+		{
+			"no change",
+			func(ms *mockService) {},
+			want{diff: DiffNoChange, size: 1000},
+			3,
+		},
+		{
+			"new items",
+			func(ms *mockService) { ms.addNew(2) },
+			want{diff: DiffNew, added: []string{"id-1001", "id-1002"}, size: 1002},
+			3,
+		},
+		{
+			"modified items",
+			func(ms *mockService) { ms.modify([]string{"id-10", "id-500"}, false) },
+			want{diff: DiffModified, updated: []string{"id-10", "id-500"}, size: 1002},
+			3,
+		},
+		{
+			"reported deletion",
+			func(ms *mockService) { ms.modify([]string{"id-20"}, true) },
+			want{diff: DiffDeleted, deleted: []string{"id-20"}, size: 1002},
+			3,
+		},
+		{
+			"silent deletion",
+			func(ms *mockService) { ms.remove("id-700") },
+			want{diff: DiffDeleted, deleted: []string{"id-700"}, size: 1001},
+			12,
+		},
+		{
+			"silent deletions in different ranges",
+			func(ms *mockService) { ms.remove("id-30", "id-31", "id-900") },
+			want{diff: DiffDeleted, deleted: []string{"id-30", "id-31", "id-900"}, size: 998},
+			20,
+		},
+		{
+			"deleted, modified and new items",
+			func(ms *mockService) {
+				ms.remove("id-400")
+				ms.modify([]string{"id-401"}, false)
+				ms.addNew(1)
+			},
+			want{
+				diff:    DiffDeletedModifiedNew,
+				added:   []string{"id-1003"},
+				updated: []string{"id-401"},
+				deleted: []string{"id-400"},
+				size:    998,
+			},
+			20,
+		},
+		{
+			"unknown item with an old modification date",
+			func(ms *mockService) {
+				ms.items = append(ms.items, &mockItem{id: "old", lastModified: ms.seedTime})
+				sortItems(ms.items)
+			},
+			want{diff: DiffNew, added: []string{"old"}, size: 999},
+			24, // the whole list is harvested
+		},
+		{
+			"all items removed",
+			func(ms *mockService) { ms.items = []Item{} },
+			want{diff: DiffDeleted, size: 0},
+			2,
+		},
+	}
+
+	ms := newMockService(1000)
+	ms.seedTime = time.Date(2021, 3, 17, 10, 0, 0, 0, time.UTC)
+
+	store := NewMemoryStateStore()
+	known := 0
+
+	for _, step := range steps {
+		step.change(ms)
+
+		events := eventRecorder{}
+
+		res, err := NewDriver("mock", ms, store).Sync(context.TODO(), events.handle)
+		if err != nil {
+			t.Fatalf("%s: Sync() error = %v", step.name, err)
+		}
+
+		if res.Diff != step.want.diff {
+			t.Errorf("%s: Diff = %s, want %s", step.name, res.Diff, step.want.diff)
+		}
+
+		if res.CompleteListSize != step.want.size {
+			t.Errorf("%s: CompleteListSize = %d, want %d", step.name, res.CompleteListSize, step.want.size)
+		}
+
+		if res.Requests > step.maxRequests {
+			t.Errorf("%s: Requests = %d, want at most %d", step.name, res.Requests, step.maxRequests)
+		}
+
+		switch step.name {
+		case "first sync":
+			if len(events[EventAdd]) != 1000 {
+				t.Errorf("%s: got %d add events, want 1000", step.name, len(events[EventAdd]))
+			}
+		case "all items removed":
+			if len(events[EventDelete]) != known {
+				t.Errorf("%s: got %d delete events, want %d", step.name, len(events[EventDelete]), known)
+			}
+		default:
+			got := want{
+				diff:    res.Diff,
+				added:   events.sorted(EventAdd),
+				updated: events.sorted(EventUpdate),
+				deleted: events.sorted(EventDelete),
+				size:    res.CompleteListSize,
+			}
+
+			expected := step.want
+			for _, ids := range []*[]string{&expected.added, &expected.updated, &expected.deleted} {
+				if *ids == nil {
+					*ids = []string{}
+				}
+
+				sort.Strings(*ids)
+			}
+
+			if diff := cmp.Diff(expected, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("%s: mismatch (-want +got):\n%s", step.name, diff)
+			}
+		}
+
+		state := NewState()
+		if err := store.Get("mock", state); err != nil {
+			t.Fatalf("%s: store.Get() error = %v", step.name, err)
+		}
+
+		known = len(state.Items)
+		if known+len(state.Deleted) != res.CompleteListSize {
+			t.Errorf("%s: state has %d items, want %d", step.name, known, res.CompleteListSize)
+		}
+	}
+}
+
+func TestDriver_SyncHandlerError(t *testing.T) {
+	is := is.New(t)
+
+	ms := newMockService(10)
+	ms.seedTime = time.Date(2021, 3, 17, 10, 0, 0, 0, time.UTC)
+
+	store, err := NewFileStateStore(t.TempDir())
+	is.NoErr(err)
+
+	driver := NewDriver("mock", ms, store)
+
+	errHandler := errors.New("handler failed")
+
+	_, err = driver.Sync(context.TODO(), func(ctx context.Context, event Event) error {
+		return errHandler
+	})
+	is.True(errors.Is(err, errHandler))
+
+	// the state is not stored, so the events are emitted again
+	err = store.Get("mock", NewState())
+	is.True(errors.Is(err, ErrStateNotFound))
+
+	events := eventRecorder{}
+
+	res, err := driver.Sync(context.TODO(), events.handle)
+	is.NoErr(err)
+	is.Equal(res.New, 10)
+	is.Equal(len(events[EventAdd]), 10)
+
+	state := NewState()
+	is.NoErr(store.Get("mock", state))
+	is.Equal(len(state.Items), 10)
+	is.Equal(state.CompleteListSize, 10)
+	is.True(state.Watermark.Equal(ms.seedTime.Add(10 * time.Second)))
+}
+
+// querySyncer records the queries of the Driver.
+type querySyncer struct {
+	*mockService
+	queries []Query
+}
+
+func (qs *querySyncer) First(q Query) (Page, error) {
+	qs.queries = append(qs.queries, q)
+	return qs.mockService.First(q)
+}
+
+func TestDriver_SyncInclusiveBounds(t *testing.T) {
+	is := is.New(t)
+
+	ms := newMockService(10)
+	ms.seedTime = time.Date(2021, 3, 17, 10, 0, 0, 0, time.UTC)
+	qs := &querySyncer{mockService: ms}
+
+	store := NewMemoryStateStore()
+	driver := NewDriver("mock", qs, store)
+
+	_, err := driver.Sync(context.TODO(), eventRecorder{}.handle)
+	is.NoErr(err)
+
+	watermark := ms.seedTime.Add(10 * time.Second)
+
+	// added in the same second as the watermark after the previous sync
+	ms.items = append(ms.items, &mockItem{id: "late", lastModified: watermark.Add(500 * time.Millisecond)})
+	qs.queries = nil
+
+	events := eventRecorder{}
+
+	res, err := driver.Sync(context.TODO(), events.handle)
+	is.NoErr(err)
+	is.Equal(res.Diff, DiffNew)
+	is.Equal(events.sorted(EventAdd), []string{"late"})
+	is.Equal(len(events[EventUpdate]), 0) // id-10 is listed again, but not modified
+	is.Equal(res.CompleteListSize, 11)
+
+	is.Equal(qs.queries[0], Query{From: watermark})
+	is.Equal(qs.queries[1], Query{Until: watermark.Add(-time.Second)})
+
+	state := NewState()
+	is.NoErr(store.Get("mock", state))
+	is.True(state.Items["late"].Equal(watermark)) // truncated to the granularity
+	is.True(state.Watermark.Equal(watermark))
+
+	// removed at the watermark
+	ms.remove("id-10")
+
+	events = eventRecorder{}
+
+	res, err = driver.Sync(context.TODO(), events.handle)
+	is.NoErr(err)
+	is.Equal(res.Diff, DiffDeleted)
+	is.Equal(events.sorted(EventDelete), []string{"id-10"})
+	is.Equal(res.CompleteListSize, 10)
+
+	// removed just before the watermark
+	ms.remove("id-9")
+	qs.queries = nil
+
+	events = eventRecorder{}
+
+	res, err = driver.Sync(context.TODO(), events.handle)
+	is.NoErr(err)
+	is.Equal(events.sorted(EventDelete), []string{"id-9"})
+	is.Equal(res.CompleteListSize, 9)
+
+	for _, q := range qs.queries {
+		is.True(q.From.Equal(q.From.Truncate(time.Second)))
+		is.True(q.Until.Equal(q.Until.Truncate(time.Second)))
+	}
+}
+
+func TestDriver_SyncUnknownListSize(t *testing.T) {
+	is := is.New(t)
+
+	ms := newMockService(120)
+	ms.seedTime = time.Date(2021, 3, 17, 10, 0, 0, 0, time.UTC)
+	ms.unknownSize = true
+
+	store := NewMemoryStateStore()
+	driver := NewDriver("mock", ms, store)
+
+	events := eventRecorder{}
+
+	res, err := driver.Sync(context.TODO(), events.handle)
+	is.NoErr(err)
+	is.Equal(len(events[EventAdd]), 120) // all pages are harvested
+	is.Equal(res.CompleteListSize, 120)
+
+	ms.remove("id-60")
+
+	events = eventRecorder{}
+
+	res, err = driver.Sync(context.TODO(), events.handle)
+	is.NoErr(err)
+	is.Equal(events.sorted(EventDelete), []string{"id-60"})
+	is.Equal(res.CompleteListSize, 119)
+}
+
+func TestDriver_SyncUnknownDeletedItem(t *testing.T) {
+	is := is.New(t)
+
+	ms := newMockService(10)
+	ms.seedTime = time.Date(2021, 3, 17, 10, 0, 0, 0, time.UTC)
+
+	store := NewMemoryStateStore()
+	driver := NewDriver("mock", ms, store)
+
+	_, err := driver.Sync(context.TODO(), eventRecorder{}.handle)
+	is.NoErr(err)
+
+	// a deleted item that was never harvested appears in the old range
+	ms.items = append(ms.items, &mockItem{id: "gone", lastModified: ms.seedTime, deleted: true})
+	sortItems(ms.items)
+
+	events := eventRecorder{}
+
+	res, err := driver.Sync(context.TODO(), events.handle)
+	is.NoErr(err)
+	is.Equal(res.Diff, DiffNoChange)
+	is.Equal(len(events), 0)
+	is.Equal(res.CompleteListSize, 11)
+
+	state := NewState()
+	is.NoErr(store.Get("mock", state))
+	is.True(state.Deleted["gone"].Equal(ms.seedTime))
+
+	// the count is in step with the source, so the next sync does not scan
+	res, err = driver.Sync(context.TODO(), eventRecorder{}.handle)
+	is.NoErr(err)
+	is.Equal(res.Diff, DiffNoChange)
+	is.Equal(res.Requests, 3)
+}
@@ -8,6 +8,8 @@ import (
 
 var ErrNoMatch = errors.New("no items match harvest request")
 
+// Syncer lists the items of a source. Next returns an empty Page or ErrNoMatch
+// when all items of the Query are listed.
 type Syncer interface {
 	Next() (Page, error)
 	First(q Query) (Page, error)
@@ -19,25 +21,36 @@ type Item interface {
 	GetData() io.Reader
 }
 
+// Page is a page of the items of a Query. GetCompleteListSize returns 0 when
+// the size of the list is unknown, because it is optional in OAI-PMH.
 type Page interface {
 	GetCursor() int
 	GetCompleteListSize() int
 	GetItems() []Item
 }
 
+// Query selects the items by modification date. Like the from and until
+// arguments of OAI-PMH both bounds are inclusive. A zero bound is unbounded.
 type Query struct {
 	From  time.Time
 	Until time.Time
 }
 
+// Valid returns true when t is within the bounds of the Query.
 func (q Query) Valid(t time.Time) bool {
-	if !q.From.IsZero() && !t.After(q.From) {
+	if !q.From.IsZero() && t.Before(q.From) {
 		return false
 	}
 
-	if !q.Until.IsZero() && !t.Before(q.Until) {
+	if !q.Until.IsZero() && t.After(q.Until) {
 		return false
 	}
 
 	return true
 }
+
+// Deletable is implemented by the Items of sources that keep deleted items in
+// their list with a new modification date.
+type Deletable interface {
+	IsDeleted() bool
+}
@@ -69,6 +69,24 @@ func SetOrganisationService(org *organization.Service) Option {
 	}
 }
 
+// SetRecordTrainers sets the RecordTrainers that are trained with the indexed
+// v2 records.
+func SetRecordTrainers(trainers ...RecordTrainer) Option {
+	return func(s *Service) error {
+		s.trainers = append(s.trainers, trainers...)
+		return nil
+	}
+}
+
+// SetOrphanRecorder sets the OrphanRecorder that records the orphans before
+// they are dropped.
+func SetOrphanRecorder(recorder OrphanRecorder) Option {
+	return func(s *Service) error {
+		s.orphans = recorder
+		return nil
+	}
+}
+
 func SetPostHookService(hooks ...domain.PostHookService) Option {
 	return func(s *Service) error {
 		for _, hook := range hooks {
@@ -58,6 +58,25 @@ type Service struct {
 	disableMetrics bool
 	log            zerolog.Logger
 	orgs           *organization.Service
+	trainers       []RecordTrainer
+	orphans        OrphanRecorder
+}
+
+// RecordTrainer is trained with the source of each indexed v2 record, e.g. to
+// build spelling models or autocomplete suggestions.
+type RecordTrainer interface {
+	TrainRecord(orgID string, source []byte) error
+}
+
+// OrphanRecorder records the v2 records matched by the orphans query before
+// they are removed from the index, e.g. to keep track of deleted records.
+type OrphanRecorder interface {
+	RecordOrphans(ctx context.Context, indexName, orgID, datasetID string, orphans elastic.Query) error
+	// TombstoneIndex returns the index with the recorded orphans. The
+	// recorded orphan of a record is removed when the record is indexed again.
+	TombstoneIndex(orgID string) (string, error)
+	// ExpireTombstones removes the orphans that were recorded before the time.
+	ExpireTombstones(ctx context.Context, orgID string, before time.Time) error
 }
 
 func NewService(options ...Option) (*Service, error) {
@@ -242,6 +261,9 @@ func (s *Service) dropOrphanGroup(orgID, datasetID string, revision *domainpb.Re
 	v2 = v2.Must(tags)
 	v2 = v2.Must(elastic.NewTermQuery(es.PathDatasetID, datasetID))
 	v2 = v2.Must(elastic.NewTermQuery(es.PathOrgID, orgID))
+
+	s.recordOrphans(&cfg, datasetID, v2)
+
 	indices := []string{cfg.GetDigitalObjectIndexName()}
 
 	if strings.HasPrefix(revision.GetGroupID(), "NT") {
@@ -277,6 +299,59 @@ func (s *Service) dropOrphanGroup(orgID, datasetID string, revision *domainpb.Re
 	return nil
 }
 
+// recordOrphans passes the orphans to the OrphanRecorder when the
+// organization tracks deleted records. Errors are logged, so they never block
+// the removal of the orphans.
+func (s *Service) recordOrphans(cfg *domain.OrganizationConfig, datasetID string, orphans elastic.Query) {
+	if s.orphans == nil || !cfg.OAIPMH.TrackDeleted() {
+		return
+	}
+
+	err := s.orphans.RecordOrphans(context.Background(), cfg.GetIndexName(), cfg.OrgID(), datasetID, orphans)
+	if err != nil {
+		s.log.Error().
+			Err(err).
+			Str("orgID", cfg.OrgID()).
+			Str("datasetID", datasetID).
+			Msg("unable to record deleted records")
+	}
+
+	if expiry := cfg.OAIPMH.DeletedExpiry(); expiry > 0 {
+		if err := s.orphans.ExpireTombstones(context.Background(), cfg.OrgID(), time.Now().Add(-expiry)); err != nil {
+			s.log.Error().
+				Err(err).
+				Str("orgID", cfg.OrgID()).
+				Msg("unable to expire deleted records")
+		}
+	}
+}
+
+// removeTombstone adds the removal of the recorded orphan of the v2 record to
+// the bulk indexer, so a record that is indexed again is no longer reported
+// as deleted.
+func (s *Service) removeTombstone(ctx context.Context, cfg *domain.OrganizationConfig, recordID string) error {
+	if s.orphans == nil || !cfg.OAIPMH.TrackDeleted() {
+		return nil
+	}
+
+	tombstones, err := s.orphans.TombstoneIndex(cfg.OrgID())
+	if err != nil {
+		return err
+	}
+
+	return s.bi.Add(ctx, esutil.BulkIndexerItem{
+		Action:     "delete",
+		Index:      tombstones,
+		DocumentID: recordID,
+		OnFailure: func(ctx context.Context, item esutil.BulkIndexerItem, res esutil.BulkIndexerResponseItem, err error) {
+			// most indexed records have no tombstone
+			if err != nil || res.Status != http.StatusNotFound {
+				log.Error().Err(err).Str("hubID", item.DocumentID).Int("status", res.Status).Msg("unable to remove tombstone")
+			}
+		},
+	})
+}
+
 // dropOrphans is a background function to remove orphans from the index when the timer is expired
 func (s *Service) dropOrphans(orgID, datasetID string, revision *domainpb.Revision) {
 	go func() {
@@ -316,6 +391,10 @@ func (s *Service) dropOrphans(orgID, datasetID string, revision *domainpb.Revisi
 			return
 		}
 
+		if cfg, ok := s.orgs.RetrieveConfig(orgID); ok {
+			s.recordOrphans(&cfg, datasetID, models.OrphanQuery(orgID, datasetID, ds.Revision))
+		}
+
 		if _, err := ds.DropOrphans(context.Background(), nil, nil); err != nil {
 			log.Error().
 				Err(err).
@@ -461,6 +540,18 @@ func (s *Service) submitBulkMsg(ctx context.Context, m *domainpb.IndexMessage) e
 		},
 	}
 
+	if action == "index" && m.GetIndexType() == domainpb.IndexType_V2 {
+		for _, trainer := range s.trainers {
+			if err := trainer.TrainRecord(orgID, m.GetSource()); err != nil {
+				log.Warn().Err(err).Str("hubID", m.GetRecordID()).Msg("unable to train with indexed record")
+			}
+		}
+
+		if err := s.removeTombstone(ctx, &cfg, m.GetRecordID()); err != nil {
+			log.Warn().Err(err).Str("hubID", m.GetRecordID()).Msg("unable to remove tombstone of indexed record")
+		}
+	}
+
 	if m.GetSource() != nil {
 		// Body is an `io.Reader` with the payload
 		bulkMsg.Body = bytes.NewReader(m.GetSource())
@@ -0,0 +1,167 @@
+package lod
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrUnsupportedFormat is returned when the requested format is not supported.
+	ErrUnsupportedFormat = errors.New("unsupported format")
+
+	// ErrNotAcceptable is returned when none of the media types in the Accept
+	// header can be served.
+	ErrNotAcceptable = errors.New("no acceptable media type")
+)
+
+// format is a representation of a resolved resource.
+type format struct {
+	// name is used as the value of the format query parameter
+	name string
+	// mimeType is the Content-Type of the representation
+	mimeType string
+	// alternatives are other media types that are served with this format
+	alternatives []string
+	// aliases are alternative values for the format query parameter
+	aliases []string
+}
+
+// formats are the supported formats. The order determines the preference
+// when media ranges in the Accept header are matched with the same quality.
+var formats = []format{
+	{name: "turtle", mimeType: "text/turtle", aliases: []string{"ttl"}},
+	{name: "json-ld", mimeType: "application/ld+json", alternatives: []string{"application/json"}, aliases: []string{"jsonld"}},
+	{name: "rdfxml", mimeType: "application/rdf+xml", aliases: []string{"rdf", "xml"}},
+	{name: "n-triples", mimeType: "application/n-triples", aliases: []string{"ntriples", "nt"}},
+	{name: "html", mimeType: "text/html", alternatives: []string{"application/xhtml+xml"}},
+}
+
+// lookupFormat returns the format for the value of the format query parameter.
+func lookupFormat(name string) (format, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	for _, f := range formats {
+		if f.name == name {
+			return f, nil
+		}
+
+		for _, alias := range f.aliases {
+			if alias == name {
+				return f, nil
+			}
+		}
+	}
+
+	return format{}, fmt.Errorf("%w: %q", ErrUnsupportedFormat, name)
+}
+
+// mediaRange is a single entry of the Accept header.
+type mediaRange struct {
+	mimeType string
+	q        float64
+}
+
+// parseAccept parses the Accept header into its media ranges in the order of the header.
+func parseAccept(accept string) []mediaRange {
+	ranges := []mediaRange{}
+
+	for _, field := range strings.Split(accept, ",") {
+		parts := strings.Split(field, ";")
+
+		mr := mediaRange{
+			mimeType: strings.ToLower(strings.TrimSpace(parts[0])),
+			q:        1,
+		}
+
+		if mr.mimeType == "" {
+			continue
+		}
+
+		for _, param := range parts[1:] {
+			key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || !strings.EqualFold(strings.TrimSpace(key), "q") {
+				continue
+			}
+
+			q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err == nil {
+				mr.q = q
+			}
+		}
+
+		ranges = append(ranges, mr)
+	}
+
+	return ranges
+}
+
+// match returns the quality and specificity of the media range for the mime-type.
+// The specificity is -1 when the media range does not match.
+func (mr mediaRange) match(mimeType string) (q float64, specificity int) {
+	switch {
+	case mr.mimeType == mimeType:
+		return mr.q, 2
+	case mr.mimeType == "*/*":
+		return mr.q, 0
+	case strings.HasSuffix(mr.mimeType, "/*") &&
+		strings.HasPrefix(mimeType, strings.TrimSuffix(mr.mimeType, "*")):
+		return mr.q, 1
+	}
+
+	return 0, -1
+}
+
+// negotiate returns the format that best matches the Accept header. The first
+// format is returned when the Accept header is empty.
+//
+// The quality of a format is determined by the most specific matching media range.
+// When formats have the same quality, the format whose media range is listed first
+// in the Accept header is selected.
+func negotiate(accept string) (format, error) {
+	ranges := parseAccept(accept)
+	if len(ranges) == 0 {
+		return formats[0], nil
+	}
+
+	var (
+		best         format
+		bestQ        float64
+		bestPosition int
+	)
+
+	for _, f := range formats {
+		q, position := quality(f, ranges)
+		if q <= 0 {
+			continue
+		}
+
+		if q > bestQ || (q == bestQ && position < bestPosition) {
+			best, bestQ, bestPosition = f, q, position
+		}
+	}
+
+	if bestQ <= 0 {
+		return format{}, fmt.Errorf("%w: %q", ErrNotAcceptable, accept)
+	}
+
+	return best, nil
+}
+
+// quality returns the quality of the format and the position of the media range
+// in the Accept header that determined it.
+func quality(f format, ranges []mediaRange) (q float64, position int) {
+	specificity := -1
+
+	for _, mimeType := range append([]string{f.mimeType}, f.alternatives...) {
+		for i, mr := range ranges {
+			rangeQ, s := mr.match(mimeType)
+			if s > specificity || (s == specificity && s >= 0 && rangeQ > q) {
+				q, position, specificity = rangeQ, i, s
+			}
+		}
+	}
+
+	return q, position
+}
@@ -0,0 +1,69 @@
+package lod
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name    string
+		accept  string
+		want    string
+		wantErr error
+	}{
+		{"empty accept", "", "turtle", nil},
+		{"wildcard", "*/*", "turtle", nil},
+		{"turtle", "text/turtle", "turtle", nil},
+		{"json-ld with charset", "application/ld+json; charset=utf-8", "json-ld", nil},
+		{"plain json", "application/json", "json-ld", nil},
+		{"rdfxml", "application/rdf+xml", "rdfxml", nil},
+		{"n-triples", "application/n-triples", "n-triples", nil},
+		{
+			"browser",
+			"text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8",
+			"html",
+			nil,
+		},
+		{"quality", "text/turtle;q=0.5, application/rdf+xml", "rdfxml", nil},
+		{"header order breaks ties", "application/n-triples, text/turtle", "n-triples", nil},
+		{"specific range wins over wildcard", "*/*;q=0.9, text/html;q=0.1", "turtle", nil},
+		{"excluded format", "text/turtle;q=0, */*", "json-ld", nil},
+		{"type wildcard", "application/*", "json-ld", nil},
+		{"not acceptable", "image/png", "", ErrNotAcceptable},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+
+			got, err := negotiate(tt.accept)
+			if tt.wantErr != nil {
+				is.True(errors.Is(err, tt.wantErr))
+				return
+			}
+
+			is.NoErr(err)
+			is.Equal(got.name, tt.want)
+		})
+	}
+}
+
+func TestLookupFormat(t *testing.T) {
+	is := is.New(t)
+
+	f, err := lookupFormat("TTL")
+	is.NoErr(err)
+	is.Equal(f.name, "turtle")
+
+	f, err = lookupFormat("json-ld")
+	is.NoErr(err)
+	is.Equal(f.mimeType, "application/ld+json")
+
+	_, err = lookupFormat("csv")
+	is.True(errors.Is(err, ErrUnsupportedFormat))
+}
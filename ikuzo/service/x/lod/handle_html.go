@@ -0,0 +1,139 @@
+package lod
+
+import (
+	_ "embed"
+	"html/template"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/delving/hub3/ikuzo/rdf"
+)
+
+//go:embed resource.html
+var resourceHTML string
+
+var resourceTemplate = template.Must(template.New("resource").Parse(resourceHTML))
+
+type htmlObject struct {
+	Value    string
+	IsIRI    bool
+	IsBlank  bool
+	Lang     string
+	DataType string
+}
+
+type htmlProperty struct {
+	Predicate string
+	Label     string
+	Objects   []htmlObject
+}
+
+type htmlResource struct {
+	Subject    string
+	IsBlank    bool
+	Properties []*htmlProperty
+}
+
+type htmlAlternate struct {
+	Name string
+	URL  string
+}
+
+type htmlPage struct {
+	Subject    string
+	Resources  []*htmlResource
+	Alternates []htmlAlternate
+}
+
+// renderHTML writes the graph as a HTML page, where the triples are grouped by
+// subject and predicate.
+func renderHTML(w io.Writer, r *http.Request, subj rdf.IRI, g *rdf.Graph) error {
+	nm := g.NamespaceManager
+	if nm == nil {
+		nm = rdf.DefaultNamespaceManager
+	}
+
+	page := htmlPage{Subject: subj.RawValue()}
+
+	for _, f := range formats {
+		if f.name == "html" {
+			continue
+		}
+
+		page.Alternates = append(page.Alternates, htmlAlternate{Name: f.name, URL: formatURL(r, f)})
+	}
+
+	resources := map[string]*htmlResource{}
+	properties := map[string]*htmlProperty{}
+
+	for _, t := range g.Triples() {
+		sKey := t.Subject.String()
+
+		res, ok := resources[sKey]
+		if !ok {
+			res = &htmlResource{
+				Subject: t.Subject.RawValue(),
+				IsBlank: t.Subject.Type() == rdf.TermBlankNode,
+			}
+			resources[sKey] = res
+
+			// the requested subject is always shown first
+			if t.Subject.Equal(subj) {
+				page.Resources = append([]*htmlResource{res}, page.Resources...)
+			} else {
+				page.Resources = append(page.Resources, res)
+			}
+		}
+
+		pKey := sKey + t.Predicate.String()
+
+		prop, ok := properties[pKey]
+		if !ok {
+			prop = &htmlProperty{
+				Predicate: t.Predicate.RawValue(),
+				Label:     label(nm, t.Predicate.RawValue()),
+			}
+			properties[pKey] = prop
+			res.Properties = append(res.Properties, prop)
+		}
+
+		obj := htmlObject{
+			Value:   t.Object.RawValue(),
+			IsIRI:   t.Object.Type() == rdf.TermIRI,
+			IsBlank: t.Object.Type() == rdf.TermBlankNode,
+		}
+
+		if l, ok := t.Object.(rdf.Literal); ok {
+			obj.Lang = strings.TrimPrefix(l.Lang(), "@")
+
+			if !l.DataType.Equal(rdf.IRI{}) && !l.HasImpliedDataType() {
+				obj.DataType = label(nm, l.DataType.RawValue())
+			}
+		}
+
+		prop.Objects = append(prop.Objects, obj)
+	}
+
+	return resourceTemplate.Execute(w, page)
+}
+
+// label returns the prefixed name of the IRI when its namespace is known.
+func label(nm rdf.NamespaceManager, iri string) string {
+	u, err := rdf.NewIRI(iri)
+	if err != nil || nm == nil {
+		return iri
+	}
+
+	base, local := u.Split()
+	if base == "" || local == "" {
+		return iri
+	}
+
+	ns, err := nm.GetWithBase(base)
+	if err != nil || ns.Prefix == "" || ns.Base != base {
+		return iri
+	}
+
+	return ns.Prefix + ":" + local
+}
@@ -1,20 +1,52 @@
 package lod
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/delving/hub3/ikuzo/domain"
 	"github.com/delving/hub3/ikuzo/rdf"
+	"github.com/delving/hub3/ikuzo/rdf/formats/jsonld"
 	"github.com/delving/hub3/ikuzo/rdf/formats/ntriples"
+	"github.com/delving/hub3/ikuzo/rdf/formats/rdfxml"
+	"github.com/delving/hub3/ikuzo/rdf/formats/turtle"
 	"github.com/delving/hub3/ikuzo/render"
 )
 
+// ErrResourceNotFound is returned by a Resolver when the subject is unknown.
+var ErrResourceNotFound = errors.New("lod resource not found")
+
 type Request struct {
 	URI    string
 	Format string
 }
 
+// newRequest returns the Request from the query parameters.
+func newRequest(r *http.Request) (*Request, error) {
+	req := &Request{
+		URI:    r.URL.Query().Get("uri"),
+		Format: r.URL.Query().Get("format"),
+	}
+
+	if req.URI == "" {
+		return nil, fmt.Errorf("uri param cannot be empty")
+	}
+
+	return req, nil
+}
+
+// handleResolve resolves the uri parameter with content negotiation.
+//
+// When the format parameter is empty, the format is negotiated with the Accept
+// header and the client is redirected with '303 See Other' to the document in that
+// format, without resolving the uri. When the format parameter is set the document
+// is returned directly. Both responses link to the alternate formats of the document.
+//
+// Errors of the Resolver, other than ErrResourceNotFound, are returned as
+// '500 Internal Server Error'.
 func (s *Service) handleResolve(w http.ResponseWriter, r *http.Request) {
 	store := r.URL.Query().Get("store")
 	if store == "" {
@@ -30,18 +62,16 @@ func (s *Service) handleResolve(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	uri := r.URL.Query().Get("uri")
-	if uri == "" {
-		render.Error(w, r, fmt.Errorf("uri param cannot be empty"), &render.ErrorConfig{
+	req, err := newRequest(r)
+	if err != nil {
+		render.Error(w, r, err, &render.ErrorConfig{
 			StatusCode: http.StatusBadRequest,
 		})
 
 		return
 	}
 
-	orgID := domain.GetOrganizationID(r)
-
-	subj, err := rdf.NewIRI(uri)
+	subj, err := rdf.NewIRI(req.URI)
 	if err != nil {
 		render.Error(w, r, err, &render.ErrorConfig{
 			StatusCode: http.StatusBadRequest,
@@ -50,16 +80,78 @@ func (s *Service) handleResolve(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var f format
+
+	if req.Format != "" {
+		f, err = lookupFormat(req.Format)
+		if err != nil {
+			render.Error(w, r, err, &render.ErrorConfig{
+				StatusCode: http.StatusBadRequest,
+			})
+
+			return
+		}
+	} else {
+		w.Header().Add("Vary", "Accept")
+
+		f, err = negotiate(r.Header.Get("Accept"))
+		if err != nil {
+			render.Error(w, r, err, &render.ErrorConfig{
+				StatusCode: http.StatusNotAcceptable,
+			})
+
+			return
+		}
+	}
+
+	for _, alt := range formats {
+		w.Header().Add("Link", fmt.Sprintf("<%s>; rel=\"alternate\"; type=\"%s\"", formatURL(r, alt), alt.mimeType))
+	}
+
+	if req.Format == "" {
+		http.Redirect(w, r, formatURL(r, f), http.StatusSeeOther)
+		return
+	}
+
+	orgID := domain.GetOrganizationID(r)
+
 	g, err := resolver.Resolve(r.Context(), orgID, rdf.Subject(subj))
+	if err == nil && (g == nil || g.Len() == 0) {
+		err = fmt.Errorf("%w: %s", ErrResourceNotFound, req.URI)
+	}
+
 	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, ErrResourceNotFound) {
+			status = http.StatusNotFound
+		}
+
 		render.Error(w, r, err, &render.ErrorConfig{
-			StatusCode: http.StatusBadRequest,
+			StatusCode: status,
 		})
 
 		return
 	}
 
-	if err := ntriples.Serialize(g, w); err != nil {
+	var buf bytes.Buffer
+
+	switch f.name {
+	case "turtle":
+		err = turtle.Serialize(g, &buf)
+	case "json-ld":
+		err = jsonld.Serialize(g, &buf, &jsonld.SerializeConfig{
+			Form:         jsonld.Framed,
+			FrameSubject: rdf.Subject(subj),
+		})
+	case "rdfxml":
+		err = rdfxml.Serialize(g, &buf)
+	case "n-triples":
+		err = ntriples.Serialize(g, &buf)
+	case "html":
+		err = renderHTML(&buf, r, subj, g)
+	}
+
+	if err != nil {
 		render.Error(w, r, err, &render.ErrorConfig{
 			StatusCode: http.StatusInternalServerError,
 		})
@@ -67,5 +159,31 @@ func (s *Service) handleResolve(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	render.NTriples(w, r, "")
+	switch f.name {
+	case "turtle":
+		render.Turtle(w, r, buf.String())
+	case "json-ld":
+		render.JSONLD(w, r, buf.String())
+	case "rdfxml":
+		render.RDFXML(w, r, buf.String())
+	case "n-triples":
+		render.NTriples(w, r, buf.String())
+	case "html":
+		render.HTML(w, r, buf.String())
+	}
+}
+
+// formatURL returns the URL of the request with the format parameter set to
+// the format.
+func formatURL(r *http.Request, f format) string {
+	q := r.URL.Query()
+	q.Set("format", f.name)
+
+	var sb strings.Builder
+
+	sb.WriteString(r.URL.Path)
+	sb.WriteString("?")
+	sb.WriteString(q.Encode())
+
+	return sb.String()
 }
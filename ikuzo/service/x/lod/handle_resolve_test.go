@@ -0,0 +1,200 @@
+package lod
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/delving/hub3/ikuzo/domain"
+	"github.com/delving/hub3/ikuzo/rdf"
+	"github.com/matryer/is"
+)
+
+type mockResolver struct {
+	g *rdf.Graph
+}
+
+var errBackend = errors.New("backend unavailable")
+
+func (m *mockResolver) Resolve(ctx context.Context, orgID domain.OrganizationID, s rdf.Subject) (*rdf.Graph, error) {
+	if s.String() == "<http://example.org/id/error>" {
+		return nil, errBackend
+	}
+
+	if !s.Equal(m.g.Triples()[0].Subject) {
+		return nil, ErrResourceNotFound
+	}
+
+	return m.g, nil
+}
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+
+	s, err := rdf.NewIRI("http://example.org/id/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := rdf.DC.IRI("title")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	o, err := rdf.NewLiteralWithLang("Nachtwacht", "nl")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := rdf.NewGraph()
+	g.Add(rdf.NewTriple(s, p, o))
+
+	svc, err := NewService(SetResolver("mock", &mockResolver{g: g}, true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return svc
+}
+
+func TestService_handleResolve(t *testing.T) {
+	svc := newTestService(t)
+
+	tests := []struct {
+		name        string
+		query       string
+		accept      string
+		status      int
+		location    string
+		contentType string
+		body        string
+	}{
+		{
+			name:     "negotiate turtle",
+			query:    "uri=http://example.org/id/1",
+			accept:   "text/turtle",
+			status:   http.StatusSeeOther,
+			location: "/resolve?format=turtle&uri=http%3A%2F%2Fexample.org%2Fid%2F1",
+		},
+		{
+			name:     "negotiate html for browsers",
+			query:    "uri=http://example.org/id/1",
+			accept:   "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+			status:   http.StatusSeeOther,
+			location: "/resolve?format=html&uri=http%3A%2F%2Fexample.org%2Fid%2F1",
+		},
+		{
+			name:   "not acceptable",
+			query:  "uri=http://example.org/id/1",
+			accept: "image/png",
+			status: http.StatusNotAcceptable,
+		},
+		{
+			name:        "turtle",
+			query:       "uri=http://example.org/id/1&format=turtle",
+			status:      http.StatusOK,
+			contentType: "text/turtle",
+			body:        `dc:title "Nachtwacht"@nl .`,
+		},
+		{
+			name:        "json-ld",
+			query:       "uri=http://example.org/id/1&format=json-ld",
+			status:      http.StatusOK,
+			contentType: "application/ld+json",
+			body:        `"@id":"http://example.org/id/1"`,
+		},
+		{
+			name:        "rdfxml",
+			query:       "uri=http://example.org/id/1&format=rdfxml",
+			status:      http.StatusOK,
+			contentType: "application/rdf+xml",
+			body:        `<dc:title xml:lang="nl">Nachtwacht</dc:title>`,
+		},
+		{
+			name:        "n-triples",
+			query:       "uri=http://example.org/id/1&format=nt",
+			status:      http.StatusOK,
+			contentType: "application/n-triples",
+			body:        `<http://example.org/id/1> <http://purl.org/dc/elements/1.1/title> "Nachtwacht"@nl .`,
+		},
+		{
+			name:        "html",
+			query:       "uri=http://example.org/id/1&format=html",
+			status:      http.StatusOK,
+			contentType: "text/html",
+			body:        `<a href="http://purl.org/dc/elements/1.1/title" title="http://purl.org/dc/elements/1.1/title">dc:title</a>`,
+		},
+		{
+			name:   "unknown format",
+			query:  "uri=http://example.org/id/1&format=csv",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "missing uri",
+			query:  "format=turtle",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:     "negotiate without resolving",
+			query:    "uri=http://example.org/id/2",
+			accept:   "text/turtle",
+			status:   http.StatusSeeOther,
+			location: "/resolve?format=turtle&uri=http%3A%2F%2Fexample.org%2Fid%2F2",
+		},
+		{
+			name:   "unknown resource",
+			query:  "uri=http://example.org/id/2&format=turtle",
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "resolver error",
+			query:  "uri=http://example.org/id/error&format=turtle",
+			status: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+
+			r := httptest.NewRequest(http.MethodGet, "/resolve?"+tt.query, nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+
+			w := httptest.NewRecorder()
+			svc.ServeHTTP(w, r)
+
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			is.Equal(resp.StatusCode, tt.status)
+
+			if tt.location != "" {
+				is.Equal(resp.Header.Get("Location"), tt.location)
+				is.Equal(resp.Header.Get("Vary"), "Accept")
+			}
+
+			if tt.status == http.StatusOK || tt.status == http.StatusSeeOther {
+				is.Equal(len(resp.Header.Values("Link")), len(formats))
+				is.True(strings.Contains(resp.Header.Values("Link")[0], `rel="alternate"; type="text/turtle"`))
+			}
+
+			if tt.contentType != "" {
+				is.True(strings.HasPrefix(resp.Header.Get("Content-Type"), tt.contentType))
+			}
+
+			if tt.body != "" {
+				body := w.Body.String()
+				if !strings.Contains(body, tt.body) {
+					t.Errorf("body does not contain %q:\n%s", tt.body, body)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+// Package crosswalk contains the registry of metadata formats that the
+// OAI-PMH server can disseminate.
+//
+// Each metadata prefix is served by a Crosswalk that transforms a stored
+// record into the XML of the format. The default Registry contains graph
+// serializations (rdfxml, edm, ntriples), Go-template based transformations
+// (lido, mods) and the legacy oai_dc output. The EAD passthrough of archive
+// datasets must be registered with the function that opens the stored source.
+package crosswalk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/delving/hub3/ikuzo/service/x/oaipmh"
+)
+
+var ErrUnknownFormat = errors.New("unknown metadata format")
+
+// Crosswalk transforms a Record into the metadata format it serves.
+type Crosswalk interface {
+	// Format is the metadata format that is returned by ListMetadataFormats.
+	Format() oaipmh.MetadataFormat
+	// Tags limits the crosswalk to records that have one of the tags.
+	// When no tags are returned all records can be transformed.
+	Tags() []string
+	// Transform writes the metadata of the record to w. The output must be a
+	// single XML element without an XML declaration.
+	Transform(ctx context.Context, rec *Record, w io.Writer) error
+}
+
+// Accepts returns true when the crosswalk can transform records with the
+// given tags.
+func Accepts(cw Crosswalk, tags []string) bool {
+	required := cw.Tags()
+	if len(required) == 0 {
+		return true
+	}
+
+	for _, tag := range tags {
+		for _, req := range required {
+			if tag == req {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Registry contains the crosswalks by metadata prefix.
+type Registry struct {
+	rw         sync.RWMutex
+	crosswalks map[string]Crosswalk
+	prefixes   []string // in order of registration
+}
+
+// NewRegistry returns an empty Registry with the crosswalks registered.
+func NewRegistry(crosswalks ...Crosswalk) *Registry {
+	r := &Registry{
+		crosswalks: map[string]Crosswalk{},
+	}
+
+	for _, cw := range crosswalks {
+		r.Register(cw)
+	}
+
+	return r
+}
+
+// NewDefaultRegistry returns a Registry with all crosswalks that do not need
+// any configuration.
+func NewDefaultRegistry() *Registry {
+	return NewRegistry(
+		NewRDFXML(),
+		NewOAIDC(),
+		NewEDM(),
+		NewNTriples(),
+		NewLIDO(),
+		NewMODS(),
+	)
+}
+
+// Register adds the crosswalk. A crosswalk with the same metadata prefix is
+// replaced.
+func (r *Registry) Register(cw Crosswalk) {
+	r.rw.Lock()
+	defer r.rw.Unlock()
+
+	prefix := cw.Format().MetadataPrefix
+
+	if _, ok := r.crosswalks[prefix]; !ok {
+		r.prefixes = append(r.prefixes, prefix)
+	}
+
+	r.crosswalks[prefix] = cw
+}
+
+// Get returns the crosswalk for the metadata prefix.
+func (r *Registry) Get(prefix string) (Crosswalk, error) {
+	r.rw.RLock()
+	defer r.rw.RUnlock()
+
+	cw, ok := r.crosswalks[prefix]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, prefix)
+	}
+
+	return cw, nil
+}
+
+// Crosswalks returns the crosswalks in order of registration.
+func (r *Registry) Crosswalks() []Crosswalk {
+	r.rw.RLock()
+	defer r.rw.RUnlock()
+
+	crosswalks := make([]Crosswalk, 0, len(r.prefixes))
+
+	for _, prefix := range r.prefixes {
+		crosswalks = append(crosswalks, r.crosswalks[prefix])
+	}
+
+	return crosswalks
+}
+
+// Formats returns the metadata formats in order of registration.
+func (r *Registry) Formats() []oaipmh.MetadataFormat {
+	return r.formats(func(Crosswalk) bool { return true })
+}
+
+// FormatsFor returns the metadata formats of the crosswalks that accept
+// records with the tags.
+func (r *Registry) FormatsFor(tags []string) []oaipmh.MetadataFormat {
+	return r.formats(func(cw Crosswalk) bool { return Accepts(cw, tags) })
+}
+
+func (r *Registry) formats(include func(Crosswalk) bool) []oaipmh.MetadataFormat {
+	r.rw.RLock()
+	defer r.rw.RUnlock()
+
+	formats := []oaipmh.MetadataFormat{}
+
+	for _, prefix := range r.prefixes {
+		cw := r.crosswalks[prefix]
+
+		if include(cw) {
+			formats = append(formats, cw.Format())
+		}
+	}
+
+	return formats
+}
@@ -0,0 +1,245 @@
+package crosswalk
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/delving/hub3/hub3/fragments"
+	"github.com/delving/hub3/ikuzo/rdf"
+	"github.com/delving/hub3/ikuzo/service/x/oaipmh"
+	"github.com/matryer/is"
+)
+
+func testRecord(t *testing.T) *Record {
+	t.Helper()
+
+	g := rdf.NewGraph()
+
+	iri := func(s string) rdf.IRI {
+		i, err := rdf.NewIRI(expand(s))
+		if err != nil {
+			t.Fatalf("NewIRI(%q) error = %v", s, err)
+		}
+
+		return i
+	}
+
+	literal := func(s string) rdf.Literal {
+		l, err := rdf.NewLiteral(s)
+		if err != nil {
+			t.Fatalf("NewLiteral(%q) error = %v", s, err)
+		}
+
+		return l
+	}
+
+	cho := iri("http://data.hub3.org/ds1/1")
+	agg := iri("http://data.hub3.org/ds1/1/aggregation")
+	agent := iri("http://data.hub3.org/agent/rembrandt")
+
+	g.AddTriple(cho, rdf.IsA, iri("edm:ProvidedCHO"))
+	g.AddTriple(cho, iri("dc:title"), literal("Night Watch & Co"))
+	g.AddTriple(cho, iri("dc:creator"), agent)
+	g.AddTriple(cho, iri("dc:date"), literal("1642"))
+	g.AddTriple(cho, iri("dc:subject"), literal("militia"))
+	g.AddTriple(cho, iri("nave:private"), literal("internal"))
+	g.AddTriple(agent, iri("skos:prefLabel"), literal("Rembrandt"))
+	g.AddTriple(agg, rdf.IsA, iri("ore:Aggregation"))
+	g.AddTriple(agg, iri("edm:aggregatedCHO"), cho)
+	g.AddTriple(agg, iri("edm:dataProvider"), literal("Rijksmuseum"))
+	g.AddTriple(agg, iri("edm:isShownBy"), iri("http://images.hub3.org/1.jpg"))
+	g.AddTriple(agg, iri("edm:rights"), iri("http://creativecommons.org/publicdomain/mark/1.0/"))
+	g.AddTriple(iri("urn:private/1"), iri("dc:title"), literal("private"))
+
+	return NewGraphRecord(&fragments.Header{
+		OrgID:    "hub3",
+		Spec:     "ds1",
+		HubID:    "hub3_ds1_1",
+		EntryURI: cho.RawValue(),
+	}, g)
+}
+
+func transform(t *testing.T, cw Crosswalk, rec *Record) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	if err := cw.Transform(context.TODO(), rec, &buf); err != nil {
+		t.Fatalf("Transform() error = %v", err)
+	}
+
+	return buf.String()
+}
+
+// wellFormed returns an error when the output is not a single well-formed
+// XML element.
+func wellFormed(output string) error {
+	var root struct {
+		XMLName xml.Name
+		Inner   []byte `xml:",innerxml"`
+	}
+
+	return xml.Unmarshal([]byte(output), &root)
+}
+
+func TestRegistry(t *testing.T) {
+	is := is.New(t)
+
+	r := NewDefaultRegistry()
+
+	prefixes := []string{}
+	for _, format := range r.Formats() {
+		prefixes = append(prefixes, format.MetadataPrefix)
+	}
+
+	is.Equal(prefixes, []string{"rdfxml", "oai_dc", "edm", "ntriples", "lido", "mods"})
+
+	_, err := r.Get("marc21")
+	is.True(errors.Is(err, ErrUnknownFormat))
+
+	r.Register(NewEAD(nil))
+
+	cw, err := r.Get("ead")
+	is.NoErr(err)
+	is.Equal(cw.Format().MetadataNamespace, "urn:isbn:1-931666-22-9")
+
+	is.Equal(len(r.Formats()), 7)
+	is.Equal(len(r.FormatsFor([]string{"ead", "eadDesc"})), 7)
+	is.Equal(len(r.FormatsFor([]string{"mdr"})), 6) // ead only applies to archive descriptions
+	is.Equal(len(r.FormatsFor(nil)), 6)
+
+	r.Register(NewGraphCrosswalk(oaipmh.MetadataFormat{MetadataPrefix: "ead"}, nil))
+	is.Equal(len(r.Formats()), 7) // replaced
+
+	crosswalks := r.Crosswalks()
+	is.Equal(len(crosswalks), 7)
+	is.Equal(crosswalks[6].Format().MetadataPrefix, "ead")
+}
+
+func TestCrosswalks(t *testing.T) {
+	tests := []struct {
+		name     string
+		cw       Crosswalk
+		contains []string
+		excludes []string
+	}{
+		{
+			"edm",
+			NewEDM(),
+			[]string{"Night Watch &amp; Co", "http://images.hub3.org/1.jpg", "ProvidedCHO"},
+			[]string{"<?xml", "urn:private", "internal"},
+		},
+		{
+			"lido",
+			NewLIDO(),
+			[]string{
+				`<lido:lidoRecID lido:type="local">hub3_ds1_1</lido:lidoRecID>`,
+				`<lido:appellationValue lido:pref="preferred">Night Watch &amp; Co</lido:appellationValue>`,
+				`<lido:displayActorInRole>Rembrandt</lido:displayActorInRole>`,
+				`<lido:displayDate>1642</lido:displayDate>`,
+				`<lido:appellationValue>Rijksmuseum</lido:appellationValue>`,
+				`<lido:linkResource>http://images.hub3.org/1.jpg</lido:linkResource>`,
+				`<lido:term>Object</lido:term>`,
+				`xml:lang="und"`,
+			},
+			[]string{"private"},
+		},
+		{
+			"mods",
+			NewMODS(),
+			[]string{
+				`<mods:title>Night Watch &amp; Co</mods:title>`,
+				`<mods:namePart>Rembrandt</mods:namePart>`,
+				`<mods:dateOther>1642</mods:dateOther>`,
+				`<mods:topic>militia</mods:topic>`,
+				`<mods:url access="raw object">http://images.hub3.org/1.jpg</mods:url>`,
+				`xlink:href="http://creativecommons.org/publicdomain/mark/1.0/"`,
+				`<mods:recordContentSource>Rijksmuseum</mods:recordContentSource>`,
+			},
+			[]string{"<mods:physicalDescription>", "private"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+
+			output := transform(t, tt.cw, testRecord(t))
+			is.NoErr(wellFormed(output))
+
+			for _, want := range tt.contains {
+				if !strings.Contains(output, want) {
+					t.Errorf("%s output does not contain %q\n%s", tt.name, want, output)
+				}
+			}
+
+			for _, unwanted := range tt.excludes {
+				if strings.Contains(output, unwanted) {
+					t.Errorf("%s output contains %q\n%s", tt.name, unwanted, output)
+				}
+			}
+		})
+	}
+}
+
+func TestNTriples(t *testing.T) {
+	is := is.New(t)
+
+	output := transform(t, NewNTriples(), testRecord(t))
+	is.True(strings.HasPrefix(output, "<![CDATA[\n"))
+	is.True(strings.HasSuffix(output, "]]>"))
+	is.True(strings.Contains(output, `"Night Watch & Co"`))
+}
+
+func TestEAD(t *testing.T) {
+	is := is.New(t)
+
+	source := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE ead PUBLIC "+//ISBN 1-931666-00-8//DTD ead.dtd (Encoded Archival Description (EAD) Version 2002)//EN" "ead.dtd">
+<ead xmlns="urn:isbn:1-931666-22-9"><eadheader/></ead>`
+
+	var opened string
+
+	cw := NewEAD(func(ctx context.Context, orgID, datasetID string) (io.ReadCloser, error) {
+		opened = orgID + "/" + datasetID
+		return io.NopCloser(strings.NewReader(source)), nil
+	})
+
+	is.Equal(cw.Tags(), []string{"eadDesc"})
+
+	output := transform(t, cw, testRecord(t))
+	is.Equal(opened, "hub3/ds1")
+	is.Equal(output, `<ead xmlns="urn:isbn:1-931666-22-9"><eadheader/></ead>`)
+}
+
+func TestStripProlog(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{"no prolog", "<a/>", "<a/>"},
+		{"declaration", "<?xml version=\"1.0\"?>\n<a/>", "<a/>"},
+		{"byte order mark", "\ufeff<?xml version=\"1.0\"?><a/>", "<a/>"},
+		{"comment and pi", "<!-- c --><?pi x?>\n<a/>", "<a/>"},
+		{"doctype with internal subset", "<!DOCTYPE a [<!ENTITY e \"x\">]>\n<a/>", "<a/>"},
+		{"unterminated", "<?xml version=\"1.0\"", "<?xml version=\"1.0\""},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(stripProlog([]byte(tt.doc))); got != tt.want {
+				t.Errorf("stripProlog() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
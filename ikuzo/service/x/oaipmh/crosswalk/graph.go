@@ -0,0 +1,159 @@
+package crosswalk
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/delving/hub3/ikuzo/rdf"
+	"github.com/delving/hub3/ikuzo/rdf/formats/mappingxml"
+	"github.com/delving/hub3/ikuzo/rdf/formats/ntriples"
+	"github.com/delving/hub3/ikuzo/rdf/formats/rdfxml"
+	"github.com/delving/hub3/ikuzo/service/x/oaipmh"
+)
+
+// privatePrefix is the prefix of the resources that are never disseminated.
+const privatePrefix = "urn:private"
+
+// GraphFunc writes the rdf.Graph of the record to w.
+type GraphFunc func(rec *Record, g *rdf.Graph, w io.Writer) error
+
+// graphCrosswalk is a Crosswalk that serializes the rdf.Graph of a record.
+type graphCrosswalk struct {
+	format    oaipmh.MetadataFormat
+	serialize GraphFunc
+}
+
+// NewGraphCrosswalk returns a Crosswalk that transforms the rdf.Graph of a
+// record with fn.
+func NewGraphCrosswalk(format oaipmh.MetadataFormat, fn GraphFunc) Crosswalk {
+	return &graphCrosswalk{
+		format:    format,
+		serialize: fn,
+	}
+}
+
+func (gc *graphCrosswalk) Format() oaipmh.MetadataFormat {
+	return gc.format
+}
+
+func (gc *graphCrosswalk) Tags() []string {
+	return nil
+}
+
+func (gc *graphCrosswalk) Transform(ctx context.Context, rec *Record, w io.Writer) error {
+	g, err := rec.Graph()
+	if err != nil {
+		return fmt.Errorf("unable to get rdf.Graph of %s; %w", rec.Meta.GetHubID(), err)
+	}
+
+	return gc.serialize(rec, g, w)
+}
+
+// NewRDFXML returns the crosswalk to the nested XML of the record that is
+// rooted at the entry resource.
+func NewRDFXML() Crosswalk {
+	return NewGraphCrosswalk(
+		oaipmh.MetadataFormat{
+			MetadataPrefix:    "rdfxml",
+			MetadataNamespace: "http://www.w3.org/1999/02/22-rdf-syntax-ns#",
+		},
+		serializeMappingXML,
+	)
+}
+
+// NewOAIDC returns the oai_dc crosswalk.
+//
+// For backwards compatibility it returns the same output as NewRDFXML.
+func NewOAIDC() Crosswalk {
+	return NewGraphCrosswalk(
+		oaipmh.MetadataFormat{
+			MetadataPrefix:    "oai_dc",
+			Schema:            "http://www.openarchives.org/OAI/2.0/oai_dc.xsd",
+			MetadataNamespace: "http://www.openarchives.org/OAI/2.0/oai_dc/",
+		},
+		serializeMappingXML,
+	)
+}
+
+func serializeMappingXML(rec *Record, g *rdf.Graph, w io.Writer) error {
+	iri, err := rdf.NewIRI(rec.Meta.GetEntryURI())
+	if err != nil {
+		return err
+	}
+
+	cfg := &mappingxml.FilterConfig{
+		Subject:         iri,
+		URIPrefixFilter: privatePrefix,
+	}
+
+	return mappingxml.Serialize(g, w, cfg)
+}
+
+// NewEDM returns the crosswalk to EDM-external: the RDF/XML of the public
+// triples of the record. Private resources and the Delving 'nave'
+// annotations are removed.
+func NewEDM() Crosswalk {
+	return NewGraphCrosswalk(
+		oaipmh.MetadataFormat{
+			MetadataPrefix:    "edm",
+			Schema:            "http://www.europeana.eu/schemas/edm/EDM.xsd",
+			MetadataNamespace: "http://www.europeana.eu/schemas/edm/",
+		},
+		serializeEDM,
+	)
+}
+
+func serializeEDM(rec *Record, g *rdf.Graph, w io.Writer) error {
+	external := rdf.NewGraph()
+	external.NamespaceManager = g.NamespaceManager
+
+	nave := prefixes["nave"]
+
+	for _, t := range g.Triples() {
+		if strings.HasPrefix(t.Subject.RawValue(), privatePrefix) ||
+			strings.HasPrefix(t.Object.RawValue(), privatePrefix) ||
+			strings.HasPrefix(t.Predicate.RawValue(), nave) {
+			continue
+		}
+
+		external.Add(t)
+	}
+
+	var buf bytes.Buffer
+
+	if err := rdfxml.Serialize(external, &buf); err != nil {
+		return err
+	}
+
+	_, err := w.Write(stripProlog(buf.Bytes()))
+
+	return err
+}
+
+// NewNTriples returns the crosswalk to N-Triples wrapped in a CDATA section.
+func NewNTriples() Crosswalk {
+	return NewGraphCrosswalk(
+		oaipmh.MetadataFormat{
+			MetadataPrefix:    "ntriples",
+			MetadataNamespace: "http://www.w3.org/ns/formats/N-Triples",
+		},
+		serializeNTriples,
+	)
+}
+
+func serializeNTriples(rec *Record, g *rdf.Graph, w io.Writer) error {
+	if _, err := io.WriteString(w, "<![CDATA[\n"); err != nil {
+		return err
+	}
+
+	if err := ntriples.Serialize(g, w); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "]]>")
+
+	return err
+}
@@ -0,0 +1,304 @@
+package crosswalk
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/delving/hub3/hub3/fragments"
+	"github.com/delving/hub3/ikuzo/rdf"
+)
+
+// prefixes are the namespace prefixes that can be used in the predicates of
+// the Resource lookups.
+var prefixes = map[string]string{
+	"dc":      "http://purl.org/dc/elements/1.1/",
+	"dcterms": "http://purl.org/dc/terms/",
+	"edm":     "http://www.europeana.eu/schemas/edm/",
+	"foaf":    "http://xmlns.com/foaf/0.1/",
+	"nave":    "http://schemas.delving.eu/nave/terms/",
+	"ore":     "http://www.openarchives.org/ore/terms/",
+	"rdf":     "http://www.w3.org/1999/02/22-rdf-syntax-ns#",
+	"rdfs":    "http://www.w3.org/2000/01/rdf-schema#",
+	"skos":    "http://www.w3.org/2004/02/skos/core#",
+}
+
+// expand returns the full IRI of a prefixed predicate like 'dc:title'.
+// Full IRIs and unknown prefixes are returned unchanged.
+func expand(predicate string) string {
+	prefix, label, ok := strings.Cut(predicate, ":")
+	if !ok {
+		return predicate
+	}
+
+	base, ok := prefixes[prefix]
+	if !ok {
+		return predicate
+	}
+
+	return base + label
+}
+
+// Record is a stored record that is transformed by a Crosswalk.
+type Record struct {
+	Meta  *fragments.Header
+	fg    *fragments.FragmentGraph
+	graph *rdf.Graph
+}
+
+// NewRecord returns the Record of a FragmentGraph. The rdf.Graph is only
+// created when a crosswalk needs it.
+func NewRecord(fg *fragments.FragmentGraph) *Record {
+	meta := fg.Meta
+	if meta == nil {
+		meta = &fragments.Header{}
+	}
+
+	return &Record{
+		Meta: meta,
+		fg:   fg,
+	}
+}
+
+// NewGraphRecord returns the Record of a graph that is not stored as a
+// FragmentGraph, e.g. the N-Triples of the file record store.
+func NewGraphRecord(meta *fragments.Header, g *rdf.Graph) *Record {
+	if meta == nil {
+		meta = &fragments.Header{}
+	}
+
+	return &Record{
+		Meta:  meta,
+		graph: g,
+	}
+}
+
+// Graph returns the rdf.Graph of the record.
+func (r *Record) Graph() (*rdf.Graph, error) {
+	if r.graph != nil {
+		return r.graph, nil
+	}
+
+	if r.fg == nil {
+		return nil, fmt.Errorf("record %s has no graph", r.Meta.GetHubID())
+	}
+
+	g, err := r.fg.Graph()
+	if err != nil {
+		return nil, err
+	}
+
+	r.graph = g
+
+	return g, nil
+}
+
+// Resource is a read-only view of a resource in the graph of a Record for use
+// in templates. The zero Resource has no values.
+type Resource struct {
+	rsc *rdf.Resource
+	g   *rdf.Graph
+}
+
+// ID returns the subject of the resource.
+func (r Resource) ID() string {
+	if r.rsc == nil {
+		return ""
+	}
+
+	return r.rsc.Subject().RawValue()
+}
+
+// objects returns the sorted objects of the predicate.
+func (r Resource) objects(predicate string) []rdf.Object {
+	if r.rsc == nil {
+		return nil
+	}
+
+	iri := expand(predicate)
+
+	for p, rp := range r.rsc.Predicates() {
+		if p.RawValue() == iri {
+			return rp.Objects()
+		}
+	}
+
+	return nil
+}
+
+// Values returns the values of the predicate. Objects that are resources in
+// the graph are returned by their skos:prefLabel or rdfs:label when present.
+func (r Resource) Values(predicate string) []string {
+	values := []string{}
+
+	for _, obj := range r.objects(predicate) {
+		if linked, ok := r.resource(obj); ok {
+			if label := linked.label(); label != "" {
+				values = append(values, label)
+				continue
+			}
+		}
+
+		values = append(values, obj.RawValue())
+	}
+
+	return values
+}
+
+// Value returns the first value of the predicate.
+func (r Resource) Value(predicate string) string {
+	values := r.Values(predicate)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+// IRIs returns the IRI objects of the predicate without resolving labels.
+func (r Resource) IRIs(predicate string) []string {
+	iris := []string{}
+
+	for _, obj := range r.objects(predicate) {
+		if obj.Type() == rdf.TermIRI {
+			iris = append(iris, obj.RawValue())
+		}
+	}
+
+	return iris
+}
+
+// IRI returns the first IRI object of the predicate.
+func (r Resource) IRI(predicate string) string {
+	iris := r.IRIs(predicate)
+	if len(iris) == 0 {
+		return ""
+	}
+
+	return iris[0]
+}
+
+// Linked returns the objects of the predicate that are resources in the
+// graph.
+func (r Resource) Linked(predicate string) []Resource {
+	linked := []Resource{}
+
+	for _, obj := range r.objects(predicate) {
+		if rsc, ok := r.resource(obj); ok {
+			linked = append(linked, rsc)
+		}
+	}
+
+	return linked
+}
+
+func (r Resource) resource(obj rdf.Object) (Resource, bool) {
+	subject, ok := obj.(rdf.Subject)
+	if !ok || r.g == nil {
+		return Resource{}, false
+	}
+
+	rsc, ok := r.g.Get(subject)
+	if !ok {
+		return Resource{}, false
+	}
+
+	return Resource{rsc: rsc, g: r.g}, true
+}
+
+func (r Resource) label() string {
+	for _, predicate := range []string{"skos:prefLabel", "rdfs:label"} {
+		for _, obj := range r.objects(predicate) {
+			if obj.Type() == rdf.TermLiteral {
+				return obj.RawValue()
+			}
+		}
+	}
+
+	return ""
+}
+
+// hasType returns true when the resource has the rdf:type.
+func (r Resource) hasType(iri string) bool {
+	if r.rsc == nil {
+		return false
+	}
+
+	for _, t := range r.rsc.Types() {
+		if t.RawValue() == iri {
+			return true
+		}
+	}
+
+	return false
+}
+
+// view is the template data of a Record.
+//
+// The provided cultural heritage object (CHO) is the entry resource of the
+// record. The Aggregation is the ore:Aggregation of the CHO and WebResources
+// contains all edm:WebResources in the graph.
+type view struct {
+	Meta         *fragments.Header
+	CHO          Resource
+	Aggregation  Resource
+	WebResources []Resource
+}
+
+func newView(rec *Record) (*view, error) {
+	g, err := rec.Graph()
+	if err != nil {
+		return nil, err
+	}
+
+	v := &view{
+		Meta:         rec.Meta,
+		WebResources: []Resource{},
+	}
+
+	entry, err := rdf.NewIRI(rec.Meta.GetEntryURI())
+	if err == nil {
+		if rsc, ok := g.Get(entry); ok {
+			v.CHO = Resource{rsc: rsc, g: g}
+		}
+	}
+
+	resources := sortedResources(g)
+
+	if v.CHO.rsc == nil {
+		for _, rsc := range resources {
+			if rsc.hasType(expand("edm:ProvidedCHO")) {
+				v.CHO = rsc
+				break
+			}
+		}
+	}
+
+	for _, rsc := range resources {
+		switch {
+		case rsc.hasType(expand("ore:Aggregation")):
+			if v.Aggregation.rsc == nil || rsc.IRI("edm:aggregatedCHO") == v.CHO.ID() {
+				v.Aggregation = rsc
+			}
+		case rsc.hasType(expand("edm:WebResource")):
+			v.WebResources = append(v.WebResources, rsc)
+		}
+	}
+
+	return v, nil
+}
+
+// sortedResources returns the resources of the graph in the order of their
+// subject, so the output of the templates is stable.
+func sortedResources(g *rdf.Graph) []Resource {
+	resources := make([]Resource, 0, len(g.Resources()))
+	for _, rsc := range g.Resources() {
+		resources = append(resources, Resource{rsc: rsc, g: g})
+	}
+
+	sort.Slice(resources, func(i, j int) bool {
+		return resources[i].ID() < resources[j].ID()
+	})
+
+	return resources
+}
@@ -0,0 +1,106 @@
+package crosswalk
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/delving/hub3/ikuzo/service/x/oaipmh"
+)
+
+// SourceFunc opens the stored source of a dataset.
+type SourceFunc func(ctx context.Context, orgID, datasetID string) (io.ReadCloser, error)
+
+// sourceCrosswalk is a Crosswalk that passes the stored source of the dataset
+// of a record through.
+type sourceCrosswalk struct {
+	format oaipmh.MetadataFormat
+	tags   []string
+	open   SourceFunc
+}
+
+// NewSourceCrosswalk returns a Crosswalk that returns the source of the
+// dataset for the records with one of the tags.
+func NewSourceCrosswalk(format oaipmh.MetadataFormat, open SourceFunc, tags ...string) Crosswalk {
+	return &sourceCrosswalk{
+		format: format,
+		tags:   tags,
+		open:   open,
+	}
+}
+
+// NewEAD returns the passthrough of the stored EAD of archive datasets. Only
+// the archive description record (tag 'eadDesc') of a dataset is
+// disseminated in this format.
+func NewEAD(open SourceFunc) Crosswalk {
+	return NewSourceCrosswalk(
+		oaipmh.MetadataFormat{
+			MetadataPrefix:    "ead",
+			Schema:            "http://www.loc.gov/ead/ead.xsd",
+			MetadataNamespace: "urn:isbn:1-931666-22-9",
+		},
+		open,
+		"eadDesc",
+	)
+}
+
+func (sc *sourceCrosswalk) Format() oaipmh.MetadataFormat {
+	return sc.format
+}
+
+func (sc *sourceCrosswalk) Tags() []string {
+	return sc.tags
+}
+
+func (sc *sourceCrosswalk) Transform(ctx context.Context, rec *Record, w io.Writer) error {
+	r, err := sc.open(ctx, rec.Meta.GetOrgID(), rec.Meta.GetSpec())
+	if err != nil {
+		return fmt.Errorf("unable to open %s source of %s; %w", sc.format.MetadataPrefix, rec.Meta.GetSpec(), err)
+	}
+	defer r.Close()
+
+	source, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(stripProlog(source))
+
+	return err
+}
+
+// stripProlog removes the XML declaration, processing instructions, comments
+// and document type declaration before the root element, so the document
+// can be embedded in the metadata element of an OAI-PMH record.
+func stripProlog(doc []byte) []byte {
+	for {
+		doc = bytes.TrimLeft(doc, " \t\r\n\ufeff")
+
+		var end []byte
+
+		switch {
+		case bytes.HasPrefix(doc, []byte("<?")):
+			end = []byte("?>")
+		case bytes.HasPrefix(doc, []byte("<!--")):
+			end = []byte("-->")
+		case bytes.HasPrefix(doc, []byte("<!")):
+			end = []byte(">")
+
+			// skip the internal subset of the document type declaration
+			subset := bytes.IndexByte(doc, '[')
+			if subset != -1 && subset < bytes.IndexByte(doc, '>') {
+				end = []byte("]>")
+			}
+		default:
+			return doc
+		}
+
+		idx := bytes.Index(doc, end)
+		if idx == -1 {
+			return doc
+		}
+
+		doc = doc[idx+len(end):]
+	}
+}
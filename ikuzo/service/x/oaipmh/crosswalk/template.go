@@ -0,0 +1,119 @@
+package crosswalk
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"text/template"
+
+	"github.com/delving/hub3/ikuzo/service/x/oaipmh"
+)
+
+//go:embed templates
+var templates embed.FS
+
+// funcs are the functions that are available in the crosswalk templates.
+var funcs = template.FuncMap{
+	"xml": escapeXML,
+	"default": func(fallback, value string) string {
+		if value == "" {
+			return fallback
+		}
+
+		return value
+	},
+}
+
+func escapeXML(s string) (string, error) {
+	var buf bytes.Buffer
+	if err := xml.EscapeText(&buf, []byte(s)); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// templateCrosswalk is a Crosswalk that executes a Go template with the view
+// of the record.
+type templateCrosswalk struct {
+	format oaipmh.MetadataFormat
+	tmpl   *template.Template
+}
+
+// NewTemplateCrosswalk returns a Crosswalk that executes the Go template
+// text for each record.
+//
+// The template data has the fields Meta (the *fragments.Header), CHO,
+// Aggregation and WebResources. Their values are read with Values, Value,
+// IRIs, IRI and Linked by predicate, e.g. '{{ .CHO.Value "dc:title" | xml }}'.
+// Values must be escaped with the 'xml' function.
+func NewTemplateCrosswalk(format oaipmh.MetadataFormat, text string) (Crosswalk, error) {
+	tmpl, err := template.New(format.MetadataPrefix).Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse template for %s; %w", format.MetadataPrefix, err)
+	}
+
+	return &templateCrosswalk{
+		format: format,
+		tmpl:   tmpl,
+	}, nil
+}
+
+// mustTemplateCrosswalk returns the crosswalk of an embedded template.
+func mustTemplateCrosswalk(format oaipmh.MetadataFormat, name string) Crosswalk {
+	text, err := templates.ReadFile("templates/" + name)
+	if err != nil {
+		panic(err)
+	}
+
+	cw, err := NewTemplateCrosswalk(format, string(text))
+	if err != nil {
+		panic(err)
+	}
+
+	return cw
+}
+
+func (tc *templateCrosswalk) Format() oaipmh.MetadataFormat {
+	return tc.format
+}
+
+func (tc *templateCrosswalk) Tags() []string {
+	return nil
+}
+
+func (tc *templateCrosswalk) Transform(ctx context.Context, rec *Record, w io.Writer) error {
+	v, err := newView(rec)
+	if err != nil {
+		return fmt.Errorf("unable to get rdf.Graph of %s; %w", rec.Meta.GetHubID(), err)
+	}
+
+	return tc.tmpl.Execute(w, v)
+}
+
+// NewLIDO returns the crosswalk from EDM to LIDO 1.0.
+func NewLIDO() Crosswalk {
+	return mustTemplateCrosswalk(
+		oaipmh.MetadataFormat{
+			MetadataPrefix:    "lido",
+			Schema:            "http://www.lido-schema.org/schema/v1.0/lido-v1.0.xsd",
+			MetadataNamespace: "http://www.lido-schema.org",
+		},
+		"lido.xml.tmpl",
+	)
+}
+
+// NewMODS returns the crosswalk from EDM to MODS 3.7.
+func NewMODS() Crosswalk {
+	return mustTemplateCrosswalk(
+		oaipmh.MetadataFormat{
+			MetadataPrefix:    "mods",
+			Schema:            "http://www.loc.gov/standards/mods/v3/mods-3-7.xsd",
+			MetadataNamespace: "http://www.loc.gov/mods/v3",
+		},
+		"mods.xml.tmpl",
+	)
+}
@@ -0,0 +1,98 @@
+package oaipmh
+
+import (
+	"fmt"
+	"time"
+)
+
+// Granularities of the datestamps that are supported for selective harvesting.
+const (
+	GranularityDay     = "YYYY-MM-DD"
+	GranularitySeconds = "YYYY-MM-DDThh:mm:ssZ"
+)
+
+const (
+	dayLayout     = "2006-01-02"
+	secondsLayout = "2006-01-02T15:04:05Z"
+)
+
+// ParseDatestamp parses a UTC datestamp in day or seconds granularity and
+// returns the granularity of the datestamp.
+func ParseDatestamp(datestamp string) (t time.Time, granularity string, err error) {
+	switch len(datestamp) {
+	case len(dayLayout):
+		t, err = time.Parse(dayLayout, datestamp)
+		return t, GranularityDay, err
+	case len(secondsLayout):
+		t, err = time.Parse(secondsLayout, datestamp)
+		return t, GranularitySeconds, err
+	}
+
+	return t, "", fmt.Errorf("invalid datestamp %q", datestamp)
+}
+
+// DateRange is the datestamp range of a selective harvest. Both bounds are
+// inclusive. A zero From or Until means the range is open on that side.
+type DateRange struct {
+	From  time.Time
+	Until time.Time
+}
+
+// IsZero returns true when the range does not limit the records.
+func (dr DateRange) IsZero() bool {
+	return dr.From.IsZero() && dr.Until.IsZero()
+}
+
+// Contains returns true when t is within the range.
+func (dr DateRange) Contains(t time.Time) bool {
+	if !dr.From.IsZero() && t.Before(dr.From) {
+		return false
+	}
+
+	if !dr.Until.IsZero() && t.After(dr.Until) {
+		return false
+	}
+
+	return true
+}
+
+// DateRange returns the range of the from and until arguments of the request.
+//
+// The until bound includes the whole day or second of its datestamp, so a
+// record with the datestamp of until is always part of the range.
+// ErrBadArgument is returned when a datestamp is invalid, when from and until
+// have a different granularity or when from is later than until.
+func (request *Request) DateRange() (dr DateRange, err error) {
+	var fromGranularity, untilGranularity string
+
+	if request.From != "" {
+		dr.From, fromGranularity, err = ParseDatestamp(request.From)
+		if err != nil {
+			return dr, ErrBadArgument
+		}
+	}
+
+	if request.Until != "" {
+		var until time.Time
+
+		until, untilGranularity, err = ParseDatestamp(request.Until)
+		if err != nil {
+			return dr, ErrBadArgument
+		}
+
+		switch untilGranularity {
+		case GranularityDay:
+			dr.Until = until.Add(24*time.Hour - time.Nanosecond)
+		default:
+			dr.Until = until.Add(time.Second - time.Nanosecond)
+		}
+	}
+
+	if request.From != "" && request.Until != "" {
+		if fromGranularity != untilGranularity || dr.From.After(dr.Until) {
+			return dr, ErrBadArgument
+		}
+	}
+
+	return dr, nil
+}
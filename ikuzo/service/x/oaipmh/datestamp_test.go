@@ -0,0 +1,105 @@
+package oaipmh
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRequest_DateRange(t *testing.T) {
+	date := func(s string) time.Time {
+		d, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			t.Fatalf("unable to parse %s: %s", s, err)
+		}
+
+		return d
+	}
+
+	tests := []struct {
+		name    string
+		from    string
+		until   string
+		want    DateRange
+		wantErr error
+	}{
+		{"no range", "", "", DateRange{}, nil},
+		{
+			"day granularity",
+			"2021-01-01",
+			"2021-01-31",
+			DateRange{From: date("2021-01-01T00:00:00Z"), Until: date("2021-01-31T23:59:59.999999999Z")},
+			nil,
+		},
+		{
+			"seconds granularity",
+			"2021-01-01T10:00:00Z",
+			"2021-01-01T11:30:15Z",
+			DateRange{From: date("2021-01-01T10:00:00Z"), Until: date("2021-01-01T11:30:15.999999999Z")},
+			nil,
+		},
+		{"only from", "2021-01-01", "", DateRange{From: date("2021-01-01T00:00:00Z")}, nil},
+		{"only until", "", "2021-01-01", DateRange{Until: date("2021-01-01T23:59:59.999999999Z")}, nil},
+		{"same day", "2021-01-01", "2021-01-01", DateRange{From: date("2021-01-01T00:00:00Z"), Until: date("2021-01-01T23:59:59.999999999Z")}, nil},
+		{"invalid date", "2021-13-01", "", DateRange{}, ErrBadArgument},
+		{"time zone offset", "2021-01-01T10:00:00+02:00", "", DateRange{}, ErrBadArgument},
+		{"fractional seconds", "", "2021-01-01T10:00:00.5Z", DateRange{}, ErrBadArgument},
+		{"mixed granularity", "2021-01-01", "2021-01-02T10:00:00Z", DateRange{}, ErrBadArgument},
+		{"from after until", "2021-02-01", "2021-01-01", DateRange{}, ErrBadArgument},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			request := &Request{From: tt.from, Until: tt.until}
+
+			got, err := request.DateRange()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Request.DateRange() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Request.DateRange() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDateRange_Contains(t *testing.T) {
+	request := &Request{From: "2021-01-01T10:00:00Z", Until: "2021-01-01T10:00:05Z"}
+
+	dr, err := request.DateRange()
+	if err != nil {
+		t.Fatalf("Request.DateRange() error = %v", err)
+	}
+
+	base := time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		t    time.Time
+		want bool
+	}{
+		{"before from", base.Add(-time.Millisecond), false},
+		{"at from", base, true},
+		{"within the until second", base.Add(5*time.Second + 500*time.Millisecond), true},
+		{"after until", base.Add(6 * time.Second), false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dr.Contains(tt.t); got != tt.want {
+				t.Errorf("DateRange.Contains() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
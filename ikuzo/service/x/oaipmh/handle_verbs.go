@@ -82,24 +82,41 @@ func (s *Service) handleIdentify(resp *Response) {
 		BaseURL:           resp.Request.BaseURL,
 		ProtocolVersion:   "2.0",
 		AdminEmail:        resp.Request.orgConfig.OAIPMH.AdminEmails,
-		DeletedRecord:     "no", // TODO(kiivihal): change later to persistent
+		DeletedRecord:     resp.Request.orgConfig.OAIPMH.DeletedRecord(),
 		EarliestDatestamp: "1970-01-01T00:00:00Z",
-		Granularity:       "YYYY-MM-DDThh:mm:ssZ",
+		Granularity:       GranularitySeconds,
 	}
 }
 
 func (s *Service) handleListMetadataFormats(resp *Response) error {
-	// TODO(kiivihal): implement check for identifier
-
 	cfg := resp.Request.RequestConfig()
 
 	formats, err := s.store.ListMetadataFormats(context.TODO(), &cfg)
 	if err != nil {
+		var pmhErr Error
+		if errors.As(err, &pmhErr) {
+			resp.Error = append(resp.Error, pmhErr)
+			return nil
+		}
+
 		return err
 	}
 
+	allowed := []MetadataFormat{}
+
+	for _, format := range formats {
+		if cfg.AllowsFormat("", format.MetadataPrefix) {
+			allowed = append(allowed, format)
+		}
+	}
+
+	if len(allowed) == 0 {
+		resp.Error = append(resp.Error, ErrNoMetadataFormats)
+		return nil
+	}
+
 	resp.ListMetadataFormats = &ListMetadataFormats{
-		MetadataFormat: formats,
+		MetadataFormat: allowed,
 	}
 
 	return nil
@@ -192,6 +209,16 @@ func (s *Service) handleListIdentifiers(resp *Response) error {
 		return nil
 	}
 
+	if _, err := cfg.DateRange(); err != nil {
+		resp.Error = append(resp.Error, ErrBadArgument)
+		return nil
+	}
+
+	if !cfg.AllowsFormat(cfg.DatasetID, cfg.FirstRequest.MetadataPrefix) {
+		resp.Error = append(resp.Error, ErrCannotDisseminateFormat)
+		return nil
+	}
+
 	res, err := s.store.ListIdentifiers(ctx, &cfg)
 	if err != nil {
 		return fmt.Errorf("error during listIdentifiers: %w", err)
@@ -241,6 +268,16 @@ func (s *Service) handleListRecords(resp *Response) error {
 		return nil
 	}
 
+	if _, err := cfg.DateRange(); err != nil {
+		resp.Error = append(resp.Error, ErrBadArgument)
+		return nil
+	}
+
+	if !cfg.AllowsFormat(cfg.DatasetID, cfg.FirstRequest.MetadataPrefix) {
+		resp.Error = append(resp.Error, ErrCannotDisseminateFormat)
+		return nil
+	}
+
 	res, err := s.store.ListRecords(ctx, &cfg)
 	if err != nil {
 		return fmt.Errorf("error during listRecords: %w", err)
@@ -286,9 +323,30 @@ func (s *Service) handleGetRecord(resp *Response) error {
 		return nil
 	}
 
+	if !allowsRecordFormat(&q, &record.Header) {
+		resp.Error = append(resp.Error, ErrCannotDisseminateFormat)
+		return nil
+	}
+
 	resp.GetRecord = &GetRecord{
 		Record: record,
 	}
 
 	return nil
 }
+
+// allowsRecordFormat returns true when the requested metadata format may be
+// disseminated for one of the sets of the record.
+func allowsRecordFormat(q *RequestConfig, header *Header) bool {
+	if len(header.SetSpec) == 0 {
+		return q.AllowsFormat("", q.FirstRequest.MetadataPrefix)
+	}
+
+	for _, setSpec := range header.SetSpec {
+		if q.AllowsFormat(setSpec, q.FirstRequest.MetadataPrefix) {
+			return true
+		}
+	}
+
+	return false
+}
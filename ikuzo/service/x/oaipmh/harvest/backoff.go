@@ -0,0 +1,85 @@
+package harvest
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/delving/hub3/ikuzo/domain"
+)
+
+// BackoffPolicy determines when a failed harvest job is retried.
+type BackoffPolicy struct {
+	Initial    time.Duration
+	Max        time.Duration
+	Multiplier float64
+	// MaxRetries is the number of retries before the job waits for its next
+	// scheduled run. No retries are done when it is negative.
+	MaxRetries int
+}
+
+// DefaultBackoff is used for the settings that are not configured.
+var DefaultBackoff = BackoffPolicy{
+	Initial:    time.Minute,
+	Max:        time.Hour,
+	Multiplier: 2,
+	MaxRetries: 5,
+}
+
+// NewBackoffPolicy returns the BackoffPolicy of the configuration.
+func NewBackoffPolicy(cfg domain.HarvestBackoffConfig) (BackoffPolicy, error) {
+	b := DefaultBackoff
+
+	if cfg.Initial != "" {
+		d, err := time.ParseDuration(cfg.Initial)
+		if err != nil {
+			return b, fmt.Errorf("invalid initial backoff %q; %w", cfg.Initial, err)
+		}
+
+		b.Initial = d
+	}
+
+	if cfg.Max != "" {
+		d, err := time.ParseDuration(cfg.Max)
+		if err != nil {
+			return b, fmt.Errorf("invalid maximum backoff %q; %w", cfg.Max, err)
+		}
+
+		b.Max = d
+	}
+
+	if cfg.Multiplier != 0 {
+		if cfg.Multiplier < 1 {
+			return b, fmt.Errorf("backoff multiplier must be at least 1")
+		}
+
+		b.Multiplier = cfg.Multiplier
+	}
+
+	if cfg.MaxRetries != 0 {
+		b.MaxRetries = cfg.MaxRetries
+	}
+
+	return b, nil
+}
+
+// Retry returns true when the job must be retried after the number of
+// consecutive failures.
+func (b BackoffPolicy) Retry(failures int) bool {
+	return failures > 0 && failures <= b.MaxRetries
+}
+
+// Delay returns the delay before the retry after the number of consecutive
+// failures.
+func (b BackoffPolicy) Delay(failures int) time.Duration {
+	if failures < 1 {
+		return 0
+	}
+
+	delay := float64(b.Initial) * math.Pow(b.Multiplier, float64(failures-1))
+	if b.Max > 0 && delay > float64(b.Max) {
+		return b.Max
+	}
+
+	return time.Duration(delay)
+}
@@ -12,4 +12,11 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
+// Package harvest is an OAI-PMH client that harvests endpoints incrementally.
+//
+// Harvest jobs are configured per organization and run on a cron-like
+// Schedule. A failed run is retried according to the BackoffPolicy of the
+// job. The progress, the paused state and the history of the runs are stored
+// in the StateStore of service/x/harvest, so jobs continue where they left off after a restart. The
+// jobs can be listed, paused, resumed and run via /api/harvest/jobs.
 package harvest
@@ -0,0 +1,72 @@
+package harvest
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/delving/hub3/ikuzo/domain"
+	"github.com/delving/hub3/ikuzo/render"
+	"github.com/go-chi/chi"
+)
+
+func (s *Service) handleJobs(w http.ResponseWriter, r *http.Request) {
+	org, ok := domain.GetOrganization(r)
+	if !ok {
+		http.Error(w, domain.ErrOrgNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	render.JSON(w, r, s.Jobs(org.ID.String()))
+}
+
+func (s *Service) handleJob(w http.ResponseWriter, r *http.Request) {
+	s.jobAction(w, r, s.Job, http.StatusOK)
+}
+
+func (s *Service) handlePause(w http.ResponseWriter, r *http.Request) {
+	s.jobAction(w, r, s.Pause, http.StatusOK)
+}
+
+func (s *Service) handleResume(w http.ResponseWriter, r *http.Request) {
+	s.jobAction(w, r, s.Resume, http.StatusOK)
+}
+
+// handleRun queues a run of the job. The run can be followed with the job
+// endpoint.
+func (s *Service) handleRun(w http.ResponseWriter, r *http.Request) {
+	s.jobAction(w, r, s.Trigger, http.StatusAccepted)
+}
+
+func (s *Service) jobAction(
+	w http.ResponseWriter, r *http.Request,
+	action func(orgID, id string) (*Job, error), status int,
+) {
+	org, ok := domain.GetOrganization(r)
+	if !ok {
+		http.Error(w, domain.ErrOrgNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	job, err := action(org.ID.String(), chi.URLParam(r, "id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrJobNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, ErrJobRunning):
+			http.Error(w, err.Error(), http.StatusConflict)
+		case errors.Is(err, ErrQueueFull):
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		default:
+			render.Error(w, r, err, &render.ErrorConfig{
+				StatusCode: http.StatusInternalServerError,
+				Message:    "unable to update harvest job",
+				Log:        &s.log,
+			})
+		}
+
+		return
+	}
+
+	render.Status(r, status)
+	render.JSON(w, r, job)
+}
@@ -33,6 +33,12 @@ type HarvestInfo struct {
 	Error        string
 }
 
+// HarvestInfoStore persists the HarvestInfo of a HarvestTask between runs.
+type HarvestInfoStore interface {
+	GetHarvestInfo(name string) (*HarvestInfo, error)
+	PutHarvestInfo(name string, info *HarvestInfo) error
+}
+
 type HarvestCallback func(r *oai.Response) error
 
 type HarvestMetrics struct {
@@ -58,9 +64,23 @@ type HarvestTask struct {
 	running     bool
 	m           HarvestMetrics
 	Client      *http.Client
+	// InfoStore persists the HarvestInfo. When nil it is written as a JSON
+	// file in the EAD cache directory.
+	InfoStore HarvestInfoStore
 }
 
 func (ht *HarvestTask) getOrCreateHarvestInfo() error {
+	if ht.InfoStore != nil {
+		info, err := ht.InfoStore.GetHarvestInfo(ht.Name)
+		if err != nil {
+			return err
+		}
+
+		ht.HarvestInfo = info
+
+		return nil
+	}
+
 	path := ht.getHarvestInfoPath()
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		ht.HarvestInfo = &HarvestInfo{}
@@ -96,6 +116,10 @@ func (ht *HarvestTask) getOrCreateHarvestInfo() error {
 }
 
 func (ht *HarvestTask) writeHarvestInfo() error {
+	if ht.InfoStore != nil {
+		return ht.InfoStore.PutHarvestInfo(ht.Name, ht.HarvestInfo)
+	}
+
 	b, err := json.Marshal(ht.HarvestInfo)
 	if err != nil {
 		return err
@@ -197,10 +221,11 @@ func (ht *HarvestTask) harvest(ctx context.Context, request *oai.Request) error
 	}
 
 	switch resp.Error.Code {
-	case "noRecordMatch":
+	case "":
+	case "noRecordsMatch":
 		ht.m.NoRecordsMatch = true
 		return ErrNoRecordsMatch
-	case "error":
+	default:
 		pmhErr := resp.Error
 		err := fmt.Errorf(
 			"OAI-PMH response returns an error %s: %s", pmhErr.Code, pmhErr.Message,
@@ -215,7 +240,6 @@ func (ht *HarvestTask) harvest(ctx context.Context, request *oai.Request) error
 		ht.m.Aborted = true
 
 		return err
-	default:
 	}
 
 	ht.m.Pages++
@@ -358,6 +382,14 @@ func (ht *HarvestTask) updateHarvestInfo() {
 	}
 }
 
+// Metrics returns the metrics of the last harvest run.
+func (ht *HarvestTask) Metrics() HarvestMetrics {
+	ht.rw.Lock()
+	defer ht.rw.Unlock()
+
+	return ht.m
+}
+
 // GetLastCheck returns last time the task has run.
 func (ht *HarvestTask) GetLastCheck() time.Time {
 	if ht.HarvestInfo == nil {
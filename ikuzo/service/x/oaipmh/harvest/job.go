@@ -0,0 +1,334 @@
+package harvest
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/delving/hub3/ikuzo/domain"
+	"github.com/kiivihal/goharvest/oai"
+)
+
+var (
+	ErrInvalidJob       = errors.New("invalid harvest job")
+	ErrJobExists        = errors.New("harvest job already exists")
+	ErrJobNotFound      = errors.New("harvest job not found")
+	ErrJobRunning       = errors.New("harvest job is already queued or running")
+	ErrQueueFull        = errors.New("harvest queue is full")
+	ErrUnknownProcessor = errors.New("unknown harvest processor")
+)
+
+// Processor handles a page of records that is harvested for an organization.
+type Processor func(orgID string, r *oai.Response) error
+
+// The triggers of a Run.
+const (
+	TriggerSchedule = "schedule"
+	TriggerRetry    = "retry"
+	TriggerManual   = "manual"
+)
+
+// JobStatus is the status of a Job.
+type JobStatus string
+
+const (
+	StatusIdle    JobStatus = "idle"
+	StatusQueued  JobStatus = "queued"
+	StatusRunning JobStatus = "running"
+	StatusPaused  JobStatus = "paused"
+)
+
+// defaultHistorySize is the number of runs that are kept in the JobState.
+const defaultHistorySize = 50
+
+var validJobID = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Job is a scheduled harvest of an OAI-PMH endpoint for an organization.
+type Job struct {
+	rw             sync.RWMutex
+	ID             string    `json:"id"`
+	OrgID          string    `json:"orgID"`
+	URL            string    `json:"url"`
+	Verb           string    `json:"verb"`
+	MetadataPrefix string    `json:"metadataPrefix"`
+	Set            string    `json:"set,omitempty"`
+	Schedule       string    `json:"schedule"`
+	Processor      string    `json:"processor"`
+	Status         JobStatus `json:"status"`
+	State          JobState  `json:"state"`
+	schedule       Schedule
+	backoff        BackoffPolicy
+	task           *HarvestTask
+	trigger        string
+}
+
+// NewJob returns the Job of the configuration. The processor handles the
+// harvested pages.
+func NewJob(orgID string, cfg domain.HarvestJobConfig, processor Processor) (*Job, error) {
+	if !validJobID.MatchString(cfg.ID) {
+		return nil, fmt.Errorf("%w: id %q must only contain letters, digits, '-' and '_'", ErrInvalidJob, cfg.ID)
+	}
+
+	if cfg.URL == "" || cfg.MetadataPrefix == "" {
+		return nil, fmt.Errorf("%w: %s must have an url and a metadataPrefix", ErrInvalidJob, cfg.ID)
+	}
+
+	verb := cfg.Verb
+	if verb == "" {
+		verb = VerbListRecords
+	}
+
+	if verb != VerbListRecords && verb != VerbListIdentifiers {
+		return nil, fmt.Errorf("%w: %s has unsupported verb %q", ErrInvalidJob, cfg.ID, verb)
+	}
+
+	schedule, err := ParseSchedule(cfg.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s; %s", ErrInvalidJob, cfg.ID, err)
+	}
+
+	backoff, err := NewBackoffPolicy(cfg.Backoff)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s; %s", ErrInvalidJob, cfg.ID, err)
+	}
+
+	j := &Job{
+		ID:             cfg.ID,
+		OrgID:          orgID,
+		URL:            cfg.URL,
+		Verb:           verb,
+		MetadataPrefix: cfg.MetadataPrefix,
+		Set:            cfg.Set,
+		Schedule:       cfg.Schedule,
+		Processor:      cfg.Processor,
+		Status:         StatusIdle,
+		State: JobState{
+			OrgID:    orgID,
+			ID:       cfg.ID,
+			Schedule: cfg.Schedule,
+		},
+		schedule: schedule,
+		backoff:  backoff,
+	}
+
+	j.task = &HarvestTask{
+		OrgID: orgID,
+		Name:  orgID + "_" + cfg.ID,
+		Request: oai.Request{
+			BaseURL:        cfg.URL,
+			Verb:           verb,
+			MetadataPrefix: cfg.MetadataPrefix,
+			Set:            cfg.Set,
+		},
+		CallbackFn: func(r *oai.Response) error {
+			return processor(orgID, r)
+		},
+		InfoStore: jobInfoStore{j},
+	}
+
+	return j, nil
+}
+
+// restore replaces the state with the persisted state. The next run is
+// computed again when the schedule has changed.
+func (j *Job) restore(state *JobState, now time.Time) {
+	j.rw.Lock()
+	defer j.rw.Unlock()
+
+	if state != nil {
+		j.State = *state.copy()
+		j.State.OrgID = j.OrgID
+		j.State.ID = j.ID
+	}
+
+	if j.State.NextRun.IsZero() || j.State.Schedule != j.Schedule {
+		j.State.Schedule = j.Schedule
+		j.State.NextRun = j.schedule.Next(now)
+	}
+}
+
+// Snapshot returns a copy of the job that is safe to read while the job runs.
+func (j *Job) Snapshot() *Job {
+	j.rw.RLock()
+	defer j.rw.RUnlock()
+
+	status := j.Status
+	if status == StatusIdle && j.State.Paused {
+		status = StatusPaused
+	}
+
+	return &Job{
+		ID:             j.ID,
+		OrgID:          j.OrgID,
+		URL:            j.URL,
+		Verb:           j.Verb,
+		MetadataPrefix: j.MetadataPrefix,
+		Set:            j.Set,
+		Schedule:       j.Schedule,
+		Processor:      j.Processor,
+		Status:         status,
+		State:          *j.State.copy(),
+	}
+}
+
+func (j *Job) stateCopy() *JobState {
+	j.rw.RLock()
+	defer j.rw.RUnlock()
+
+	return j.State.copy()
+}
+
+// due returns true when the job must be queued by the scheduler.
+func (j *Job) due(now time.Time) bool {
+	j.rw.RLock()
+	defer j.rw.RUnlock()
+
+	return j.Status == StatusIdle &&
+		!j.State.Paused &&
+		!j.State.NextRun.IsZero() &&
+		!j.State.NextRun.After(now)
+}
+
+// enqueue marks the job as queued. ErrJobRunning is returned when the job
+// is already queued or running.
+func (j *Job) enqueue(trigger string) error {
+	j.rw.Lock()
+	defer j.rw.Unlock()
+
+	if j.Status != StatusIdle {
+		return fmt.Errorf("%w: %s", ErrJobRunning, j.ID)
+	}
+
+	if trigger == TriggerSchedule && j.State.Failures > 0 {
+		trigger = TriggerRetry
+	}
+
+	j.Status = StatusQueued
+	j.trigger = trigger
+
+	return nil
+}
+
+// dequeue marks a queued job as idle again.
+func (j *Job) dequeue() {
+	j.rw.Lock()
+	defer j.rw.Unlock()
+
+	j.Status = StatusIdle
+	j.trigger = ""
+}
+
+// start marks the job as running. False is returned when the job has been
+// paused after it was queued by the scheduler.
+func (j *Job) start(now time.Time) (Run, bool) {
+	j.rw.Lock()
+	defer j.rw.Unlock()
+
+	if j.State.Paused && j.trigger != TriggerManual {
+		j.Status = StatusIdle
+		return Run{}, false
+	}
+
+	j.Status = StatusRunning
+
+	return Run{Trigger: j.trigger, Started: now}, true
+}
+
+// finish adds the run to the history and schedules the next run. A failed
+// run is retried according to the BackoffPolicy, unless the next scheduled
+// run is earlier.
+func (j *Job) finish(run Run, m HarvestMetrics, err error, now time.Time, historySize int) {
+	j.rw.Lock()
+	defer j.rw.Unlock()
+
+	run.Finished = now
+	run.Duration = now.Sub(run.Started)
+	run.From = m.From
+	run.Until = m.Until
+	run.Records = m.Processed
+	run.Deleted = m.Deleted
+	run.Pages = m.Pages
+	run.Failed = err != nil
+
+	for _, e := range m.Errors {
+		run.Errors = append(run.Errors, e.Error())
+	}
+
+	if err != nil && len(run.Errors) == 0 {
+		run.Errors = append(run.Errors, err.Error())
+	}
+
+	j.State.History = append(j.State.History, run)
+	if len(j.State.History) > historySize {
+		j.State.History = j.State.History[len(j.State.History)-historySize:]
+	}
+
+	j.State.NextRun = j.schedule.Next(now)
+
+	switch {
+	case !run.Failed:
+		j.State.Failures = 0
+		j.State.Info.Error = ""
+	default:
+		j.State.Failures++
+		j.State.Info.Error = run.Errors[len(run.Errors)-1]
+
+		if !j.backoff.Retry(j.State.Failures) {
+			j.State.Failures = 0
+			break
+		}
+
+		retry := now.Add(j.backoff.Delay(j.State.Failures))
+		if j.State.NextRun.IsZero() || retry.Before(j.State.NextRun) {
+			j.State.NextRun = retry
+		}
+	}
+
+	j.Status = StatusIdle
+	j.trigger = ""
+}
+
+func (j *Job) setPaused(paused bool, now time.Time) {
+	j.rw.Lock()
+	defer j.rw.Unlock()
+
+	j.State.Paused = paused
+
+	if !paused {
+		j.State.Failures = 0
+
+		// runs that were missed while paused are skipped
+		if j.State.NextRun.Before(now) {
+			j.State.NextRun = j.schedule.Next(now)
+		}
+	}
+}
+
+// jobInfoStore keeps the HarvestInfo of the HarvestTask in the JobState.
+type jobInfoStore struct {
+	j *Job
+}
+
+func (s jobInfoStore) GetHarvestInfo(name string) (*HarvestInfo, error) {
+	s.j.rw.RLock()
+	defer s.j.rw.RUnlock()
+
+	info := s.j.State.Info
+
+	return &info, nil
+}
+
+func (s jobInfoStore) PutHarvestInfo(name string, info *HarvestInfo) error {
+	s.j.rw.Lock()
+	defer s.j.rw.Unlock()
+
+	s.j.State.Info = *info
+
+	return nil
+}
+
+func jobKey(orgID, jobID string) string {
+	return orgID + "/" + jobID
+}
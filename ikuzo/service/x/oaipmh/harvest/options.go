@@ -1,5 +1,11 @@
 package harvest
 
+import (
+	"fmt"
+
+	iharvest "github.com/delving/hub3/ikuzo/service/x/harvest"
+)
+
 type Option func(*Service) error
 
 func SetDelay(delay int) Option {
@@ -8,3 +14,33 @@ func SetDelay(delay int) Option {
 		return nil
 	}
 }
+
+// SetStateStore sets the StateStore that persists the state of the harvest
+// jobs. When it is not set the state is only kept in memory.
+func SetStateStore(store iharvest.StateStore) Option {
+	return func(s *Service) error {
+		s.store = store
+		return nil
+	}
+}
+
+// SetProcessor registers the Processor that harvest jobs refer to by name.
+func SetProcessor(name string, processor Processor) Option {
+	return func(s *Service) error {
+		if processor == nil {
+			return fmt.Errorf("processor %q cannot be nil", name)
+		}
+
+		s.processors[name] = processor
+
+		return nil
+	}
+}
+
+// SetHistorySize sets the number of runs that are kept per harvest job.
+func SetHistorySize(size int) Option {
+	return func(s *Service) error {
+		s.historySize = size
+		return nil
+	}
+}
@@ -5,4 +5,9 @@ import "github.com/go-chi/chi"
 func (s *Service) Routes(pattern string, r chi.Router) {
 	r.Get("/oai/!open_oai.OAIHandler", s.ServeHTTP)
 	r.Post("/oai/harvest-now", s.HarvestNow)
+	r.Get("/api/harvest/jobs", s.handleJobs)
+	r.Get("/api/harvest/jobs/{id}", s.handleJob)
+	r.Post("/api/harvest/jobs/{id}/pause", s.handlePause)
+	r.Post("/api/harvest/jobs/{id}/resume", s.handleResume)
+	r.Post("/api/harvest/jobs/{id}/run", s.handleRun)
 }
@@ -0,0 +1,198 @@
+package harvest
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidSchedule = errors.New("invalid harvest schedule")
+
+// Schedule returns the time of the next run of a job.
+type Schedule interface {
+	// Next returns the first run after t.
+	Next(t time.Time) time.Time
+}
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseSchedule parses a cron expression with the five fields minute, hour,
+// day of month, month and day of week. Each field is a '*' or a comma
+// separated list of values and ranges ('1-5'), with an optional step ('*/15').
+// Sunday is both 0 and 7 in the day of week field.
+//
+// The descriptors @yearly, @monthly, @weekly, @daily and @hourly are
+// supported, and '@every <duration>' runs the job at a fixed interval.
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+
+	if strings.HasPrefix(spec, "@every ") {
+		d, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(spec, "@every ")))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidSchedule, spec)
+		}
+
+		return every(d), nil
+	}
+
+	if expr, ok := descriptors[spec]; ok {
+		spec = expr
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: %q must have 5 fields", ErrInvalidSchedule, spec)
+	}
+
+	var (
+		cs  cronSchedule
+		err error
+	)
+
+	for _, f := range []struct {
+		expr     string
+		min, max int
+		bits     *uint64
+	}{
+		{fields[0], 0, 59, &cs.minute},
+		{fields[1], 0, 23, &cs.hour},
+		{fields[2], 1, 31, &cs.dom},
+		{fields[3], 1, 12, &cs.month},
+		{fields[4], 0, 7, &cs.dow},
+	} {
+		*f.bits, err = parseField(f.expr, f.min, f.max)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q; %s", ErrInvalidSchedule, spec, err)
+		}
+	}
+
+	// 7 is an alias for sunday
+	if cs.dow&(1<<7) != 0 {
+		cs.dow |= 1
+	}
+
+	cs.domStar = fields[2] == "*"
+	cs.dowStar = fields[4] == "*"
+
+	return &cs, nil
+}
+
+func parseField(expr string, min, max int) (uint64, error) {
+	var bits uint64
+
+	for _, item := range strings.Split(expr, ",") {
+		rangeExpr, step := item, 1
+
+		if idx := strings.IndexByte(item, '/'); idx != -1 {
+			var err error
+
+			rangeExpr = item[:idx]
+
+			step, err = strconv.Atoi(item[idx+1:])
+			if err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step in %q", item)
+			}
+		}
+
+		start, end := min, max
+
+		switch {
+		case rangeExpr == "*":
+		case strings.Contains(rangeExpr, "-"):
+			parts := strings.SplitN(rangeExpr, "-", 2)
+
+			var err error
+
+			if start, err = strconv.Atoi(parts[0]); err != nil {
+				return 0, fmt.Errorf("invalid range %q", item)
+			}
+
+			if end, err = strconv.Atoi(parts[1]); err != nil {
+				return 0, fmt.Errorf("invalid range %q", item)
+			}
+		default:
+			value, err := strconv.Atoi(rangeExpr)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value %q", item)
+			}
+
+			start = value
+
+			// a single value with a step runs until the maximum, e.g. '5/15'
+			end = value
+			if step > 1 {
+				end = max
+			}
+		}
+
+		if start < min || end > max || start > end {
+			return 0, fmt.Errorf("%q is out of range %d-%d", item, min, max)
+		}
+
+		for i := start; i <= end; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+
+	return bits, nil
+}
+
+// cronSchedule contains the allowed values of each field as a bitset.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool
+}
+
+// Next returns the first minute after t that matches the expression. The
+// zero time is returned when no run is found within five years.
+func (cs *cronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case cs.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !cs.matchDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case cs.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case cs.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// matchDay follows cron: when both the day of month and the day of week are
+// restricted, a day matching either field is a match.
+func (cs *cronSchedule) matchDay(t time.Time) bool {
+	dom := cs.dom&(1<<uint(t.Day())) != 0
+	dow := cs.dow&(1<<uint(t.Weekday())) != 0
+
+	if cs.domStar || cs.dowStar {
+		return dom && dow
+	}
+
+	return dom || dow
+}
+
+// every is a Schedule with a fixed interval.
+type every time.Duration
+
+func (e every) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(e)).Truncate(time.Second)
+}
@@ -0,0 +1,97 @@
+package harvest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/delving/hub3/ikuzo/domain"
+	"github.com/matryer/is"
+)
+
+func TestParseSchedule(t *testing.T) {
+	// a wednesday
+	now := time.Date(2021, 3, 17, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		spec    string
+		want    time.Time
+		wantErr bool
+	}{
+		{"*/15 * * * *", time.Date(2021, 3, 17, 10, 15, 0, 0, time.UTC), false},
+		{"5 * * * *", time.Date(2021, 3, 17, 11, 5, 0, 0, time.UTC), false},
+		{"0 2 * * *", time.Date(2021, 3, 18, 2, 0, 0, 0, time.UTC), false},
+		{"30 8-9,12 * * *", time.Date(2021, 3, 17, 12, 30, 0, 0, time.UTC), false},
+		{"0 0 1 * *", time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC), false},
+		{"0 0 * * 7", time.Date(2021, 3, 21, 0, 0, 0, 0, time.UTC), false},
+		{"0 0 * * 1-5", time.Date(2021, 3, 18, 0, 0, 0, 0, time.UTC), false},
+		// day of month or day of week
+		{"0 0 20 * 5", time.Date(2021, 3, 19, 0, 0, 0, 0, time.UTC), false},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), false},
+		{"@hourly", time.Date(2021, 3, 17, 11, 0, 0, 0, time.UTC), false},
+		{"@daily", time.Date(2021, 3, 18, 0, 0, 0, 0, time.UTC), false},
+		{"@weekly", time.Date(2021, 3, 21, 0, 0, 0, 0, time.UTC), false},
+		{"@every 90m", time.Date(2021, 3, 17, 11, 37, 30, 0, time.UTC), false},
+		{"", time.Time{}, true},
+		{"* * * *", time.Time{}, true},
+		{"60 * * * *", time.Time{}, true},
+		{"*/0 * * * *", time.Time{}, true},
+		{"5-1 * * * *", time.Time{}, true},
+		{"@every -1h", time.Time{}, true},
+		{"@sometimes", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.spec, func(t *testing.T) {
+			schedule, err := ParseSchedule(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSchedule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidSchedule) {
+					t.Errorf("ParseSchedule() error = %v, want ErrInvalidSchedule", err)
+				}
+
+				return
+			}
+
+			if got := schedule.Next(now); !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackoffPolicy(t *testing.T) {
+	is := is.New(t)
+
+	b, err := NewBackoffPolicy(domain.HarvestBackoffConfig{
+		Initial:    "10s",
+		Max:        "1m",
+		Multiplier: 3,
+		MaxRetries: 3,
+	})
+	is.NoErr(err)
+
+	is.Equal(b.Delay(0), time.Duration(0))
+	is.Equal(b.Delay(1), 10*time.Second)
+	is.Equal(b.Delay(2), 30*time.Second)
+	is.Equal(b.Delay(3), time.Minute) // capped
+
+	is.True(b.Retry(3))
+	is.True(!b.Retry(4))
+
+	b, err = NewBackoffPolicy(domain.HarvestBackoffConfig{MaxRetries: -1})
+	is.NoErr(err)
+	is.Equal(b.Initial, DefaultBackoff.Initial)
+	is.True(!b.Retry(1))
+
+	_, err = NewBackoffPolicy(domain.HarvestBackoffConfig{Initial: "soon"})
+	is.True(err != nil)
+
+	_, err = NewBackoffPolicy(domain.HarvestBackoffConfig{Multiplier: 0.5})
+	is.True(err != nil)
+}
@@ -0,0 +1,233 @@
+package harvest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/delving/hub3/ikuzo/domain"
+	iharvest "github.com/delving/hub3/ikuzo/service/x/harvest"
+)
+
+// AddJob adds the harvest job of the organization. The persisted state of the
+// job is restored from the StateStore, so the history, the paused state and
+// the harvest progress survive restarts.
+func (s *Service) AddJob(orgID string, cfg domain.HarvestJobConfig) (*Job, error) {
+	processor, ok := s.processors[cfg.Processor]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q for job %s", ErrUnknownProcessor, cfg.Processor, cfg.ID)
+	}
+
+	job, err := NewJob(orgID, cfg, processor)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &JobState{}
+
+	err = s.store.Get(jobKey(orgID, cfg.ID), state)
+	switch {
+	case errors.Is(err, iharvest.ErrStateNotFound):
+		state = nil
+	case err != nil:
+		return nil, fmt.Errorf("unable to restore state of harvest job %s; %w", cfg.ID, err)
+	}
+
+	job.restore(state, time.Now())
+
+	s.rw.Lock()
+	defer s.rw.Unlock()
+
+	key := jobKey(orgID, cfg.ID)
+	if _, ok := s.jobs[key]; ok {
+		return nil, fmt.Errorf("%w: %s", ErrJobExists, key)
+	}
+
+	s.jobs[key] = job
+
+	return job.Snapshot(), nil
+}
+
+// Jobs returns the harvest jobs of the organization sorted by ID.
+func (s *Service) Jobs(orgID string) []*Job {
+	s.rw.Lock()
+	defer s.rw.Unlock()
+
+	jobs := []*Job{}
+
+	for _, job := range s.jobs {
+		if job.OrgID == orgID {
+			jobs = append(jobs, job.Snapshot())
+		}
+	}
+
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].ID < jobs[j].ID })
+
+	return jobs
+}
+
+// Job returns the harvest job of the organization.
+func (s *Service) Job(orgID, id string) (*Job, error) {
+	job, err := s.getJob(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return job.Snapshot(), nil
+}
+
+func (s *Service) getJob(orgID, id string) (*Job, error) {
+	s.rw.Lock()
+	defer s.rw.Unlock()
+
+	job, ok := s.jobs[jobKey(orgID, id)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrJobNotFound, id)
+	}
+
+	return job, nil
+}
+
+// Pause stops the scheduled runs of the job. A running harvest is finished.
+func (s *Service) Pause(orgID, id string) (*Job, error) {
+	return s.setPaused(orgID, id, true)
+}
+
+// Resume schedules the job again. Runs that were missed while the job was
+// paused are skipped.
+func (s *Service) Resume(orgID, id string) (*Job, error) {
+	return s.setPaused(orgID, id, false)
+}
+
+func (s *Service) setPaused(orgID, id string, paused bool) (*Job, error) {
+	job, err := s.getJob(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	job.setPaused(paused, time.Now())
+
+	if err := s.store.Put(jobKey(job.OrgID, job.ID), job.stateCopy()); err != nil {
+		return nil, fmt.Errorf("unable to store state of harvest job %s; %w", id, err)
+	}
+
+	return job.Snapshot(), nil
+}
+
+// Trigger queues a run of the job. Paused jobs can be triggered as well.
+func (s *Service) Trigger(orgID, id string) (*Job, error) {
+	job, err := s.getJob(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := job.enqueue(TriggerManual); err != nil {
+		return nil, err
+	}
+
+	select {
+	case s.queue <- job:
+	default:
+		job.dequeue()
+		return nil, ErrQueueFull
+	}
+
+	return job.Snapshot(), nil
+}
+
+// scheduleJobs queues the jobs that are due until the context is canceled.
+func (s *Service) scheduleJobs(ctx context.Context) error {
+	ticker := time.NewTicker(s.tick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-ticker.C:
+			if err := s.queueDueJobs(ctx, now); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *Service) queueDueJobs(ctx context.Context, now time.Time) error {
+	s.rw.Lock()
+
+	due := []*Job{}
+
+	for _, job := range s.jobs {
+		if job.due(now) {
+			due = append(due, job)
+		}
+	}
+
+	s.rw.Unlock()
+
+	for _, job := range due {
+		if err := job.enqueue(TriggerSchedule); err != nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			job.dequeue()
+			return ctx.Err()
+		case s.queue <- job:
+		}
+	}
+
+	return nil
+}
+
+// runJob harvests the job and persists the report of the run. Only the
+// cancellation of the context is returned as an error.
+func (s *Service) runJob(ctx context.Context, job *Job) error {
+	run, ok := job.start(time.Now())
+	if !ok {
+		return nil
+	}
+
+	err := s.harvestJob(ctx, job)
+
+	job.finish(run, job.task.Metrics(), err, time.Now(), s.historySize)
+
+	if putErr := s.store.Put(jobKey(job.OrgID, job.ID), job.stateCopy()); putErr != nil {
+		s.log.Error().Err(putErr).
+			Str("orgID", job.OrgID).
+			Str("jobID", job.ID).
+			Msg("unable to store state of harvest job")
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Service) harvestJob(ctx context.Context, job *Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic message : %v on url %s", r, job.task.Request.GetFullURL())
+
+			s.log.Error().Err(err).
+				Str("orgID", job.OrgID).
+				Str("jobID", job.ID).
+				Msg("unable to run harvest job")
+		}
+	}()
+
+	err = job.task.Harvest(ctx)
+	if err != nil {
+		s.log.Warn().Err(err).
+			Str("orgID", job.OrgID).
+			Str("jobID", job.ID).
+			Msg("harvest job failed")
+	}
+
+	return err
+}
@@ -0,0 +1,306 @@
+package harvest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/delving/hub3/ikuzo/domain"
+	iharvest "github.com/delving/hub3/ikuzo/service/x/harvest"
+	"github.com/kiivihal/goharvest/oai"
+	"github.com/matryer/is"
+)
+
+const listRecordsPage = `<?xml version="1.0" encoding="UTF-8"?>
+<OAI-PMH xmlns="http://www.openarchives.org/OAI/2.0/">
+  <responseDate>2021-03-17T10:00:00Z</responseDate>
+  <request verb="ListRecords">http://localhost/oai</request>
+  <ListRecords>
+    <record><header><identifier>%[1]s-1</identifier><datestamp>2021-03-17</datestamp></header><metadata><ead/></metadata></record>
+    <record><header status="deleted"><identifier>%[1]s-2</identifier><datestamp>2021-03-17</datestamp></header></record>
+    <resumptionToken completeListSize="3">%[2]s</resumptionToken>
+  </ListRecords>
+</OAI-PMH>`
+
+// oaiEndpoint is an OAI-PMH endpoint that returns two pages of records.
+type oaiEndpoint struct {
+	rw     sync.Mutex
+	status int
+}
+
+func (e *oaiEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.rw.Lock()
+	defer e.rw.Unlock()
+
+	if e.status != 0 {
+		w.WriteHeader(e.status)
+		return
+	}
+
+	if r.URL.Query().Get("resumptionToken") == "" {
+		fmt.Fprintf(w, listRecordsPage, "page1", "token")
+		return
+	}
+
+	fmt.Fprintf(w, listRecordsPage, "page2", "")
+}
+
+func newTestService(t *testing.T, dir string, processed *[]string) *Service {
+	t.Helper()
+
+	store, err := iharvest.NewFileStateStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStateStore() error = %v", err)
+	}
+
+	svc, err := NewService(
+		SetStateStore(store),
+		SetProcessor("ead", func(orgID string, r *oai.Response) error {
+			for _, record := range r.ListRecords.Records {
+				*processed = append(*processed, orgID+":"+record.Header.Identifier)
+			}
+
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+
+	return svc
+}
+
+func testJobConfig(url string) domain.HarvestJobConfig {
+	return domain.HarvestJobConfig{
+		ID:             "archives",
+		Enabled:        true,
+		URL:            url,
+		MetadataPrefix: "ead",
+		Schedule:       "@daily",
+		Processor:      "ead",
+		Backoff: domain.HarvestBackoffConfig{
+			Initial: "5m",
+		},
+	}
+}
+
+func runNow(t *testing.T, svc *Service, id string) *Job {
+	t.Helper()
+
+	job, err := svc.getJob("demo", id)
+	if err != nil {
+		t.Fatalf("getJob() error = %v", err)
+	}
+
+	if err := job.enqueue(TriggerManual); err != nil {
+		t.Fatalf("enqueue() error = %v", err)
+	}
+
+	if err := svc.runJob(context.TODO(), job); err != nil {
+		t.Fatalf("runJob() error = %v", err)
+	}
+
+	return job.Snapshot()
+}
+
+func TestService_AddJob(t *testing.T) {
+	is := is.New(t)
+
+	processed := []string{}
+	svc := newTestService(t, t.TempDir(), &processed)
+
+	cfg := testJobConfig("http://localhost/oai")
+
+	job, err := svc.AddJob("demo", cfg)
+	is.NoErr(err)
+	is.Equal(job.Verb, VerbListRecords)
+	is.Equal(job.Status, StatusIdle)
+	is.True(job.State.NextRun.After(time.Now()))
+
+	_, err = svc.AddJob("demo", cfg)
+	is.True(errors.Is(err, ErrJobExists))
+
+	unknown := cfg
+	unknown.ID = "other"
+	unknown.Processor = "marc"
+	_, err = svc.AddJob("demo", unknown)
+	is.True(errors.Is(err, ErrUnknownProcessor))
+
+	invalid := cfg
+	invalid.ID = "../other"
+	_, err = svc.AddJob("demo", invalid)
+	is.True(errors.Is(err, ErrInvalidJob))
+
+	invalid.ID = "other"
+	invalid.Schedule = "every day"
+	_, err = svc.AddJob("demo", invalid)
+	is.True(errors.Is(err, ErrInvalidJob))
+
+	is.Equal(len(svc.Jobs("demo")), 1)
+	is.Equal(len(svc.Jobs("other")), 0)
+}
+
+func TestService_runJob(t *testing.T) {
+	is := is.New(t)
+
+	endpoint := &oaiEndpoint{}
+	server := httptest.NewServer(endpoint)
+	defer server.Close()
+
+	dir := t.TempDir()
+	processed := []string{}
+	svc := newTestService(t, dir, &processed)
+
+	_, err := svc.AddJob("demo", testJobConfig(server.URL))
+	is.NoErr(err)
+
+	job := runNow(t, svc, "archives")
+	is.Equal(processed, []string{"demo:page1-1", "demo:page1-2", "demo:page2-1", "demo:page2-2"})
+	is.Equal(job.Status, StatusIdle)
+	is.Equal(job.State.Failures, 0)
+	is.Equal(len(job.State.History), 1)
+
+	run := job.State.History[0]
+	is.Equal(run.Trigger, TriggerManual)
+	is.Equal(run.Records, 4)
+	is.Equal(run.Deleted, 2)
+	is.Equal(run.Pages, 2)
+	is.Equal(run.From, "")
+	is.True(!run.Failed)
+	is.True(!job.State.Info.LastModified.IsZero())
+	is.True(job.State.NextRun.After(time.Now().Add(time.Hour)))
+
+	// the next run harvests incrementally
+	job = runNow(t, svc, "archives")
+	is.Equal(len(job.State.History), 2)
+	is.Equal(job.State.History[1].From, job.State.History[0].Until)
+
+	// a failed run is retried with backoff
+	endpoint.status = http.StatusForbidden
+	before := time.Now()
+
+	job = runNow(t, svc, "archives")
+	is.Equal(job.State.Failures, 1)
+	is.True(job.State.History[2].Failed)
+	is.True(len(job.State.History[2].Errors) > 0)
+	is.True(job.State.Info.Error != "")
+	is.True(!job.State.NextRun.Before(before.Add(5 * time.Minute)))
+	is.True(job.State.NextRun.Before(time.Now().Add(6 * time.Minute)))
+
+	_, err = svc.Pause("demo", "archives")
+	is.NoErr(err)
+
+	// the state survives a restart
+	restarted := newTestService(t, dir, &processed)
+
+	job, err = restarted.AddJob("demo", testJobConfig(server.URL))
+	is.NoErr(err)
+	is.Equal(job.Status, StatusPaused)
+	is.Equal(job.State.Failures, 1)
+	is.Equal(len(job.State.History), 3)
+	is.True(!job.State.Info.LastModified.IsZero())
+
+	endpoint.status = 0
+
+	job = runNow(t, restarted, "archives")
+	is.Equal(len(job.State.History), 4)
+	is.Equal(job.State.Failures, 0)
+	is.True(job.State.History[3].From != "") // restored harvest progress
+	is.Equal(job.State.History[3].Trigger, TriggerManual)
+}
+
+func TestService_queueDueJobs(t *testing.T) {
+	is := is.New(t)
+
+	processed := []string{}
+	svc := newTestService(t, t.TempDir(), &processed)
+
+	_, err := svc.AddJob("demo", testJobConfig("http://localhost/oai"))
+	is.NoErr(err)
+
+	job, err := svc.getJob("demo", "archives")
+	is.NoErr(err)
+
+	is.NoErr(svc.queueDueJobs(context.TODO(), time.Now()))
+	is.Equal(len(svc.queue), 0) // not due yet
+
+	later := job.Snapshot().State.NextRun
+
+	_, err = svc.Pause("demo", "archives")
+	is.NoErr(err)
+	is.NoErr(svc.queueDueJobs(context.TODO(), later))
+	is.Equal(len(svc.queue), 0) // paused
+
+	_, err = svc.Resume("demo", "archives")
+	is.NoErr(err)
+	is.NoErr(svc.queueDueJobs(context.TODO(), later))
+	is.Equal(len(svc.queue), 1)
+	is.Equal(job.Snapshot().Status, StatusQueued)
+	is.Equal(job.trigger, TriggerSchedule)
+
+	_, err = svc.Trigger("demo", "archives")
+	is.True(errors.Is(err, ErrJobRunning))
+}
+
+func TestService_handleJobs(t *testing.T) {
+	is := is.New(t)
+
+	processed := []string{}
+	svc := newTestService(t, t.TempDir(), &processed)
+
+	_, err := svc.AddJob("demo", testJobConfig("http://localhost/oai"))
+	is.NoErr(err)
+
+	orgID, err := domain.NewOrganizationID("demo")
+	is.NoErr(err)
+
+	org := domain.Organization{ID: orgID}
+
+	do := func(method, target string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, target, nil)
+		r = domain.SetOrganization(r, &org)
+
+		w := httptest.NewRecorder()
+		svc.ServeHTTP(w, r)
+
+		return w
+	}
+
+	w := do(http.MethodGet, "/api/harvest/jobs")
+	is.Equal(w.Code, http.StatusOK)
+
+	var jobs []*Job
+	is.NoErr(json.NewDecoder(w.Body).Decode(&jobs))
+	is.Equal(len(jobs), 1)
+	is.Equal(jobs[0].ID, "archives")
+
+	w = do(http.MethodPost, "/api/harvest/jobs/archives/pause")
+	is.Equal(w.Code, http.StatusOK)
+
+	var job Job
+	is.NoErr(json.NewDecoder(w.Body).Decode(&job))
+	is.Equal(job.Status, StatusPaused)
+
+	w = do(http.MethodPost, "/api/harvest/jobs/archives/resume")
+	is.Equal(w.Code, http.StatusOK)
+
+	w = do(http.MethodPost, "/api/harvest/jobs/archives/run")
+	is.Equal(w.Code, http.StatusAccepted)
+
+	w = do(http.MethodPost, "/api/harvest/jobs/archives/run")
+	is.Equal(w.Code, http.StatusConflict)
+
+	w = do(http.MethodGet, "/api/harvest/jobs/archives")
+	is.Equal(w.Code, http.StatusOK)
+	is.NoErr(json.NewDecoder(w.Body).Decode(&job))
+	is.Equal(job.Status, StatusQueued)
+
+	w = do(http.MethodGet, "/api/harvest/jobs/unknown")
+	is.Equal(w.Code, http.StatusNotFound)
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/delving/hub3/ikuzo/domain"
+	iharvest "github.com/delving/hub3/ikuzo/service/x/harvest"
 	"github.com/go-chi/chi"
 	"github.com/go-chi/render"
 	"github.com/rs/zerolog"
@@ -29,11 +30,21 @@ type Service struct {
 	tasks        []*HarvestTask
 	log          zerolog.Logger
 	orgs         domain.OrgConfigRetriever
+	jobs         map[string]*Job
+	processors   map[string]Processor
+	store        iharvest.StateStore
+	queue        chan *Job
+	tick         time.Duration
+	historySize  int
 }
 
 func NewService(options ...Option) (*Service, error) {
 	s := &Service{
-		tasks: []*HarvestTask{},
+		tasks:      []*HarvestTask{},
+		jobs:       map[string]*Job{},
+		processors: map[string]Processor{},
+		queue:      make(chan *Job, 100),
+		tick:       time.Minute,
 	}
 
 	// apply options
@@ -51,6 +62,14 @@ func NewService(options ...Option) (*Service, error) {
 		s.defaultDelay = 1
 	}
 
+	if s.store == nil {
+		s.store = iharvest.NewMemoryStateStore()
+	}
+
+	if s.historySize == 0 {
+		s.historySize = defaultHistorySize
+	}
+
 	return s, nil
 }
 
@@ -76,12 +95,20 @@ func (s *Service) StartHarvestSync() error {
 
 	ticker := time.NewTicker(time.Duration(s.defaultDelay) * time.Minute)
 
+	g.Go(func() error {
+		return s.scheduleJobs(gctx)
+	})
+
 	for i := 0; i < s.workers; i++ {
 		g.Go(func() error {
 			for {
 				select {
 				case <-gctx.Done():
 					return gctx.Err()
+				case job := <-s.queue:
+					if err := s.runJob(gctx, job); err != nil {
+						return err
+					}
 				case <-ticker.C:
 					s.rw.Lock()
 					task := s.findAvailableTask()
@@ -151,7 +178,7 @@ func (s *Service) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Service) SetServiceBuilder(b *domain.ServiceBuilder) {
-	s.log = b.Logger.With().Str("svc", "sitemap").Logger()
+	s.log = b.Logger.With().Str("svc", "harvest").Logger()
 	s.orgs = b.Orgs
 }
 
@@ -0,0 +1,40 @@
+package harvest
+
+import "time"
+
+// Run is the report of a single run of a harvest job.
+type Run struct {
+	Trigger  string        `json:"trigger"` // schedule, retry or manual
+	Started  time.Time     `json:"started"`
+	Finished time.Time     `json:"finished"`
+	Duration time.Duration `json:"duration"`
+	From     string        `json:"from,omitempty"`
+	Until    string        `json:"until,omitempty"`
+	Records  int           `json:"records"`
+	Deleted  int           `json:"deleted"`
+	Pages    int           `json:"pages"`
+	Errors   []string      `json:"errors,omitempty"`
+	Failed   bool          `json:"failed"`
+}
+
+// JobState is the state of a harvest job that is persisted between restarts.
+type JobState struct {
+	OrgID string `json:"orgID"`
+	ID    string `json:"id"`
+	// Schedule is the schedule that NextRun was computed with
+	Schedule string      `json:"schedule"`
+	Paused   bool        `json:"paused"`
+	Info     HarvestInfo `json:"info"`
+	// Failures is the number of consecutive failed runs
+	Failures int       `json:"failures"`
+	NextRun  time.Time `json:"nextRun"`
+	// History contains the most recent runs, the latest last
+	History []Run `json:"history"`
+}
+
+func (state *JobState) copy() *JobState {
+	c := *state
+	c.History = append([]Run(nil), state.History...)
+
+	return &c
+}
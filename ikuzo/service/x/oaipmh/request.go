@@ -18,7 +18,6 @@ import (
 // 4. In cases where the request that generated this response resulted in a badVerb or badArgument error condition, the repository must return the base URL of the protocol request only. Attributes must not be provided in these cases.
 //
 // http://www.openarchives.org/OAI/openarchivesprotocol.html#XMLResponse
-//
 type Request struct {
 	Verb             string `xml:"verb,attr,omitempty"`
 	Identifier       string `xml:"identifier,attr,omitempty"`
@@ -61,14 +60,22 @@ func NewRequest(r *http.Request) Request {
 }
 
 func (request *Request) RequestConfig() RequestConfig {
-	return RequestConfig{
-		ID:           "",
-		FirstRequest: request,
-		OrgID:        request.orgConfig.OrgID(),
-		DatasetID:    request.Set,
-		TotalSize:    0,
-		Finished:     false,
+	cfg := RequestConfig{
+		ID:            "",
+		FirstRequest:  request,
+		OrgID:         request.orgConfig.OrgID(),
+		DatasetID:     request.Set,
+		TotalSize:     0,
+		Finished:      false,
+		DeletedRecord: request.orgConfig.OAIPMH.DeletedRecord(),
+		oaipmhCfg:     request.orgConfig.OAIPMH,
+	}
+
+	if request.Set == "" {
+		cfg.IncludedSets, cfg.ExcludedSets = request.orgConfig.OAIPMH.FormatSets(request.MetadataPrefix)
 	}
+
+	return cfg
 }
 
 func (request *Request) rawToken() (RawToken, error) {
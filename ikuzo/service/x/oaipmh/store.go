@@ -3,6 +3,8 @@ package oaipmh
 import (
 	"context"
 	"time"
+
+	"github.com/delving/hub3/ikuzo/domain"
 )
 
 type Store interface {
@@ -23,6 +25,64 @@ type RequestConfig struct {
 	TotalSize      int
 	Finished       bool
 	Filters        []string
+	DeletedRecord  string // level of deleted record tracking of the organization
+	// IncludedSets and ExcludedSets limit a harvest without a set to the sets
+	// that may disseminate the metadata prefix. IncludedSets is nil when all
+	// sets are harvested. See domain.OAIPMHConfig.FormatSets.
+	IncludedSets []string
+	ExcludedSets []string
+	oaipmhCfg    domain.OAIPMHConfig
+}
+
+// AllowsFormat returns true when the metadata prefix may be disseminated for
+// the set. See domain.OAIPMHConfig.AllowsFormat.
+func (q *RequestConfig) AllowsFormat(setSpec, prefix string) bool {
+	return q.oaipmhCfg.AllowsFormat(setSpec, prefix)
+}
+
+// HarvestsSet returns true when the records of the set are harvested, i.e. it
+// is not limited by IncludedSets and ExcludedSets.
+func (q *RequestConfig) HarvestsSet(setSpec string) bool {
+	contains := func(sets []string) bool {
+		for _, set := range sets {
+			if set == setSpec {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if q.IncludedSets != nil && !contains(q.IncludedSets) {
+		return false
+	}
+
+	return !contains(q.ExcludedSets)
+}
+
+// DeletedSince returns the time from which deleted records are reported. It
+// is zero when deleted records do not expire.
+func (q *RequestConfig) DeletedSince() time.Time {
+	expiry := q.oaipmhCfg.DeletedExpiry()
+	if expiry == 0 {
+		return time.Time{}
+	}
+
+	return time.Now().Add(-expiry)
+}
+
+// TrackDeleted returns true when the store must report deleted records.
+func (q *RequestConfig) TrackDeleted() bool {
+	return q.DeletedRecord != "" && q.DeletedRecord != domain.DeletedRecordNo
+}
+
+// DateRange returns the datestamp range of the first request of the harvest.
+func (q *RequestConfig) DateRange() (DateRange, error) {
+	if q.FirstRequest == nil {
+		return DateRange{}, nil
+	}
+
+	return q.FirstRequest.DateRange()
 }
 
 func (q *RequestConfig) IsResumedRequest() bool {
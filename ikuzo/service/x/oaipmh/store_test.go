@@ -0,0 +1,52 @@
+package oaipmh
+
+import (
+	"testing"
+	"time"
+
+	"github.com/delving/hub3/ikuzo/domain"
+	"github.com/matryer/is"
+)
+
+func TestRequestConfig_DeletedSince(t *testing.T) {
+	is := is.New(t)
+
+	q := &RequestConfig{oaipmhCfg: domain.OAIPMHConfig{Deleted: domain.DeletedRecordPersistent}}
+	is.True(q.DeletedSince().IsZero()) // persistent deleted records never expire
+
+	q.oaipmhCfg = domain.OAIPMHConfig{Deleted: domain.DeletedRecordTransient, DeletedTTL: "1h"}
+
+	since := q.DeletedSince()
+	is.True(since.Before(time.Now().Add(-59 * time.Minute)))
+	is.True(since.After(time.Now().Add(-61 * time.Minute)))
+}
+
+func TestRequestConfig_HarvestsSet(t *testing.T) {
+	is := is.New(t)
+
+	orgConfig := &domain.OrganizationConfig{}
+	orgConfig.OAIPMH.SetMetadataFormats = map[string][]string{"archive": {"ead"}}
+
+	request := &Request{MetadataPrefix: "oai_dc", orgConfig: orgConfig}
+
+	q := request.RequestConfig()
+	is.True(q.HarvestsSet("ds1"))
+	is.True(!q.HarvestsSet("archive")) // the set does not allow oai_dc
+
+	request.MetadataPrefix = "ead"
+
+	q = request.RequestConfig()
+	is.True(q.HarvestsSet("archive"))
+	is.True(q.HarvestsSet("ds1")) // no organization formats, so all formats are allowed
+
+	orgConfig.OAIPMH.MetadataFormats = []string{"oai_dc"}
+
+	q = request.RequestConfig()
+	is.True(q.HarvestsSet("archive"))
+	is.True(!q.HarvestsSet("ds1"))
+
+	request.Set = "ds1"
+
+	q = request.RequestConfig()
+	is.True(q.HarvestsSet("ds1")) // a harvest of a set is checked with AllowsFormat
+}
@@ -0,0 +1,28 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package reindex rebuilds a search index without downtime.
+//
+// A new versioned index is created next to the index behind the alias. It is
+// populated from a Source, e.g. the current index or records on disk, and the
+// document count is verified before the alias is switched atomically to the
+// new index. Optionally the old index is deleted afterwards.
+//
+// Records that are indexed via the alias during a reindex are written to the
+// old index. Writes are never blocked: after the alias is switched the
+// documents of the old index that are modified since the start of the reindex
+// are copied to the new index, unless the new index already has a newer
+// version. This also holds when the new index is populated from an older
+// version of the index or from records on disk.
+package reindex
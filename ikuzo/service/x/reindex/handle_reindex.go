@@ -0,0 +1,126 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reindex
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/delving/hub3/ikuzo/domain"
+	"github.com/delving/hub3/ikuzo/render"
+	"github.com/go-chi/chi"
+)
+
+// handleStart starts a background reindex of an index type of the
+// organization. The documents are copied from the current index of the alias,
+// or from the older versioned index of the alias in the 'from' parameter.
+func (s *Service) handleStart(w http.ResponseWriter, r *http.Request) {
+	org, ok := domain.GetOrganization(r)
+	if !ok {
+		http.Error(w, domain.ErrOrgNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	if s.backend == nil {
+		http.Error(w, ErrBackendNotSet.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	params := r.URL.Query()
+
+	indexType := domain.SanitizeParam(params.Get("indexType"))
+	if indexType == "" {
+		indexType = "v2"
+	}
+
+	alias, mapping, err := s.backend.Mapping(&org.Config, indexType)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	source := alias
+
+	if from := domain.SanitizeParam(params.Get("from")); from != "" {
+		if err := CheckSource(alias, from); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		source = from
+	}
+
+	var deleteOld bool
+
+	if param := params.Get("deleteOld"); param != "" {
+		deleteOld, err = strconv.ParseBool(param)
+		if err != nil {
+			http.Error(w, "deleteOld must be a boolean", http.StatusBadRequest)
+			return
+		}
+	}
+
+	job, err := s.Start(&Request{
+		OrgID:     org.ID.String(),
+		Alias:     alias,
+		Mapping:   mapping,
+		Source:    s.backend.IndexSource(source),
+		DeleteOld: deleteOld,
+	})
+	if err != nil {
+		if errors.Is(err, ErrJobRunning) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+
+		render.Error(w, r, err, &render.ErrorConfig{
+			StatusCode: http.StatusInternalServerError,
+			Message:    "unable to start reindex",
+			Log:        &s.log,
+		})
+
+		return
+	}
+
+	render.Status(r, http.StatusAccepted)
+	render.JSON(w, r, job)
+}
+
+func (s *Service) handleJobs(w http.ResponseWriter, r *http.Request) {
+	org, ok := domain.GetOrganization(r)
+	if !ok {
+		http.Error(w, domain.ErrOrgNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	render.JSON(w, r, s.Jobs(org.ID.String()))
+}
+
+func (s *Service) handleJob(w http.ResponseWriter, r *http.Request) {
+	org, ok := domain.GetOrganization(r)
+	if !ok {
+		http.Error(w, domain.ErrOrgNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	job, err := s.Job(org.ID.String(), chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	render.JSON(w, r, job)
+}
@@ -0,0 +1,185 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reindex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/delving/hub3/ikuzo/domain"
+)
+
+var (
+	ErrBackendNotSet  = errors.New("no reindex backend is configured")
+	ErrCountMismatch  = errors.New("document count of the new index does not match the source")
+	ErrJobNotFound    = errors.New("reindex job not found")
+	ErrJobRunning     = errors.New("a reindex job is already running for the alias")
+	ErrUnknownIndex   = errors.New("unknown index type")
+	ErrMissingRequest = errors.New("alias, mapping and source are required")
+	ErrInvalidSource  = errors.New("source must be the alias or a versioned index of the alias")
+)
+
+// Backend executes the reindex steps on the search engine.
+type Backend interface {
+	// CurrentIndex returns the index behind the alias. An empty string is
+	// returned when the alias does not exist.
+	CurrentIndex(alias string) (string, error)
+	// CreateIndex creates a new versioned index for the alias with the mapping.
+	// The alias is not changed.
+	CreateIndex(alias, mapping string) (string, error)
+	// Count returns the number of documents in the index after refreshing it.
+	Count(ctx context.Context, indexName string) (int64, error)
+	// SwitchAlias atomically moves the alias to the index. The alias is
+	// created when it does not exist yet.
+	SwitchAlias(alias, indexName string) error
+	DeleteIndex(indexName string) error
+	// Mapping returns the alias and the mapping of the index type of the
+	// organization, e.g. 'v2' or 'fragments'.
+	Mapping(cfg *domain.OrganizationConfig, indexType string) (alias, mapping string, err error)
+	// IndexSource returns a Source that copies the documents of an index or alias.
+	IndexSource(indexName string) Source
+	// CatchUp copies the documents of fromIndex that are modified since the
+	// start of the reindex to toIndex, unless toIndex has a newer version. It
+	// is called after the alias is switched to toIndex, so records that are
+	// indexed via the alias during the reindex are not lost. It returns the
+	// number of copied documents.
+	CatchUp(ctx context.Context, fromIndex, toIndex string, since time.Time) (int64, error)
+}
+
+// Source populates the new index.
+type Source interface {
+	// Populate adds the documents to the index. It returns the number of
+	// documents that are expected in the index.
+	Populate(ctx context.Context, indexName string, progress ProgressFunc) (int64, error)
+}
+
+// Progress is the progress of populating the new index.
+type Progress struct {
+	Total int64 `json:"total"`
+	Done  int64 `json:"done"`
+}
+
+// ProgressFunc is called when the progress of populating the index changes.
+type ProgressFunc func(p Progress)
+
+// versionSuffix matches the timestamp suffix of the versioned indices that
+// are created for an alias.
+var versionSuffix = regexp.MustCompile(`^\d{14}(\.\d+)?$`)
+
+// CheckSource returns ErrInvalidSource when indexName is not the alias or a
+// versioned index of the alias. This prevents copying the documents of another
+// index type into the new index.
+func CheckSource(alias, indexName string) error {
+	if indexName == alias {
+		return nil
+	}
+
+	if version := strings.TrimPrefix(indexName, alias+"_"); version != indexName && versionSuffix.MatchString(version) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s is not an index of %s", ErrInvalidSource, indexName, alias)
+}
+
+// Request configures a reindex.
+type Request struct {
+	OrgID     string
+	Alias     string
+	Mapping   string
+	Source    Source
+	DeleteOld bool
+	// Progress is called in addition to updating the progress of the Job.
+	Progress ProgressFunc
+}
+
+// State is the state of a reindex Job.
+type State string
+
+const (
+	StateRunning  State = "running"
+	StateFinished State = "finished"
+	StateFailed   State = "failed"
+)
+
+// Job reports the state of a reindex.
+type Job struct {
+	rw        sync.RWMutex
+	ID        string    `json:"id"`
+	OrgID     string    `json:"orgID"`
+	Alias     string    `json:"alias"`
+	OldIndex  string    `json:"oldIndex,omitempty"`
+	NewIndex  string    `json:"newIndex,omitempty"`
+	DeleteOld bool      `json:"deleteOld"`
+	State     State     `json:"state"`
+	Progress  Progress  `json:"progress"`
+	Expected  int64     `json:"expected"`
+	Count     int64     `json:"count"`
+	CaughtUp  int64     `json:"caughtUp"`
+	Error     string    `json:"error,omitempty"`
+	Started   time.Time `json:"started"`
+	Finished  time.Time `json:"finished,omitempty"`
+}
+
+// Snapshot returns a copy of the job that is safe to read while the job runs.
+func (j *Job) Snapshot() *Job {
+	j.rw.RLock()
+	defer j.rw.RUnlock()
+
+	return &Job{
+		ID:        j.ID,
+		OrgID:     j.OrgID,
+		Alias:     j.Alias,
+		OldIndex:  j.OldIndex,
+		NewIndex:  j.NewIndex,
+		DeleteOld: j.DeleteOld,
+		State:     j.State,
+		Progress:  j.Progress,
+		Expected:  j.Expected,
+		Count:     j.Count,
+		CaughtUp:  j.CaughtUp,
+		Error:     j.Error,
+		Started:   j.Started,
+		Finished:  j.Finished,
+	}
+}
+
+func (j *Job) update(fn func(j *Job)) {
+	j.rw.Lock()
+	defer j.rw.Unlock()
+
+	fn(j)
+}
+
+func (j *Job) isRunning() bool {
+	j.rw.RLock()
+	defer j.rw.RUnlock()
+
+	return j.State == StateRunning
+}
+
+func (j *Job) fail(err error) error {
+	j.update(func(j *Job) {
+		j.State = StateFailed
+		j.Error = err.Error()
+		j.Finished = time.Now()
+	})
+
+	return err
+}
@@ -0,0 +1,286 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reindex
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/delving/hub3/ikuzo/domain"
+	"github.com/go-chi/chi"
+	"github.com/rs/xid"
+	"github.com/rs/zerolog"
+)
+
+var _ domain.Service = (*Service)(nil)
+
+type Service struct {
+	backend Backend
+	rw      sync.RWMutex
+	jobs    map[string]*Job
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	log     zerolog.Logger
+}
+
+func NewService(options ...Option) (*Service, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	s := &Service{
+		jobs:   map[string]*Job{},
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	// apply options
+	for _, option := range options {
+		if err := option(s); err != nil {
+			cancel()
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// Reindex creates a new index for the alias, populates it from the Source and
+// switches the alias when the document count of the new index matches the
+// count reported by the Source. The writes to the old index during the
+// reindex are caught up after the switch. The new index is deleted when the
+// reindex fails before the alias is switched.
+func (s *Service) Reindex(ctx context.Context, req *Request) (*Job, error) {
+	job, err := s.newJob(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.run(ctx, job, req); err != nil {
+		return job.Snapshot(), err
+	}
+
+	return job.Snapshot(), nil
+}
+
+// Start runs the reindex in the background. The Job can be followed with Job.
+func (s *Service) Start(req *Request) (*Job, error) {
+	job, err := s.newJob(req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.wg.Add(1)
+
+	go func() {
+		defer s.wg.Done()
+
+		if err := s.run(s.ctx, job, req); err != nil {
+			s.log.Error().Err(err).Str("alias", req.Alias).Str("jobID", job.ID).Msg("reindex failed")
+		}
+	}()
+
+	return job.Snapshot(), nil
+}
+
+func (s *Service) newJob(req *Request) (*Job, error) {
+	if s.backend == nil {
+		return nil, ErrBackendNotSet
+	}
+
+	if req.Alias == "" || req.Mapping == "" || req.Source == nil {
+		return nil, ErrMissingRequest
+	}
+
+	s.rw.Lock()
+	defer s.rw.Unlock()
+
+	for _, job := range s.jobs {
+		if job.Alias == req.Alias && job.isRunning() {
+			return nil, fmt.Errorf("%w: %s", ErrJobRunning, req.Alias)
+		}
+	}
+
+	job := &Job{
+		ID:        xid.New().String(),
+		OrgID:     req.OrgID,
+		Alias:     req.Alias,
+		DeleteOld: req.DeleteOld,
+		State:     StateRunning,
+		Started:   time.Now(),
+	}
+
+	s.jobs[job.ID] = job
+
+	return job, nil
+}
+
+func (s *Service) run(ctx context.Context, job *Job, req *Request) error {
+	oldIndex, err := s.backend.CurrentIndex(req.Alias)
+	if err != nil {
+		return job.fail(fmt.Errorf("unable to get index for alias %s; %w", req.Alias, err))
+	}
+
+	newIndex, err := s.backend.CreateIndex(req.Alias, req.Mapping)
+	if err != nil {
+		return job.fail(fmt.Errorf("unable to create index for alias %s; %w", req.Alias, err))
+	}
+
+	job.update(func(j *Job) {
+		j.OldIndex = oldIndex
+		j.NewIndex = newIndex
+	})
+
+	s.log.Info().Str("alias", req.Alias).Str("oldIndex", oldIndex).Str("newIndex", newIndex).Msg("start reindex")
+
+	started := time.Now()
+
+	progress := func(p Progress) {
+		job.update(func(j *Job) { j.Progress = p })
+
+		if req.Progress != nil {
+			req.Progress(p)
+		}
+	}
+
+	expected, err := req.Source.Populate(ctx, newIndex, progress)
+	if err != nil {
+		return job.fail(s.cleanup(newIndex, fmt.Errorf("unable to populate index %s; %w", newIndex, err)))
+	}
+
+	count, err := s.backend.Count(ctx, newIndex)
+	if err != nil {
+		return job.fail(s.cleanup(newIndex, fmt.Errorf("unable to count index %s; %w", newIndex, err)))
+	}
+
+	job.update(func(j *Job) {
+		j.Expected = expected
+		j.Count = count
+	})
+
+	if count != expected {
+		return job.fail(s.cleanup(newIndex, fmt.Errorf("%w: %s has %d documents, expected %d", ErrCountMismatch, newIndex, count, expected)))
+	}
+
+	if err := s.backend.SwitchAlias(req.Alias, newIndex); err != nil {
+		return job.fail(s.cleanup(newIndex, fmt.Errorf("unable to switch alias %s to %s; %w", req.Alias, newIndex, err)))
+	}
+
+	// the old index is kept when the catch-up fails, so it can be retried
+	if oldIndex != "" {
+		caughtUp, err := s.backend.CatchUp(ctx, oldIndex, newIndex, started)
+
+		job.update(func(j *Job) { j.CaughtUp = caughtUp })
+
+		if err != nil {
+			return job.fail(fmt.Errorf("alias %s is switched, but unable to catch up with writes to %s; %w", req.Alias, oldIndex, err))
+		}
+	}
+
+	if req.DeleteOld && oldIndex != "" {
+		if err := s.backend.DeleteIndex(oldIndex); err != nil {
+			return job.fail(fmt.Errorf("alias %s is switched, but unable to delete old index %s; %w", req.Alias, oldIndex, err))
+		}
+	}
+
+	job.update(func(j *Job) {
+		j.State = StateFinished
+		j.Finished = time.Now()
+	})
+
+	s.log.Info().Str("alias", req.Alias).Str("newIndex", newIndex).Int64("count", count).Msg("finished reindex")
+
+	return nil
+}
+
+// cleanup deletes the new index after a failed reindex and returns err.
+func (s *Service) cleanup(indexName string, err error) error {
+	if deleteErr := s.backend.DeleteIndex(indexName); deleteErr != nil {
+		s.log.Error().Err(deleteErr).Str("index", indexName).Msg("unable to delete index of failed reindex")
+	}
+
+	return err
+}
+
+// Job returns the reindex job of the organization.
+func (s *Service) Job(orgID, id string) (*Job, error) {
+	s.rw.RLock()
+	defer s.rw.RUnlock()
+
+	job, ok := s.jobs[id]
+	if !ok || job.OrgID != orgID {
+		return nil, fmt.Errorf("%w: %s", ErrJobNotFound, id)
+	}
+
+	return job.Snapshot(), nil
+}
+
+// Jobs returns the reindex jobs of the organization, ordered by start time.
+func (s *Service) Jobs(orgID string) []*Job {
+	s.rw.RLock()
+	defer s.rw.RUnlock()
+
+	jobs := []*Job{}
+
+	for _, job := range s.jobs {
+		if job.OrgID == orgID {
+			jobs = append(jobs, job.Snapshot())
+		}
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].Started.Before(jobs[j].Started)
+	})
+
+	return jobs
+}
+
+func (s *Service) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	router := chi.NewRouter()
+	s.Routes("", router)
+	router.ServeHTTP(w, r)
+}
+
+func (s *Service) Routes(pattern string, router chi.Router) {
+	router.Post("/api/index/reindex", s.handleStart)
+	router.Get("/api/index/reindex", s.handleJobs)
+	router.Get("/api/index/reindex/{id}", s.handleJob)
+}
+
+// Shutdown cancels the running jobs and waits for them to stop.
+func (s *Service) Shutdown(ctx context.Context) error {
+	s.cancel()
+
+	done := make(chan struct{})
+
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Service) SetServiceBuilder(b *domain.ServiceBuilder) {
+	s.log = b.Logger.With().Str("svc", "reindex").Logger()
+}
@@ -0,0 +1,375 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reindex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/delving/hub3/ikuzo/domain"
+	"github.com/matryer/is"
+)
+
+// mockBackend keeps the aliases and document counts of the indices in memory.
+type mockBackend struct {
+	mu      sync.Mutex
+	aliases map[string]string
+	indices map[string]int64
+	version int
+	// writes is the number of documents that are written to the old index
+	// during the reindex
+	writes     int64
+	catchUpErr error
+	caughtUp   []string
+}
+
+func newMockBackend() *mockBackend {
+	return &mockBackend{
+		aliases: map[string]string{"demov2": "demov2_1"},
+		indices: map[string]int64{"demov2_1": 3},
+	}
+}
+
+func (m *mockBackend) CurrentIndex(alias string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.aliases[alias], nil
+}
+
+func (m *mockBackend) CreateIndex(alias, mapping string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.version++
+	name := fmt.Sprintf("%s_new%d", alias, m.version)
+	m.indices[name] = 0
+
+	return name, nil
+}
+
+func (m *mockBackend) Count(ctx context.Context, indexName string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.indices[indexName], nil
+}
+
+func (m *mockBackend) SwitchAlias(alias, indexName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.aliases[alias] = indexName
+
+	return nil
+}
+
+func (m *mockBackend) DeleteIndex(indexName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.indices, indexName)
+
+	return nil
+}
+
+func (m *mockBackend) Mapping(cfg *domain.OrganizationConfig, indexType string) (alias, mapping string, err error) {
+	if indexType != "v2" {
+		return "", "", fmt.Errorf("%w: %s", ErrUnknownIndex, indexType)
+	}
+
+	return cfg.GetIndexName(), "{}", nil
+}
+
+func (m *mockBackend) IndexSource(indexName string) Source {
+	return &mockSource{backend: m, from: indexName}
+}
+
+func (m *mockBackend) CatchUp(ctx context.Context, fromIndex, toIndex string, since time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.caughtUp = append(m.caughtUp, fromIndex+">"+toIndex)
+
+	if m.catchUpErr != nil {
+		return 0, m.catchUpErr
+	}
+
+	m.indices[toIndex] += m.writes
+
+	return m.writes, nil
+}
+
+// mockSource copies the document count of the index behind the alias. The
+// missing number of documents is not copied to simulate failures.
+type mockSource struct {
+	backend *mockBackend
+	from    string
+	missing int64
+	err     error
+}
+
+func (ms *mockSource) Populate(ctx context.Context, indexName string, progress ProgressFunc) (int64, error) {
+	if ms.err != nil {
+		return 0, ms.err
+	}
+
+	ms.backend.mu.Lock()
+	defer ms.backend.mu.Unlock()
+
+	total := ms.backend.indices[ms.backend.aliases[ms.from]]
+	ms.backend.indices[indexName] = total - ms.missing
+	ms.backend.indices[ms.backend.aliases["demov2"]] += ms.backend.writes
+
+	progress(Progress{Total: total, Done: total})
+
+	return total, nil
+}
+
+func TestService_Reindex(t *testing.T) {
+	tests := []struct {
+		name      string
+		source    func(b *mockBackend) Source
+		deleteOld bool
+		wantErr   error
+		wantAlias string
+		wantOld   bool
+		wantNew   bool
+		// writes are made to the old index during the reindex
+		writes     int64
+		catchUpErr error
+	}{
+		{
+			"switch alias and keep old index",
+			func(b *mockBackend) Source { return b.IndexSource("demov2") },
+			false,
+			nil,
+			"demov2_new1",
+			true,
+			true,
+			0,
+			nil,
+		},
+		{
+			"switch alias and delete old index",
+			func(b *mockBackend) Source { return b.IndexSource("demov2") },
+			true,
+			nil,
+			"demov2_new1",
+			false,
+			true,
+			0,
+			nil,
+		},
+		{
+			"count mismatch",
+			func(b *mockBackend) Source { return &mockSource{backend: b, from: "demov2", missing: 1} },
+			true,
+			ErrCountMismatch,
+			"demov2_1",
+			true,
+			false,
+			0,
+			nil,
+		},
+		{
+			"populate error",
+			func(b *mockBackend) Source { return &mockSource{backend: b, err: context.Canceled} },
+			false,
+			context.Canceled,
+			"demov2_1",
+			true,
+			false,
+			0,
+			nil,
+		},
+		{
+			"catch up with writes during reindex",
+			func(b *mockBackend) Source { return b.IndexSource("demov2") },
+			true,
+			nil,
+			"demov2_new1",
+			false,
+			true,
+			2,
+			nil,
+		},
+		{
+			"catch-up error keeps old index",
+			func(b *mockBackend) Source { return b.IndexSource("demov2") },
+			true,
+			context.DeadlineExceeded,
+			"demov2_new1",
+			true,
+			true,
+			2,
+			context.DeadlineExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+
+			backend := newMockBackend()
+			backend.writes = tt.writes
+			backend.catchUpErr = tt.catchUpErr
+
+			svc, err := NewService(SetBackend(backend))
+			is.NoErr(err)
+
+			var progress []Progress
+
+			source := tt.source(backend)
+
+			job, err := svc.Reindex(context.TODO(), &Request{
+				OrgID:     "demo",
+				Alias:     "demov2",
+				Mapping:   "{}",
+				Source:    source,
+				DeleteOld: tt.deleteOld,
+				Progress:  func(p Progress) { progress = append(progress, p) },
+			})
+			is.True(errors.Is(err, tt.wantErr))
+			is.Equal(backend.aliases["demov2"], tt.wantAlias)
+
+			_, ok := backend.indices["demov2_1"]
+			is.Equal(ok, tt.wantOld)
+
+			_, ok = backend.indices["demov2_new1"]
+			is.Equal(ok, tt.wantNew)
+
+			is.Equal(job.OldIndex, "demov2_1")
+			is.Equal(job.NewIndex, "demov2_new1")
+
+			if tt.wantNew && backend.aliases["demov2"] == "demov2_new1" {
+				is.Equal(backend.caughtUp, []string{"demov2_1>demov2_new1"}) // caught up after the switch
+			}
+
+			if tt.wantErr != nil {
+				is.Equal(job.State, StateFailed)
+				return
+			}
+
+			is.Equal(job.State, StateFinished)
+			is.Equal(job.Count, int64(3))
+			is.Equal(job.CaughtUp, tt.writes)
+			is.Equal(backend.indices["demov2_new1"], 3+tt.writes)
+			is.Equal(progress, []Progress{{Total: 3, Done: 3}})
+		})
+	}
+}
+
+func TestService_handleStart(t *testing.T) {
+	is := is.New(t)
+
+	backend := newMockBackend()
+
+	svc, err := NewService(SetBackend(backend))
+	is.NoErr(err)
+
+	orgID, err := domain.NewOrganizationID("demo")
+	is.NoErr(err)
+
+	org := domain.Organization{ID: orgID}
+	org.Config.ElasticSearch.IndexName = "demo"
+
+	do := func(method, target string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, target, nil)
+		r = domain.SetOrganization(r, &org)
+
+		w := httptest.NewRecorder()
+		svc.ServeHTTP(w, r)
+
+		return w
+	}
+
+	w := do(http.MethodPost, "/api/index/reindex?indexType=fragments")
+	is.Equal(w.Code, http.StatusBadRequest)
+
+	w = do(http.MethodPost, "/api/index/reindex?deleteOld=maybe")
+	is.Equal(w.Code, http.StatusBadRequest)
+
+	w = do(http.MethodPost, "/api/index/reindex?from=demov2_frag")
+	is.Equal(w.Code, http.StatusBadRequest)
+
+	w = do(http.MethodPost, "/api/index/reindex?deleteOld=true")
+	is.Equal(w.Code, http.StatusAccepted)
+
+	var job Job
+	is.NoErr(json.NewDecoder(w.Body).Decode(&job))
+	is.Equal(job.Alias, "demov2")
+
+	is.NoErr(svc.Shutdown(context.TODO()))
+
+	w = do(http.MethodGet, "/api/index/reindex/"+job.ID)
+	is.Equal(w.Code, http.StatusOK)
+
+	var got Job
+	is.NoErr(json.NewDecoder(w.Body).Decode(&got))
+	is.Equal(got.State, StateFinished)
+	is.Equal(got.NewIndex, "demov2_new1")
+	is.True(got.Finished.After(time.Time{}))
+	is.Equal(backend.aliases["demov2"], "demov2_new1")
+
+	w = do(http.MethodGet, "/api/index/reindex")
+	is.Equal(w.Code, http.StatusOK)
+
+	var jobs []*Job
+	is.NoErr(json.NewDecoder(w.Body).Decode(&jobs))
+	is.Equal(len(jobs), 1)
+
+	w = do(http.MethodGet, "/api/index/reindex/unknown")
+	is.Equal(w.Code, http.StatusNotFound)
+}
+
+func TestCheckSource(t *testing.T) {
+	tests := []struct {
+		name      string
+		indexName string
+		wantErr   bool
+	}{
+		{"alias", "demov2", false},
+		{"versioned index", "demov2_20200716073932", false},
+		{"versioned index with millis", "demov2_20200716073932.12", false},
+		{"other index type", "demov2_frag", true},
+		{"versioned index of other index type", "demov2_frag_20200716073932", true},
+		{"other alias", "otherv2", true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckSource("demov2", tt.indexName)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckSource() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err != nil && !errors.Is(err, ErrInvalidSource) {
+				t.Errorf("CheckSource() error = %v, want %v", err, ErrInvalidSource)
+			}
+		})
+	}
+}
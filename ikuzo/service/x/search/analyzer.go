@@ -14,20 +14,177 @@
 
 package search
 
-import "strings"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
 const (
 	trimCharacters = "\".,;:[]()?'`"
 )
 
+var ErrUnknownLanguage = errors.New("unknown analyzer language")
+
+// Language selects the stopwords and stemmer of an Analyzer.
+type Language string
+
+const (
+	// DefaultLanguage only folds to ASCII and lowercases.
+	DefaultLanguage Language = ""
+	Dutch           Language = "nl"
+	English         Language = "en"
+)
+
+// ParseLanguage returns the Language for an ISO 639-1 code or the English name
+// of the language, e.g. 'nl' or 'dutch'. An empty string is the DefaultLanguage.
+func ParseLanguage(lang string) (Language, error) {
+	switch strings.ToLower(lang) {
+	case "":
+		return DefaultLanguage, nil
+	case "nl", "dutch":
+		return Dutch, nil
+	case "en", "english":
+		return English, nil
+	}
+
+	return DefaultLanguage, fmt.Errorf("%w: %q", ErrUnknownLanguage, lang)
+}
+
+// Name returns the lowercase English name of the language, as used by the
+// ElasticSearch stop filter and, except for English, the stemmer filter.
+func (l Language) Name() string {
+	switch l {
+	case Dutch:
+		return "dutch"
+	case English:
+		return "english"
+	}
+
+	return ""
+}
+
+// TokenFilter transforms an analyzed term. It returns an empty string when the
+// term must not be indexed.
+type TokenFilter func(term string) string
+
+// StopFilter returns a TokenFilter that removes the stopwords.
+func StopFilter(stopwords ...string) TokenFilter {
+	stop := make(map[string]bool, len(stopwords))
+	for _, word := range stopwords {
+		stop[word] = true
+	}
+
+	return func(term string) string {
+		if stop[term] {
+			return ""
+		}
+
+		return term
+	}
+}
+
+// TokenizerFunc splits the text of a document into a TokenStream.
+type TokenizerFunc func(text string, docID int) *TokenStream
+
+// DefaultTokenizer is the TokenizerFunc of an Analyzer without SetTokenizer.
+// It splits the text with the Tokenizer.
+func DefaultTokenizer(text string, docID int) *TokenStream {
+	return NewTokenizer().ParseString(text, docID)
+}
+
 // Analyzer is the default analyzer for Search actions.
-// It folds unicode to ASCII characters and lowercases them all.
+// The text is split into tokens by its TokenizerFunc. Each term is folded from
+// unicode to ASCII characters and lowercased. The resulting terms are passed
+// through the TokenFilters of the analyzer, e.g. a StopFilter and a stemmer.
 //
 // The goal is to have this analyzer behave similarly to the ElasticSearch
 // Analyzer that Ikuzo comes preconfigured with.
-type Analyzer struct{}
+type Analyzer struct {
+	language  Language
+	tokenizer TokenizerFunc
+	filters   []TokenFilter
+}
 
-func (a *Analyzer) Transform(text string) string {
+type AnalyzerOption func(a *Analyzer) error
+
+// NewAnalyzer returns an Analyzer. Without options it behaves like the zero
+// value Analyzer.
+func NewAnalyzer(options ...AnalyzerOption) (*Analyzer, error) {
+	a := &Analyzer{}
+
+	for _, option := range options {
+		if err := option(a); err != nil {
+			return nil, err
+		}
+	}
+
+	return a, nil
+}
+
+// SetLanguage adds the stopwords and stemmer of the language to the analyzer.
+// See ParseLanguage for the supported values.
+func SetLanguage(lang string) AnalyzerOption {
+	return func(a *Analyzer) error {
+		l, err := ParseLanguage(lang)
+		if err != nil {
+			return err
+		}
+
+		a.language = l
+
+		switch l {
+		case Dutch:
+			a.filters = append(a.filters, StopFilter(DutchStopWords...), StemDutch)
+		case English:
+			a.filters = append(a.filters, StopFilter(EnglishStopWords...), StemEnglish)
+		case DefaultLanguage:
+		}
+
+		return nil
+	}
+}
+
+// SetTokenizer replaces the DefaultTokenizer of the analyzer.
+func SetTokenizer(tokenizer TokenizerFunc) AnalyzerOption {
+	return func(a *Analyzer) error {
+		if tokenizer == nil {
+			return fmt.Errorf("tokenizer of the analyzer cannot be nil")
+		}
+
+		a.tokenizer = tokenizer
+
+		return nil
+	}
+}
+
+// SetTokenFilters appends the filters to the analyzer.
+func SetTokenFilters(filters ...TokenFilter) AnalyzerOption {
+	return func(a *Analyzer) error {
+		a.filters = append(a.filters, filters...)
+		return nil
+	}
+}
+
+// Language returns the language of the analyzer.
+func (a *Analyzer) Language() Language {
+	return a.language
+}
+
+// Tokenize splits the text of the document into a TokenStream with the
+// TokenizerFunc of the analyzer.
+func (a *Analyzer) Tokenize(text string, docID int) *TokenStream {
+	if a.tokenizer == nil {
+		return DefaultTokenizer(text, docID)
+	}
+
+	return a.tokenizer(text, docID)
+}
+
+// Normalize folds the text to ASCII and lowercases it without applying the
+// TokenFilters. It is used for terms that are not analyzed, like wildcard and
+// fuzzy terms.
+func (a *Analyzer) Normalize(text string) string {
 	return strings.Trim(
 		strings.ToLower(
 			LuceneASCIIFolding(text),
@@ -36,11 +193,35 @@ func (a *Analyzer) Transform(text string) string {
 	)
 }
 
+// Transform normalizes the text and applies the TokenFilters. It returns an
+// empty string when the text is removed by one of the filters.
+func (a *Analyzer) Transform(text string) string {
+	term := a.Normalize(text)
+
+	for _, filter := range a.filters {
+		if term == "" {
+			break
+		}
+
+		term = filter(term)
+	}
+
+	return term
+}
+
+// TransformPhrase transforms each token of the text. Tokens that are removed by
+// the TokenFilters are dropped from the phrase.
 func (a *Analyzer) TransformPhrase(text string) string {
 	cleanWords := []string{}
 
-	for _, word := range strings.Fields(text) {
-		cleanWords = append(cleanWords, a.Transform(word))
+	for _, token := range a.Tokenize(text, 0).Tokens() {
+		if !token.isTermVector() {
+			continue
+		}
+
+		if clean := a.Transform(token.RawText); clean != "" {
+			cleanWords = append(cleanWords, clean)
+		}
 	}
 
 	return strings.Join(cleanWords, " ")
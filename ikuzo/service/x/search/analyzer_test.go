@@ -15,6 +15,8 @@
 package search
 
 import (
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -79,3 +81,113 @@ func TestAnalyzer_Transform(t *testing.T) {
 		})
 	}
 }
+
+func TestAnalyzer_language(t *testing.T) {
+	tests := []struct {
+		name       string
+		lang       string
+		text       string
+		want       string
+		phrase     string
+		wantPhrase string
+	}{
+		{"default", "", "Boerderijen", "boerderijen", "de boerderijen", "de boerderijen"},
+		{"dutch", "nl", "Boerderijen", "boerderij", "de oude Boerderijen", "oud boerderij"},
+		{"dutch by name", "Dutch", "lichamelijke", "licham", "van", ""},
+		{"dutch stopword", "nl", "van", "", "huis van Oranje", "huis oranj"},
+		{"english", "en", "Consolations", "consol", "the consolations", "consol"},
+		{"english stopword", "english", "The", "", "running with the wind", "run wind"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := NewAnalyzer(SetLanguage(tt.lang))
+			if err != nil {
+				t.Fatalf("NewAnalyzer() error = %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, a.Transform(tt.text)); diff != "" {
+				t.Errorf("Analyzer.Transform(); %s = mismatch (-want +got):\n%s", tt.name, diff)
+			}
+
+			if diff := cmp.Diff(tt.wantPhrase, a.TransformPhrase(tt.phrase)); diff != "" {
+				t.Errorf("Analyzer.TransformPhrase(); %s = mismatch (-want +got):\n%s", tt.name, diff)
+			}
+		})
+	}
+}
+
+func TestNewAnalyzer(t *testing.T) {
+	_, err := NewAnalyzer(SetLanguage("klingon"))
+	if !errors.Is(err, ErrUnknownLanguage) {
+		t.Errorf("NewAnalyzer() error = %v, want %v", err, ErrUnknownLanguage)
+	}
+
+	reverse := func(term string) string {
+		r := []rune(term)
+		for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+			r[i], r[j] = r[j], r[i]
+		}
+
+		return string(r)
+	}
+
+	a, err := NewAnalyzer(SetTokenFilters(StopFilter("stop"), reverse))
+	if err != nil {
+		t.Fatalf("NewAnalyzer() error = %v", err)
+	}
+
+	if got := a.Transform("Stop"); got != "" {
+		t.Errorf("Analyzer.Transform() = %q, want stopword to be removed", got)
+	}
+
+	if got := a.Transform("Woord"); got != "droow" {
+		t.Errorf("Analyzer.Transform() = %q, want %q", got, "droow")
+	}
+
+	if got := a.Normalize("Woord*"); got != "woord*" {
+		t.Errorf("Analyzer.Normalize() = %q, want %q", got, "woord*")
+	}
+}
+
+func TestAnalyzer_Tokenize(t *testing.T) {
+	a, err := NewAnalyzer()
+	if err != nil {
+		t.Fatalf("NewAnalyzer() error = %v", err)
+	}
+
+	if got := a.TransformPhrase("Oude boerderij, Drenthe"); got != "oude boerderij drenthe" {
+		t.Errorf("Analyzer.TransformPhrase() = %q, want %q", got, "oude boerderij drenthe")
+	}
+
+	// split compound words on a hyphen
+	hyphens := func(text string, docID int) *TokenStream {
+		tokens := []Token{}
+
+		for i, word := range strings.FieldsFunc(text, func(r rune) bool { return r == '-' || r == ' ' }) {
+			tokens = append(tokens, Token{RawText: word, TermVector: i + 1, Vector: i + 1, DocID: docID})
+		}
+
+		return NewTokenStream(tokens)
+	}
+
+	_, err = NewAnalyzer(SetTokenizer(nil))
+	if err == nil {
+		t.Errorf("NewAnalyzer() expected error for nil tokenizer")
+	}
+
+	a, err = NewAnalyzer(SetTokenizer(hyphens))
+	if err != nil {
+		t.Fatalf("NewAnalyzer() error = %v", err)
+	}
+
+	if got := a.TransformPhrase("Noord-Brabant"); got != "noord brabant" {
+		t.Errorf("Analyzer.TransformPhrase() = %q, want %q", got, "noord brabant")
+	}
+
+	if got := len(a.Tokenize("Noord-Brabant", 1).Tokens()); got != 2 {
+		t.Errorf("Analyzer.Tokenize() returned %d tokens, want 2", got)
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidDateMath = errors.New("invalid date math expression")
+
+// dateLayouts are the supported layouts of a date anchor, from the most to the
+// least precise.
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+	"2006-01",
+	"2006",
+}
+
+// ParseDate parses a date in one of the supported layouts: RFC3339,
+// 2006-01-02T15:04:05, 2006-01-02, 2006-01 or 2006.
+func ParseDate(s string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%w: unable to parse date %q", ErrInvalidDateMath, s)
+}
+
+// ParseDateMath resolves an Elasticsearch style date math expression relative
+// to now.
+//
+// The expression starts with 'now' or a date followed by '||', and is followed
+// by zero or more operations: '+1y' or '-10d' add or subtract a number of units
+// and '/M' rounds down to the start of the unit. The supported units are y
+// (year), M (month), w (week), d (day), h or H (hour), m (minute) and s (second).
+// A date without operations can also be given without '||'.
+//
+// Examples: 'now-10y', 'now/d', '2020-01-01||+1M/M' and '1900'.
+func ParseDateMath(expr string, now time.Time) (time.Time, error) {
+	var (
+		anchor time.Time
+		ops    string
+		err    error
+	)
+
+	switch {
+	case strings.HasPrefix(expr, "now"):
+		anchor = now
+		ops = strings.TrimPrefix(expr, "now")
+	case strings.Contains(expr, "||"):
+		parts := strings.SplitN(expr, "||", 2)
+
+		anchor, err = ParseDate(parts[0])
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		ops = parts[1]
+	default:
+		return ParseDate(expr)
+	}
+
+	for ops != "" {
+		op := ops[0]
+		ops = ops[1:]
+
+		switch op {
+		case '+', '-':
+			i := 0
+			for i < len(ops) && ops[i] >= '0' && ops[i] <= '9' {
+				i++
+			}
+
+			if i == 0 || i == len(ops) {
+				return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidDateMath, expr)
+			}
+
+			n, err := strconv.Atoi(ops[:i])
+			if err != nil {
+				return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidDateMath, expr)
+			}
+
+			if op == '-' {
+				n = -n
+			}
+
+			anchor, err = addUnit(anchor, ops[i], n)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("%w: %q; %s", ErrInvalidDateMath, expr, err)
+			}
+
+			ops = ops[i+1:]
+		case '/':
+			if ops == "" {
+				return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidDateMath, expr)
+			}
+
+			anchor, err = roundUnit(anchor, ops[0])
+			if err != nil {
+				return time.Time{}, fmt.Errorf("%w: %q; %s", ErrInvalidDateMath, expr, err)
+			}
+
+			ops = ops[1:]
+		default:
+			return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidDateMath, expr)
+		}
+	}
+
+	return anchor, nil
+}
+
+func addUnit(t time.Time, unit byte, n int) (time.Time, error) {
+	switch unit {
+	case 'y':
+		return t.AddDate(n, 0, 0), nil
+	case 'M':
+		return t.AddDate(0, n, 0), nil
+	case 'w':
+		return t.AddDate(0, 0, 7*n), nil
+	case 'd':
+		return t.AddDate(0, 0, n), nil
+	case 'h', 'H':
+		return t.Add(time.Duration(n) * time.Hour), nil
+	case 'm':
+		return t.Add(time.Duration(n) * time.Minute), nil
+	case 's':
+		return t.Add(time.Duration(n) * time.Second), nil
+	}
+
+	return t, fmt.Errorf("unknown unit %q", unit)
+}
+
+func roundUnit(t time.Time, unit byte) (time.Time, error) {
+	y, m, d := t.Date()
+
+	switch unit {
+	case 'y':
+		return time.Date(y, time.January, 1, 0, 0, 0, 0, t.Location()), nil
+	case 'M':
+		return time.Date(y, m, 1, 0, 0, 0, 0, t.Location()), nil
+	case 'w':
+		offset := (int(t.Weekday()) + 6) % 7 // weeks start on monday
+		return time.Date(y, m, d-offset, 0, 0, 0, 0, t.Location()), nil
+	case 'd':
+		return time.Date(y, m, d, 0, 0, 0, 0, t.Location()), nil
+	case 'h', 'H':
+		return t.Truncate(time.Hour), nil
+	case 'm':
+		return t.Truncate(time.Minute), nil
+	case 's':
+		return t.Truncate(time.Second), nil
+	}
+
+	return t, fmt.Errorf("unknown unit %q", unit)
+}
@@ -0,0 +1,68 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// nolint:gocritic
+package search
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseDateMath(t *testing.T) {
+	now := time.Date(2020, time.June, 17, 14, 30, 15, 0, time.UTC) // a wednesday
+
+	tests := []struct {
+		name    string
+		expr    string
+		want    time.Time
+		wantErr bool
+	}{
+		{"now", "now", now, false},
+		{"subtract years", "now-10y", time.Date(2010, time.June, 17, 14, 30, 15, 0, time.UTC), false},
+		{"add months", "now+2M", time.Date(2020, time.August, 17, 14, 30, 15, 0, time.UTC), false},
+		{"round to day", "now/d", time.Date(2020, time.June, 17, 0, 0, 0, 0, time.UTC), false},
+		{"round to week", "now/w", time.Date(2020, time.June, 15, 0, 0, 0, 0, time.UTC), false},
+		{"chained operations", "now-1d/M+1h", time.Date(2020, time.June, 1, 1, 0, 0, 0, time.UTC), false},
+		{"date anchor", "2020-01-31||+1M/M", time.Date(2020, time.March, 1, 0, 0, 0, 0, time.UTC), false},
+		{"year", "1900", time.Date(1900, time.January, 1, 0, 0, 0, 0, time.UTC), false},
+		{"month", "1900-05", time.Date(1900, time.May, 1, 0, 0, 0, 0, time.UTC), false},
+		{"timestamp", "2020-06-17T10:00:00Z", time.Date(2020, time.June, 17, 10, 0, 0, 0, time.UTC), false},
+		{"unknown unit", "now-1q", time.Time{}, true},
+		{"missing unit", "now-1", time.Time{}, true},
+		{"missing number", "now-y", time.Time{}, true},
+		{"bad anchor", "yesterday||-1d", time.Time{}, true},
+		{"not a date", "archief", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDateMath(tt.expr, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDateMath() %s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+
+			if err != nil && !errors.Is(err, ErrInvalidDateMath) {
+				t.Errorf("ParseDateMath() %s error = %v, want ErrInvalidDateMath", tt.name, err)
+			}
+
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseDateMath() %s = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import "strings"
+
+// Expander returns the alternative values for the value of a query term, like
+// the synonyms and narrower terms from a vocabulary.
+type Expander interface {
+	Expand(value string) []string
+}
+
+// SetExpander expands the term and phrase queries with the values returned by
+// the Expander. The expansions are added as optional clauses next to the
+// query term. A required query term is replaced by a BoolQuery where either the
+// query term or one of the expansions must match. Prohibited query terms are
+// not expanded.
+func SetExpander(e Expander) QueryOption {
+	return func(qp *QueryParser) error {
+		qp.expander = e
+		return nil
+	}
+}
+
+// expand returns the analyzed expansions of the raw value of the query term.
+func (qp *QueryParser) expand(term *QueryTerm, raw string) []*QueryTerm {
+	if qp.expander == nil {
+		return nil
+	}
+
+	switch term.Type() {
+	case TermQuery, PhraseQuery:
+	default:
+		return nil
+	}
+
+	expansions := []*QueryTerm{}
+	seen := map[string]bool{term.Value: true}
+
+	for _, value := range qp.expander.Expand(raw) {
+		qt := &QueryTerm{
+			Field:  term.Field,
+			Boost:  term.Boost,
+			Value:  value,
+			Phrase: len(strings.Fields(value)) > 1,
+		}
+
+		exp := qp.analyze(qt)
+		if exp.Value == "" || seen[exp.Value] {
+			continue
+		}
+
+		seen[exp.Value] = true
+
+		expansions = append(expansions, exp)
+	}
+
+	return expansions
+}
@@ -0,0 +1,115 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// nolint:gocritic
+package search
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/matryer/is"
+)
+
+type mapExpander map[string][]string
+
+func (m mapExpander) Expand(value string) []string {
+	return m[value]
+}
+
+func TestSetExpander(t *testing.T) {
+	is := is.New(t)
+
+	expander := mapExpander{
+		"Landbouw": {"agrarische sector", "Akkerbouw", "landbouw"},
+		"kaart":    {"plattegrond"},
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  *QueryTerm
+	}{
+		{
+			"optional term",
+			"Landbouw",
+			&QueryTerm{
+				shouldClauses: []*QueryTerm{
+					{Value: "landbouw"},
+					{Value: "agrarische sector", Phrase: true},
+					{Value: "akkerbouw"},
+				},
+			},
+		},
+		{
+			"required term",
+			"kaart AND archief",
+			&QueryTerm{
+				mustClauses: []*QueryTerm{
+					{
+						shouldClauses: []*QueryTerm{
+							{Value: "kaart"},
+							{Value: "plattegrond"},
+						},
+					},
+					{Value: "archief"},
+				},
+			},
+		},
+		{
+			"field and boost are kept",
+			"subject:kaart^2",
+			&QueryTerm{
+				shouldClauses: []*QueryTerm{
+					{Field: "subject", Value: "kaart", Boost: 2},
+					{Field: "subject", Value: "plattegrond", Boost: 2},
+				},
+			},
+		},
+		{
+			"prohibited term is not expanded",
+			"-kaart",
+			&QueryTerm{
+				mustNotClauses: []*QueryTerm{
+					{Value: "kaart", Prohibited: true},
+				},
+			},
+		},
+		{
+			"wildcard is not expanded",
+			"kaart*",
+			&QueryTerm{
+				shouldClauses: []*QueryTerm{
+					{Value: "kaart", PrefixWildcard: true},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			qp, err := NewQueryParser(SetExpander(expander))
+			is.NoErr(err)
+
+			got, err := qp.Parse(tt.query)
+			is.NoErr(err)
+
+			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(QueryTerm{})); diff != "" {
+				t.Errorf("SetExpander(); %s = mismatch (-want +got):\n%s", tt.name, diff)
+			}
+		})
+	}
+}
@@ -14,15 +14,25 @@
 
 package search
 
+import "sort"
+
+// DocScore is the relevance score of a matching document.
+type DocScore struct {
+	DocID int     `json:"docID"`
+	Score float64 `json:"score"`
+}
+
 type Matches struct {
 	termFrequency map[string]int
 	termVectors   *Vectors
+	scores        map[int]float64
 }
 
 func NewMatches() *Matches {
 	return &Matches{
 		termFrequency: make(map[string]int),
 		termVectors:   NewVectors(),
+		scores:        make(map[int]float64),
 	}
 }
 
@@ -30,6 +40,7 @@ func NewMatches() *Matches {
 func (m *Matches) Reset() {
 	m.termFrequency = make(map[string]int)
 	m.termVectors = NewVectors()
+	m.scores = make(map[int]float64)
 }
 
 func (m *Matches) AppendTerm(term string, tv *Vectors) {
@@ -61,6 +72,10 @@ func (m *Matches) Merge(matches *Matches) {
 	}
 
 	m.mergeVectors(matches.termVectors)
+
+	for docID, score := range matches.scores {
+		m.AddScore(docID, score)
+	}
 }
 
 func (m *Matches) mergeVectors(tv *Vectors) {
@@ -87,3 +102,37 @@ func (m *Matches) Total() int {
 func (m *Matches) Vectors() *Vectors {
 	return m.termVectors
 }
+
+// AddScore adds score to the relevance score of the document.
+func (m *Matches) AddScore(docID int, score float64) {
+	if m.scores == nil {
+		m.scores = make(map[int]float64)
+	}
+
+	m.scores[docID] += score
+}
+
+// Score returns the relevance score of the document.
+func (m *Matches) Score(docID int) float64 {
+	return m.scores[docID]
+}
+
+// Ranked returns the matching documents ordered by descending score. Documents
+// with the same score are ordered by DocID.
+func (m *Matches) Ranked() []DocScore {
+	ranked := make([]DocScore, 0, len(m.termVectors.Docs))
+
+	for docID := range m.termVectors.Docs {
+		ranked = append(ranked, DocScore{DocID: docID, Score: m.scores[docID]})
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+
+		return ranked[i].DocID < ranked[j].DocID
+	})
+
+	return ranked
+}
@@ -43,6 +43,7 @@ const (
 	BoolQuery QueryType = iota
 	FuzzyQuery
 	PhraseQuery
+	RangeQuery
 	TermQuery
 	WildCardQuery
 )
@@ -52,11 +53,23 @@ func (qt QueryType) String() string {
 		"BoolQuery",
 		"FuzzyQuery",
 		"PhraseQuery",
+		"RangeQuery",
 		"TermQuery",
 		"WildCardQuery",
 	}[qt]
 }
 
+// Range contains the bounds of a RangeQuery. An empty bound is unbounded.
+//
+// The bounds are not analyzed. They can be numbers, strings, dates or date
+// math expressions like 'now-10y' (see ParseDateMath).
+type Range struct {
+	Lower        string
+	Upper        string
+	IncludeLower bool
+	IncludeUpper bool
+}
+
 type QueryTerm struct {
 	Field          string
 	Value          string
@@ -67,6 +80,7 @@ type QueryTerm struct {
 	Boost          float64
 	Fuzzy          int // fuzzy is for words
 	Slop           int // slop is for phrases
+	Range          *Range
 	mustClauses    []*QueryTerm
 	mustNotClauses []*QueryTerm
 	shouldClauses  []*QueryTerm
@@ -78,6 +92,8 @@ func (qt *QueryTerm) Type() QueryType {
 	switch {
 	case qt.IsBoolQuery():
 		return BoolQuery
+	case qt.Range != nil:
+		return RangeQuery
 	case qt.Phrase:
 		return PhraseQuery
 	case qt.PrefixWildcard, qt.SuffixWildcard:
@@ -220,6 +236,11 @@ type QueryOption func(*QueryParser) error
 // '^N' at the end of phrases specifies a boost query: "term1 term2"~2.4
 // '(' and ')' specifies precedence: token1 + (token2 | token3)
 // ':' in the middle of terms specifies the end of a query field
+// '[a TO b]' specifies an inclusive range query: field:[1900 TO 1950]
+// '{a TO b}' specifies an exclusive range query; '[' and '}' can be mixed
+// '*' as a range bound specifies an open-ended range: field:[1900 TO *]
+// '>', '>=', '<' and '<=' after a field specify open-ended ranges: field:>=1900
+// 'now-10y' style date math can be used as range bound: date:[now-10y TO now]
 
 //
 // The default operator is OR if no other operator is specified. For example, the following will OR token1 and token2
@@ -257,6 +278,7 @@ type QueryParser struct {
 	s          *scanner.Scanner
 	a          Analyzer
 	fields     []string
+	expander   Expander
 }
 
 // NewQueryParser returns a QueryParser that can be used to parse user queries.
@@ -303,6 +325,15 @@ func SetFields(field ...string) QueryOption {
 	}
 }
 
+// SetAnalyzer sets the Analyzer that transforms the query terms. It must be
+// the same Analyzer that was used to create the index that is searched.
+func SetAnalyzer(a *Analyzer) QueryOption {
+	return func(qp *QueryParser) error {
+		qp.a = *a
+		return nil
+	}
+}
+
 // Fields returns the default search fields for the query
 func (qp *QueryParser) Fields() []string {
 	return qp.fields
@@ -322,19 +353,50 @@ func (qp *QueryParser) appendQuery(parent *QueryTerm, op Operator, qt *QueryTerm
 		qt.nested = nil
 	}
 
-	qt.Value = qp.a.TransformPhrase(qt.Value)
+	raw := qt.Value
+	term := qp.analyze(qt)
 
 	switch op {
 	case AndOperator:
-		parent.mustClauses = append(parent.mustClauses, qt.copy())
+		if expansions := qp.expand(term, raw); len(expansions) != 0 {
+			// one of the term or its expansions must match
+			term = &QueryTerm{shouldClauses: append([]*QueryTerm{term}, expansions...)}
+		}
+
+		parent.mustClauses = append(parent.mustClauses, term)
 	case OrOperator:
-		parent.shouldClauses = append(parent.shouldClauses, qt.copy())
+		parent.shouldClauses = append(parent.shouldClauses, term)
+		parent.shouldClauses = append(parent.shouldClauses, qp.expand(term, raw)...)
 	case NotOperator:
 		qt.Prohibited = true
-		parent.mustNotClauses = append(parent.mustNotClauses, qt.copy())
+		term.Prohibited = true
+		parent.mustNotClauses = append(parent.mustNotClauses, term)
 	}
 }
 
+// analyze transforms the value of the query term with the Analyzer and returns
+// a copy of the query term.
+func (qp *QueryParser) analyze(qt *QueryTerm) *QueryTerm {
+	var removed int
+
+	switch qt.Type() {
+	case WildCardQuery, FuzzyQuery:
+		qt.Value = qp.a.Normalize(qt.Value)
+	case PhraseQuery:
+		words := len(strings.Fields(qt.Value))
+		qt.Value = qp.a.TransformPhrase(qt.Value)
+		removed = words - len(strings.Fields(qt.Value))
+	default:
+		qt.Value = qp.a.TransformPhrase(qt.Value)
+	}
+
+	term := qt.copy()
+	// keep the positions of the stopwords that are removed from a phrase as slop
+	term.Slop += removed
+
+	return term
+}
+
 func (qp *QueryParser) tokenText() string {
 	return qp.s.TokenText()
 }
@@ -405,6 +467,26 @@ func (qp *QueryParser) runParser(q *QueryTerm, op Operator, qt *QueryTerm) error
 		qt.Value = ""
 		tok = qp.s.Scan()
 		text = qp.tokenText()
+
+		if isRangeStart(text) {
+			if err := qp.parseRange(qt, text); err != nil {
+				return err
+			}
+
+			return qp.runParser(q, op, qt)
+		}
+	case "[", "{":
+		if qt != nil && (qt.Value != "" || qt.Range != nil) {
+			qp.appendQuery(q, op, qt)
+		}
+
+		qt = &QueryTerm{}
+
+		if err := qp.parseRange(qt, text); err != nil {
+			return err
+		}
+
+		return qp.runParser(q, op, qt)
 	case "(":
 		// start now bool
 		nestedBoolQuery := &QueryTerm{}
@@ -424,8 +506,13 @@ func (qp *QueryParser) runParser(q *QueryTerm, op Operator, qt *QueryTerm) error
 		return qp.runParser(q, op, qt)
 	}
 
-	if qt != nil && qt.Value != "" {
+	if qt != nil && (qt.Value != "" || qt.Range != nil) {
 		qp.appendQuery(q, op, qt)
+
+		if qt.Range != nil {
+			// the range must not be inherited by the next term
+			qt = nil
+		}
 	}
 
 	// end of the group so return so the nested bool can be closed
@@ -449,6 +536,91 @@ func (qp *QueryParser) runParser(q *QueryTerm, op Operator, qt *QueryTerm) error
 	return qp.runParser(q, op, qt)
 }
 
+func isRangeStart(text string) bool {
+	switch text {
+	case "[", "{", ">", "<":
+		return true
+	}
+
+	return false
+}
+
+// parseRange parses the range that starts with the open token into qt.Range.
+func (qp *QueryParser) parseRange(qt *QueryTerm, open string) error {
+	r := &Range{}
+
+	switch open {
+	case ">", "<":
+		inclusive := qp.s.Peek() == '='
+		if inclusive {
+			qp.s.Scan()
+		}
+
+		value := qp.scanRangeValue()
+		if value == "" {
+			return fmt.Errorf("range operator %s must be followed by a value", open)
+		}
+
+		if open == ">" {
+			r.Lower, r.IncludeLower = value, inclusive
+		} else {
+			r.Upper, r.IncludeUpper = value, inclusive
+		}
+	default:
+		r.IncludeLower = open == "["
+
+		lower := qp.scanRangeValue()
+
+		qp.s.Scan()
+
+		if !strings.EqualFold(qp.tokenText(), "TO") {
+			return fmt.Errorf("range must have the form [lower TO upper]")
+		}
+
+		upper := qp.scanRangeValue()
+
+		switch qp.s.Scan() {
+		case ']':
+			r.IncludeUpper = true
+		case '}':
+			r.IncludeUpper = false
+		default:
+			return fmt.Errorf("range %s%s TO %s is not closed", open, lower, upper)
+		}
+
+		r.Lower, r.Upper = unboundedRange(lower), unboundedRange(upper)
+	}
+
+	qt.Range = r
+
+	return nil
+}
+
+// scanRangeValue scans the next range bound. Quotes are removed and a leading
+// sign is joined with the number that follows it.
+func (qp *QueryParser) scanRangeValue() string {
+	if qp.s.Scan() == scanner.EOF {
+		return ""
+	}
+
+	text := qp.tokenText()
+
+	if text == "-" || text == "+" {
+		qp.s.Scan()
+		text += qp.tokenText()
+	}
+
+	return strings.Trim(text, "\"'")
+}
+
+func unboundedRange(bound string) string {
+	if bound == string(WildCardOperator) {
+		return ""
+	}
+
+	return bound
+}
+
 func isIdentRune(ch rune, i int) bool {
 	return ch == '_' || unicode.IsLetter(ch) || unicode.IsDigit(ch) || ch == '.' && i > 0 ||
 		ch == '-' && i > 0 || ch == '/' || ch == '\'' || ch == '"' || ch == '`'
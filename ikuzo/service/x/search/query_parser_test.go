@@ -521,6 +521,58 @@ func TestSetFields(t *testing.T) {
 	}
 }
 
+func TestSetAnalyzer(t *testing.T) {
+	is := is.New(t)
+
+	a, err := NewAnalyzer(SetLanguage("nl"))
+	is.NoErr(err)
+
+	tests := []struct {
+		name  string
+		query string
+		want  *QueryTerm
+	}{
+		{
+			"term is stemmed",
+			"Boerderijen",
+			&QueryTerm{Value: "boerderij"},
+		},
+		{
+			"removed stopwords are added as slop",
+			`"huis van oranje"`,
+			&QueryTerm{Value: "huis oranj", Phrase: true, Slop: 1},
+		},
+		{
+			"wildcard is not stemmed",
+			"Boerderijen*",
+			&QueryTerm{Value: "boerderijen", PrefixWildcard: true},
+		},
+		{
+			"fuzzy term is not stemmed",
+			"boerderijen~1",
+			&QueryTerm{Value: "boerderijen", Fuzzy: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			qp, err := NewQueryParser(SetAnalyzer(a))
+			is.NoErr(err)
+
+			got, err := qp.Parse(tt.query)
+			is.NoErr(err)
+
+			want := &QueryTerm{shouldClauses: []*QueryTerm{tt.want}}
+
+			if diff := cmp.Diff(want, got, cmp.AllowUnexported(QueryTerm{})); diff != "" {
+				t.Errorf("SetAnalyzer(); %s = mismatch (-want +got):\n%s", tt.name, diff)
+			}
+		})
+	}
+}
+
 func TestSetDefaultOperator(t *testing.T) {
 	is := is.New(t)
 
@@ -757,6 +809,60 @@ func TestQueryParser_Parse(t *testing.T) {
 			nil,
 			true,
 		},
+		{
+			"inclusive range",
+			args{"date:[1900 TO 1950]"},
+			&QueryTerm{
+				shouldClauses: []*QueryTerm{
+					{Field: "date", Range: &Range{Lower: "1900", Upper: "1950", IncludeLower: true, IncludeUpper: true}},
+				},
+			},
+			false,
+		},
+		{
+			"exclusive unbounded range with boost",
+			args{"date:{now-10y TO *]^2 word"},
+			&QueryTerm{
+				shouldClauses: []*QueryTerm{
+					{Field: "date", Boost: 2, Range: &Range{Lower: "now-10y", IncludeUpper: true}},
+					{Value: "word"},
+				},
+			},
+			false,
+		},
+		{
+			"open-ended ranges",
+			args{"year:>=1900 AND year:<\"2000\""},
+			&QueryTerm{
+				mustClauses: []*QueryTerm{
+					{Field: "year", Range: &Range{Lower: "1900", IncludeLower: true}},
+					{Field: "year", Range: &Range{Upper: "2000"}},
+				},
+			},
+			false,
+		},
+		{
+			"range without field",
+			args{"[-5 TO 5}"},
+			&QueryTerm{
+				shouldClauses: []*QueryTerm{
+					{Range: &Range{Lower: "-5", Upper: "5", IncludeLower: true}},
+				},
+			},
+			false,
+		},
+		{
+			"unclosed range",
+			args{"date:[1900 TO"},
+			nil,
+			true,
+		},
+		{
+			"range without TO",
+			args{"date:[1900 1950]"},
+			nil,
+			true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -1018,6 +1124,7 @@ func TestQueryType_String(t *testing.T) {
 		{"bool query", BoolQuery, "BoolQuery"},
 		{"fuzzy query", FuzzyQuery, "FuzzyQuery"},
 		{"phrase query", PhraseQuery, "PhraseQuery"},
+		{"range query", RangeQuery, "RangeQuery"},
 		{"term query", TermQuery, "TermQuery"},
 		{"wildcard query", WildCardQuery, "WildCardQuery"},
 	}
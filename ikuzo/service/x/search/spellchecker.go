@@ -15,6 +15,9 @@
 package search
 
 import (
+	"fmt"
+	"io"
+
 	"github.com/sajari/fuzzy"
 )
 
@@ -61,6 +64,55 @@ func (s *SpellChecker) Train(stream *TokenStream) {
 	}
 }
 
+// Forget decreases the count of each term, e.g. when the text that the terms
+// were trained with is no longer indexed. Terms without count are removed.
+func (s *SpellChecker) Forget(counts map[string]int) {
+	if s.m == nil {
+		return
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	for term, n := range counts {
+		c, ok := s.m.Data[term]
+		if !ok {
+			continue
+		}
+
+		if c.Corpus >= s.m.Threshold && c.Corpus-n < s.m.Threshold {
+			s.removeSuggestKeys(term)
+		}
+
+		c.Corpus -= n
+		if c.Corpus <= 0 && c.Query == 0 {
+			delete(s.m.Data, term)
+		}
+	}
+}
+
+// removeSuggestKeys removes the term from the lookup keys that are created
+// when its count reaches the threshold of the model.
+func (s *SpellChecker) removeSuggestKeys(term string) {
+	for _, edit := range s.m.EditsMulti(term, s.m.Depth) {
+		terms := s.m.Suggest[edit]
+
+		for i, hit := range terms {
+			if hit == term {
+				terms = append(terms[:i], terms[i+1:]...)
+				break
+			}
+		}
+
+		if len(terms) == 0 {
+			delete(s.m.Suggest, edit)
+			continue
+		}
+
+		s.m.Suggest[edit] = terms
+	}
+}
+
 func (s *SpellChecker) SetCount(term string, count int, suggest bool) {
 	if s.m == nil {
 		s.m = s.newModel()
@@ -69,6 +121,31 @@ func (s *SpellChecker) SetCount(term string, count int, suggest bool) {
 	s.m.SetCount(term, count, suggest)
 }
 
+// Save writes the trained model as JSON to w.
+func (s *SpellChecker) Save(w io.Writer) error {
+	if s.m == nil {
+		s.m = s.newModel()
+	}
+
+	if _, err := s.m.WriteTo(w); err != nil {
+		return fmt.Errorf("unable to save spellcheck model; %w", err)
+	}
+
+	return nil
+}
+
+// Load replaces the model with a model that is written by Save.
+func (s *SpellChecker) Load(r io.Reader) error {
+	m, err := fuzzy.FromReader(r)
+	if err != nil {
+		return fmt.Errorf("unable to load spellcheck model; %w", err)
+	}
+
+	s.m = m
+
+	return nil
+}
+
 // Return the most likely correction for the input termgg
 func (s *SpellChecker) SpellCheck(input string) string {
 	if s.m == nil {
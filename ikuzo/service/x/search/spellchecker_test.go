@@ -15,6 +15,7 @@
 package search
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -128,3 +129,41 @@ func TestSpellChecker_NewSpellCheck(t *testing.T) {
 
 	is.Equal(s.SpellCheck("bom"), "boom")
 }
+
+func TestSpellChecker_SaveLoad(t *testing.T) {
+	is := is.New(t)
+
+	s := NewSpellCheck(SetThreshold(1))
+	s.SetCount("boom", 10, true)
+
+	var buf bytes.Buffer
+	is.NoErr(s.Save(&buf))
+
+	loaded := NewSpellCheck()
+	is.NoErr(loaded.Load(&buf))
+	is.Equal(loaded.SpellCheck("bom"), "boom")
+
+	err := loaded.Load(bytes.NewBufferString("not a model"))
+	is.True(err != nil)
+}
+
+func TestSpellChecker_Forget(t *testing.T) {
+	is := is.New(t)
+
+	s := NewSpellCheck(SetThreshold(2))
+	s.Forget(map[string]int{"boom": 1}) // no model yet
+
+	tok := NewTokenizer()
+	s.Train(tok.ParseString("boom boom boom", 0))
+	is.Equal(s.SpellCheck("bom"), "boom")
+
+	s.Forget(map[string]int{"boom": 1, "unknown": 1})
+	is.Equal(s.SpellCheck("bom"), "boom")
+
+	s.Forget(map[string]int{"boom": 1})
+	is.Equal(s.SpellCheck("bom"), "") // below the threshold
+
+	s.Forget(map[string]int{"boom": 1})
+	_, ok := s.m.Data["boom"]
+	is.True(!ok)
+}
@@ -0,0 +1,263 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+// StemDutch returns the stem of a lowercase Dutch word.
+//
+// It implements the Snowball Dutch stemming algorithm, which is also used by
+// the 'dutch' stemmer of ElasticSearch, so the in-memory index and ElasticSearch
+// produce the same terms. See https://snowballstem.org/algorithms/dutch/stemmer.html
+func StemDutch(word string) string {
+	w := []rune(word)
+
+	for i, r := range w {
+		switch r {
+		case 'ä', 'á':
+			w[i] = 'a'
+		case 'ë', 'é':
+			w[i] = 'e'
+		case 'ï', 'í':
+			w[i] = 'i'
+		case 'ö', 'ó':
+			w[i] = 'o'
+		case 'ü', 'ú':
+			w[i] = 'u'
+		}
+	}
+
+	if len(w) == 0 {
+		return word
+	}
+
+	// mark the consonant i and y as I and Y, so they are not treated as vowels
+	if w[0] == 'y' {
+		w[0] = 'Y'
+	}
+
+	for i := 1; i < len(w); i++ {
+		if !isDutchVowel(w[i-1]) {
+			continue
+		}
+
+		switch {
+		case w[i] == 'i' && i+1 < len(w) && isDutchVowel(w[i+1]):
+			w[i] = 'I'
+		case w[i] == 'y':
+			w[i] = 'Y'
+		}
+	}
+
+	p1 := stemRegion(w, 0, isDutchVowel)
+	p2 := stemRegion(w, p1, isDutchVowel)
+
+	if p1 < 3 {
+		p1 = 3
+	}
+
+	s := &dutchStem{w: w, p1: p1, p2: p2}
+	s.step1()
+	s.step2()
+	s.step3a()
+	s.step3b()
+	s.step4()
+
+	for i, r := range s.w {
+		switch r {
+		case 'I':
+			s.w[i] = 'i'
+		case 'Y':
+			s.w[i] = 'y'
+		}
+	}
+
+	return string(s.w)
+}
+
+func isDutchVowel(r rune) bool {
+	switch r {
+	case 'a', 'e', 'i', 'o', 'u', 'y', 'è':
+		return true
+	}
+
+	return false
+}
+
+type dutchStem struct {
+	w      []rune
+	p1     int
+	p2     int
+	eFound bool
+}
+
+// step1 removes the plural and inflection endings -heden, -en, -ene, -s and -se.
+func (s *dutchStem) step1() {
+	switch {
+	case hasRuneSuffix(s.w, "heden"):
+		if start := len(s.w) - 5; start >= s.p1 {
+			s.w = append(s.w[:start], []rune("heid")...)
+		}
+	case hasRuneSuffix(s.w, "ene"):
+		s.enEnding(3)
+	case hasRuneSuffix(s.w, "en"):
+		s.enEnding(2)
+	case hasRuneSuffix(s.w, "se"):
+		s.sEnding(2)
+	case hasRuneSuffix(s.w, "s"):
+		s.sEnding(1)
+	}
+}
+
+// enEnding removes the suffix when it is in R1 and preceded by a non-vowel
+// that is not part of 'gem'.
+func (s *dutchStem) enEnding(size int) {
+	start := len(s.w) - size
+	if start < s.p1 || start == 0 || isDutchVowel(s.w[start-1]) || hasRuneSuffix(s.w[:start], "gem") {
+		return
+	}
+
+	s.w = s.w[:start]
+	s.undouble()
+}
+
+// sEnding removes the suffix when it is in R1 and preceded by a non-vowel
+// other than j.
+func (s *dutchStem) sEnding(size int) {
+	start := len(s.w) - size
+	if start < s.p1 || start == 0 || isDutchVowel(s.w[start-1]) || s.w[start-1] == 'j' {
+		return
+	}
+
+	s.w = s.w[:start]
+}
+
+// step2 removes a final e in R1 that is preceded by a non-vowel.
+func (s *dutchStem) step2() {
+	s.eFound = false
+
+	start := len(s.w) - 1
+	if !hasRuneSuffix(s.w, "e") || start < s.p1 || start == 0 || isDutchVowel(s.w[start-1]) {
+		return
+	}
+
+	s.w = s.w[:start]
+	s.eFound = true
+	s.undouble()
+}
+
+// step3a removes -heid in R2 when not preceded by c, and the -en before it.
+func (s *dutchStem) step3a() {
+	start := len(s.w) - 4
+	if !hasRuneSuffix(s.w, "heid") || start < s.p2 || (start > 0 && s.w[start-1] == 'c') {
+		return
+	}
+
+	s.w = s.w[:start]
+
+	if hasRuneSuffix(s.w, "en") {
+		s.enEnding(2)
+	}
+}
+
+// step3b removes the derivational suffixes in R2.
+func (s *dutchStem) step3b() {
+	switch {
+	case hasRuneSuffix(s.w, "end"), hasRuneSuffix(s.w, "ing"):
+		start := len(s.w) - 3
+		if start < s.p2 {
+			return
+		}
+
+		s.w = s.w[:start]
+
+		if !s.removeIG() {
+			s.undouble()
+		}
+	case hasRuneSuffix(s.w, "ig"):
+		s.removeIG()
+	case hasRuneSuffix(s.w, "lijk"):
+		if start := len(s.w) - 4; start >= s.p2 {
+			s.w = s.w[:start]
+			s.step2()
+		}
+	case hasRuneSuffix(s.w, "baar"):
+		if start := len(s.w) - 4; start >= s.p2 {
+			s.w = s.w[:start]
+		}
+	case hasRuneSuffix(s.w, "bar"):
+		if start := len(s.w) - 3; start >= s.p2 && s.eFound {
+			s.w = s.w[:start]
+		}
+	}
+}
+
+// removeIG removes -ig in R2 when not preceded by e.
+func (s *dutchStem) removeIG() bool {
+	start := len(s.w) - 2
+	if !hasRuneSuffix(s.w, "ig") || start < s.p2 || (start > 0 && s.w[start-1] == 'e') {
+		return false
+	}
+
+	s.w = s.w[:start]
+
+	return true
+}
+
+// step4 undoubles the vowel of a final consonant-vowel-consonant ending, so
+// 'maan' becomes 'man'.
+func (s *dutchStem) step4() {
+	n := len(s.w)
+	if n < 4 {
+		return
+	}
+
+	last := s.w[n-1]
+	if isDutchVowel(last) || last == 'I' || isDutchVowel(s.w[n-4]) {
+		return
+	}
+
+	switch string(s.w[n-3 : n-1]) {
+	case "aa", "ee", "oo", "uu":
+		s.w = append(s.w[:n-2], last)
+	}
+}
+
+// undouble removes the last letter of a final kk, dd or tt.
+func (s *dutchStem) undouble() {
+	if hasRuneSuffix(s.w, "kk") || hasRuneSuffix(s.w, "dd") || hasRuneSuffix(s.w, "tt") {
+		s.w = s.w[:len(s.w)-1]
+	}
+}
+
+// stemRegion returns the position after the first non-vowel that follows a
+// vowel, starting at start. It returns the length of the word when there is no
+// such position. This is how the Snowball algorithms define R1 and R2.
+func stemRegion(w []rune, start int, isVowel func(rune) bool) int {
+	for i := start; i+1 < len(w); i++ {
+		if isVowel(w[i]) && !isVowel(w[i+1]) {
+			return i + 2
+		}
+	}
+
+	return len(w)
+}
+
+func hasRuneSuffix(w []rune, suffix string) bool {
+	s := []rune(suffix)
+	if len(s) > len(w) {
+		return false
+	}
+
+	return string(w[len(w)-len(s):]) == suffix
+}
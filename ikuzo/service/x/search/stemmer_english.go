@@ -0,0 +1,352 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import "strings"
+
+// englishExceptions are the words that are not stemmed by the algorithm.
+var englishExceptions = map[string]string{
+	"skis":   "ski",
+	"skies":  "sky",
+	"dying":  "die",
+	"lying":  "lie",
+	"tying":  "tie",
+	"idly":   "idl",
+	"gently": "gentl",
+	"ugly":   "ugli",
+	"early":  "earli",
+	"only":   "onli",
+	"singly": "singl",
+	"sky":    "sky",
+	"news":   "news",
+	"howe":   "howe",
+	"atlas":  "atlas",
+	"cosmos": "cosmos",
+	"bias":   "bias",
+	"andes":  "andes",
+}
+
+// englishInvariants are left alone once the plural endings are removed.
+var englishInvariants = map[string]bool{
+	"inning":  true,
+	"outing":  true,
+	"canning": true,
+	"herring": true,
+	"earring": true,
+	"proceed": true,
+	"exceed":  true,
+	"succeed": true,
+}
+
+// StemEnglish returns the stem of a lowercase English word.
+//
+// It implements the Snowball English (Porter2) stemming algorithm, which is
+// the 'porter2' stemmer of ElasticSearch. Its 'english' stemmer is the
+// original Porter algorithm, which produces other stems, e.g. for "generously".
+// See https://snowballstem.org/algorithms/english/stemmer.html
+func StemEnglish(word string) string {
+	if stem, ok := englishExceptions[word]; ok {
+		return stem
+	}
+
+	w := []rune(strings.TrimPrefix(word, "'"))
+	if len(w) <= 2 {
+		return word
+	}
+
+	// mark the consonant y as Y, so it is not treated as a vowel
+	if w[0] == 'y' {
+		w[0] = 'Y'
+	}
+
+	for i := 1; i < len(w); i++ {
+		if w[i] == 'y' && isEnglishVowel(w[i-1]) {
+			w[i] = 'Y'
+		}
+	}
+
+	s := &englishStem{w: w, p1: -1}
+
+	for _, prefix := range []string{"gener", "commun", "arsen"} {
+		if strings.HasPrefix(string(w), prefix) {
+			s.p1 = len(prefix)
+			break
+		}
+	}
+
+	if s.p1 == -1 {
+		s.p1 = stemRegion(w, 0, isEnglishVowel)
+	}
+
+	s.p2 = stemRegion(w, s.p1, isEnglishVowel)
+
+	s.step0()
+	s.step1a()
+
+	if !englishInvariants[string(s.w)] {
+		s.step1b()
+		s.step1c()
+		s.step2()
+		s.step3()
+		s.step4()
+		s.step5()
+	}
+
+	return strings.ReplaceAll(string(s.w), "Y", "y")
+}
+
+func isEnglishVowel(r rune) bool {
+	switch r {
+	case 'a', 'e', 'i', 'o', 'u', 'y':
+		return true
+	}
+
+	return false
+}
+
+type englishStem struct {
+	w  []rune
+	p1 int
+	p2 int
+}
+
+// suffix returns the longest of the suffixes that the word ends with and the
+// position where it starts.
+func (s *englishStem) suffix(suffixes ...string) (suffix string, start int) {
+	for _, candidate := range suffixes {
+		if len(candidate) > len(suffix) && hasRuneSuffix(s.w, candidate) {
+			suffix = candidate
+		}
+	}
+
+	return suffix, len(s.w) - len([]rune(suffix))
+}
+
+func (s *englishStem) replace(start int, replacement string) {
+	s.w = append(s.w[:start], []rune(replacement)...)
+}
+
+func (s *englishStem) hasVowel(w []rune) bool {
+	for _, r := range w {
+		if isEnglishVowel(r) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// shortSyllable reports if the word ends with a short syllable: a non-vowel
+// other than w, x or Y preceded by a vowel preceded by a non-vowel, or a vowel
+// followed by a non-vowel at the start of the word.
+func shortSyllable(w []rune) bool {
+	n := len(w)
+
+	if n == 2 {
+		return isEnglishVowel(w[0]) && !isEnglishVowel(w[1])
+	}
+
+	if n < 3 {
+		return false
+	}
+
+	last := w[n-1]
+
+	return !isEnglishVowel(last) && last != 'w' && last != 'x' && last != 'Y' &&
+		isEnglishVowel(w[n-2]) && !isEnglishVowel(w[n-3])
+}
+
+// step0 removes the possessive endings.
+func (s *englishStem) step0() {
+	if suffix, start := s.suffix("'", "'s", "'s'"); suffix != "" {
+		s.w = s.w[:start]
+	}
+}
+
+// step1a removes the plural endings.
+func (s *englishStem) step1a() {
+	suffix, start := s.suffix("sses", "ied", "ies", "us", "ss", "s")
+
+	switch suffix {
+	case "sses":
+		s.replace(start, "ss")
+	case "ied", "ies":
+		if start > 1 {
+			s.replace(start, "i")
+		} else {
+			s.replace(start, "ie")
+		}
+	case "s":
+		if start > 1 && s.hasVowel(s.w[:start-1]) {
+			s.w = s.w[:start]
+		}
+	}
+}
+
+// step1b removes the past tense and progressive endings.
+func (s *englishStem) step1b() {
+	suffix, start := s.suffix("eed", "eedly", "ed", "edly", "ing", "ingly")
+
+	switch suffix {
+	case "":
+		return
+	case "eed", "eedly":
+		if start >= s.p1 {
+			s.replace(start, "ee")
+		}
+
+		return
+	}
+
+	if !s.hasVowel(s.w[:start]) {
+		return
+	}
+
+	s.w = s.w[:start]
+
+	switch ending, _ := s.suffix("at", "bl", "iz", "bb", "dd", "ff", "gg", "mm", "nn", "pp", "rr", "tt"); ending {
+	case "at", "bl", "iz":
+		s.w = append(s.w, 'e')
+	case "":
+		if s.p1 == len(s.w) && shortSyllable(s.w) {
+			s.w = append(s.w, 'e')
+		}
+	default:
+		s.w = s.w[:len(s.w)-1]
+	}
+}
+
+// step1c replaces a final y by i when it is preceded by a non-vowel that is not
+// the first letter of the word.
+func (s *englishStem) step1c() {
+	n := len(s.w)
+	if n < 3 || (s.w[n-1] != 'y' && s.w[n-1] != 'Y') || isEnglishVowel(s.w[n-2]) {
+		return
+	}
+
+	s.w[n-1] = 'i'
+}
+
+var englishStep2 = map[string]string{
+	"tional":  "tion",
+	"enci":    "ence",
+	"anci":    "ance",
+	"abli":    "able",
+	"entli":   "ent",
+	"izer":    "ize",
+	"ization": "ize",
+	"ational": "ate",
+	"ation":   "ate",
+	"ator":    "ate",
+	"alism":   "al",
+	"aliti":   "al",
+	"alli":    "al",
+	"fulness": "ful",
+	"ousli":   "ous",
+	"ousness": "ous",
+	"iveness": "ive",
+	"iviti":   "ive",
+	"biliti":  "ble",
+	"bli":     "ble",
+	"ogi":     "og",
+	"fulli":   "ful",
+	"lessli":  "less",
+	"li":      "",
+}
+
+// step2 normalises the derivational suffixes in R1.
+func (s *englishStem) step2() {
+	suffix, start := s.suffix(mapKeys(englishStep2)...)
+	if suffix == "" || start < s.p1 {
+		return
+	}
+
+	switch suffix {
+	case "ogi":
+		if start == 0 || s.w[start-1] != 'l' {
+			return
+		}
+	case "li":
+		if start == 0 || !strings.ContainsRune("cdeghkmnrt", s.w[start-1]) {
+			return
+		}
+	}
+
+	s.replace(start, englishStep2[suffix])
+}
+
+var englishStep3 = map[string]string{
+	"tional":  "tion",
+	"ational": "ate",
+	"alize":   "al",
+	"icate":   "ic",
+	"iciti":   "ic",
+	"ical":    "ic",
+	"ful":     "",
+	"ness":    "",
+	"ative":   "",
+}
+
+// step3 normalises the remaining derivational suffixes in R1.
+func (s *englishStem) step3() {
+	suffix, start := s.suffix(mapKeys(englishStep3)...)
+	if suffix == "" || start < s.p1 || (suffix == "ative" && start < s.p2) {
+		return
+	}
+
+	s.replace(start, englishStep3[suffix])
+}
+
+// step4 removes the suffixes in R2.
+func (s *englishStem) step4() {
+	suffix, start := s.suffix(
+		"al", "ance", "ence", "er", "ic", "able", "ible", "ant", "ement", "ment",
+		"ent", "ism", "ate", "iti", "ous", "ive", "ize", "ion",
+	)
+	if suffix == "" || start < s.p2 {
+		return
+	}
+
+	if suffix == "ion" && (start == 0 || (s.w[start-1] != 's' && s.w[start-1] != 't')) {
+		return
+	}
+
+	s.w = s.w[:start]
+}
+
+// step5 removes a final e or the last l of a final ll.
+func (s *englishStem) step5() {
+	start := len(s.w) - 1
+
+	switch {
+	case hasRuneSuffix(s.w, "e"):
+		if start >= s.p2 || (start >= s.p1 && !shortSyllable(s.w[:start])) {
+			s.w = s.w[:start]
+		}
+	case hasRuneSuffix(s.w, "l"):
+		if start >= s.p2 && start > 0 && s.w[start-1] == 'l' {
+			s.w = s.w[:start]
+		}
+	}
+}
+
+func mapKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
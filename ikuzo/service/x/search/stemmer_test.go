@@ -0,0 +1,101 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// nolint:gocritic
+package search
+
+import "testing"
+
+func TestStemDutch(t *testing.T) {
+	// samples from the Snowball Dutch vocabulary
+	tests := map[string]string{
+		"boerderij":          "boerderij",
+		"boerderijen":        "boerderij",
+		"lichaamsziek":       "lichaamsziek",
+		"lichamelijk":        "licham",
+		"lichamelijke":       "licham",
+		"lichamelijkheden":   "licham",
+		"lichamen":           "licham",
+		"lichere":            "licher",
+		"licht":              "licht",
+		"lichtbeelden":       "lichtbeeld",
+		"lichtdoorlatende":   "lichtdoorlat",
+		"lichte":             "licht",
+		"lichten":            "licht",
+		"lichtende":          "lichtend",
+		"lichtere":           "lichter",
+		"lichters":           "lichter",
+		"lichtgevoeligheid":  "lichtgevoel",
+		"lichthoeveelheid":   "lichthoevel",
+		"lichtje":            "lichtj",
+		"lichtjes":           "lichtjes",
+		"lichtkranten":       "lichtkrant",
+		"lichtkringen":       "lichtkring",
+		"lichtregelsystemen": "lichtregelsystem",
+		"lichtvoetige":       "lichtvoet",
+		"lichtwaarde":        "lichtwaard",
+		"maan":               "man",
+		"":                   "",
+	}
+
+	for word, want := range tests {
+		if got := StemDutch(word); got != want {
+			t.Errorf("StemDutch(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
+
+func TestStemEnglish(t *testing.T) {
+	// samples from the Snowball English vocabulary
+	tests := map[string]string{
+		"consign":       "consign",
+		"consigned":     "consign",
+		"consignment":   "consign",
+		"consistency":   "consist",
+		"consistently":  "consist",
+		"consolation":   "consol",
+		"consolatory":   "consolatori",
+		"console":       "consol",
+		"consolidating": "consolid",
+		"consolingly":   "consol",
+		"conspicuously": "conspicu",
+		"conspiracy":    "conspiraci",
+		"conspirators":  "conspir",
+		"constable":     "constabl",
+		"constancy":     "constanc",
+		"caresses":      "caress",
+		"ponies":        "poni",
+		"ties":          "tie",
+		"cries":         "cri",
+		"gaps":          "gap",
+		"gas":           "gas",
+		"hoping":        "hope",
+		"hopping":       "hop",
+		"running":       "run",
+		"generously":    "generous",
+		"communication": "communic",
+		"happy":         "happi",
+		"sky":           "sky",
+		"skies":         "sky",
+		"news":          "news",
+		"succeed":       "succeed",
+		"by":            "by",
+	}
+
+	for word, want := range tests {
+		if got := StemEnglish(word); got != want {
+			t.Errorf("StemEnglish(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+// DutchStopWords are the stopwords of the ElasticSearch '_dutch_' stop filter.
+var DutchStopWords = []string{
+	"de", "en", "van", "ik", "te", "dat", "die", "in", "een", "hij", "het",
+	"niet", "zijn", "is", "was", "op", "aan", "met", "als", "voor", "had",
+	"er", "maar", "om", "hem", "dan", "zou", "of", "wat", "mijn", "men",
+	"dit", "zo", "door", "over", "ze", "zich", "bij", "ook", "tot", "je",
+	"mij", "uit", "der", "daar", "haar", "naar", "heb", "hoe", "heeft",
+	"hebben", "deze", "u", "want", "nog", "zal", "me", "zij", "nu", "ge",
+	"geen", "omdat", "iets", "worden", "toch", "al", "waren", "veel", "meer",
+	"doen", "toen", "moet", "ben", "zonder", "kan", "hun", "dus", "alles",
+	"onder", "ja", "eens", "hier", "wie", "werd", "altijd", "doch", "wordt",
+	"wezen", "kunnen", "ons", "zelf", "tegen", "na", "reeds", "wil", "kon",
+	"niets", "uw", "iemand", "geweest", "andere",
+}
+
+// EnglishStopWords are the stopwords of the ElasticSearch '_english_' stop
+// filter.
+var EnglishStopWords = []string{
+	"a", "an", "and", "are", "as", "at", "be", "but", "by", "for", "if", "in",
+	"into", "is", "it", "no", "not", "of", "on", "or", "such", "that", "the",
+	"their", "then", "there", "these", "they", "this", "to", "was", "will",
+	"with",
+}
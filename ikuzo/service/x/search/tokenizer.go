@@ -219,6 +219,11 @@ type TokenStream struct {
 	tokens []Token
 }
 
+// NewTokenStream returns a TokenStream of the tokens, e.g. for a TokenizerFunc.
+func NewTokenStream(tokens []Token) *TokenStream {
+	return &TokenStream{tokens: tokens}
+}
+
 func (ts *TokenStream) next(idx int) (Token, bool) {
 	if idx > len(ts.tokens) {
 		return Token{}, false
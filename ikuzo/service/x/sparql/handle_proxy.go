@@ -1,18 +1,28 @@
 package sparql
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"strings"
 
 	"github.com/delving/hub3/ikuzo/domain"
+	"github.com/delving/hub3/ikuzo/rdf/formats/ntriples"
 	"github.com/delving/hub3/ikuzo/render"
 )
 
 func (s *Service) sparqlProxy(w http.ResponseWriter, r *http.Request) {
 	orgID := domain.GetOrganizationID(r)
+
+	if local, ok := s.local[orgID]; ok {
+		s.localQuery(w, r, local)
+		return
+	}
+
 	repo, err := s.GetRepo(orgID)
 	if err != nil {
 		if errors.Is(err, domain.ErrServiceNotEnabled) {
@@ -29,23 +39,13 @@ func (s *Service) sparqlProxy(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var query string
-	switch r.Method {
-	case http.MethodGet:
-		query = r.URL.Query().Get("query")
-	case http.MethodPost:
-		query = r.FormValue("query")
-	}
-
-	if query == "" {
-		render.Error(w, r, fmt.Errorf("sparql query cannot be empty"), &render.ErrorConfig{
+	query, err := s.getQuery(r)
+	if err != nil {
+		render.Error(w, r, err, &render.ErrorConfig{
 			StatusCode: http.StatusBadRequest,
 		})
 		return
 	}
-	if !strings.Contains(strings.ToLower(query), "limit ") {
-		query = fmt.Sprintf("%s LIMIT %d", query, s.queryLimit)
-	}
 
 	resp, statusCode, contentType, err := s.runSparqlQuery(repo, query)
 	if err != nil {
@@ -70,6 +70,81 @@ func (s *Service) sparqlProxy(w http.ResponseWriter, r *http.Request) {
 	return
 }
 
+// getQuery returns the SPARQL query from the request. The default query limit
+// is added when the query has no LIMIT.
+func (s *Service) getQuery(r *http.Request) (string, error) {
+	var query string
+	switch r.Method {
+	case http.MethodGet:
+		query = r.URL.Query().Get("query")
+	case http.MethodPost:
+		query = r.FormValue("query")
+	}
+
+	if query == "" {
+		return "", fmt.Errorf("sparql query cannot be empty")
+	}
+
+	if !strings.Contains(strings.ToLower(query), "limit ") {
+		query = fmt.Sprintf("%s LIMIT %d", query, s.queryLimit)
+	}
+
+	return query, nil
+}
+
+// localQuery evaluates the SPARQL query with the LocalRepo.
+func (s *Service) localQuery(w http.ResponseWriter, r *http.Request, local *LocalRepo) {
+	query, err := s.getQuery(r)
+	if err != nil {
+		render.Error(w, r, err, &render.ErrorConfig{
+			StatusCode: http.StatusBadRequest,
+		})
+		return
+	}
+
+	var buf bytes.Buffer
+
+	contentType, err := runLocalQuery(local, query, &buf)
+	if err != nil {
+		render.Error(w, r, fmt.Errorf("error with sparql query"), &render.ErrorConfig{
+			StatusCode: http.StatusBadRequest,
+			Message:    err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+
+	if _, err := buf.WriteTo(w); err != nil {
+		s.log.Error().Err(err).Msg("unable to write sparql response")
+	}
+}
+
+// runLocalQuery writes the SPARQL results as application/sparql-results+json for
+// SELECT and ASK queries, and the graph as N-Triples for CONSTRUCT queries.
+func runLocalQuery(local *LocalRepo, q string, w io.Writer) (contentType string, err error) {
+	parsed, err := parseQuery(q)
+	if err != nil {
+		return "", err
+	}
+
+	if parsed.form == formConstruct {
+		g, err := local.construct(parsed)
+		if err != nil {
+			return "", err
+		}
+
+		return "application/n-triples", ntriples.Serialize(g, w)
+	}
+
+	res, err := local.query(parsed)
+	if err != nil {
+		return "", err
+	}
+
+	return "application/sparql-results+json", json.NewEncoder(w).Encode(res)
+}
+
 // runSparqlQuery sends a SPARQL query to the SPARQL-endpoint specified in the configuration
 func (s *Service) runSparqlQuery(repo *Repo, query string) (body []byte, statusCode int, contentType string, err error) {
 	resp, err := repo.queryRaw(query, http.MethodGet, "")
@@ -0,0 +1,142 @@
+package sparql
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/delving/hub3/ikuzo/rdf"
+	"github.com/delving/hub3/ikuzo/service/x/lod"
+)
+
+// LocalRepo evaluates SPARQL queries in-process against a rdf.Graph. It can
+// be used instead of a Repo for tests and small deployments that do not run
+// an external triple store.
+//
+// SELECT, CONSTRUCT and ASK queries are supported with basic graph patterns,
+// OPTIONAL, UNION, FILTER, ORDER BY and LIMIT/OFFSET. Named graphs, property
+// paths, aggregates and sub-queries are not supported.
+type LocalRepo struct {
+	g   *rdf.Graph
+	idx *tripleIndex
+	m   sync.Mutex
+}
+
+// NewLocalRepo returns a LocalRepo for the Graph. Triples that are added to the
+// Graph later are included in the queries.
+func NewLocalRepo(g *rdf.Graph) *LocalRepo {
+	if g == nil {
+		g = rdf.NewGraph()
+	}
+
+	return &LocalRepo{g: g}
+}
+
+// Graph returns the rdf.Graph that is queried.
+func (r *LocalRepo) Graph() *rdf.Graph {
+	return r.g
+}
+
+// evaluator returns an evaluator with an up-to-date index of the Graph.
+func (r *LocalRepo) evaluator() *evaluator {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	if r.idx == nil || len(r.idx.triples) != r.g.Len() {
+		r.idx = newTripleIndex(r.g)
+	}
+
+	return &evaluator{idx: r.idx}
+}
+
+// Query evaluates a SELECT or ASK query and returns the Results.
+func (r *LocalRepo) Query(q string) (*Results, error) {
+	parsed, err := parseQuery(q)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.query(parsed)
+}
+
+func (r *LocalRepo) query(q *query) (*Results, error) {
+	solutions := r.evaluator().solutions(q)
+
+	switch q.form {
+	case formAsk:
+		ok := len(solutions) > 0
+		return &Results{Head: Header{Vars: []string{}}, Boolean: &ok}, nil
+	case formSelect:
+		res := &Results{
+			Head: Header{Vars: q.projection()},
+			Results: results{
+				Distinct: q.distinct,
+				Ordered:  len(q.orderBy) > 0,
+				Bindings: make([]map[string]*Entry, 0, len(solutions)),
+			},
+		}
+
+		for _, s := range solutions {
+			b := make(map[string]*Entry, len(s))
+			for k, term := range s {
+				b[k] = newEntry(term)
+			}
+
+			res.Results.Bindings = append(res.Results.Bindings, b)
+		}
+
+		return res, nil
+	}
+
+	return nil, fmt.Errorf("%w: use Construct for CONSTRUCT queries", ErrInvalidQuery)
+}
+
+// Ask evaluates an ASK query.
+func (r *LocalRepo) Ask(q string) (bool, error) {
+	res, err := r.Query(q)
+	if err != nil {
+		return false, err
+	}
+
+	if res.Boolean == nil {
+		return false, fmt.Errorf("%w: expected ASK query", ErrInvalidQuery)
+	}
+
+	return *res.Boolean, nil
+}
+
+// Construct evaluates a CONSTRUCT query and returns the constructed rdf.Graph.
+func (r *LocalRepo) Construct(q string) (*rdf.Graph, error) {
+	parsed, err := parseQuery(q)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.construct(parsed)
+}
+
+func (r *LocalRepo) construct(q *query) (*rdf.Graph, error) {
+	if q.form != formConstruct {
+		return nil, fmt.Errorf("%w: expected CONSTRUCT query", ErrInvalidQuery)
+	}
+
+	g := construct(q.template, r.evaluator().solutions(q))
+	g.NamespaceManager = r.g.NamespaceManager
+
+	return g, nil
+}
+
+// Resolve returns all the triples with subj as subject. lod.ErrResourceNotFound
+// is returned when there are none.
+func (r *LocalRepo) Resolve(ctx context.Context, subj rdf.Subject) (*rdf.Graph, error) {
+	g := rdf.NewGraph()
+	g.NamespaceManager = r.g.NamespaceManager
+
+	g.Add(r.evaluator().idx.bySubject[subj.String()]...)
+
+	if g.Len() == 0 {
+		return nil, lod.ErrResourceNotFound
+	}
+
+	return g, nil
+}
@@ -0,0 +1,305 @@
+package sparql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/matryer/is"
+
+	"github.com/delving/hub3/ikuzo/domain"
+	"github.com/delving/hub3/ikuzo/rdf"
+	"github.com/delving/hub3/ikuzo/rdf/formats/turtle"
+	"github.com/delving/hub3/ikuzo/service/x/lod"
+)
+
+const testPrefixes = `
+PREFIX dc: <http://purl.org/dc/elements/1.1/>
+PREFIX edm: <http://www.europeana.eu/schemas/edm/>
+PREFIX ex: <http://example.org/>
+`
+
+func newTestLocalRepo(t *testing.T) *LocalRepo {
+	t.Helper()
+
+	f, err := os.Open("./testdata/local.ttl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	g, err := turtle.Parse(f, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewLocalRepo(g)
+}
+
+// values returns the raw values of the variable in each solution; unbound
+// variables are returned as empty strings.
+func values(res *Results, variable string) []string {
+	values := []string{}
+
+	for _, b := range res.Results.Bindings {
+		var v string
+		if e, ok := b[variable]; ok {
+			v = e.Value
+		}
+
+		values = append(values, v)
+	}
+
+	return values
+}
+
+func sorted(s []string) []string {
+	sort.Strings(s)
+	return s
+}
+
+func TestLocalRepo_Query(t *testing.T) {
+	repo := newTestLocalRepo(t)
+
+	tests := []struct {
+		name     string
+		query    string
+		variable string
+		want     []string
+		ordered  bool
+	}{
+		{
+			"basic graph pattern",
+			`SELECT ?s WHERE { ?s a edm:ProvidedCHO ; dc:creator ?c . ?c dc:title "Johannes Vermeer" }`,
+			"s",
+			[]string{"http://example.org/milkmaid"},
+			false,
+		},
+		{
+			"optional",
+			`SELECT ?s ?name WHERE { ?s a edm:ProvidedCHO OPTIONAL { ?s dc:creator ?c . ?c dc:title ?name } }`,
+			"name",
+			[]string{"", "Johannes Vermeer", "Rembrandt van Rijn"},
+			false,
+		},
+		{
+			"filter with language",
+			`SELECT ?title WHERE { ?s dc:title ?title FILTER (lang(?title) = "en") }`,
+			"title",
+			[]string{"The Night Watch"},
+			false,
+		},
+		{
+			"filter with regex and logical or",
+			`SELECT ?title WHERE { ?s dc:title ?title . FILTER (regex(?title, "^het", "i") || contains(?title, "Rijn")) }`,
+			"title",
+			[]string{"Het melkmeisje", "Rembrandt van Rijn"},
+			false,
+		},
+		{
+			"filter numeric comparison",
+			`SELECT ?s WHERE { ?s dc:extent ?e FILTER (?e > 100 && ?e * 2 < 1000) }`,
+			"s",
+			[]string{"http://example.org/nightwatch"},
+			false,
+		},
+		{
+			"filter not bound",
+			`SELECT ?s WHERE { ?s a edm:ProvidedCHO OPTIONAL { ?s dc:creator ?c } FILTER (!bound(?c)) }`,
+			"s",
+			[]string{"http://example.org/sketch"},
+			false,
+		},
+		{
+			"union",
+			`SELECT ?x WHERE { { ex:nightwatch dc:creator ?x } UNION { ex:milkmaid dc:creator ?x } }`,
+			"x",
+			[]string{"http://example.org/rembrandt", "http://example.org/vermeer"},
+			false,
+		},
+		{
+			"distinct",
+			`SELECT DISTINCT ?type WHERE { ?s a ?type }`,
+			"type",
+			[]string{"http://www.europeana.eu/schemas/edm/Agent", "http://www.europeana.eu/schemas/edm/ProvidedCHO"},
+			false,
+		},
+		{
+			"order by with limit and offset",
+			`SELECT ?title WHERE { ?s a edm:Agent ; dc:title ?title } ORDER BY DESC(?title) LIMIT 1 OFFSET 1`,
+			"title",
+			[]string{"Johannes Vermeer"},
+			true,
+		},
+		{
+			"blank node variable",
+			`SELECT * WHERE { ?s dc:creator _:c . _:c a edm:Agent }`,
+			"s",
+			[]string{"http://example.org/milkmaid", "http://example.org/nightwatch"},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+
+			res, err := repo.Query(testPrefixes + tt.query)
+			is.NoErr(err)
+
+			got := values(res, tt.variable)
+			if !tt.ordered {
+				got = sorted(got)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Query() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLocalRepo_QueryProjection(t *testing.T) {
+	is := is.New(t)
+
+	repo := newTestLocalRepo(t)
+
+	res, err := repo.Query(testPrefixes + `SELECT * WHERE { ?s dc:extent ?extent ; dc:title ?title } LIMIT 1`)
+	is.NoErr(err)
+	is.Equal(res.Head.Vars, []string{"s", "extent", "title"})
+	is.Equal(len(res.Results.Bindings), 1)
+
+	extent := res.Results.Bindings[0]["extent"]
+	is.Equal(extent.DataType, "http://www.w3.org/2001/XMLSchema#integer")
+
+	// the results can be converted back to RDF terms
+	solutions := res.Solutions()
+	is.Equal(len(solutions), 1)
+	is.Equal(solutions[0]["s"].RawValue(), "http://example.org/nightwatch")
+}
+
+func TestLocalRepo_Ask(t *testing.T) {
+	is := is.New(t)
+
+	repo := newTestLocalRepo(t)
+
+	ok, err := repo.Ask(testPrefixes + `ASK { ex:nightwatch dc:creator ex:rembrandt }`)
+	is.NoErr(err)
+	is.True(ok)
+
+	ok, err = repo.Ask(testPrefixes + `ASK WHERE { ex:nightwatch dc:creator ex:vermeer }`)
+	is.NoErr(err)
+	is.True(!ok)
+}
+
+func TestLocalRepo_Construct(t *testing.T) {
+	is := is.New(t)
+
+	repo := newTestLocalRepo(t)
+
+	g, err := repo.Construct(testPrefixes + `
+		CONSTRUCT { ?c ex:created ?s . ?s ex:meta _:m . _:m ex:source "local" }
+		WHERE { ?s dc:creator ?c }`)
+	is.NoErr(err)
+	is.Equal(g.Len(), 6)
+
+	created := []string{}
+
+	for _, t := range g.Triples() {
+		if t.Predicate.RawValue() == "http://example.org/created" {
+			created = append(created, t.Subject.RawValue())
+		}
+	}
+
+	is.Equal(sorted(created), []string{"http://example.org/rembrandt", "http://example.org/vermeer"})
+
+	_, err = repo.Construct(testPrefixes + `SELECT * WHERE { ?s ?p ?o }`)
+	is.True(errors.Is(err, ErrInvalidQuery))
+}
+
+func TestLocalRepo_Resolve(t *testing.T) {
+	is := is.New(t)
+
+	repo := newTestLocalRepo(t)
+
+	subj, err := rdf.NewIRI("http://example.org/nightwatch")
+	is.NoErr(err)
+
+	g, err := repo.Resolve(context.TODO(), subj)
+	is.NoErr(err)
+	is.Equal(g.Len(), 5)
+
+	unknown, err := rdf.NewIRI("http://example.org/unknown")
+	is.NoErr(err)
+
+	_, err = repo.Resolve(context.TODO(), unknown)
+	is.True(errors.Is(err, lod.ErrResourceNotFound))
+}
+
+func TestLocalRepo_AddedTriples(t *testing.T) {
+	is := is.New(t)
+
+	repo := NewLocalRepo(nil)
+
+	ok, err := repo.Ask(`ASK { <urn:s> <urn:p> ?o }`)
+	is.NoErr(err)
+	is.True(!ok)
+
+	s, _ := rdf.NewIRI("urn:s")
+	p, _ := rdf.NewIRI("urn:p")
+	o, _ := rdf.NewLiteral("o")
+	repo.Graph().AddTriple(s, p, o)
+
+	ok, err = repo.Ask(`ASK { <urn:s> <urn:p> ?o }`)
+	is.NoErr(err)
+	is.True(ok)
+}
+
+func TestService_LocalQuery(t *testing.T) {
+	is := is.New(t)
+
+	orgID := domain.OrganizationID("hub3")
+
+	svc, err := NewService(SetLocalRepo(orgID, newTestLocalRepo(t)))
+	is.NoErr(err)
+
+	query := func(q string) *httptest.ResponseRecorder {
+		form := url.Values{"query": []string{testPrefixes + q}}
+
+		r := httptest.NewRequest(http.MethodPost, "/sparql", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r = domain.SetOrganization(r, &domain.Organization{ID: orgID})
+
+		w := httptest.NewRecorder()
+		svc.ServeHTTP(w, r)
+
+		return w
+	}
+
+	w := query(`SELECT ?s WHERE { ?s a edm:Agent }`)
+	is.Equal(w.Code, http.StatusOK)
+	is.Equal(w.Header().Get("Content-Type"), "application/sparql-results+json")
+
+	var res Results
+	is.NoErr(json.NewDecoder(w.Body).Decode(&res))
+	is.Equal(sorted(values(&res, "s")), []string{"http://example.org/rembrandt", "http://example.org/vermeer"})
+
+	w = query(`CONSTRUCT WHERE { ?s a edm:Agent }`)
+	is.Equal(w.Code, http.StatusOK)
+	is.Equal(w.Header().Get("Content-Type"), "application/n-triples")
+	is.Equal(bytes.Count(w.Body.Bytes(), []byte("\n")), 2)
+
+	w = query(`SELECT ?s WHERE { ?s a }`)
+	is.Equal(w.Code, http.StatusBadRequest)
+}
@@ -3,6 +3,7 @@ package sparql
 import (
 	"bytes"
 
+	"github.com/delving/hub3/ikuzo/domain"
 	"github.com/knakk/sparql"
 )
 
@@ -17,3 +18,12 @@ func SetCustomQueries(queries string) Option {
 		return s.mergeBank(sparql.LoadBank(f))
 	}
 }
+
+// SetLocalRepo evaluates the SPARQL queries of the organization in-process with
+// the LocalRepo instead of proxying them to the configured SPARQL endpoint.
+func SetLocalRepo(orgID domain.OrganizationID, repo *LocalRepo) Option {
+	return func(s *Service) error {
+		s.local[orgID] = repo
+		return nil
+	}
+}
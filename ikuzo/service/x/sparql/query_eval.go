@@ -0,0 +1,480 @@
+package sparql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/delving/hub3/ikuzo/rdf"
+)
+
+// binding maps variable names to RDF terms.
+type binding map[string]rdf.Term
+
+func (b binding) extend() binding {
+	extended := make(binding, len(b)+3)
+	for k, v := range b {
+		extended[k] = v
+	}
+
+	return extended
+}
+
+// tripleIndex indexes the triples of a rdf.Graph by subject, predicate and object.
+type tripleIndex struct {
+	triples     []*rdf.Triple
+	bySubject   map[string][]*rdf.Triple
+	byPredicate map[string][]*rdf.Triple
+	byObject    map[string][]*rdf.Triple
+}
+
+func newTripleIndex(g *rdf.Graph) *tripleIndex {
+	triples := g.Triples()
+
+	idx := &tripleIndex{
+		triples:     triples,
+		bySubject:   map[string][]*rdf.Triple{},
+		byPredicate: map[string][]*rdf.Triple{},
+		byObject:    map[string][]*rdf.Triple{},
+	}
+
+	for _, t := range triples {
+		s, p, o := t.Subject.String(), t.Predicate.String(), t.Object.String()
+
+		idx.bySubject[s] = append(idx.bySubject[s], t)
+		idx.byPredicate[p] = append(idx.byPredicate[p], t)
+		idx.byObject[o] = append(idx.byObject[o], t)
+	}
+
+	return idx
+}
+
+// candidates returns the smallest list of triples that can match the
+// resolved subject, predicate and object. A nil term is unbound.
+func (idx *tripleIndex) candidates(s, p, o rdf.Term) []*rdf.Triple {
+	candidates := idx.triples
+
+	if s != nil {
+		if c := idx.bySubject[s.String()]; len(c) < len(candidates) {
+			candidates = c
+		}
+	}
+
+	if p != nil {
+		if c := idx.byPredicate[p.String()]; len(c) < len(candidates) {
+			candidates = c
+		}
+	}
+
+	if o != nil {
+		if c := idx.byObject[o.String()]; len(c) < len(candidates) {
+			candidates = c
+		}
+	}
+
+	return candidates
+}
+
+// count estimates the number of matches of the pattern when the variables in
+// bound have a value.
+func (idx *tripleIndex) count(tp triplePattern, bound map[string]bool) int {
+	count := len(idx.triples)
+
+	for i, n := range []node{tp.s, tp.p, tp.o} {
+		switch {
+		case n.term != nil:
+			var c int
+
+			switch i {
+			case 0:
+				c = len(idx.bySubject[n.term.String()])
+			case 1:
+				c = len(idx.byPredicate[n.term.String()])
+			default:
+				c = len(idx.byObject[n.term.String()])
+			}
+
+			if c < count {
+				count = c
+			}
+		case bound[n.variable]:
+			// a join on a bound variable is typically very selective
+			count /= 10
+		}
+	}
+
+	return count
+}
+
+type evaluator struct {
+	idx *tripleIndex
+}
+
+func (e *evaluator) evalGroup(group *groupPattern, input []binding) []binding {
+	solutions := input
+	filters := []expression{}
+
+	for _, elem := range group.elements {
+		switch el := elem.(type) {
+		case triplesBlock:
+			solutions = e.evalBGP(el, solutions)
+		case optionalPattern:
+			out := []binding{}
+
+			for _, b := range solutions {
+				matches := e.evalGroup(el.group, []binding{b})
+				if len(matches) == 0 {
+					out = append(out, b)
+					continue
+				}
+
+				out = append(out, matches...)
+			}
+
+			solutions = out
+		case unionPattern:
+			out := []binding{}
+
+			for _, g := range el.groups {
+				out = append(out, e.evalGroup(g, solutions)...)
+			}
+
+			solutions = out
+		case *groupPattern:
+			solutions = e.evalGroup(el, solutions)
+		case filterPattern:
+			// filters apply to the whole group
+			filters = append(filters, el.expr)
+		}
+	}
+
+	if len(filters) == 0 {
+		return solutions
+	}
+
+	out := []binding{}
+
+	for _, b := range solutions {
+		if passFilters(filters, b) {
+			out = append(out, b)
+		}
+	}
+
+	return out
+}
+
+func passFilters(filters []expression, b binding) bool {
+	for _, f := range filters {
+		v, err := f.eval(b)
+		if err != nil {
+			return false
+		}
+
+		ok, err := v.ebv()
+		if err != nil || !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// evalBGP evaluates the basic graph pattern. The triple patterns are joined in
+// order of their estimated selectivity.
+func (e *evaluator) evalBGP(block triplesBlock, input []binding) []binding {
+	if len(input) == 0 {
+		return input
+	}
+
+	bound := map[string]bool{}
+	for k := range input[0] {
+		bound[k] = true
+	}
+
+	remaining := append([]triplePattern{}, block...)
+	solutions := input
+
+	for len(remaining) > 0 && len(solutions) > 0 {
+		best := 0
+
+		for i := 1; i < len(remaining); i++ {
+			if e.idx.count(remaining[i], bound) < e.idx.count(remaining[best], bound) {
+				best = i
+			}
+		}
+
+		tp := remaining[best]
+		remaining = append(remaining[:best], remaining[best+1:]...)
+
+		out := []binding{}
+		for _, b := range solutions {
+			out = append(out, e.match(tp, b)...)
+		}
+
+		for _, n := range []node{tp.s, tp.p, tp.o} {
+			if n.isVar() {
+				bound[n.variable] = true
+			}
+		}
+
+		solutions = out
+	}
+
+	return solutions
+}
+
+func resolveNode(n node, b binding) rdf.Term {
+	if n.isVar() {
+		return b[n.variable]
+	}
+
+	return n.term
+}
+
+// match returns the solutions of the triple pattern that extend binding b.
+func (e *evaluator) match(tp triplePattern, b binding) []binding {
+	s, p, o := resolveNode(tp.s, b), resolveNode(tp.p, b), resolveNode(tp.o, b)
+
+	out := []binding{}
+
+	for _, t := range e.idx.candidates(s, p, o) {
+		if !termMatches(s, t.Subject) || !termMatches(p, t.Predicate) || !termMatches(o, t.Object) {
+			continue
+		}
+
+		extended := b.extend()
+
+		if bindNode(extended, tp.s, t.Subject) && bindNode(extended, tp.p, t.Predicate) && bindNode(extended, tp.o, t.Object) {
+			out = append(out, extended)
+		}
+	}
+
+	return out
+}
+
+func termMatches(pattern, term rdf.Term) bool {
+	return pattern == nil || pattern.String() == term.String()
+}
+
+// bindNode binds the term to the variable of the node. It returns false when
+// the variable is already bound to another term.
+func bindNode(b binding, n node, term rdf.Term) bool {
+	if !n.isVar() {
+		return true
+	}
+
+	if existing, ok := b[n.variable]; ok {
+		return existing.String() == term.String()
+	}
+
+	b[n.variable] = term
+
+	return true
+}
+
+// variables returns the variables that can be projected from the group in
+// order of appearance. Blank node variables are excluded.
+func (group *groupPattern) variables() []string {
+	vars := []string{}
+	seen := map[string]bool{}
+
+	add := func(n node) {
+		if n.isVar() && !strings.HasPrefix(n.variable, "_:") && !seen[n.variable] {
+			seen[n.variable] = true
+			vars = append(vars, n.variable)
+		}
+	}
+
+	var walk func(g *groupPattern)
+
+	walk = func(g *groupPattern) {
+		for _, elem := range g.elements {
+			switch el := elem.(type) {
+			case triplesBlock:
+				for _, tp := range el {
+					add(tp.s)
+					add(tp.p)
+					add(tp.o)
+				}
+			case optionalPattern:
+				walk(el.group)
+			case unionPattern:
+				for _, g := range el.groups {
+					walk(g)
+				}
+			case *groupPattern:
+				walk(el)
+			}
+		}
+	}
+
+	walk(group)
+
+	return vars
+}
+
+// solutions evaluates the WHERE clause and applies the solution modifiers.
+func (e *evaluator) solutions(q *query) []binding {
+	solutions := e.evalGroup(q.where, []binding{{}})
+
+	if len(q.orderBy) > 0 {
+		sort.SliceStable(solutions, func(i, j int) bool {
+			for _, cond := range q.orderBy {
+				cmp := orderCompare(cond.expr, solutions[i], solutions[j])
+				if cmp == 0 {
+					continue
+				}
+
+				if cond.descending {
+					return cmp > 0
+				}
+
+				return cmp < 0
+			}
+
+			return false
+		})
+	}
+
+	if q.form == formSelect {
+		solutions = project(solutions, q.projection(), q.distinct)
+	}
+
+	if q.offset > 0 {
+		if q.offset >= len(solutions) {
+			return []binding{}
+		}
+
+		solutions = solutions[q.offset:]
+	}
+
+	if q.limit >= 0 && q.limit < len(solutions) {
+		solutions = solutions[:q.limit]
+	}
+
+	return solutions
+}
+
+func (q *query) projection() []string {
+	if len(q.vars) > 0 {
+		return q.vars
+	}
+
+	return q.where.variables()
+}
+
+func project(solutions []binding, vars []string, distinct bool) []binding {
+	out := make([]binding, 0, len(solutions))
+	seen := map[string]bool{}
+
+	for _, s := range solutions {
+		projected := binding{}
+
+		var key strings.Builder
+
+		for _, v := range vars {
+			if term, ok := s[v]; ok {
+				projected[v] = term
+
+				key.WriteString(term.String())
+			}
+
+			key.WriteString("\x00")
+		}
+
+		if distinct {
+			if seen[key.String()] {
+				continue
+			}
+
+			seen[key.String()] = true
+		}
+
+		out = append(out, projected)
+	}
+
+	return out
+}
+
+// orderCompare orders unbound values first, followed by blank nodes, IRIs and literals.
+func orderCompare(expr expression, a, b binding) int {
+	va, errA := expr.eval(a)
+	vb, errB := expr.eval(b)
+
+	switch {
+	case errA != nil && errB != nil:
+		return 0
+	case errA != nil:
+		return -1
+	case errB != nil:
+		return 1
+	}
+
+	rank := map[rdf.TermType]int{rdf.TermBlankNode: 0, rdf.TermIRI: 1, rdf.TermLiteral: 2}
+	if rank[va.kind] != rank[vb.kind] {
+		return rank[va.kind] - rank[vb.kind]
+	}
+
+	if cmp, err := compareValues(va, vb); err == nil {
+		return cmp
+	}
+
+	return strings.Compare(va.lex, vb.lex)
+}
+
+// construct instantiates the template for each solution. Triples with unbound
+// variables or invalid terms are skipped. Blank nodes in the template are
+// unique per solution.
+func construct(template []triplePattern, solutions []binding) *rdf.Graph {
+	g := rdf.NewGraph()
+
+	for i, s := range solutions {
+		bnodes := map[string]rdf.Term{}
+
+		instantiate := func(n node) rdf.Term {
+			if !n.isVar() {
+				return n.term
+			}
+
+			if !strings.HasPrefix(n.variable, "_:") {
+				return s[n.variable]
+			}
+
+			bnode, ok := bnodes[n.variable]
+			if !ok {
+				label := fmt.Sprintf("%s%d", strings.TrimPrefix(n.variable, "_:"), i)
+
+				b, err := rdf.NewBlankNode(label)
+				if err != nil {
+					return nil
+				}
+
+				bnode = b
+				bnodes[n.variable] = b
+			}
+
+			return bnode
+		}
+
+		for _, tp := range template {
+			subject, ok := instantiate(tp.s).(rdf.Subject)
+			if !ok {
+				continue
+			}
+
+			predicate, ok := instantiate(tp.p).(rdf.Predicate)
+			if !ok {
+				continue
+			}
+
+			object, ok := instantiate(tp.o).(rdf.Object)
+			if !ok {
+				continue
+			}
+
+			g.AddTriple(subject, predicate, object)
+		}
+	}
+
+	return g
+}
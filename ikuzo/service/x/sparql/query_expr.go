@@ -0,0 +1,778 @@
+package sparql
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/delving/hub3/ikuzo/rdf"
+)
+
+// errTypeError is the SPARQL expression type error. A FILTER with a type
+// error removes the solution.
+var errTypeError = errors.New("sparql: type error")
+
+const rdfLangString = "http://www.w3.org/1999/02/22-rdf-syntax-ns#langString"
+
+// value is the result of evaluating an expression. Unlike rdf.Literal it can
+// hold empty strings.
+type value struct {
+	kind     rdf.TermType
+	lex      string
+	lang     string
+	datatype string
+}
+
+func termValue(term rdf.Term) value {
+	switch t := term.(type) {
+	case rdf.Literal:
+		v := value{kind: rdf.TermLiteral, lex: t.RawValue(), lang: strings.TrimPrefix(t.Lang(), "@")}
+
+		switch {
+		case v.lang != "":
+			v.datatype = rdfLangString
+		case t.DataType.RawValue() != "":
+			v.datatype = t.DataType.RawValue()
+		default:
+			v.datatype = xsdNS + "string"
+		}
+
+		return v
+	default:
+		return value{kind: term.Type(), lex: term.RawValue()}
+	}
+}
+
+func stringValue(s string) value {
+	return value{kind: rdf.TermLiteral, lex: s, datatype: xsdNS + "string"}
+}
+
+func boolValue(b bool) value {
+	return value{kind: rdf.TermLiteral, lex: strconv.FormatBool(b), datatype: xsdNS + "boolean"}
+}
+
+func (v value) isNumeric() bool {
+	if v.kind != rdf.TermLiteral {
+		return false
+	}
+
+	switch strings.TrimPrefix(v.datatype, xsdNS) {
+	case "integer", "int", "decimal", "double", "float", "long", "short", "byte",
+		"nonNegativeInteger", "positiveInteger", "negativeInteger", "nonPositiveInteger",
+		"unsignedLong", "unsignedInt", "unsignedShort", "unsignedByte":
+		return true
+	}
+
+	return false
+}
+
+func (v value) isInteger() bool {
+	return v.isNumeric() && !strings.HasSuffix(v.datatype, "decimal") &&
+		!strings.HasSuffix(v.datatype, "double") && !strings.HasSuffix(v.datatype, "float")
+}
+
+func (v value) number() (float64, error) {
+	if !v.isNumeric() {
+		return 0, errTypeError
+	}
+
+	f, err := strconv.ParseFloat(v.lex, 64)
+	if err != nil {
+		return 0, errTypeError
+	}
+
+	return f, nil
+}
+
+// isString returns true for simple literals, xsd:string and language tagged literals.
+func (v value) isString() bool {
+	return v.kind == rdf.TermLiteral && (v.datatype == xsdNS+"string" || v.datatype == rdfLangString)
+}
+
+// ebv returns the effective boolean value.
+func (v value) ebv() (bool, error) {
+	switch {
+	case v.kind != rdf.TermLiteral:
+		return false, errTypeError
+	case v.datatype == xsdNS+"boolean":
+		return v.lex == "true" || v.lex == "1", nil
+	case v.isNumeric():
+		f, err := v.number()
+		if err != nil {
+			return false, nil
+		}
+
+		return f != 0, nil
+	case v.isString():
+		return v.lex != "", nil
+	}
+
+	return false, errTypeError
+}
+
+// expression is a FILTER or ORDER BY expression.
+type expression interface {
+	eval(b binding) (value, error)
+}
+
+type varExpr string
+
+func (e varExpr) eval(b binding) (value, error) {
+	term, ok := b[string(e)]
+	if !ok {
+		return value{}, errTypeError
+	}
+
+	return termValue(term), nil
+}
+
+type constExpr struct {
+	v value
+}
+
+func (e constExpr) eval(b binding) (value, error) {
+	return e.v, nil
+}
+
+type notExpr struct {
+	expr expression
+}
+
+func (e notExpr) eval(b binding) (value, error) {
+	v, err := e.expr.eval(b)
+	if err != nil {
+		return value{}, err
+	}
+
+	ok, err := v.ebv()
+	if err != nil {
+		return value{}, err
+	}
+
+	return boolValue(!ok), nil
+}
+
+type negateExpr struct {
+	expr expression
+}
+
+func (e negateExpr) eval(b binding) (value, error) {
+	return arithmeticExpr{op: "-", left: constExpr{v: value{kind: rdf.TermLiteral, lex: "0", datatype: xsdNS + "integer"}}, right: e.expr}.eval(b)
+}
+
+type logicalExpr struct {
+	and         bool
+	left, right expression
+}
+
+// eval implements the SPARQL logical-and and logical-or, where an error in one
+// of the operands can be ignored when the other operand determines the result.
+func (e logicalExpr) eval(b binding) (value, error) {
+	ebv := func(expr expression) (bool, error) {
+		v, err := expr.eval(b)
+		if err != nil {
+			return false, err
+		}
+
+		return v.ebv()
+	}
+
+	l, lErr := ebv(e.left)
+	r, rErr := ebv(e.right)
+
+	switch {
+	case e.and && ((lErr == nil && !l) || (rErr == nil && !r)):
+		return boolValue(false), nil
+	case !e.and && ((lErr == nil && l) || (rErr == nil && r)):
+		return boolValue(true), nil
+	case lErr != nil:
+		return value{}, lErr
+	case rErr != nil:
+		return value{}, rErr
+	}
+
+	return boolValue(e.and), nil
+}
+
+type compareExpr struct {
+	op          string
+	left, right expression
+}
+
+func (e compareExpr) eval(b binding) (value, error) {
+	l, err := e.left.eval(b)
+	if err != nil {
+		return value{}, err
+	}
+
+	r, err := e.right.eval(b)
+	if err != nil {
+		return value{}, err
+	}
+
+	if e.op == "=" || e.op == "!=" {
+		eq, err := valuesEqual(l, r)
+		if err != nil {
+			return value{}, err
+		}
+
+		return boolValue(eq == (e.op == "=")), nil
+	}
+
+	cmp, err := compareValues(l, r)
+	if err != nil {
+		return value{}, err
+	}
+
+	switch e.op {
+	case "<":
+		return boolValue(cmp < 0), nil
+	case ">":
+		return boolValue(cmp > 0), nil
+	case "<=":
+		return boolValue(cmp <= 0), nil
+	case ">=":
+		return boolValue(cmp >= 0), nil
+	}
+
+	return value{}, fmt.Errorf("sparql: unknown operator %s", e.op)
+}
+
+func valuesEqual(l, r value) (bool, error) {
+	if l.isNumeric() && r.isNumeric() {
+		lf, err := l.number()
+		if err != nil {
+			return false, err
+		}
+
+		rf, err := r.number()
+		if err != nil {
+			return false, err
+		}
+
+		return lf == rf, nil
+	}
+
+	return l == r, nil
+}
+
+// compareValues compares numbers, strings, booleans and dateTimes.
+func compareValues(l, r value) (int, error) {
+	switch {
+	case l.isNumeric() && r.isNumeric():
+		lf, err := l.number()
+		if err != nil {
+			return 0, err
+		}
+
+		rf, err := r.number()
+		if err != nil {
+			return 0, err
+		}
+
+		switch {
+		case lf < rf:
+			return -1, nil
+		case lf > rf:
+			return 1, nil
+		}
+
+		return 0, nil
+	case l.isString() && r.isString(), l.kind == rdf.TermLiteral && l.datatype == r.datatype:
+		return strings.Compare(l.lex, r.lex), nil
+	}
+
+	return 0, errTypeError
+}
+
+type arithmeticExpr struct {
+	op          string
+	left, right expression
+}
+
+func (e arithmeticExpr) eval(b binding) (value, error) {
+	l, err := e.left.eval(b)
+	if err != nil {
+		return value{}, err
+	}
+
+	r, err := e.right.eval(b)
+	if err != nil {
+		return value{}, err
+	}
+
+	lf, err := l.number()
+	if err != nil {
+		return value{}, err
+	}
+
+	rf, err := r.number()
+	if err != nil {
+		return value{}, err
+	}
+
+	var result float64
+
+	switch e.op {
+	case "+":
+		result = lf + rf
+	case "-":
+		result = lf - rf
+	case "*":
+		result = lf * rf
+	case "/":
+		if rf == 0 {
+			return value{}, errTypeError
+		}
+
+		result = lf / rf
+	}
+
+	if l.isInteger() && r.isInteger() && e.op != "/" {
+		return value{kind: rdf.TermLiteral, lex: strconv.FormatInt(int64(result), 10), datatype: xsdNS + "integer"}, nil
+	}
+
+	return value{kind: rdf.TermLiteral, lex: strconv.FormatFloat(result, 'f', -1, 64), datatype: xsdNS + "decimal"}, nil
+}
+
+type boundExpr string
+
+func (e boundExpr) eval(b binding) (value, error) {
+	_, ok := b[string(e)]
+	return boolValue(ok), nil
+}
+
+// funcExpr is a call of one of the supported builtin functions.
+type funcExpr struct {
+	name  string
+	args  []expression
+	regex *regexp.Regexp
+}
+
+func (e funcExpr) eval(b binding) (value, error) {
+	args := make([]value, 0, len(e.args))
+
+	for _, arg := range e.args {
+		v, err := arg.eval(b)
+		if err != nil {
+			return value{}, err
+		}
+
+		args = append(args, v)
+	}
+
+	switch e.name {
+	case "ISIRI", "ISURI":
+		return boolValue(args[0].kind == rdf.TermIRI), nil
+	case "ISBLANK":
+		return boolValue(args[0].kind == rdf.TermBlankNode), nil
+	case "ISLITERAL":
+		return boolValue(args[0].kind == rdf.TermLiteral), nil
+	case "ISNUMERIC":
+		return boolValue(args[0].isNumeric()), nil
+	case "STR":
+		if args[0].kind == rdf.TermBlankNode {
+			return value{}, errTypeError
+		}
+
+		return stringValue(args[0].lex), nil
+	case "LANG":
+		if args[0].kind != rdf.TermLiteral {
+			return value{}, errTypeError
+		}
+
+		return stringValue(args[0].lang), nil
+	case "DATATYPE":
+		if args[0].kind != rdf.TermLiteral {
+			return value{}, errTypeError
+		}
+
+		return value{kind: rdf.TermIRI, lex: args[0].datatype}, nil
+	case "SAMETERM":
+		return boolValue(args[0] == args[1]), nil
+	case "LANGMATCHES":
+		tag, rng := strings.ToLower(args[0].lex), strings.ToLower(args[1].lex)
+
+		switch {
+		case rng == "*":
+			return boolValue(tag != ""), nil
+		case tag == rng, strings.HasPrefix(tag, rng+"-"):
+			return boolValue(true), nil
+		}
+
+		return boolValue(false), nil
+	case "STRLEN":
+		if !args[0].isString() {
+			return value{}, errTypeError
+		}
+
+		return value{kind: rdf.TermLiteral, lex: strconv.Itoa(len([]rune(args[0].lex))), datatype: xsdNS + "integer"}, nil
+	case "UCASE", "LCASE":
+		if !args[0].isString() {
+			return value{}, errTypeError
+		}
+
+		v := args[0]
+		if e.name == "UCASE" {
+			v.lex = strings.ToUpper(v.lex)
+		} else {
+			v.lex = strings.ToLower(v.lex)
+		}
+
+		return v, nil
+	case "CONTAINS", "STRSTARTS", "STRENDS":
+		if !args[0].isString() || !args[1].isString() {
+			return value{}, errTypeError
+		}
+
+		fn := map[string]func(string, string) bool{
+			"CONTAINS":  strings.Contains,
+			"STRSTARTS": strings.HasPrefix,
+			"STRENDS":   strings.HasSuffix,
+		}[e.name]
+
+		return boolValue(fn(args[0].lex, args[1].lex)), nil
+	case "REGEX":
+		if !args[0].isString() {
+			return value{}, errTypeError
+		}
+
+		rgx := e.regex
+		if rgx == nil {
+			var flags string
+			if len(args) == 3 {
+				flags = args[2].lex
+			}
+
+			var err error
+
+			rgx, err = compileRegex(args[1].lex, flags)
+			if err != nil {
+				return value{}, errTypeError
+			}
+		}
+
+		return boolValue(rgx.MatchString(args[0].lex)), nil
+	}
+
+	return value{}, fmt.Errorf("sparql: unsupported function %s", e.name)
+}
+
+// functionArity lists the supported functions with their minimum and maximum
+// number of arguments.
+var functionArity = map[string][2]int{
+	"ISIRI":       {1, 1},
+	"ISURI":       {1, 1},
+	"ISBLANK":     {1, 1},
+	"ISLITERAL":   {1, 1},
+	"ISNUMERIC":   {1, 1},
+	"STR":         {1, 1},
+	"LANG":        {1, 1},
+	"DATATYPE":    {1, 1},
+	"SAMETERM":    {2, 2},
+	"LANGMATCHES": {2, 2},
+	"STRLEN":      {1, 1},
+	"UCASE":       {1, 1},
+	"LCASE":       {1, 1},
+	"CONTAINS":    {2, 2},
+	"STRSTARTS":   {2, 2},
+	"STRENDS":     {2, 2},
+	"REGEX":       {2, 3},
+}
+
+func compileRegex(pattern, flags string) (*regexp.Regexp, error) {
+	var goFlags string
+
+	for _, f := range flags {
+		switch f {
+		case 'i', 'm', 's':
+			goFlags += string(f)
+		default:
+			return nil, fmt.Errorf("unsupported regex flag %q", f)
+		}
+	}
+
+	if goFlags != "" {
+		pattern = "(?" + goFlags + ")" + pattern
+	}
+
+	return regexp.Compile(pattern)
+}
+
+// parseConstraint parses the constraint of a FILTER.
+func (p *queryParser) parseConstraint() (expression, error) {
+	tok := p.peek()
+
+	switch {
+	case tok.is("("):
+		return p.parseBracketted()
+	case tok.typ == tokKeyword:
+		return p.parsePrimary()
+	}
+
+	return nil, p.errorf("expected FILTER constraint, got %s", tok)
+}
+
+func (p *queryParser) parseBracketted() (expression, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	expr, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+
+	return expr, nil
+}
+
+func (p *queryParser) parseExpression() (expression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = logicalExpr{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (expression, error) {
+	left, err := p.parseRelational()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.accept("&&") {
+		right, err := p.parseRelational()
+		if err != nil {
+			return nil, err
+		}
+
+		left = logicalExpr{and: true, left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *queryParser) parseRelational() (expression, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, op := range []string{"=", "!=", "<=", ">=", "<", ">"} {
+		if p.accept(op) {
+			right, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+
+			return compareExpr{op: op, left: left, right: right}, nil
+		}
+	}
+
+	return left, nil
+}
+
+func (p *queryParser) parseAdditive() (expression, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		tok := p.peek()
+		if !tok.is("+") && !tok.is("-") {
+			return left, nil
+		}
+
+		p.next()
+
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+
+		left = arithmeticExpr{op: tok.val, left: left, right: right}
+	}
+}
+
+func (p *queryParser) parseMultiplicative() (expression, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		tok := p.peek()
+		if !tok.is("*") && !tok.is("/") {
+			return left, nil
+		}
+
+		p.next()
+
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		left = arithmeticExpr{op: tok.val, left: left, right: right}
+	}
+}
+
+func (p *queryParser) parseUnary() (expression, error) {
+	switch {
+	case p.accept("!"):
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return notExpr{expr: expr}, nil
+	case p.accept("-"):
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return negateExpr{expr: expr}, nil
+	case p.accept("+"):
+		return p.parseUnary()
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *queryParser) parsePrimary() (expression, error) {
+	tok := p.peek()
+
+	switch tok.typ {
+	case tokVar:
+		p.next()
+		return varExpr(tok.val), nil
+	case tokIRI, tokPName:
+		iri, err := p.parseIRI()
+		if err != nil {
+			return nil, err
+		}
+
+		return constExpr{v: termValue(iri)}, nil
+	case tokString, tokNumber:
+		l, _, err := p.parseLiteral()
+		if err != nil {
+			return nil, err
+		}
+
+		return constExpr{v: termValue(l)}, nil
+	case tokPunct:
+		if tok.is("(") {
+			return p.parseBracketted()
+		}
+	case tokKeyword:
+		if tok.is("true") || tok.is("false") {
+			p.next()
+			return constExpr{v: boolValue(strings.EqualFold(tok.val, "true"))}, nil
+		}
+
+		return p.parseFunction()
+	}
+
+	return nil, p.errorf("unexpected %s in expression", tok)
+}
+
+func (p *queryParser) parseFunction() (expression, error) {
+	name := strings.ToUpper(p.next().val)
+
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	if name == "BOUND" {
+		tok := p.next()
+		if tok.typ != tokVar {
+			return nil, p.errorf("expected variable in BOUND, got %s", tok)
+		}
+
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+
+		return boundExpr(tok.val), nil
+	}
+
+	arity, ok := functionArity[name]
+	if !ok {
+		return nil, p.errorf("unsupported function %s", name)
+	}
+
+	fn := funcExpr{name: name}
+
+	for !p.accept(")") {
+		if len(fn.args) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+
+		arg, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+
+		fn.args = append(fn.args, arg)
+	}
+
+	if len(fn.args) < arity[0] || len(fn.args) > arity[1] {
+		return nil, p.errorf("%s expects %d to %d arguments, got %d", name, arity[0], arity[1], len(fn.args))
+	}
+
+	// precompile constant regular expressions
+	if name == "REGEX" {
+		if err := fn.compileRegex(); err != nil {
+			return nil, p.errorf("invalid REGEX; %s", err)
+		}
+	}
+
+	return fn, nil
+}
+
+func (e *funcExpr) compileRegex() error {
+	pattern, ok := e.args[1].(constExpr)
+	if !ok {
+		return nil
+	}
+
+	var flags string
+
+	if len(e.args) == 3 {
+		f, ok := e.args[2].(constExpr)
+		if !ok {
+			return nil
+		}
+
+		flags = f.v.lex
+	}
+
+	rgx, err := compileRegex(pattern.v.lex, flags)
+	if err != nil {
+		return err
+	}
+
+	e.regex = rgx
+
+	return nil
+}
@@ -0,0 +1,323 @@
+package sparql
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenType int
+
+const (
+	tokEOF tokenType = iota
+	tokIRI
+	tokPName
+	tokVar
+	tokBlankNode
+	tokString
+	tokLangTag
+	tokNumber
+	tokKeyword
+	tokPunct
+)
+
+type token struct {
+	typ tokenType
+	val string
+	pos int
+}
+
+func (t token) String() string {
+	if t.typ == tokEOF {
+		return "end of query"
+	}
+
+	return fmt.Sprintf("%q at offset %d", t.val, t.pos)
+}
+
+// is returns true when the token is the keyword or punctuation. Keywords are
+// compared case-insensitive.
+func (t token) is(val string) bool {
+	switch t.typ {
+	case tokKeyword:
+		return strings.EqualFold(t.val, val)
+	case tokPunct:
+		return t.val == val
+	}
+
+	return false
+}
+
+// lex splits the query into tokens.
+func lex(query string) ([]token, error) {
+	l := &lexer{input: query}
+
+	for {
+		tok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+
+		l.tokens = append(l.tokens, tok)
+
+		if tok.typ == tokEOF {
+			return l.tokens, nil
+		}
+	}
+}
+
+type lexer struct {
+	input  string
+	pos    int
+	tokens []token
+}
+
+func (l *lexer) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s at offset %d", ErrInvalidQuery, fmt.Sprintf(format, args...), l.pos)
+}
+
+func (l *lexer) peek(offset int) byte {
+	if l.pos+offset >= len(l.input) {
+		return 0
+	}
+
+	return l.input[l.pos+offset]
+}
+
+func (l *lexer) skipWhitespace() {
+	for l.pos < len(l.input) {
+		c := l.input[l.pos]
+
+		switch {
+		case c == '#':
+			for l.pos < len(l.input) && l.input[l.pos] != '\n' {
+				l.pos++
+			}
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			l.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (l *lexer) next() (token, error) {
+	l.skipWhitespace()
+
+	start := l.pos
+	emit := func(typ tokenType, val string) (token, error) {
+		return token{typ: typ, val: val, pos: start}, nil
+	}
+
+	if l.pos >= len(l.input) {
+		return emit(tokEOF, "")
+	}
+
+	c := l.input[l.pos]
+
+	switch {
+	case c == '<':
+		if iri, ok := l.scanIRI(); ok {
+			return emit(tokIRI, iri)
+		}
+
+		if l.peek(1) == '=' {
+			l.pos += 2
+			return emit(tokPunct, "<=")
+		}
+
+		l.pos++
+
+		return emit(tokPunct, "<")
+	case c == '?' || c == '$':
+		l.pos++
+		name := l.scanName()
+
+		if name == "" {
+			return token{}, l.errorf("empty variable name")
+		}
+
+		return emit(tokVar, name)
+	case c == '_' && l.peek(1) == ':':
+		l.pos += 2
+		label := l.scanName()
+
+		if label == "" {
+			return token{}, l.errorf("empty blank node label")
+		}
+
+		return emit(tokBlankNode, label)
+	case c == '"' || c == '\'':
+		s, err := l.scanString()
+		if err != nil {
+			return token{}, err
+		}
+
+		return emit(tokString, s)
+	case c == '@':
+		l.pos++
+		lang := l.scanWhile(func(r rune) bool {
+			return r == '-' || unicode.IsLetter(r) || unicode.IsDigit(r)
+		})
+
+		if lang == "" {
+			return token{}, l.errorf("empty language tag")
+		}
+
+		return emit(tokLangTag, lang)
+	case isDigit(c) || (c == '.' && isDigit(l.peek(1))):
+		return emit(tokNumber, l.scanNumber())
+	case c == ':' || isNameStart(l.input[l.pos:]):
+		word := l.scanName()
+
+		if l.peek(0) == ':' {
+			l.pos++
+			local := l.scanLocalName()
+
+			return emit(tokPName, word+":"+local)
+		}
+
+		return emit(tokKeyword, word)
+	}
+
+	for _, punct := range []string{"^^", "&&", "||", "!=", ">=", "{", "}", "(", ")", "[", "]", ".", ";", ",", "*", "=", ">", "!", "+", "-", "/"} {
+		if strings.HasPrefix(l.input[l.pos:], punct) {
+			l.pos += len(punct)
+			return emit(tokPunct, punct)
+		}
+	}
+
+	return token{}, l.errorf("unexpected character %q", c)
+}
+
+// scanIRI scans an IRIREF. ok is false when the '<' is a comparison operator.
+func (l *lexer) scanIRI() (string, bool) {
+	for i := l.pos + 1; i < len(l.input); i++ {
+		switch c := l.input[i]; c {
+		case '>':
+			iri := l.input[l.pos+1 : i]
+			l.pos = i + 1
+
+			return iri, true
+		case ' ', '\t', '\n', '\r', '<', '"', '{', '}', '|', '^', '`', '\\':
+			return "", false
+		}
+	}
+
+	return "", false
+}
+
+func (l *lexer) scanWhile(fn func(r rune) bool) string {
+	start := l.pos
+
+	for l.pos < len(l.input) {
+		r, size := utf8.DecodeRuneInString(l.input[l.pos:])
+		if !fn(r) {
+			break
+		}
+
+		l.pos += size
+	}
+
+	return l.input[start:l.pos]
+}
+
+func (l *lexer) scanName() string {
+	return l.scanWhile(func(r rune) bool {
+		return r == '_' || r == '-' || unicode.IsLetter(r) || unicode.IsDigit(r)
+	})
+}
+
+// scanLocalName scans the local part of a prefixed name. A trailing '.' is not
+// part of the local name.
+func (l *lexer) scanLocalName() string {
+	local := l.scanWhile(func(r rune) bool {
+		return r == '_' || r == '-' || r == '.' || r == ':' || unicode.IsLetter(r) || unicode.IsDigit(r)
+	})
+
+	for strings.HasSuffix(local, ".") {
+		local = local[:len(local)-1]
+		l.pos--
+	}
+
+	return local
+}
+
+func (l *lexer) scanNumber() string {
+	start := l.pos
+
+	for isDigit(l.peek(0)) {
+		l.pos++
+	}
+
+	if l.peek(0) == '.' && isDigit(l.peek(1)) {
+		l.pos++
+
+		for isDigit(l.peek(0)) {
+			l.pos++
+		}
+	}
+
+	if c := l.peek(0); c == 'e' || c == 'E' {
+		l.pos++
+
+		if c := l.peek(0); c == '+' || c == '-' {
+			l.pos++
+		}
+
+		for isDigit(l.peek(0)) {
+			l.pos++
+		}
+	}
+
+	return l.input[start:l.pos]
+}
+
+var stringUnescaper = strings.NewReplacer(
+	`\t`, "\t",
+	`\n`, "\n",
+	`\r`, "\r",
+	`\b`, "\b",
+	`\f`, "\f",
+	`\"`, `"`,
+	`\'`, `'`,
+	`\\`, `\`,
+)
+
+func (l *lexer) scanString() (string, error) {
+	quote := l.input[l.pos : l.pos+1]
+	if strings.HasPrefix(l.input[l.pos:], strings.Repeat(quote, 3)) {
+		quote = strings.Repeat(quote, 3)
+	}
+
+	l.pos += len(quote)
+	start := l.pos
+
+	for l.pos < len(l.input) {
+		switch {
+		case l.input[l.pos] == '\\':
+			l.pos += 2
+		case strings.HasPrefix(l.input[l.pos:], quote):
+			s := l.input[start:l.pos]
+			l.pos += len(quote)
+
+			return stringUnescaper.Replace(s), nil
+		case len(quote) == 1 && (l.input[l.pos] == '\n' || l.input[l.pos] == '\r'):
+			return "", l.errorf("unterminated string")
+		default:
+			l.pos++
+		}
+	}
+
+	return "", l.errorf("unterminated string")
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isNameStart(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r == '_' || unicode.IsLetter(r)
+}
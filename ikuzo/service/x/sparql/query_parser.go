@@ -0,0 +1,641 @@
+package sparql
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/delving/hub3/ikuzo/rdf"
+)
+
+// ErrInvalidQuery is returned when a SPARQL query cannot be parsed or is not
+// supported by the local query engine.
+var ErrInvalidQuery = errors.New("invalid SPARQL query")
+
+const xsdNS = "http://www.w3.org/2001/XMLSchema#"
+
+type queryForm int
+
+const (
+	formSelect queryForm = iota
+	formConstruct
+	formAsk
+)
+
+// query is the parsed representation of a SPARQL query.
+type query struct {
+	form     queryForm
+	distinct bool
+	// vars are the projected variables. When empty all variables are projected.
+	vars     []string
+	template []triplePattern
+	where    *groupPattern
+	orderBy  []orderCondition
+	limit    int
+	offset   int
+}
+
+// node is a term or a variable in a triple pattern.
+type node struct {
+	variable string
+	term     rdf.Term
+}
+
+func (n node) isVar() bool {
+	return n.variable != ""
+}
+
+type triplePattern struct {
+	s, p, o node
+}
+
+// patternElement is one of triplesBlock, optionalPattern, unionPattern,
+// *groupPattern or filterPattern.
+type patternElement interface{}
+
+type triplesBlock []triplePattern
+
+type optionalPattern struct {
+	group *groupPattern
+}
+
+type unionPattern struct {
+	groups []*groupPattern
+}
+
+type filterPattern struct {
+	expr expression
+}
+
+type groupPattern struct {
+	elements []patternElement
+}
+
+type orderCondition struct {
+	expr       expression
+	descending bool
+}
+
+type queryParser struct {
+	tokens   []token
+	pos      int
+	prefixes map[string]string
+	base     string
+	bnodes   int
+}
+
+// parseQuery parses a SELECT, CONSTRUCT or ASK query.
+func parseQuery(q string) (*query, error) {
+	tokens, err := lex(q)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &queryParser{
+		tokens:   tokens,
+		prefixes: map[string]string{},
+	}
+
+	return p.parse()
+}
+
+func (p *queryParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.typ != tokEOF {
+		p.pos++
+	}
+
+	return tok
+}
+
+func (p *queryParser) accept(val string) bool {
+	if p.peek().is(val) {
+		p.pos++
+		return true
+	}
+
+	return false
+}
+
+func (p *queryParser) expect(val string) error {
+	if !p.accept(val) {
+		return p.errorf("expected %q, got %s", val, p.peek())
+	}
+
+	return nil
+}
+
+func (p *queryParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidQuery, fmt.Sprintf(format, args...))
+}
+
+func (p *queryParser) parse() (*query, error) {
+	if err := p.parsePrologue(); err != nil {
+		return nil, err
+	}
+
+	q := &query{limit: -1}
+
+	var err error
+
+	switch tok := p.next(); {
+	case tok.is("SELECT"):
+		q.form = formSelect
+		err = p.parseSelect(q)
+	case tok.is("CONSTRUCT"):
+		q.form = formConstruct
+		err = p.parseConstruct(q)
+	case tok.is("ASK"):
+		q.form = formAsk
+		err = p.parseWhere(q)
+	default:
+		return nil, p.errorf("unsupported query form %s", tok)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.parseModifiers(q); err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.typ != tokEOF {
+		return nil, p.errorf("unexpected %s", tok)
+	}
+
+	return q, nil
+}
+
+func (p *queryParser) parsePrologue() error {
+	for {
+		switch {
+		case p.accept("PREFIX"):
+			tok := p.next()
+			if tok.typ != tokPName || !strings.HasSuffix(tok.val, ":") {
+				return p.errorf("expected prefix name, got %s", tok)
+			}
+
+			iri := p.next()
+			if iri.typ != tokIRI {
+				return p.errorf("expected IRI, got %s", iri)
+			}
+
+			p.prefixes[strings.TrimSuffix(tok.val, ":")] = p.resolve(iri.val)
+		case p.accept("BASE"):
+			iri := p.next()
+			if iri.typ != tokIRI {
+				return p.errorf("expected IRI, got %s", iri)
+			}
+
+			p.base = iri.val
+		default:
+			return nil
+		}
+	}
+}
+
+func (p *queryParser) resolve(iri string) string {
+	if p.base != "" && !strings.Contains(iri, ":") {
+		return p.base + iri
+	}
+
+	return iri
+}
+
+func (p *queryParser) parseSelect(q *query) error {
+	if p.accept("DISTINCT") || p.accept("REDUCED") {
+		q.distinct = true
+	}
+
+	if !p.accept("*") {
+		for p.peek().typ == tokVar {
+			q.vars = append(q.vars, p.next().val)
+		}
+
+		if len(q.vars) == 0 {
+			return p.errorf("expected variables or '*', got %s", p.peek())
+		}
+	}
+
+	return p.parseWhere(q)
+}
+
+func (p *queryParser) parseConstruct(q *query) error {
+	// short form: CONSTRUCT WHERE { triples }
+	if p.peek().is("WHERE") {
+		if err := p.parseWhere(q); err != nil {
+			return err
+		}
+
+		for _, elem := range q.where.elements {
+			block, ok := elem.(triplesBlock)
+			if !ok {
+				return p.errorf("CONSTRUCT WHERE only supports triple patterns")
+			}
+
+			q.template = append(q.template, block...)
+		}
+
+		return nil
+	}
+
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+
+	for !p.accept("}") {
+		if p.accept(".") {
+			continue
+		}
+
+		triples, err := p.parseTriples()
+		if err != nil {
+			return err
+		}
+
+		q.template = append(q.template, triples...)
+	}
+
+	return p.parseWhere(q)
+}
+
+func (p *queryParser) parseWhere(q *query) error {
+	p.accept("WHERE")
+
+	group, err := p.parseGroup()
+	if err != nil {
+		return err
+	}
+
+	q.where = group
+
+	return nil
+}
+
+func (p *queryParser) parseModifiers(q *query) error {
+	if p.accept("ORDER") {
+		if err := p.expect("BY"); err != nil {
+			return err
+		}
+
+		for {
+			var cond orderCondition
+
+			switch tok := p.peek(); {
+			case tok.is("ASC"), tok.is("DESC"):
+				p.next()
+
+				cond.descending = tok.is("DESC")
+
+				if err := p.expect("("); err != nil {
+					return err
+				}
+
+				expr, err := p.parseExpression()
+				if err != nil {
+					return err
+				}
+
+				if err := p.expect(")"); err != nil {
+					return err
+				}
+
+				cond.expr = expr
+			case tok.typ == tokVar:
+				p.next()
+
+				cond.expr = varExpr(tok.val)
+			case tok.is("("):
+				expr, err := p.parseBracketted()
+				if err != nil {
+					return err
+				}
+
+				cond.expr = expr
+			default:
+				if len(q.orderBy) == 0 {
+					return p.errorf("expected order condition, got %s", tok)
+				}
+			}
+
+			if cond.expr == nil {
+				break
+			}
+
+			q.orderBy = append(q.orderBy, cond)
+		}
+	}
+
+	for {
+		var target *int
+
+		switch {
+		case p.accept("LIMIT"):
+			target = &q.limit
+		case p.accept("OFFSET"):
+			target = &q.offset
+		default:
+			return nil
+		}
+
+		tok := p.next()
+
+		n, err := strconv.Atoi(tok.val)
+		if tok.typ != tokNumber || err != nil || n < 0 {
+			return p.errorf("expected non-negative integer, got %s", tok)
+		}
+
+		*target = n
+	}
+}
+
+func (p *queryParser) parseGroup() (*groupPattern, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	group := &groupPattern{}
+
+	for {
+		tok := p.peek()
+
+		switch {
+		case tok.is("}"):
+			p.next()
+			return group, nil
+		case tok.typ == tokEOF:
+			return nil, p.errorf("unexpected end of query, missing '}'")
+		case tok.is("."):
+			p.next()
+		case tok.is("OPTIONAL"):
+			p.next()
+
+			optional, err := p.parseGroup()
+			if err != nil {
+				return nil, err
+			}
+
+			group.elements = append(group.elements, optionalPattern{group: optional})
+		case tok.is("FILTER"):
+			p.next()
+
+			expr, err := p.parseConstraint()
+			if err != nil {
+				return nil, err
+			}
+
+			group.elements = append(group.elements, filterPattern{expr: expr})
+		case tok.is("{"):
+			elem, err := p.parseGroupOrUnion()
+			if err != nil {
+				return nil, err
+			}
+
+			group.elements = append(group.elements, elem)
+		default:
+			triples, err := p.parseTriples()
+			if err != nil {
+				return nil, err
+			}
+
+			// merge adjacent triple blocks into one basic graph pattern
+			if n := len(group.elements); n > 0 {
+				if block, ok := group.elements[n-1].(triplesBlock); ok {
+					group.elements[n-1] = append(block, triples...)
+					continue
+				}
+			}
+
+			group.elements = append(group.elements, triplesBlock(triples))
+		}
+	}
+}
+
+func (p *queryParser) parseGroupOrUnion() (patternElement, error) {
+	group, err := p.parseGroup()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.peek().is("UNION") {
+		return group, nil
+	}
+
+	union := unionPattern{groups: []*groupPattern{group}}
+
+	for p.accept("UNION") {
+		group, err := p.parseGroup()
+		if err != nil {
+			return nil, err
+		}
+
+		union.groups = append(union.groups, group)
+	}
+
+	return union, nil
+}
+
+// parseTriples parses the triples with the same subject.
+func (p *queryParser) parseTriples() ([]triplePattern, error) {
+	subject, err := p.parseNode(false)
+	if err != nil {
+		return nil, err
+	}
+
+	triples := []triplePattern{}
+
+	for {
+		predicate, err := p.parseVerb()
+		if err != nil {
+			return nil, err
+		}
+
+		for {
+			object, err := p.parseNode(true)
+			if err != nil {
+				return nil, err
+			}
+
+			triples = append(triples, triplePattern{s: subject, p: predicate, o: object})
+
+			if !p.accept(",") {
+				break
+			}
+		}
+
+		if !p.accept(";") {
+			return triples, nil
+		}
+
+		// a trailing ';' is allowed
+		if tok := p.peek(); tok.is(".") || tok.is("}") {
+			return triples, nil
+		}
+	}
+}
+
+func (p *queryParser) parseVerb() (node, error) {
+	tok := p.peek()
+
+	if tok.typ == tokKeyword && tok.val == "a" {
+		p.next()
+		return node{term: rdf.IsA}, nil
+	}
+
+	switch tok.typ {
+	case tokVar, tokIRI, tokPName:
+		return p.parseNode(false)
+	}
+
+	return node{}, p.errorf("expected predicate, got %s", tok)
+}
+
+// parseNode parses a variable, IRI, blank node or, when allowLiteral is true, a literal.
+func (p *queryParser) parseNode(allowLiteral bool) (node, error) {
+	tok := p.peek()
+
+	switch tok.typ {
+	case tokVar:
+		p.next()
+		return node{variable: tok.val}, nil
+	case tokBlankNode:
+		p.next()
+		// blank nodes in patterns behave as variables that are not projected
+		return node{variable: "_:" + tok.val}, nil
+	case tokIRI, tokPName:
+		iri, err := p.parseIRI()
+		if err != nil {
+			return node{}, err
+		}
+
+		return node{term: iri}, nil
+	}
+
+	if tok.is("[") {
+		p.next()
+
+		if err := p.expect("]"); err != nil {
+			return node{}, err
+		}
+
+		p.bnodes++
+
+		return node{variable: fmt.Sprintf("_:anon%d", p.bnodes)}, nil
+	}
+
+	if allowLiteral {
+		l, ok, err := p.parseLiteral()
+		if err != nil {
+			return node{}, err
+		}
+
+		if ok {
+			return node{term: l}, nil
+		}
+	}
+
+	return node{}, p.errorf("unexpected %s", tok)
+}
+
+func (p *queryParser) parseIRI() (rdf.IRI, error) {
+	tok := p.next()
+
+	switch tok.typ {
+	case tokIRI:
+		return rdf.NewIRI(p.resolve(tok.val))
+	case tokPName:
+		parts := strings.SplitN(tok.val, ":", 2)
+
+		base, ok := p.prefixes[parts[0]]
+		if !ok {
+			return rdf.IRI{}, p.errorf("unknown prefix %q", parts[0])
+		}
+
+		return rdf.NewIRI(base + parts[1])
+	}
+
+	return rdf.IRI{}, p.errorf("expected IRI, got %s", tok)
+}
+
+// parseLiteral parses a string, numeric or boolean literal. ok is false when
+// the next token is not a literal.
+func (p *queryParser) parseLiteral() (l rdf.Literal, ok bool, err error) {
+	tok := p.peek()
+
+	switch {
+	case tok.typ == tokString:
+		p.next()
+
+		if lang := p.peek(); lang.typ == tokLangTag {
+			p.next()
+
+			l, err = rdf.NewLiteralWithLang(tok.val, lang.val)
+
+			return l, true, err
+		}
+
+		if p.accept("^^") {
+			dt, err := p.parseIRI()
+			if err != nil {
+				return l, true, err
+			}
+
+			l, err = rdf.NewLiteralWithType(tok.val, dt)
+
+			return l, true, err
+		}
+
+		l, err = rdf.NewLiteral(tok.val)
+
+		return l, true, err
+	case tok.typ == tokNumber, tok.is("+"), tok.is("-"):
+		sign := ""
+		if tok.typ == tokPunct {
+			if p.tokens[p.pos+1].typ != tokNumber {
+				return l, false, nil
+			}
+
+			p.next()
+
+			sign = tok.val
+		}
+
+		num := p.next().val
+
+		l, err = rdf.NewLiteralWithType(sign+num, numericType(num))
+
+		return l, true, err
+	case tok.is("true"), tok.is("false"):
+		p.next()
+
+		l, err = rdf.NewLiteralWithType(strings.ToLower(tok.val), xsdIRI("boolean"))
+
+		return l, true, err
+	}
+
+	return l, false, nil
+}
+
+// numericType returns the XSD datatype of a numeric literal.
+func numericType(num string) rdf.IRI {
+	switch {
+	case strings.ContainsAny(num, "eE"):
+		return xsdIRI("double")
+	case strings.Contains(num, "."):
+		return xsdIRI("decimal")
+	default:
+		return xsdIRI("integer")
+	}
+}
+
+// xsdIRI returns the IRI of a XSD datatype.
+func xsdIRI(local string) rdf.IRI {
+	iri, _ := rdf.NewIRI(xsdNS + local)
+	return iri
+}
@@ -0,0 +1,77 @@
+package sparql
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestParseQuery(t *testing.T) {
+	is := is.New(t)
+
+	q, err := parseQuery(`
+		PREFIX dc: <http://purl.org/dc/elements/1.1/>
+		# comments are ignored
+		SELECT DISTINCT ?s ?title
+		WHERE {
+			?s a <urn:Type> ;
+				dc:title ?title , "x"@en .
+			OPTIONAL { ?s dc:date ?date }
+			{ ?s dc:subject ?o } UNION { ?s dc:type ?o }
+			FILTER (?date >= "2020"^^<http://www.w3.org/2001/XMLSchema#integer>)
+		}
+		ORDER BY ?title DESC(?s)
+		OFFSET 10 LIMIT 5`)
+	is.NoErr(err)
+
+	is.Equal(q.form, formSelect)
+	is.True(q.distinct)
+	is.Equal(q.vars, []string{"s", "title"})
+	is.Equal(q.limit, 5)
+	is.Equal(q.offset, 10)
+	is.Equal(len(q.orderBy), 2)
+	is.True(q.orderBy[1].descending)
+
+	is.Equal(len(q.where.elements), 4)
+
+	block, ok := q.where.elements[0].(triplesBlock)
+	is.True(ok)
+	is.Equal(len(block), 3)
+	is.Equal(block[0].p.term.RawValue(), "http://www.w3.org/1999/02/22-rdf-syntax-ns#type")
+	is.Equal(block[2].o.term.String(), `"x"@en`)
+
+	union, ok := q.where.elements[2].(unionPattern)
+	is.True(ok)
+	is.Equal(len(union.groups), 2)
+
+	is.Equal(q.where.variables(), []string{"s", "title", "date", "o"})
+}
+
+func TestParseQueryInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"unsupported form", `DESCRIBE <urn:s>`},
+		{"unknown prefix", `SELECT * WHERE { ?s dc:title ?o }`},
+		{"missing brace", `SELECT * WHERE { ?s ?p ?o `},
+		{"missing object", `SELECT * WHERE { ?s ?p }`},
+		{"unsupported function", `SELECT * WHERE { ?s ?p ?o FILTER (now() > ?o) }`},
+		{"invalid regex", `SELECT * WHERE { ?s ?p ?o FILTER regex(?o, "[a-") }`},
+		{"invalid limit", `SELECT * WHERE { ?s ?p ?o } LIMIT ?x`},
+		{"unterminated string", `SELECT * WHERE { ?s ?p "o }`},
+		{"trailing tokens", `ASK { ?s ?p ?o } }`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+
+			_, err := parseQuery(tt.query)
+			is.True(errors.Is(err, ErrInvalidQuery))
+		})
+	}
+}
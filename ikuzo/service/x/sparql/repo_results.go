@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	fmt "fmt"
 	"io"
+	"strings"
 
 	"github.com/delving/hub3/ikuzo/rdf"
 )
@@ -19,26 +20,51 @@ const (
 
 // Results holds the parsed results of a application/sparql-results+json response.
 type Results struct {
-	Head    Header
-	Results results
+	Head    Header  `json:"head"`
+	Results results `json:"results"`
+	// Boolean is the result of an ASK query
+	Boolean *bool `json:"boolean,omitempty"`
 }
 
 type Header struct {
-	Link []string
-	Vars []string
+	Link []string `json:"link,omitempty"`
+	Vars []string `json:"vars"`
 }
 
 type results struct {
-	Distinct bool
-	Ordered  bool
-	Bindings []map[string]*Entry
+	Distinct bool                `json:"distinct"`
+	Ordered  bool                `json:"ordered"`
+	Bindings []map[string]*Entry `json:"bindings"`
 }
 
 type Entry struct {
 	Type     TermType `json:"type"` // "uri", "literal", "typed-literal" or "bnode"
-	XMLLang  string   `json:"xml:lang"`
+	XMLLang  string   `json:"xml:lang,omitempty"`
 	Value    string   `json:"value"`
-	DataType string   `json:"datatype"`
+	DataType string   `json:"datatype,omitempty"`
+}
+
+// newEntry returns the Entry for the RDF term.
+func newEntry(term rdf.Term) *Entry {
+	switch t := term.(type) {
+	case rdf.IRI:
+		return &Entry{Type: TypeURI, Value: t.RawValue()}
+	case rdf.BlankNode:
+		return &Entry{Type: TypeBnode, Value: t.RawValue()}
+	case rdf.Literal:
+		e := &Entry{Type: TypeLiteral, Value: t.RawValue()}
+
+		switch {
+		case t.Lang() != "":
+			e.XMLLang = strings.TrimPrefix(t.Lang(), "@")
+		case !t.DataType.Equal(rdf.IRI{}) && !t.HasImpliedDataType():
+			e.DataType = t.DataType.RawValue()
+		}
+
+		return e
+	}
+
+	return &Entry{Type: TypeLiteral, Value: term.RawValue()}
 }
 
 func (e *Entry) asSubject() (rdf.Subject, error) {
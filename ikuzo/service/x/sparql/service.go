@@ -18,9 +18,11 @@ var _ domain.Service = (*Service)(nil)
 type Service struct {
 	bank sparql.Bank
 	// store      TripleStore
-	orgs       domain.OrgConfigRetriever
-	log        zerolog.Logger
-	repos      map[domain.OrganizationID]*Repo
+	orgs  domain.OrgConfigRetriever
+	log   zerolog.Logger
+	repos map[domain.OrganizationID]*Repo
+	// local repos are queried in-process instead of the external SPARQL endpoint
+	local      map[domain.OrganizationID]*LocalRepo
 	retry      int
 	timeout    int
 	queryLimit int
@@ -31,6 +33,8 @@ func NewService(options ...Option) (*Service, error) {
 		retry:      1,
 		timeout:    5,
 		queryLimit: 50,
+		repos:      map[domain.OrganizationID]*Repo{},
+		local:      map[domain.OrganizationID]*LocalRepo{},
 	}
 
 	f := bytes.NewBufferString(queries)
@@ -74,15 +78,28 @@ func (s *Service) SetServiceBuilder(b *domain.ServiceBuilder) {
 
 var _ lod.Resolver = (*Service)(nil)
 
+// Resolve returns the graph of the subject from the LocalRepo of the organization,
+// or from its SPARQL endpoint when no LocalRepo is set.
 func (s *Service) Resolve(ctx context.Context, orgID domain.OrganizationID, subj rdf.Subject) (g *rdf.Graph, err error) {
-	cfg, ok := s.orgs.RetrieveConfig(orgID.String())
-	if !ok {
-		return nil, domain.ErrOrgNotFound
+	if local, ok := s.local[orgID]; ok {
+		return local.Resolve(ctx, subj)
+	}
+
+	repo, err := s.GetRepo(orgID)
+	if err != nil {
+		return nil, err
 	}
 
-	_ = cfg.Config
+	g, err = repo.Resolve(ctx, subj)
+	if err != nil {
+		return nil, err
+	}
+
+	if g == nil || g.Len() == 0 {
+		return nil, lod.ErrResourceNotFound
+	}
 
-	return nil, nil
+	return g, nil
 }
 
 func (s *Service) GetRepo(orgID domain.OrganizationID) (*Repo, error) {
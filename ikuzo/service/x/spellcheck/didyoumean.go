@@ -0,0 +1,97 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spellcheck
+
+import (
+	"strings"
+	"unicode"
+)
+
+// DidYouMean is the corrected query for a search query with zero or few hits.
+type DidYouMean struct {
+	Query       string        `json:"query"`
+	Corrections []*Correction `json:"corrections"`
+}
+
+// Correction contains the suggestions for a misspelled term of the query,
+// ordered from best to worst.
+type Correction struct {
+	Term        string   `json:"term"`
+	Suggestions []string `json:"suggestions"`
+}
+
+// DidYouMean returns the corrected query when the number of hits of the query
+// does not exceed the maximum number of hits of the Service. Only plain words
+// are corrected; operators, fields, phrases, wildcards and fuzzy terms are left
+// unchanged. nil is returned when there is nothing to correct.
+func (s *Service) DidYouMean(orgID, query string, hits int) *DidYouMean {
+	if hits > s.maxHits {
+		return nil
+	}
+
+	s.rw.RLock()
+	defer s.rw.RUnlock()
+
+	checker, ok := s.checkers[orgID]
+	if !ok {
+		return nil
+	}
+
+	dym := &DidYouMean{}
+	words := strings.Fields(query)
+
+	for i, word := range words {
+		if !isCorrectable(word) {
+			continue
+		}
+
+		suggestion := checker.SpellCheck(word)
+		if suggestion == "" || suggestion == s.a.Transform(word) {
+			continue
+		}
+
+		words[i] = suggestion
+
+		dym.Corrections = append(dym.Corrections, &Correction{
+			Term:        word,
+			Suggestions: checker.SpellCheckSuggestions(word, maxSuggestions),
+		})
+	}
+
+	if len(dym.Corrections) == 0 {
+		return nil
+	}
+
+	dym.Query = strings.Join(words, " ")
+
+	return dym
+}
+
+// isCorrectable returns true when the word only contains letters and is not a
+// query operator.
+func isCorrectable(word string) bool {
+	switch word {
+	case "AND", "OR", "NOT":
+		return false
+	}
+
+	for _, r := range word {
+		if !unicode.IsLetter(r) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,26 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package spellcheck keeps a spelling model per organization that is trained
+// from the text of indexed records.
+//
+// The models are used to return a 'did you mean' suggestion when a search
+// query yields zero or few hits. When a data directory is set, the models are
+// saved periodically and on Shutdown, and loaded again when the Service is
+// created.
+//
+// The words of the records of a dataset are counted per revision of the
+// dataset. When a dataset is indexed again with a new revision, the counts of
+// the previous revision are removed from the model.
+package spellcheck
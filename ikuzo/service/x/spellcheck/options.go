@@ -0,0 +1,71 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spellcheck
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/delving/hub3/ikuzo/service/x/search"
+)
+
+type Option func(*Service) error
+
+// SetDataDir sets the directory where the spelling models are persisted.
+// Without a data directory the models are kept in memory only.
+func SetDataDir(dir string) Option {
+	return func(s *Service) error {
+		s.dataDir = dir
+		return nil
+	}
+}
+
+// SetMaxHits sets the maximum number of hits of a query for which a
+// DidYouMean suggestion is returned. The default is 0, so only queries without
+// hits get a suggestion.
+func SetMaxHits(hits int) Option {
+	return func(s *Service) error {
+		if hits < 0 {
+			return fmt.Errorf("max hits cannot be negative: %d", hits)
+		}
+
+		s.maxHits = hits
+
+		return nil
+	}
+}
+
+// SetSpellCheckOptions sets the options for each new search.SpellChecker.
+func SetSpellCheckOptions(options ...search.SpellCheckOption) Option {
+	return func(s *Service) error {
+		s.options = append(s.options, options...)
+		return nil
+	}
+}
+
+// SetSaveInterval sets the interval at which the changed spelling models are
+// saved to the data directory. The default is 5 minutes. Zero disables the
+// periodic saves, so the models are only saved on Shutdown.
+func SetSaveInterval(interval time.Duration) Option {
+	return func(s *Service) error {
+		if interval < 0 {
+			return fmt.Errorf("save interval cannot be negative: %s", interval)
+		}
+
+		s.saveInterval = interval
+
+		return nil
+	}
+}
@@ -0,0 +1,373 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spellcheck
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/delving/hub3/ikuzo/service/x/search"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	modelExt = ".json"
+	termsExt = ".terms"
+
+	// maxSuggestions is the maximum number of suggestions per Correction
+	maxSuggestions = 5
+
+	defaultSaveInterval = 5 * time.Minute
+)
+
+// Service keeps a search.SpellChecker per organization.
+type Service struct {
+	rw       sync.RWMutex
+	checkers map[string]*search.SpellChecker
+	// datasets contains the trained terms per organization and dataset
+	datasets map[string]map[string]*datasetTerms
+	// dirty contains the organizations with changes that are not saved
+	dirty        map[string]bool
+	dataDir      string
+	saveInterval time.Duration
+	maxHits      int
+	options      []search.SpellCheckOption
+	a            search.Analyzer
+	done         chan struct{}
+	stop         sync.Once
+	wg           sync.WaitGroup
+}
+
+// datasetTerms are the trained terms of a revision of a dataset. They are
+// forgotten when the records of a newer revision are trained, so indexing a
+// dataset again does not inflate the counts.
+type datasetTerms struct {
+	Revision int32          `json:"revision"`
+	Terms    map[string]int `json:"terms"`
+}
+
+func NewService(options ...Option) (*Service, error) {
+	s := &Service{
+		checkers:     map[string]*search.SpellChecker{},
+		datasets:     map[string]map[string]*datasetTerms{},
+		dirty:        map[string]bool{},
+		saveInterval: defaultSaveInterval,
+		done:         make(chan struct{}),
+	}
+
+	// apply options
+	for _, option := range options {
+		if err := option(s); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.dataDir != "" {
+		if err := s.load(); err != nil {
+			return nil, err
+		}
+
+		if s.saveInterval > 0 {
+			s.wg.Add(1)
+
+			go s.saveEvery(s.saveInterval)
+		}
+	}
+
+	return s, nil
+}
+
+// saveEvery saves the changed spelling models until the Service is shut down,
+// so a crash only loses the training of the last interval.
+func (s *Service) saveEvery(interval time.Duration) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			if err := s.Save(); err != nil {
+				log.Error().Err(err).Msg("unable to save spellcheck models")
+			}
+		}
+	}
+}
+
+// load reads the persisted spelling models from the data directory.
+func (s *Service) load() error {
+	if err := os.MkdirAll(s.dataDir, os.ModePerm); err != nil {
+		return fmt.Errorf("unable to create spellcheck data directory; %w", err)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(s.dataDir, "*"+modelExt))
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("unable to open spellcheck model; %w", err)
+		}
+
+		checker := search.NewSpellCheck(s.options...)
+		err = checker.Load(f)
+
+		f.Close()
+
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		orgID := strings.TrimSuffix(filepath.Base(path), modelExt)
+		s.checkers[orgID] = checker
+
+		if err := s.loadTerms(orgID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadTerms reads the trained terms per dataset of the organization.
+func (s *Service) loadTerms(orgID string) error {
+	path := filepath.Join(s.dataDir, orgID+termsExt)
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return fmt.Errorf("unable to read spellcheck terms; %w", err)
+	}
+
+	datasets := map[string]*datasetTerms{}
+	if err := json.Unmarshal(b, &datasets); err != nil {
+		return fmt.Errorf("%s: unable to decode spellcheck terms; %w", path, err)
+	}
+
+	s.datasets[orgID] = datasets
+
+	return nil
+}
+
+// Save writes the spelling models that have changed since the last Save to the
+// data directory. It is a no-op when no data directory is set.
+func (s *Service) Save() error {
+	if s.dataDir == "" {
+		return nil
+	}
+
+	s.rw.Lock()
+	defer s.rw.Unlock()
+
+	for orgID := range s.dirty {
+		if err := s.save(orgID); err != nil {
+			return err
+		}
+
+		delete(s.dirty, orgID)
+	}
+
+	return nil
+}
+
+func (s *Service) save(orgID string) error {
+	path, err := s.modelPath(orgID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.writeFile(orgID, path, s.checkers[orgID].Save); err != nil {
+		return err
+	}
+
+	datasets, ok := s.datasets[orgID]
+	if !ok {
+		return nil
+	}
+
+	return s.writeFile(orgID, strings.TrimSuffix(path, modelExt)+termsExt, func(w io.Writer) error {
+		return json.NewEncoder(w).Encode(datasets)
+	})
+}
+
+// writeFile writes to a temporary file first so a failed save does not corrupt
+// the file at path.
+func (s *Service) writeFile(orgID, path string, write func(w io.Writer) error) error {
+	f, err := os.CreateTemp(s.dataDir, orgID+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("unable to create spellcheck model file; %w", err)
+	}
+
+	if err := write(f); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
+
+func (s *Service) modelPath(orgID string) (string, error) {
+	if orgID == "" || orgID != filepath.Base(orgID) || strings.HasPrefix(orgID, ".") {
+		return "", fmt.Errorf("invalid organization ID for spellcheck model: %q", orgID)
+	}
+
+	return filepath.Join(s.dataDir, orgID+modelExt), nil
+}
+
+// Shutdown stops the periodic saves and saves the spelling models.
+func (s *Service) Shutdown(ctx context.Context) error {
+	s.stop.Do(func() {
+		close(s.done)
+	})
+
+	s.wg.Wait()
+
+	return s.Save()
+}
+
+// Train adds the words in the text to the spelling model of the organization.
+func (s *Service) Train(orgID, text string) {
+	s.train(orgID, "", 0, text)
+}
+
+// train adds the words in the text to the spelling model. When the dataset is
+// set, the words are counted per revision of the dataset, see datasetTerms.
+func (s *Service) train(orgID, datasetID string, revision int32, text string) {
+	tok := search.NewTokenizer()
+	stream := tok.ParseString(text, 0)
+
+	s.rw.Lock()
+	defer s.rw.Unlock()
+
+	checker, ok := s.checkers[orgID]
+	if !ok {
+		checker = search.NewSpellCheck(s.options...)
+		s.checkers[orgID] = checker
+	}
+
+	if datasetID != "" {
+		terms, ok := s.revisionTerms(checker, orgID, datasetID, revision)
+		if !ok {
+			return
+		}
+
+		for _, token := range stream.Tokens() {
+			if !token.Ignored && token.Normal != "" {
+				terms.Terms[token.Normal]++
+			}
+		}
+	}
+
+	checker.Train(stream)
+
+	s.dirty[orgID] = true
+}
+
+// revisionTerms returns the trained terms of the revision of the dataset. The
+// terms of an older revision are forgotten by the checker. false is returned
+// for a revision that is older than the trained revision.
+func (s *Service) revisionTerms(
+	checker *search.SpellChecker, orgID, datasetID string, revision int32,
+) (*datasetTerms, bool) {
+	datasets, ok := s.datasets[orgID]
+	if !ok {
+		datasets = map[string]*datasetTerms{}
+		s.datasets[orgID] = datasets
+	}
+
+	terms, ok := datasets[datasetID]
+
+	switch {
+	case ok && revision < terms.Revision:
+		return nil, false
+	case ok && revision == terms.Revision:
+		return terms, true
+	case ok:
+		checker.Forget(terms.Terms)
+	}
+
+	terms = &datasetTerms{Revision: revision, Terms: map[string]int{}}
+	datasets[datasetID] = terms
+
+	return terms, true
+}
+
+// record is the subset of the indexed v2 record that contains its text.
+type record struct {
+	Meta struct {
+		Spec     string `json:"spec"`
+		Revision int32  `json:"revision"`
+	} `json:"meta"`
+	Resources []struct {
+		Entries []struct {
+			ID    string `json:"@id"`
+			Value string `json:"@value"`
+		} `json:"entries"`
+	} `json:"resources"`
+}
+
+// TrainRecord adds the literal values of an indexed v2 record to the spelling
+// model of the organization. The words of the records of a dataset are counted
+// once per revision, so indexing the dataset again replaces the counts of the
+// previous revision.
+func (s *Service) TrainRecord(orgID string, source []byte) error {
+	var rec record
+	if err := json.Unmarshal(source, &rec); err != nil {
+		return fmt.Errorf("unable to decode record for spellcheck; %w", err)
+	}
+
+	var sb strings.Builder
+
+	for _, rsc := range rec.Resources {
+		for _, entry := range rsc.Entries {
+			// resource references contain no text
+			if entry.ID != "" || entry.Value == "" {
+				continue
+			}
+
+			sb.WriteString(entry.Value)
+			sb.WriteString("\n")
+		}
+	}
+
+	if sb.Len() != 0 {
+		s.train(orgID, rec.Meta.Spec, rec.Meta.Revision, sb.String())
+	}
+
+	return nil
+}
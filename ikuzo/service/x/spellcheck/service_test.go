@@ -0,0 +1,233 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spellcheck
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/delving/hub3/ikuzo/service/x/search"
+	"github.com/google/go-cmp/cmp"
+	"github.com/matryer/is"
+)
+
+const (
+	orgID  = "hub3"
+	corpus = "Spieghel der Zeevaerdt met zeekaarten van Waghenaer uit Enchuijsen. "
+)
+
+func newTestService(t *testing.T, options ...Option) *Service {
+	t.Helper()
+
+	options = append(options, SetSpellCheckOptions(search.SetThreshold(1)))
+
+	s, err := NewService(options...)
+	if err != nil {
+		t.Fatalf("unable to create spellcheck service: %s", err)
+	}
+
+	s.Train(orgID, strings.Repeat(corpus, 3))
+
+	return s
+}
+
+func TestService_DidYouMean(t *testing.T) {
+	tests := []struct {
+		name    string
+		orgID   string
+		query   string
+		hits    int
+		maxHits int
+		want    *DidYouMean
+	}{
+		{
+			"misspelled word",
+			orgID,
+			"zeekarten",
+			0,
+			0,
+			&DidYouMean{
+				Query:       "zeekaarten",
+				Corrections: []*Correction{{Term: "zeekarten", Suggestions: []string{"zeekaarten"}}},
+			},
+		},
+		{
+			"only misspelled words are corrected",
+			orgID,
+			"Waghenaer AND enchuysen",
+			2,
+			5,
+			&DidYouMean{
+				Query:       "Waghenaer AND enchuijsen",
+				Corrections: []*Correction{{Term: "enchuysen", Suggestions: []string{"enchuijsen"}}},
+			},
+		},
+		{
+			"fields and phrases are not corrected",
+			orgID,
+			`title:zeekarten "enchuysen"`,
+			0,
+			0,
+			nil,
+		},
+		{
+			"correct query",
+			orgID,
+			"zeevaerdt",
+			0,
+			0,
+			nil,
+		},
+		{
+			"too many hits",
+			orgID,
+			"zeekarten",
+			1,
+			0,
+			nil,
+		},
+		{
+			"unknown organization",
+			"unknown",
+			"zeekarten",
+			0,
+			0,
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t, SetMaxHits(tt.maxHits))
+
+			got := s.DidYouMean(tt.orgID, tt.query, tt.hits)
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Service.DidYouMean() %s = mismatch (-want +got):\n%s", tt.name, diff)
+			}
+		})
+	}
+}
+
+func TestService_TrainRecord(t *testing.T) {
+	is := is.New(t)
+
+	s, err := NewService(SetSpellCheckOptions(search.SetThreshold(1)))
+	is.NoErr(err)
+
+	source := `{"resources": [{"entries": [
+		{"@value": "Plantijn drukt in Leyden"},
+		{"@id": "http://example.org/plantijn", "@value": "plantyn"}
+	]}]}`
+
+	for i := 0; i < 3; i++ {
+		is.NoErr(s.TrainRecord(orgID, []byte(source)))
+	}
+
+	is.Equal(s.DidYouMean(orgID, "plantein", 0).Query, "plantijn")
+	is.Equal(s.DidYouMean(orgID, "plantyn", 0).Query, "plantijn") // resource references are not trained
+
+	is.True(s.TrainRecord(orgID, []byte("not a record")) != nil)
+}
+
+func TestService_TrainRecord_revisions(t *testing.T) {
+	is := is.New(t)
+
+	s, err := NewService(SetSpellCheckOptions(search.SetThreshold(1)))
+	is.NoErr(err)
+
+	source := func(revision int, text string) []byte {
+		return []byte(fmt.Sprintf(
+			`{"meta": {"spec": "prints", "revision": %d}, "resources": [{"entries": [{"@value": %q}]}]}`,
+			revision, text,
+		))
+	}
+
+	is.NoErr(s.TrainRecord(orgID, source(1, "Plantijn drukt")))
+	is.Equal(s.DidYouMean(orgID, "plantein", 0).Query, "plantijn")
+
+	// indexing the dataset again does not inflate the counts
+	is.NoErr(s.TrainRecord(orgID, source(2, "Plantijn drukt")))
+	is.Equal(s.datasets[orgID]["prints"].Terms, map[string]int{"plantijn": 1, "drukt": 1})
+
+	// the words of the previous revision are forgotten
+	is.NoErr(s.TrainRecord(orgID, source(3, "Moretus drukt")))
+	is.Equal(s.DidYouMean(orgID, "plantein", 0), nil)
+	is.Equal(s.DidYouMean(orgID, "moretsu", 0).Query, "moretus")
+
+	// records of an older revision are ignored
+	is.NoErr(s.TrainRecord(orgID, source(2, "Plantijn drukt")))
+	is.Equal(s.DidYouMean(orgID, "plantein", 0), nil)
+}
+
+func TestService_saveEvery(t *testing.T) {
+	is := is.New(t)
+
+	dir := t.TempDir()
+
+	s := newTestService(t, SetDataDir(dir), SetSaveInterval(10*time.Millisecond))
+
+	path := filepath.Join(dir, orgID+modelExt)
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	_, err := os.Stat(path)
+	is.NoErr(err) // saved before Shutdown
+	is.NoErr(s.Shutdown(context.Background()))
+	is.NoErr(s.Shutdown(context.Background())) // a second Shutdown is a no-op
+
+	_, err = NewService(SetSaveInterval(-time.Second))
+	is.True(err != nil)
+}
+
+func TestService_persistence(t *testing.T) {
+	is := is.New(t)
+
+	dir := t.TempDir()
+
+	s := newTestService(t, SetDataDir(dir))
+	is.NoErr(s.Shutdown(context.Background()))
+
+	loaded, err := NewService(SetDataDir(dir))
+	is.NoErr(err)
+	is.Equal(loaded.DidYouMean(orgID, "zeekarten", 0).Query, "zeekaarten")
+	is.NoErr(loaded.Shutdown(context.Background()))
+
+	// the trained terms per dataset are persisted with the model
+	is.NoErr(s.TrainRecord(orgID, []byte(`{"meta": {"spec": "maps", "revision": 1}, "resources": [{"entries": [{"@value": "Zeekaarten"}]}]}`)))
+	is.NoErr(s.Save())
+
+	loaded, err = NewService(SetDataDir(dir))
+	is.NoErr(err)
+	is.Equal(loaded.datasets[orgID]["maps"].Terms, map[string]int{"zeekaarten": 1})
+	is.NoErr(loaded.Shutdown(context.Background()))
+
+	s.Train("../outside", corpus)
+	is.True(s.Save() != nil) // organization IDs cannot escape the data directory
+}
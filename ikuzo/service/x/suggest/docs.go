@@ -0,0 +1,21 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package suggest provides the autocomplete endpoint /api/suggest.
+//
+// Suggestions are retrieved from the Store, which is backed by the
+// elasticsearch suggest index, for organizations that have the 'suggest' index
+// type enabled. Otherwise the MemoryStore is used as a fallback. It completes
+// the words of the indexed records with a search.AutoComplete.
+package suggest
@@ -0,0 +1,110 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package suggest provides the autocomplete endpoint /api/suggest.
+package suggest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/delving/hub3/ikuzo/domain"
+	"github.com/delving/hub3/ikuzo/render"
+)
+
+const (
+	defaultSize = 10
+	maxSize     = 100
+)
+
+// Response is the response of the /api/suggest endpoint.
+type Response struct {
+	Input       string       `json:"input"`
+	Mode        Mode         `json:"mode"`
+	Suggestions []Suggestion `json:"suggestions"`
+}
+
+func (s *Service) handleSuggest(w http.ResponseWriter, r *http.Request) {
+	org, ok := domain.GetOrganization(r)
+	if !ok {
+		http.Error(w, domain.ErrOrgNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	q, err := newQuery(org.ID.String(), r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	suggestions, err := s.Suggest(r.Context(), &org.Config, q)
+	if err != nil {
+		if errors.Is(err, ErrUnknownField) || errors.Is(err, ErrEmptyInput) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		render.Error(w, r, err, &render.ErrorConfig{
+			StatusCode: http.StatusInternalServerError,
+			Message:    "unable to get suggestions",
+			Log:        &s.log,
+		})
+
+		return
+	}
+
+	render.JSON(w, r, &Response{
+		Input:       q.Input,
+		Mode:        q.Mode,
+		Suggestions: suggestions,
+	})
+}
+
+// newQuery creates a Query from the sanitized URL parameters.
+func newQuery(orgID string, r *http.Request) (*Query, error) {
+	params := r.URL.Query()
+
+	mode, err := ParseMode(domain.SanitizeParam(params.Get("mode")))
+	if err != nil {
+		return nil, err
+	}
+
+	q := &Query{
+		OrgID:     orgID,
+		DatasetID: domain.SanitizeParam(params.Get("dataset")),
+		Field:     domain.SanitizeParam(params.Get("field")),
+		Input:     domain.SanitizeParam(params.Get("q")),
+		Mode:      mode,
+		Size:      defaultSize,
+	}
+
+	if q.Input == "" {
+		return nil, ErrEmptyInput
+	}
+
+	if size := params.Get("size"); size != "" {
+		q.Size, err = strconv.Atoi(size)
+		if err != nil || q.Size < 1 {
+			return nil, fmt.Errorf("size must be a positive number: %q", domain.LogUserInput(size))
+		}
+
+		if q.Size > maxSize {
+			q.Size = maxSize
+		}
+	}
+
+	return q, nil
+}
@@ -0,0 +1,207 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package suggest provides the autocomplete endpoint /api/suggest.
+package suggest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/delving/hub3/ikuzo/service/x/search"
+)
+
+var _ Store = (*MemoryStore)(nil)
+
+// key identifies the words of a field in a dataset of an organization.
+type key struct {
+	orgID     string
+	datasetID string
+	field     string
+}
+
+// MemoryStore completes the words of the indexed records. Each field of a
+// dataset has its own search.AutoComplete with the distinct words, that is
+// rebuilt when a Suggest needs it after words were added.
+type MemoryStore struct {
+	rw sync.Mutex
+	// words contains the word frequencies per key
+	words map[key]map[string]int
+	ac    map[key]*search.AutoComplete
+	a     search.Analyzer
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		words: map[key]map[string]int{},
+		ac:    map[key]*search.AutoComplete{},
+	}
+}
+
+// Add adds the words of the text to the field of the dataset.
+func (m *MemoryStore) Add(orgID, datasetID, field, text string) {
+	tok := search.NewTokenizer()
+
+	words := []string{}
+
+	for _, token := range tok.ParseString(text, 0).Tokens() {
+		if !token.Ignored && token.Normal != "" {
+			words = append(words, token.Normal)
+		}
+	}
+
+	if len(words) == 0 {
+		return
+	}
+
+	k := key{orgID: orgID, datasetID: datasetID, field: field}
+
+	m.rw.Lock()
+	defer m.rw.Unlock()
+
+	freq, ok := m.words[k]
+	if !ok {
+		freq = map[string]int{}
+		m.words[k] = freq
+	}
+
+	for _, word := range words {
+		if freq[word] == 0 {
+			// a new word requires a new suffix array
+			delete(m.ac, k)
+		}
+
+		freq[word]++
+	}
+}
+
+// record is the subset of the indexed v2 record that contains its text.
+type record struct {
+	Meta struct {
+		Spec string `json:"spec"`
+	} `json:"meta"`
+	Resources []struct {
+		Entries []struct {
+			ID          string `json:"@id"`
+			Value       string `json:"@value"`
+			SearchLabel string `json:"searchLabel"`
+		} `json:"entries"`
+	} `json:"resources"`
+}
+
+// TrainRecord adds the literal values of an indexed v2 record. The searchLabel
+// of each entry is used as the field.
+func (m *MemoryStore) TrainRecord(orgID string, source []byte) error {
+	var rec record
+	if err := json.Unmarshal(source, &rec); err != nil {
+		return fmt.Errorf("unable to decode record for suggest; %w", err)
+	}
+
+	for _, rsc := range rec.Resources {
+		for _, entry := range rsc.Entries {
+			// resource references contain no text
+			if entry.ID != "" || entry.Value == "" {
+				continue
+			}
+
+			m.Add(orgID, rec.Meta.Spec, entry.SearchLabel, entry.Value)
+		}
+	}
+
+	return nil
+}
+
+// Suggest completes the last word of the input. The other words of the input
+// are prepended to the suggested value.
+func (m *MemoryStore) Suggest(ctx context.Context, q *Query) ([]Suggestion, error) {
+	words := strings.Fields(m.a.Normalize(q.Input))
+	if len(words) == 0 {
+		return nil, ErrEmptyInput
+	}
+
+	input := words[len(words)-1]
+	leading := strings.Join(words[:len(words)-1], " ")
+
+	m.rw.Lock()
+	defer m.rw.Unlock()
+
+	counts := map[string]int{}
+
+	for k, freq := range m.words {
+		if k.orgID != q.OrgID ||
+			(q.DatasetID != "" && k.datasetID != q.DatasetID) ||
+			(q.Field != "" && k.field != q.Field) {
+			continue
+		}
+
+		autos, err := m.autoComplete(k).Suggest(input, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, auto := range autos {
+			if q.Mode == Prefix && !strings.HasPrefix(auto.Term, input) {
+				continue
+			}
+
+			counts[auto.Term] += freq[auto.Term]
+		}
+	}
+
+	suggestions := make([]Suggestion, 0, len(counts))
+
+	for term, count := range counts {
+		if leading != "" {
+			term = leading + " " + term
+		}
+
+		suggestions = append(suggestions, Suggestion{Value: term, Count: count})
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Count != suggestions[j].Count {
+			return suggestions[i].Count > suggestions[j].Count
+		}
+
+		return suggestions[i].Value < suggestions[j].Value
+	})
+
+	if q.Size > 0 && q.Size < len(suggestions) {
+		suggestions = suggestions[:q.Size]
+	}
+
+	return suggestions, nil
+}
+
+// autoComplete returns the search.AutoComplete for the key. It is built when
+// it does not exist.
+func (m *MemoryStore) autoComplete(k key) *search.AutoComplete {
+	ac, ok := m.ac[k]
+	if !ok {
+		words := make([]string, 0, len(m.words[k]))
+		for word := range m.words[k] {
+			words = append(words, word)
+		}
+
+		ac = search.NewAutoComplete()
+		ac.FromStrings(words)
+		m.ac[k] = ac
+	}
+
+	return ac
+}
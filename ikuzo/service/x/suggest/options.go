@@ -0,0 +1,34 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package suggest
+
+type Option func(*Service) error
+
+// SetStore sets the Store that is used for organizations with a suggest index.
+func SetStore(store Store) Option {
+	return func(s *Service) error {
+		s.store = store
+		return nil
+	}
+}
+
+// SetMemoryStore sets the fallback MemoryStore. By default an empty MemoryStore
+// is used.
+func SetMemoryStore(store *MemoryStore) Option {
+	return func(s *Service) error {
+		s.memory = store
+		return nil
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package suggest provides the autocomplete endpoint /api/suggest.
+package suggest
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/delving/hub3/ikuzo/domain"
+	"github.com/go-chi/chi"
+	"github.com/rs/zerolog"
+)
+
+var _ domain.Service = (*Service)(nil)
+
+// suggestIndexType is the elasticsearch index type of the suggest index.
+const suggestIndexType = "suggest"
+
+type Service struct {
+	store  Store
+	memory *MemoryStore
+	log    zerolog.Logger
+}
+
+func NewService(options ...Option) (*Service, error) {
+	s := &Service{}
+
+	// apply options
+	for _, option := range options {
+		if err := option(s); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.memory == nil {
+		s.memory = NewMemoryStore()
+	}
+
+	return s, nil
+}
+
+// Suggest returns the suggestions from the suggest index of the organization.
+// The MemoryStore is used when the organization has no suggest index or no
+// Store is set.
+func (s *Service) Suggest(ctx context.Context, cfg *domain.OrganizationConfig, q *Query) ([]Suggestion, error) {
+	if q.Input == "" {
+		return nil, ErrEmptyInput
+	}
+
+	if s.store != nil && hasSuggestIndex(cfg) {
+		q.IndexName = cfg.GetSuggestIndexName()
+		return s.store.Suggest(ctx, q)
+	}
+
+	return s.memory.Suggest(ctx, q)
+}
+
+func hasSuggestIndex(cfg *domain.OrganizationConfig) bool {
+	for _, indexType := range cfg.ElasticSearch.IndexTypes {
+		if indexType == suggestIndexType {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Service) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	router := chi.NewRouter()
+	s.Routes("", router)
+	router.ServeHTTP(w, r)
+}
+
+func (s *Service) Routes(pattern string, router chi.Router) {
+	router.Get("/api/suggest", s.handleSuggest)
+}
+
+func (s *Service) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+func (s *Service) SetServiceBuilder(b *domain.ServiceBuilder) {
+	s.log = b.Logger.With().Str("svc", "suggest").Logger()
+}
@@ -0,0 +1,239 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package suggest provides the autocomplete endpoint /api/suggest.
+package suggest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/delving/hub3/ikuzo/domain"
+	"github.com/google/go-cmp/cmp"
+	"github.com/matryer/is"
+)
+
+const orgID = "hub3"
+
+const records = `[
+	{"meta": {"spec": "gemeenten"}, "resources": [{"entries": [
+		{"@value": "Amsterdam", "searchLabel": "dc_title"},
+		{"@value": "Gemeente Amsterdam en Amstelveen", "searchLabel": "dc_description"},
+		{"@id": "http://example.org/amsterdammer", "@value": "amsterdammer", "searchLabel": "dc_subject"}
+	]}]},
+	{"meta": {"spec": "havens"}, "resources": [{"entries": [
+		{"@value": "Haven van Rotterdam", "searchLabel": "dc_title"},
+		{"@value": "Amsterdam", "searchLabel": "dc_title"}
+	]}]}
+]`
+
+func newTestMemoryStore(t *testing.T) *MemoryStore {
+	t.Helper()
+
+	var sources []json.RawMessage
+	if err := json.Unmarshal([]byte(records), &sources); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewMemoryStore()
+
+	for _, source := range sources {
+		if err := m.TrainRecord(orgID, source); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return m
+}
+
+func TestMemoryStore_Suggest(t *testing.T) {
+	m := newTestMemoryStore(t)
+
+	tests := []struct {
+		name    string
+		q       Query
+		want    []Suggestion
+		wantErr bool
+	}{
+		{
+			"prefix",
+			Query{OrgID: orgID, Input: "Amst", Mode: Prefix},
+			[]Suggestion{{Value: "amsterdam", Count: 3}, {Value: "amstelveen", Count: 1}},
+			false,
+		},
+		{
+			"infix",
+			Query{OrgID: orgID, Input: "dam", Mode: Infix},
+			[]Suggestion{{Value: "amsterdam", Count: 3}, {Value: "rotterdam", Count: 1}},
+			false,
+		},
+		{
+			"by dataset and field",
+			Query{OrgID: orgID, DatasetID: "gemeenten", Field: "dc_title", Input: "am", Mode: Prefix},
+			[]Suggestion{{Value: "amsterdam", Count: 1}},
+			false,
+		},
+		{
+			"leading words",
+			Query{OrgID: orgID, Input: "haven van rot", Mode: Prefix, Size: 1},
+			[]Suggestion{{Value: "haven van rotterdam", Count: 1}},
+			false,
+		},
+		{
+			"size",
+			Query{OrgID: orgID, Input: "dam", Mode: Infix, Size: 1},
+			[]Suggestion{{Value: "amsterdam", Count: 3}},
+			false,
+		},
+		{
+			"unknown organization",
+			Query{OrgID: "unknown", Input: "dam", Mode: Infix},
+			[]Suggestion{},
+			false,
+		},
+		{
+			"empty input",
+			Query{OrgID: orgID, Input: " ", Mode: Prefix},
+			nil,
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.Suggest(context.Background(), &tt.q)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("MemoryStore.Suggest() %s; error = %v, wantErr %v", tt.name, err, tt.wantErr)
+				return
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("MemoryStore.Suggest() %s = mismatch (-want +got):\n%s", tt.name, diff)
+			}
+		})
+	}
+}
+
+type mockStore struct {
+	q *Query
+}
+
+func (m *mockStore) Suggest(ctx context.Context, q *Query) ([]Suggestion, error) {
+	m.q = q
+	return []Suggestion{{Value: "Amsterdam"}}, nil
+}
+
+func TestService_handleSuggest(t *testing.T) {
+	store := &mockStore{}
+
+	svc, err := NewService(SetStore(store), SetMemoryStore(newTestMemoryStore(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	suggestOrg := domain.Organization{ID: orgID}
+	suggestOrg.Config.ElasticSearch.IndexName = orgID
+	suggestOrg.Config.ElasticSearch.IndexTypes = []string{"v2", "suggest"}
+
+	tests := []struct {
+		name   string
+		org    domain.Organization
+		query  string
+		status int
+		want   *Response
+		// wantQuery is the query that is passed to the elasticsearch store
+		wantQuery *Query
+	}{
+		{
+			"memory fallback",
+			domain.Organization{ID: orgID},
+			"q=rot&mode=infix",
+			http.StatusOK,
+			&Response{Input: "rot", Mode: Infix, Suggestions: []Suggestion{{Value: "rotterdam", Count: 1}}},
+			nil,
+		},
+		{
+			"suggest index",
+			suggestOrg,
+			"q=amst&dataset=../gemeenten&field=name&size=5",
+			http.StatusOK,
+			&Response{Input: "amst", Mode: Prefix, Suggestions: []Suggestion{{Value: "Amsterdam"}}},
+			&Query{
+				OrgID:     orgID,
+				IndexName: "hub3v2_suggest",
+				DatasetID: "gemeenten",
+				Field:     "name",
+				Input:     "amst",
+				Mode:      Prefix,
+				Size:      5,
+			},
+		},
+		{
+			"missing input",
+			domain.Organization{ID: orgID},
+			"mode=infix",
+			http.StatusBadRequest,
+			nil,
+			nil,
+		},
+		{
+			"unknown mode",
+			domain.Organization{ID: orgID},
+			"q=amst&mode=fuzzy",
+			http.StatusBadRequest,
+			nil,
+			nil,
+		},
+		{
+			"invalid size",
+			domain.Organization{ID: orgID},
+			"q=amst&size=-1",
+			http.StatusBadRequest,
+			nil,
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+
+			store.q = nil
+
+			r := httptest.NewRequest(http.MethodGet, "/api/suggest?"+tt.query, nil)
+			r = domain.SetOrganization(r, &tt.org)
+
+			w := httptest.NewRecorder()
+			svc.ServeHTTP(w, r)
+
+			is.Equal(w.Code, tt.status)
+			is.Equal(store.q, tt.wantQuery)
+
+			if tt.want == nil {
+				return
+			}
+
+			var got Response
+			is.NoErr(json.NewDecoder(w.Body).Decode(&got))
+			is.Equal(&got, tt.want)
+		})
+	}
+}
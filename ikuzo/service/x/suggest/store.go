@@ -0,0 +1,76 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package suggest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrUnknownMode  = errors.New("unknown suggest mode")
+	ErrUnknownField = errors.New("unknown suggest field")
+	ErrEmptyInput   = errors.New("suggest input cannot be empty")
+)
+
+// Mode determines where the input must match the suggested value.
+type Mode string
+
+const (
+	// Prefix suggests values that start with the input.
+	Prefix Mode = "prefix"
+	// Infix suggests values that contain the input, not only at the start.
+	Infix Mode = "infix"
+)
+
+// ParseMode returns the Mode for the string. An empty string returns Prefix.
+func ParseMode(mode string) (Mode, error) {
+	switch Mode(strings.ToLower(mode)) {
+	case "", Prefix:
+		return Prefix, nil
+	case Infix:
+		return Infix, nil
+	}
+
+	return "", fmt.Errorf("%w: %q", ErrUnknownMode, mode)
+}
+
+// Query is a request for suggestions.
+type Query struct {
+	OrgID string
+	// IndexName is the name of the elasticsearch suggest index of the organization
+	IndexName string
+	// DatasetID limits the suggestions to a single dataset when non-empty
+	DatasetID string
+	// Field limits the suggestions to a single field when non-empty
+	Field string
+	Input string
+	Mode  Mode
+	Size  int
+}
+
+// Suggestion is a completion of the Query input.
+type Suggestion struct {
+	Value string `json:"value"`
+	// Count is the number of occurrences when the Store provides it
+	Count int `json:"count,omitempty"`
+}
+
+// Store returns the suggestions for a Query.
+type Store interface {
+	Suggest(ctx context.Context, q *Query) ([]Suggestion, error)
+}
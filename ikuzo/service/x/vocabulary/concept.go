@@ -0,0 +1,70 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vocabulary
+
+const skosNS = "http://www.w3.org/2004/02/skos/core#"
+
+// SKOS terms that are used to build the concepts.
+const (
+	skosConcept       = skosNS + "Concept"
+	skosConceptScheme = skosNS + "ConceptScheme"
+	skosPrefLabel     = skosNS + "prefLabel"
+	skosAltLabel      = skosNS + "altLabel"
+	skosBroader       = skosNS + "broader"
+	skosNarrower      = skosNS + "narrower"
+	skosInScheme      = skosNS + "inScheme"
+	rdfType           = "http://www.w3.org/1999/02/22-rdf-syntax-ns#type"
+)
+
+// Label is a SKOS label with its language tag.
+type Label struct {
+	Value string `json:"value"`
+	Lang  string `json:"lang,omitempty"`
+}
+
+// Concept is a SKOS concept.
+type Concept struct {
+	ID        string   `json:"id"`
+	PrefLabel []Label  `json:"prefLabel,omitempty"`
+	AltLabel  []Label  `json:"altLabel,omitempty"`
+	Broader   []string `json:"broader,omitempty"`
+	Narrower  []string `json:"narrower,omitempty"`
+	InScheme  []string `json:"inScheme,omitempty"`
+}
+
+// Labels returns the values of the preferred and alternative labels.
+func (c *Concept) Labels() []string {
+	labels := make([]string, 0, len(c.PrefLabel)+len(c.AltLabel))
+
+	for _, l := range c.PrefLabel {
+		labels = append(labels, l.Value)
+	}
+
+	for _, l := range c.AltLabel {
+		labels = append(labels, l.Value)
+	}
+
+	return labels
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+
+	return append(values, value)
+}
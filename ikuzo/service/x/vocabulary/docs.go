@@ -12,4 +12,9 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
+// Package vocabulary keeps SKOS concept schemes in memory.
+//
+// The Service implements search.Expander, so it can be given to the
+// search.QueryParser with search.SetExpander to expand query terms with the
+// alternative labels and narrower concepts of the matching concepts.
 package vocabulary
@@ -0,0 +1,365 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vocabulary
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/delving/hub3/ikuzo/rdf"
+	"github.com/delving/hub3/ikuzo/rdf/formats/ntriples"
+	"github.com/delving/hub3/ikuzo/rdf/formats/turtle"
+	"github.com/delving/hub3/ikuzo/service/x/search"
+)
+
+var (
+	ErrConceptNotFound = errors.New("concept not found")
+	ErrUnknownFormat   = errors.New("unknown vocabulary format")
+)
+
+// Format is the RDF serialization of a vocabulary file.
+type Format string
+
+const (
+	NTriples Format = "ntriples"
+	Turtle   Format = "turtle"
+)
+
+// FormatFromPath returns the Format based on the extension of the path.
+func FormatFromPath(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".nt":
+		return NTriples, nil
+	case ".ttl":
+		return Turtle, nil
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrUnknownFormat, path)
+}
+
+type Option func(*Service) error
+
+// SetFiles loads the SKOS files when the Service is created.
+func SetFiles(paths ...string) Option {
+	return func(s *Service) error {
+		for _, path := range paths {
+			if err := s.LoadFile(path); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// SetNarrowerDepth sets the number of levels of narrower concepts that are used
+// to expand a query term. The default of 0 includes all levels.
+func SetNarrowerDepth(depth int) Option {
+	return func(s *Service) error {
+		if depth < 0 {
+			return fmt.Errorf("narrower depth cannot be negative: %d", depth)
+		}
+
+		s.depth = depth
+
+		return nil
+	}
+}
+
+// Service keeps SKOS concept schemes in memory. It implements search.Expander,
+// so query terms can be expanded with the labels of the matching concepts.
+type Service struct {
+	rw       sync.RWMutex
+	concepts map[string]*Concept
+	schemes  map[string]bool
+	// labels contains the concept IDs per normalized label
+	labels map[string][]string
+	depth  int
+	a      search.Analyzer
+}
+
+// NewService returns a vocabulary Service. Concept schemes can be loaded
+// with SetFiles or with Load and LoadFile.
+func NewService(options ...Option) (*Service, error) {
+	s := &Service{
+		concepts: map[string]*Concept{},
+		schemes:  map[string]bool{},
+		labels:   map[string][]string{},
+	}
+
+	// apply options
+	for _, option := range options {
+		if err := option(s); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// LoadFile loads the SKOS concepts from a N-Triples (.nt) or Turtle (.ttl) file.
+func (s *Service) LoadFile(path string) error {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("unable to open vocabulary; %w", err)
+	}
+	defer f.Close()
+
+	return s.Load(f, format)
+}
+
+// Load loads the SKOS concepts from r.
+func (s *Service) Load(r io.Reader, format Format) error {
+	var (
+		g   *rdf.Graph
+		err error
+	)
+
+	switch format {
+	case NTriples:
+		g, err = ntriples.Parse(r, nil)
+	case Turtle:
+		g, err = turtle.Parse(r, nil)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+	}
+
+	if err != nil {
+		return fmt.Errorf("unable to parse vocabulary; %w", err)
+	}
+
+	s.AddGraph(g)
+
+	return nil
+}
+
+// AddGraph adds the SKOS concepts and concept schemes in the graph. The
+// skos:broader and skos:narrower relations are added in both directions.
+// The labels of concept schemes are ignored.
+func (s *Service) AddGraph(g *rdf.Graph) {
+	s.rw.Lock()
+	defer s.rw.Unlock()
+
+	triples := g.Triples()
+
+	// first register the types, so scheme labels are not added as concepts
+	for _, t := range triples {
+		if t.Predicate.RawValue() != rdfType {
+			continue
+		}
+
+		switch t.Object.RawValue() {
+		case skosConcept:
+			s.concept(t.Subject.RawValue())
+		case skosConceptScheme:
+			s.schemes[t.Subject.RawValue()] = true
+		}
+	}
+
+	for _, t := range triples {
+		id := t.Subject.RawValue()
+		obj := t.Object.RawValue()
+
+		if s.schemes[id] {
+			continue
+		}
+
+		switch t.Predicate.RawValue() {
+		case skosPrefLabel, skosAltLabel:
+			l, ok := t.Object.(rdf.Literal)
+			if !ok {
+				continue
+			}
+
+			c := s.concept(id)
+			label := Label{Value: l.RawValue(), Lang: l.Lang()}
+
+			if t.Predicate.RawValue() == skosPrefLabel {
+				c.PrefLabel = append(c.PrefLabel, label)
+			} else {
+				c.AltLabel = append(c.AltLabel, label)
+			}
+
+			key := s.a.TransformPhrase(label.Value)
+			s.labels[key] = appendUnique(s.labels[key], id)
+		case skosBroader:
+			c := s.concept(id)
+			c.Broader = appendUnique(c.Broader, obj)
+
+			broader := s.concept(obj)
+			broader.Narrower = appendUnique(broader.Narrower, id)
+		case skosNarrower:
+			c := s.concept(id)
+			c.Narrower = appendUnique(c.Narrower, obj)
+
+			narrower := s.concept(obj)
+			narrower.Broader = appendUnique(narrower.Broader, id)
+		case skosInScheme:
+			c := s.concept(id)
+			c.InScheme = appendUnique(c.InScheme, obj)
+			s.schemes[obj] = true
+		}
+	}
+}
+
+// concept returns the concept with the id. It is created when it does not exist.
+func (s *Service) concept(id string) *Concept {
+	c, ok := s.concepts[id]
+	if !ok {
+		c = &Concept{ID: id}
+		s.concepts[id] = c
+	}
+
+	return c
+}
+
+// Len returns the number of concepts.
+func (s *Service) Len() int {
+	s.rw.RLock()
+	defer s.rw.RUnlock()
+
+	return len(s.concepts)
+}
+
+// Schemes returns the sorted IDs of the concept schemes.
+func (s *Service) Schemes() []string {
+	s.rw.RLock()
+	defer s.rw.RUnlock()
+
+	schemes := make([]string, 0, len(s.schemes))
+	for id := range s.schemes {
+		schemes = append(schemes, id)
+	}
+
+	sort.Strings(schemes)
+
+	return schemes
+}
+
+// Concept returns the concept with the id. ErrConceptNotFound is returned
+// when it does not exist.
+func (s *Service) Concept(id string) (*Concept, error) {
+	s.rw.RLock()
+	defer s.rw.RUnlock()
+
+	c, ok := s.concepts[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrConceptNotFound, id)
+	}
+
+	return c, nil
+}
+
+// Lookup returns the concepts with a preferred or alternative label that
+// matches the label. Labels are matched case-insensitive and ASCII folded.
+func (s *Service) Lookup(label string) []*Concept {
+	s.rw.RLock()
+	defer s.rw.RUnlock()
+
+	return s.lookup(label)
+}
+
+func (s *Service) lookup(label string) []*Concept {
+	concepts := []*Concept{}
+
+	for _, id := range s.labels[s.a.TransformPhrase(label)] {
+		concepts = append(concepts, s.concepts[id])
+	}
+
+	return concepts
+}
+
+// Narrower returns the narrower concepts of the concept, up to the narrower
+// depth of the Service.
+func (s *Service) Narrower(id string) []*Concept {
+	s.rw.RLock()
+	defer s.rw.RUnlock()
+
+	return s.narrower(id)
+}
+
+func (s *Service) narrower(id string) []*Concept {
+	concepts := []*Concept{}
+	seen := map[string]bool{id: true}
+	level := []string{id}
+
+	for depth := 1; len(level) != 0 && (s.depth == 0 || depth <= s.depth); depth++ {
+		next := []string{}
+
+		for _, parent := range level {
+			c, ok := s.concepts[parent]
+			if !ok {
+				continue
+			}
+
+			for _, child := range c.Narrower {
+				if seen[child] {
+					continue
+				}
+
+				seen[child] = true
+
+				concepts = append(concepts, s.concepts[child])
+				next = append(next, child)
+			}
+		}
+
+		level = next
+	}
+
+	return concepts
+}
+
+// Expand returns the labels of the concepts that match the value and the
+// labels of their narrower concepts. The value itself is not returned.
+func (s *Service) Expand(value string) []string {
+	s.rw.RLock()
+	defer s.rw.RUnlock()
+
+	key := s.a.TransformPhrase(value)
+	seen := map[string]bool{key: true}
+	expansions := []string{}
+
+	add := func(c *Concept) {
+		for _, label := range c.Labels() {
+			if k := s.a.TransformPhrase(label); !seen[k] {
+				seen[k] = true
+
+				expansions = append(expansions, label)
+			}
+		}
+	}
+
+	for _, c := range s.lookup(value) {
+		add(c)
+
+		for _, narrower := range s.narrower(c.ID) {
+			add(narrower)
+		}
+	}
+
+	return expansions
+}
@@ -0,0 +1,194 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vocabulary
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/delving/hub3/ikuzo/service/x/search"
+	"github.com/delving/hub3/ikuzo/storage/x/memory"
+	"github.com/google/go-cmp/cmp"
+	"github.com/matryer/is"
+)
+
+const ex = "http://example.org/thesaurus/"
+
+func newTestService(t *testing.T, options ...Option) *Service {
+	t.Helper()
+
+	options = append(options, SetFiles("testdata/landbouw.ttl", "testdata/visserij.nt"))
+
+	s, err := NewService(options...)
+	if err != nil {
+		t.Fatalf("unable to create vocabulary service: %s", err)
+	}
+
+	return s
+}
+
+func TestService_Load(t *testing.T) {
+	is := is.New(t)
+
+	s := newTestService(t)
+
+	is.Equal(s.Len(), 5)
+	is.Equal(s.Schemes(), []string{ex + "scheme"})
+
+	c, err := s.Concept(ex + "landbouw")
+	is.NoErr(err)
+	is.Equal(c.PrefLabel, []Label{{Value: "Landbouw", Lang: "nl"}, {Value: "Agriculture", Lang: "en"}})
+	is.Equal(c.AltLabel, []Label{{Value: "agrarische sector", Lang: "nl"}})
+	is.Equal(c.InScheme, []string{ex + "scheme"})
+
+	// broader and narrower are added in both directions
+	narrower := append([]string{}, c.Narrower...)
+	sort.Strings(narrower)
+	is.Equal(narrower, []string{ex + "akkerbouw", ex + "veeteelt", ex + "visserij"})
+
+	c, err = s.Concept(ex + "akkerbouw")
+	is.NoErr(err)
+	is.Equal(c.Broader, []string{ex + "landbouw"})
+
+	_, err = s.Concept(ex + "bosbouw")
+	is.True(errors.Is(err, ErrConceptNotFound))
+
+	is.Equal(len(s.Lookup("AGRARISCHE sector")), 1)
+	is.Equal(len(s.Lookup("bosbouw")), 0)
+
+	err = s.LoadFile("testdata/landbouw.rdf")
+	is.True(errors.Is(err, ErrUnknownFormat))
+}
+
+func TestService_Expand(t *testing.T) {
+	tests := []struct {
+		name    string
+		depth   int
+		value   string
+		want    []string
+		wantErr bool
+	}{
+		{
+			"all narrower levels",
+			0,
+			"landbouw",
+			[]string{
+				"Agriculture", "Akkerbouw", "Graanteelt", "Veehouderij",
+				"Veeteelt", "Visserij", "agrarische sector", "visvangst",
+			},
+			false,
+		},
+		{
+			"one narrower level",
+			1,
+			"Landbouw",
+			[]string{
+				"Agriculture", "Akkerbouw", "Veehouderij", "Veeteelt",
+				"Visserij", "agrarische sector", "visvangst",
+			},
+			false,
+		},
+		{
+			"alternative label",
+			0,
+			"veehouderij",
+			[]string{"Veeteelt"},
+			false,
+		},
+		{
+			"unknown label",
+			0,
+			"bosbouw",
+			[]string{},
+			false,
+		},
+		{
+			"negative depth",
+			-1,
+			"landbouw",
+			nil,
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewService(SetNarrowerDepth(tt.depth), SetFiles("testdata/landbouw.ttl", "testdata/visserij.nt"))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewService() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err != nil {
+				return
+			}
+
+			got := s.Expand(tt.value)
+			sort.Strings(got)
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Service.Expand() %s = mismatch (-want +got):\n%s", tt.name, diff)
+			}
+		})
+	}
+}
+
+func TestService_searchExpansion(t *testing.T) {
+	is := is.New(t)
+
+	s := newTestService(t)
+
+	docs := []string{
+		"notulen over de landbouw",
+		"subsidies voor de graanteelt",
+		"kaarten van de haven",
+		"de agrarische sector in Drenthe",
+	}
+
+	ti := memory.NewTextIndex()
+	for _, doc := range docs {
+		is.NoErr(ti.AppendString(doc))
+	}
+
+	qp, err := search.NewQueryParser(search.SetExpander(s))
+	is.NoErr(err)
+
+	query, err := qp.Parse("landbouw")
+	is.NoErr(err)
+
+	hits, err := ti.Search(query)
+	is.NoErr(err)
+
+	got := []int{}
+	for docID := range ti.Docs {
+		if hits.HasDocID(docID) {
+			got = append(got, docID)
+		}
+	}
+
+	sort.Ints(got)
+	is.Equal(got, []int{1, 2, 4}) // records with narrower concepts and synonyms are found
+
+	terms := []string{}
+	for term := range hits.TermFrequency() {
+		terms = append(terms, term)
+	}
+
+	sort.Strings(terms)
+	is.Equal(strings.Join(terms, ","), "agrarische sector,graanteelt,landbouw")
+}
@@ -4,4 +4,5 @@ var (
 	indexFname     = "index.gob"
 	configDirFname = ".hub3"
 	oaipmhFname    = "oaipmh.json"
+	dataFname      = "records"
 )
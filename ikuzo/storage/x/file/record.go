@@ -96,6 +96,30 @@ type recordPointer struct {
 	GraphName    string    `json:"graphName"`
 	LastModified time.Time `json:"lastModified"`
 	Deleted      bool      `json:"deleted"`
+	Revision     int64     `json:"revision"`
+}
+
+// separator returns the RecordSeparator that is written after the record data.
+func (rp *recordPointer) separator() RecordSeparator {
+	return RecordSeparator{
+		OrgID:       rp.OrgID,
+		DatasetID:   rp.DatasetID,
+		HubID:       rp.HubID,
+		LocalID:     rp.LocalID,
+		GraphURI:    rp.GraphName,
+		ContentHash: rp.RecordHash,
+	}
+}
+
+// record returns the Record for the pointer with the data read from disk.
+func (rp *recordPointer) record(data []byte) *Record {
+	return &Record{
+		RecordSeparator: rp.separator(),
+		Revision:        rp.Revision,
+		LastModified:    rp.LastModified,
+		Deleted:         rp.Deleted,
+		Data:            data,
+	}
 }
 
 func (idx *index) Data(rp *recordPointer) (data []byte, err error) {
@@ -28,8 +28,11 @@ type index struct {
 	AllowedFormats  []oaipmh.MetadataFormat
 	SourceFormat    SourceFormat
 	SourceExtension string
+	Revision        int64            // revision of the last stored record
+	DataFile        string           // name of the data file records are appended to
+	Records         []*recordPointer // sorted by revision
+	basePath        string
 	lookUp          map[string]*recordPointer
-	records         []*recordPointer // sorted by last modified
 	files           map[string]*os.File
 	m               *metrics
 }
@@ -50,8 +53,11 @@ func newIndex(orgID, datasetID string, format SourceFormat) (*index, error) {
 
 	switch format {
 	case FormatRaw:
+		idx.SourceExtension = "raw"
 	case FormatNTriples:
+		idx.SourceExtension = "nq"
 	case FormatEAD:
+		idx.SourceExtension = "xml"
 	default:
 		return nil, fmt.Errorf("unsupported source format: %s", format)
 	}
@@ -66,12 +72,216 @@ func (idx *index) Close() error {
 		}
 	}
 
+	idx.files = map[string]*os.File{}
+
 	return nil
 }
 
+// dataFileName returns the name of the data file that is started at revision.
+func (idx *index) dataFileName(revision int64) string {
+	return fmt.Sprintf("%s_%d.%s", dataFname, revision, idx.SourceExtension)
+}
+
+// openDataFile opens the current data file for reading and appending.
+//
+// The file is cached, so Reader can use it for the record pointers.
+func (idx *index) openDataFile() (*os.File, error) {
+	if idx.DataFile == "" {
+		idx.DataFile = idx.dataFileName(idx.Revision)
+	}
+
+	if f, ok := idx.files[idx.DataFile]; ok {
+		return f, nil
+	}
+
+	if err := os.MkdirAll(idx.dir(), os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(filepath.Join(idx.dir(), idx.DataFile), os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	idx.files[idx.DataFile] = f
+
+	return f, nil
+}
+
+// writeRecord writes the record data followed by its RecordSeparator to w.
+// It returns the length of the record data.
+func writeRecord(w io.Writer, sep RecordSeparator, data []byte) (int64, error) {
+	var buf bytes.Buffer
+
+	buf.Write(data)
+
+	if len(data) > 0 && !bytes.HasSuffix(data, []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+
+	length := int64(buf.Len())
+
+	buf.WriteString(sep.String())
+	buf.WriteByte('\n')
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+
+	return length, nil
+}
+
+// append writes the record to the data file and adds its pointer to the index.
+// A stored record with the same hubID is superseded by the new pointer.
+func (idx *index) append(rec *Record) (*recordPointer, error) {
+	f, err := idx.openDataFile()
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	length, err := writeRecord(f, rec.RecordSeparator, rec.Data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to append record %s; %w", rec.HubID, err)
+	}
+
+	idx.Revision++
+
+	rp := rec.RecordSeparator.newRecordPointer(idx.DataFile, "")
+	rp.Offset = info.Size()
+	rp.Length = length
+	rp.Lines = int64(bytes.Count(rec.Data, []byte("\n")))
+	rp.Deleted = rec.Deleted
+	rp.Revision = idx.Revision
+
+	if !rec.LastModified.IsZero() {
+		rp.LastModified = rec.LastModified
+	}
+
+	idx.Records = append(idx.Records, rp)
+	idx.lookUp[rp.HubID] = rp
+
+	return rp, nil
+}
+
+// live returns the pointers that are not superseded with a revision higher
+// than fromRevision in revision order.
+func (idx *index) live(fromRevision int64) []*recordPointer {
+	pointers := []*recordPointer{}
+
+	idx.each(fromRevision, func(rp *recordPointer) bool {
+		pointers = append(pointers, rp)
+		return true
+	})
+
+	return pointers
+}
+
+// each calls fn for the pointers that are not superseded with a revision
+// higher than fromRevision in revision order, until fn returns false.
+func (idx *index) each(fromRevision int64, fn func(rp *recordPointer) bool) {
+	start := sort.Search(len(idx.Records), func(i int) bool {
+		return idx.Records[i].Revision > fromRevision
+	})
+
+	for _, rp := range idx.Records[start:] {
+		if idx.lookUp[rp.HubID] == rp && !fn(rp) {
+			return
+		}
+	}
+}
+
+// compact rewrites the live records to a new data file and removes the
+// superseded records from the index. Tombstones of deleted records are kept.
+//
+// The index is written before the previous data file is removed, so an
+// interrupted compaction never leaves the index pointing to missing data.
+func (idx *index) compact() error {
+	pointers := idx.live(0)
+	if len(pointers) == len(idx.Records) {
+		return nil
+	}
+
+	name := idx.dataFileName(idx.Revision)
+	if name == idx.DataFile {
+		return nil
+	}
+
+	f, err := os.OpenFile(filepath.Join(idx.dir(), name), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	var (
+		offset  int64
+		records = make([]*recordPointer, 0, len(pointers))
+	)
+
+	w := bufio.NewWriter(f)
+
+	for _, rp := range pointers {
+		data, err := idx.Data(rp)
+		if err != nil {
+			f.Close()
+			return fmt.Errorf("unable to read record %s; %w", rp.HubID, err)
+		}
+
+		length, err := writeRecord(w, rp.separator(), data)
+		if err != nil {
+			f.Close()
+			return err
+		}
+
+		compacted := *rp
+		compacted.FileName = name
+		compacted.Offset = offset
+
+		records = append(records, &compacted)
+
+		offset += length + int64(len(rp.separator().String())) + 1
+	}
+
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	previous := idx.DataFile
+
+	if err := idx.Close(); err != nil {
+		return err
+	}
+
+	idx.DataFile = name
+	idx.Records = records
+	idx.lookUp = map[string]*recordPointer{}
+
+	for _, rp := range records {
+		idx.lookUp[rp.HubID] = rp
+	}
+
+	if err := idx.Write(); err != nil {
+		return err
+	}
+
+	if _, err := idx.openDataFile(); err != nil {
+		return err
+	}
+
+	return os.Remove(filepath.Join(idx.dir(), previous))
+}
+
 func (idx *index) sortRecords() {
-	sort.Slice(idx.records, func(i, j int) bool {
-		return idx.records[i].LastModified.Before(idx.records[j].LastModified)
+	sort.Slice(idx.Records, func(i, j int) bool {
+		return idx.Records[i].LastModified.Before(idx.Records[j].LastModified)
 	})
 }
 
@@ -109,7 +319,7 @@ func (idx *index) ParseNarthexNtriples(r io.Reader, fname, fhash string) error {
 			rp.StartLine = int64(recordStartLine)
 			rp.Lines = int64(lines - recordStartLine)
 
-			idx.records = append(idx.records, rp)
+			idx.Records = append(idx.Records, rp)
 			idx.lookUp[sep.HubID] = rp
 
 			recordOffset += (recordLength + 1 + len(line))
@@ -130,24 +340,33 @@ func (idx *index) ParseNarthexNtriples(r io.Reader, fname, fhash string) error {
 	return nil
 }
 
-func readIndex(orgID, datasetID string) (*index, error) {
-	path := filepath.Join(orgID, datasetID, configDirFname, indexFname)
+// dir returns the directory where the files of the dataset are stored.
+func (idx *index) dir() string {
+	return filepath.Join(idx.basePath, idx.OrgID, idx.DatasetID)
+}
+
+func readIndex(basePath, orgID, datasetID string) (*index, error) {
+	path := filepath.Join(basePath, orgID, datasetID, configDirFname, indexFname)
 
 	f, err := os.Open(path)
 	if err != nil {
 		return nil, err
 	}
 
+	defer f.Close()
+
 	idx, err := decodeIndex(f)
 	if err != nil {
 		return nil, err
 	}
 
+	idx.basePath = basePath
+
 	return idx, nil
 }
 
 func (idx *index) Write() error {
-	dirPath := filepath.Join(idx.OrgID, idx.DatasetID, configDirFname)
+	dirPath := filepath.Join(idx.dir(), configDirFname)
 
 	err := os.MkdirAll(dirPath, os.ModePerm)
 	if err != nil {
@@ -163,11 +382,14 @@ func (idx *index) Write() error {
 
 	path := filepath.Join(dirPath, indexFname)
 
-	return os.WriteFile(
-		path,
-		buf.Bytes(),
-		os.ModePerm,
-	)
+	// write to a temporary file first so a crash never leaves a truncated index
+	tmpPath := path + ".tmp"
+
+	if err := os.WriteFile(tmpPath, buf.Bytes(), os.ModePerm); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, path)
 }
 
 func (idx *index) encode(w io.Writer) error {
@@ -191,5 +413,13 @@ func decodeIndex(r io.Reader) (*index, error) {
 		return nil, err
 	}
 
+	idx.lookUp = map[string]*recordPointer{}
+	idx.files = map[string]*os.File{}
+
+	// superseded pointers are kept until compaction, so the last one wins
+	for _, rp := range idx.Records {
+		idx.lookUp[rp.HubID] = rp
+	}
+
 	return &idx, nil
 }
@@ -28,7 +28,7 @@ func TestParseNarthexNtriples(t *testing.T) {
 	)
 	is.NoErr(err)
 
-	is.Equal(len(idx.records), len(idx.lookUp))
+	is.Equal(len(idx.Records), len(idx.lookUp))
 	is.Equal(len(idx.lookUp), 1284)
 
 	rp, ok := idx.lookUp["NL-HaNA_2-21-284ntfoto_22325057-f020-4d14-b2ab-fc43bd2bed8e"]
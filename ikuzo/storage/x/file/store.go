@@ -1,4 +1,387 @@
 package file
 
-type Store struct { // path or bucket
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/delving/hub3/ikuzo/service/x/oaipmh/crosswalk"
+)
+
+var (
+	ErrDatasetNotFound = errors.New("dataset not found")
+	ErrRecordNotFound  = errors.New("record not found")
+	ErrInvalidRecord   = errors.New("record requires an orgID, datasetID and hubID")
+	ErrInvalidName     = errors.New("orgID and datasetID must be a single path element")
+)
+
+// Record is a single record stored in the Store.
+type Record struct {
+	RecordSeparator
+	Revision     int64
+	LastModified time.Time
+	Deleted      bool
+	Data         []byte
+}
+
+// Store is a file based source of truth for records.
+//
+// Each dataset is stored in the directory '{Path}/{orgID}/{datasetID}'. The
+// records are appended to a data file in the Narthex record separator format
+// and a gob-encoded index keeps a pointer to each record.
+// Replaced and deleted records are only removed from the data file by Compact.
+type Store struct {
+	Path         string              // TODO(kiivihal): replace with bucket later
+	Format       SourceFormat        // source format of new datasets
+	ResponseSize int                 // number of OAI-PMH headers or records per response
+	Crosswalks   *crosswalk.Registry // metadata formats that can be disseminated
+	m            sync.Mutex
+	indexes      map[string]*index
+}
+
+func NewStore(path string) (*Store, error) {
+	if err := os.MkdirAll(path, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("unable to create store path %s; %w", path, err)
+	}
+
+	s := &Store{
+		Path:         path,
+		Format:       FormatNTriples,
+		ResponseSize: 100,
+		Crosswalks:   crosswalk.NewDefaultRegistry(),
+		indexes:      map[string]*index{},
+	}
+
+	s.Crosswalks.Register(crosswalk.NewEAD(s.source))
+
+	return s, nil
+}
+
+// validName returns true when the name can be used as a directory of the
+// Store, i.e. a single local path element.
+func validName(name string) bool {
+	return filepath.IsLocal(name) && !strings.ContainsAny(name, `/\`) && name != "."
+}
+
+// getIndex returns the index of the dataset. When create is false
+// ErrDatasetNotFound is returned for unknown datasets. ErrInvalidName is
+// returned when the orgID or datasetID is not a valid directory name.
+//
+// The caller must hold s.m.
+func (s *Store) getIndex(orgID, datasetID string, create bool) (*index, error) {
+	if !validName(orgID) || !validName(datasetID) {
+		return nil, fmt.Errorf("%w: %q/%q", ErrInvalidName, orgID, datasetID)
+	}
+
+	key := filepath.Join(orgID, datasetID)
+
+	idx, ok := s.indexes[key]
+	if ok {
+		return idx, nil
+	}
+
+	idx, err := readIndex(s.Path, orgID, datasetID)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("unable to read index for %s; %w", key, err)
+		}
+
+		if !create {
+			return nil, ErrDatasetNotFound
+		}
+
+		idx, err = newIndex(orgID, datasetID, s.Format)
+		if err != nil {
+			return nil, err
+		}
+
+		idx.basePath = s.Path
+	}
+
+	if _, err := idx.openDataFile(); err != nil {
+		return nil, err
+	}
+
+	s.indexes[key] = idx
+
+	return idx, nil
+}
+
+// Put appends the records to their dataset. A record replaces the stored
+// record with the same hubID. Records with unchanged content are skipped.
+//
+// The revision and modification date of the stored records are set on the
+// records.
+func (s *Store) Put(records ...*Record) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	changed := map[*index]bool{}
+
+	for _, rec := range records {
+		if rec.OrgID == "" || rec.DatasetID == "" || rec.HubID == "" {
+			return ErrInvalidRecord
+		}
+
+		idx, err := s.getIndex(rec.OrgID, rec.DatasetID, true)
+		if err != nil {
+			return err
+		}
+
+		if rec.ContentHash == "" {
+			rec.ContentHash, err = fileHash(bytes.NewReader(rec.Data))
+			if err != nil {
+				return err
+			}
+		}
+
+		current, ok := idx.lookUp[rec.HubID]
+		if ok && !current.Deleted && current.RecordHash == rec.ContentHash {
+			rec.Revision = current.Revision
+			rec.LastModified = current.LastModified
+
+			continue
+		}
+
+		rec.Deleted = false
+
+		rp, err := idx.append(rec)
+		if err != nil {
+			return err
+		}
+
+		rec.Revision = rp.Revision
+		rec.LastModified = rp.LastModified
+		changed[idx] = true
+	}
+
+	return writeIndexes(changed)
+}
+
+// Delete replaces the records with a tombstone. Unknown hubIDs are ignored.
+func (s *Store) Delete(orgID, datasetID string, hubIDs ...string) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	idx, err := s.getIndex(orgID, datasetID, false)
+	if err != nil {
+		return err
+	}
+
+	changed := map[*index]bool{}
+
+	for _, hubID := range hubIDs {
+		current, ok := idx.lookUp[hubID]
+		if !ok || current.Deleted {
+			continue
+		}
+
+		tombstone := &Record{
+			RecordSeparator: current.separator(),
+			Deleted:         true,
+		}
+
+		if _, err := idx.append(tombstone); err != nil {
+			return err
+		}
+
+		changed[idx] = true
+	}
+
+	return writeIndexes(changed)
+}
+
+func writeIndexes(indexes map[*index]bool) error {
+	for idx := range indexes {
+		if err := idx.Write(); err != nil {
+			return fmt.Errorf("unable to write index for %s; %w", idx.dir(), err)
+		}
+	}
+
+	return nil
+}
+
+// Get returns the current version of the record. For deleted records the
+// tombstone is returned.
+func (s *Store) Get(orgID, datasetID, hubID string) (*Record, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	idx, err := s.getIndex(orgID, datasetID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	rp, ok := idx.lookUp[hubID]
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+
+	return idx.record(rp)
+}
+
+// record reads the record data of the pointer from disk.
+func (idx *index) record(rp *recordPointer) (*Record, error) {
+	data, err := idx.Data(rp)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read record %s; %w", rp.HubID, err)
+	}
+
+	return rp.record(data), nil
+}
+
+// Iterate calls fn in revision order for each current record in the dataset
+// that has a revision higher than fromRevision. Tombstones of deleted records
+// are included.
+//
+// The store is not locked while fn is called, so records that are changed
+// during the iteration are skipped.
+func (s *Store) Iterate(ctx context.Context, orgID, datasetID string, fromRevision int64, fn func(*Record) error) error {
+	pointers, err := s.pointers(orgID, datasetID, fromRevision)
+	if err != nil {
+		return err
+	}
+
+	for _, rp := range pointers {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		rec, ok, err := s.current(orgID, datasetID, rp)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			continue
+		}
+
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// pointers returns a snapshot of the current record pointers of the dataset.
+func (s *Store) pointers(orgID, datasetID string, fromRevision int64) ([]*recordPointer, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	idx, err := s.getIndex(orgID, datasetID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return idx.live(fromRevision), nil
+}
+
+// current reads the record of the pointer when it is still the current
+// revision of the record. Compaction moves records, so the pointer is looked
+// up again.
+func (s *Store) current(orgID, datasetID string, rp *recordPointer) (*Record, bool, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	idx, err := s.getIndex(orgID, datasetID, false)
+	if err != nil {
+		return nil, false, err
+	}
+
+	current, ok := idx.lookUp[rp.HubID]
+	if !ok || current.Revision != rp.Revision {
+		return nil, false, nil
+	}
+
+	rec, err := idx.record(current)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return rec, true, nil
+}
+
+// Revision returns the revision of the last change to the dataset.
+func (s *Store) Revision(orgID, datasetID string) (int64, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	idx, err := s.getIndex(orgID, datasetID, false)
+	if err != nil {
+		return 0, err
+	}
+
+	return idx.Revision, nil
+}
+
+// Compact removes the superseded records from the data file of the dataset.
+func (s *Store) Compact(orgID, datasetID string) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	idx, err := s.getIndex(orgID, datasetID, false)
+	if err != nil {
+		return err
+	}
+
+	if err := idx.compact(); err != nil {
+		return fmt.Errorf("unable to compact %s; %w", idx.dir(), err)
+	}
+
+	return nil
+}
+
+// DatasetIDs returns the sorted datasetIDs stored for the organization.
+func (s *Store) DatasetIDs(orgID string) ([]string, error) {
+	datasetIDs := []string{}
+
+	entries, err := os.ReadDir(filepath.Join(s.Path, orgID))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return datasetIDs, nil
+		}
+
+		return datasetIDs, err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		_, err := os.Stat(filepath.Join(s.Path, orgID, entry.Name(), configDirFname, indexFname))
+		if err != nil {
+			continue
+		}
+
+		datasetIDs = append(datasetIDs, entry.Name())
+	}
+
+	sort.Strings(datasetIDs)
+
+	return datasetIDs, nil
+}
+
+// Close closes the open data files of all datasets.
+func (s *Store) Close() error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	for key, idx := range s.indexes {
+		if err := idx.Close(); err != nil {
+			return err
+		}
+
+		delete(s.indexes, key)
+	}
+
+	return nil
 }
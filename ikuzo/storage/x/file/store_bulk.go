@@ -0,0 +1,68 @@
+package file
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/delving/hub3/ikuzo/service/x/bulk"
+)
+
+// bulkRequest returns the bulk.Request to index the record.
+func (rec *Record) bulkRequest() *bulk.Request {
+	return &bulk.Request{
+		HubID:         rec.HubID,
+		OrgID:         rec.OrgID,
+		DatasetID:     rec.DatasetID,
+		LocalID:       rec.LocalID,
+		NamedGraphURI: rec.GraphURI,
+		Action:        "index",
+		ContentHash:   rec.ContentHash,
+		Graph:         string(rec.Data),
+		GraphMimeType: "text/turtle", // N-Triples is a subset of Turtle
+	}
+}
+
+// Publish submits the current records of the dataset as bulk.Requests, so the
+// dataset can be re-indexed from the Store without Narthex.
+//
+// Like bulk.Publisher.Do it increments the revision at the start and drops the
+// orphans after the final submit, so deleted records are removed from the index.
+func (s *Store) Publish(ctx context.Context, orgID, datasetID string, p *bulk.Publisher) error {
+	format, err := s.sourceFormat(orgID, datasetID)
+	if err != nil {
+		return err
+	}
+
+	if format != FormatNTriples {
+		return fmt.Errorf("unable to publish %s/%s: unsupported source format %s", orgID, datasetID, format)
+	}
+
+	if err := p.IncrementRevision(orgID, datasetID); err != nil {
+		return err
+	}
+
+	err = s.Iterate(ctx, orgID, datasetID, 0, func(rec *Record) error {
+		if rec.Deleted {
+			return nil
+		}
+
+		return p.Append(rec.bulkRequest())
+	})
+	if err != nil {
+		return err
+	}
+
+	return p.DropOrphans(orgID, datasetID)
+}
+
+func (s *Store) sourceFormat(orgID, datasetID string) (SourceFormat, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	idx, err := s.getIndex(orgID, datasetID, false)
+	if err != nil {
+		return 0, err
+	}
+
+	return idx.SourceFormat, nil
+}
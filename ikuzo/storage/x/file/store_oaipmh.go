@@ -0,0 +1,581 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/delving/hub3/hub3/fragments"
+	"github.com/delving/hub3/ikuzo/rdf/formats/ntriples"
+	"github.com/delving/hub3/ikuzo/service/x/oaipmh"
+	"github.com/delving/hub3/ikuzo/service/x/oaipmh/crosswalk"
+)
+
+var _ oaipmh.Store = (*Store)(nil)
+
+// rawPrefix is the metadata prefix of the records of raw datasets. They have
+// no crosswalk and are passed through unchanged.
+const rawPrefix = "raw"
+
+// recordTags are the tags of the stored records by source format. Like the
+// tags of the records in elasticsearch they select the crosswalks that can
+// disseminate the records.
+var recordTags = map[SourceFormat][]string{
+	FormatNTriples: {"narthex", "mdr"},
+	FormatEAD:      {"eadDesc"},
+}
+
+// allowsFormat returns true when the metadata prefix is not excluded by the
+// formats that are set with SetMetadataFormats.
+func (idx *index) allowsFormat(metadataPrefix string) bool {
+	if len(idx.AllowedFormats) == 0 {
+		return true
+	}
+
+	for _, format := range idx.AllowedFormats {
+		if strings.EqualFold(format.MetadataPrefix, metadataPrefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// crosswalks returns the registered crosswalks the records of the dataset can
+// be disseminated with. N-Triples records are transformed from their graph.
+// The records of the other source formats can only be passed through, by the
+// crosswalks that are limited to their tags.
+func (s *Store) crosswalks(idx *index) []crosswalk.Crosswalk {
+	tags, ok := recordTags[idx.SourceFormat]
+	if !ok {
+		return nil
+	}
+
+	crosswalks := []crosswalk.Crosswalk{}
+
+	for _, cw := range s.Crosswalks.Crosswalks() {
+		if idx.SourceFormat != FormatNTriples && len(cw.Tags()) == 0 {
+			continue
+		}
+
+		if crosswalk.Accepts(cw, tags) && idx.allowsFormat(cw.Format().MetadataPrefix) {
+			crosswalks = append(crosswalks, cw)
+		}
+	}
+
+	return crosswalks
+}
+
+// crosswalk returns the crosswalk of the dataset for the metadata prefix. The
+// crosswalk is nil for the raw records of raw datasets.
+func (s *Store) crosswalk(idx *index, metadataPrefix string) (cw crosswalk.Crosswalk, ok bool) {
+	if idx.SourceFormat == FormatRaw {
+		return nil, strings.EqualFold(metadataPrefix, rawPrefix) && idx.allowsFormat(rawPrefix)
+	}
+
+	for _, cw := range s.crosswalks(idx) {
+		if strings.EqualFold(cw.Format().MetadataPrefix, metadataPrefix) {
+			return cw, true
+		}
+	}
+
+	return nil, false
+}
+
+// metadataFormats returns the OAI-PMH metadata formats the records of the
+// dataset can be disseminated in.
+func (s *Store) metadataFormats(idx *index) []oaipmh.MetadataFormat {
+	formats := []oaipmh.MetadataFormat{}
+
+	if idx.SourceFormat == FormatRaw {
+		if idx.allowsFormat(rawPrefix) {
+			formats = append(formats, oaipmh.MetadataFormat{MetadataPrefix: rawPrefix})
+		}
+
+		return formats
+	}
+
+	for _, cw := range s.crosswalks(idx) {
+		formats = append(formats, cw.Format())
+	}
+
+	return formats
+}
+
+// header returns the fragments.Header of the record for the crosswalks.
+func (idx *index) header(rp *recordPointer) *fragments.Header {
+	return &fragments.Header{
+		OrgID:         rp.OrgID,
+		Spec:          rp.DatasetID,
+		HubID:         rp.HubID,
+		NamedGraphURI: rp.GraphName,
+		EntryURI:      strings.TrimSuffix(rp.GraphName, "/graph"),
+		Modified:      rp.LastModified.UnixMilli(),
+		Tags:          recordTags[idx.SourceFormat],
+	}
+}
+
+// metadata returns the OAI-PMH metadata of the record data in the format of
+// the crosswalk. The data is returned unchanged when the crosswalk is nil.
+func (idx *index) metadata(ctx context.Context, cw crosswalk.Crosswalk, rp *recordPointer, data []byte) (oaipmh.Metadata, error) {
+	if cw == nil {
+		return oaipmh.Metadata{Body: data}, nil
+	}
+
+	rec := crosswalk.NewGraphRecord(idx.header(rp), nil)
+
+	if idx.SourceFormat == FormatNTriples {
+		g, err := ntriples.Parse(bytes.NewReader(data), nil)
+		if err != nil {
+			return oaipmh.Metadata{}, fmt.Errorf("unable to parse record %s; %w", rp.HubID, err)
+		}
+
+		rec = crosswalk.NewGraphRecord(idx.header(rp), g)
+	}
+
+	var buf bytes.Buffer
+
+	if err := cw.Transform(ctx, rec, &buf); err != nil {
+		return oaipmh.Metadata{}, fmt.Errorf("unable to transform record %s to %s; %w", rp.HubID, cw.Format().MetadataPrefix, err)
+	}
+
+	return oaipmh.Metadata{Body: buf.Bytes()}, nil
+}
+
+// source opens the data of the last stored record of the dataset. It is the
+// crosswalk.SourceFunc of the EAD passthrough, because the EAD of an archive
+// is stored as a single record.
+func (s *Store) source(ctx context.Context, orgID, datasetID string) (io.ReadCloser, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	idx, err := s.getIndex(orgID, datasetID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	live := idx.live(0)
+	if len(live) == 0 {
+		return nil, ErrRecordNotFound
+	}
+
+	data, err := idx.Data(live[len(live)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// SetMetadataFormats limits the OAI-PMH metadata formats of the dataset to the
+// prefixes of the formats. By default all crosswalks that accept the source
+// format of the dataset are used.
+func (s *Store) SetMetadataFormats(orgID, datasetID string, formats ...oaipmh.MetadataFormat) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	idx, err := s.getIndex(orgID, datasetID, false)
+	if err != nil {
+		return err
+	}
+
+	idx.AllowedFormats = formats
+
+	return idx.Write()
+}
+
+func (s *Store) ListSets(ctx context.Context, q *oaipmh.RequestConfig) (res oaipmh.Resumable, err error) {
+	datasetIDs, err := s.DatasetIDs(q.OrgID)
+	if err != nil {
+		return res, err
+	}
+
+	for _, datasetID := range datasetIDs {
+		stats, statsErr := s.stats(q.OrgID, datasetID)
+		if statsErr != nil {
+			return res, statsErr
+		}
+
+		res.Sets = append(res.Sets, oaipmh.Set{
+			SetSpec: datasetID,
+			SetDescription: oaipmh.Description{
+				Body: []byte(fmt.Sprintf("<totalRecords>%d</totalRecords>", stats.Records)),
+			},
+		})
+	}
+
+	if len(res.Sets) == 0 {
+		res.Errors = append(res.Errors, oaipmh.ErrNoSetHierachy)
+	}
+
+	res.Total = len(res.Sets)
+
+	return res, nil
+}
+
+// harvestCursor encodes the position of the last harvested record.
+func harvestCursor(rp *recordPointer) string {
+	return fmt.Sprintf("%s:%d", rp.DatasetID, rp.Revision)
+}
+
+func parseHarvestCursor(payload string) (datasetID string, revision int64, err error) {
+	i := strings.LastIndex(payload, ":")
+	if i == -1 {
+		return "", 0, fmt.Errorf("invalid harvest cursor: %q", payload)
+	}
+
+	revision, err = strconv.ParseInt(payload[i+1:], 10, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid harvest cursor: %q", payload)
+	}
+
+	return payload[:i], revision, nil
+}
+
+// harvest returns the next page of record pointers that match the OAI-PMH
+// request, ordered by dataset and revision, and the total number of matches.
+// The total is only counted for the first request; a resumed request starts
+// from the revision of the cursor.
+func (s *Store) harvest(q *oaipmh.RequestConfig) (page []*recordPointer, total int, pmhErrors []oaipmh.Error, err error) {
+	dateRange, rangeErr := q.DateRange()
+	if rangeErr != nil {
+		pmhErrors = append(pmhErrors, oaipmh.ErrBadArgument)
+		return page, total, pmhErrors, nil
+	}
+
+	var (
+		cursorDatasetID string
+		cursorRevision  int64
+	)
+
+	resumed := q.IsResumedRequest()
+
+	if resumed && q.CurrentRequest.StorePayload != "" {
+		cursorDatasetID, cursorRevision, err = parseHarvestCursor(q.CurrentRequest.StorePayload)
+		if err != nil {
+			return page, total, pmhErrors, err
+		}
+	}
+
+	datasetIDs := []string{q.DatasetID}
+
+	if q.DatasetID == "" {
+		datasetIDs, err = s.DatasetIDs(q.OrgID)
+		if err != nil {
+			return page, total, pmhErrors, err
+		}
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	var found, disseminated bool
+
+	for _, datasetID := range datasetIDs {
+		if resumed && datasetID < cursorDatasetID {
+			continue
+		}
+
+		idx, idxErr := s.getIndex(q.OrgID, datasetID, false)
+		if idxErr != nil {
+			switch {
+			case errors.Is(idxErr, ErrDatasetNotFound):
+				continue
+			case errors.Is(idxErr, ErrInvalidName):
+				pmhErrors = append(pmhErrors, oaipmh.ErrBadArgument)
+				return page, total, pmhErrors, nil
+			}
+
+			return page, total, pmhErrors, idxErr
+		}
+
+		found = true
+
+		if !q.HarvestsSet(datasetID) {
+			continue
+		}
+
+		if _, ok := s.crosswalk(idx, q.FirstRequest.MetadataPrefix); !ok {
+			continue
+		}
+
+		disseminated = true
+
+		var fromRevision int64
+		if datasetID == cursorDatasetID {
+			fromRevision = cursorRevision
+		}
+
+		idx.each(fromRevision, func(rp *recordPointer) bool {
+			if !dateRange.Contains(rp.LastModified) {
+				return true
+			}
+
+			total++
+
+			if len(page) < s.ResponseSize {
+				page = append(page, rp)
+				return true
+			}
+
+			// only the first request counts the remaining matches
+			return !resumed
+		})
+
+		if resumed && len(page) == s.ResponseSize {
+			break
+		}
+	}
+
+	if found && !disseminated {
+		pmhErrors = append(pmhErrors, oaipmh.ErrCannotDisseminateFormat)
+	}
+
+	return page, total, pmhErrors, nil
+}
+
+func header(rp *recordPointer) oaipmh.Header {
+	h := oaipmh.Header{
+		Identifier: rp.HubID,
+		DateStamp:  rp.LastModified.UTC().Format(oaipmh.TimeFormat),
+		SetSpec:    []string{rp.DatasetID},
+	}
+
+	if rp.Deleted {
+		h.Status = "deleted"
+	}
+
+	return h
+}
+
+func (s *Store) ListIdentifiers(ctx context.Context, q *oaipmh.RequestConfig) (res oaipmh.Resumable, err error) {
+	page, total, pmhErrors, err := s.harvest(q)
+	if err != nil || len(pmhErrors) != 0 {
+		res.Errors = pmhErrors
+		return res, err
+	}
+
+	for _, rp := range page {
+		res.Headers = append(res.Headers, header(rp))
+	}
+
+	if len(page) > 0 {
+		res.StorePayload = harvestCursor(page[len(page)-1])
+	}
+
+	res.Total = total
+
+	return res, nil
+}
+
+func (s *Store) ListRecords(ctx context.Context, q *oaipmh.RequestConfig) (res oaipmh.Resumable, err error) {
+	page, total, pmhErrors, err := s.harvest(q)
+	if err != nil || len(pmhErrors) != 0 {
+		res.Errors = pmhErrors
+		return res, err
+	}
+
+	for _, rp := range page {
+		record, ok, recordErr := s.oaipmhRecord(ctx, q.OrgID, q.FirstRequest.MetadataPrefix, rp)
+		if recordErr != nil {
+			return res, recordErr
+		}
+
+		if ok {
+			res.Records = append(res.Records, record)
+		}
+	}
+
+	if len(page) > 0 {
+		res.StorePayload = harvestCursor(page[len(page)-1])
+	}
+
+	res.Total = total
+
+	return res, nil
+}
+
+// oaipmhRecord returns the OAI-PMH record for the pointer in the metadata
+// format. It returns false when the record has been changed after the pointer
+// was retrieved.
+func (s *Store) oaipmhRecord(ctx context.Context, orgID, metadataPrefix string, rp *recordPointer) (record oaipmh.Record, ok bool, err error) {
+	idx, current, data, err := s.recordData(orgID, rp)
+	if err != nil || current == nil {
+		return record, false, err
+	}
+
+	record.Header = header(current)
+
+	if current.Deleted {
+		return record, true, nil
+	}
+
+	cw, _ := s.crosswalk(idx, metadataPrefix)
+
+	// the crosswalk is applied without holding the lock, because the EAD
+	// passthrough reads the source from the Store
+	record.Metadata, err = idx.metadata(ctx, cw, current, data)
+	if err != nil {
+		return record, false, err
+	}
+
+	return record, true, nil
+}
+
+// recordData returns the current pointer and the data of the record. The
+// pointer is nil when the record has been changed after rp was retrieved.
+func (s *Store) recordData(orgID string, rp *recordPointer) (idx *index, current *recordPointer, data []byte, err error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	idx, err = s.getIndex(orgID, rp.DatasetID, false)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	current, ok := idx.lookUp[rp.HubID]
+	if !ok || current.Revision != rp.Revision {
+		return idx, nil, nil, nil
+	}
+
+	if current.Deleted {
+		return idx, current, nil, nil
+	}
+
+	data, err = idx.Data(current)
+	if err != nil {
+		return idx, nil, nil, fmt.Errorf("unable to read record %s; %w", current.HubID, err)
+	}
+
+	return idx, current, data, nil
+}
+
+// lookup returns the current pointer for the hubID from any dataset of the
+// organization.
+func (s *Store) lookup(orgID, hubID string) (*recordPointer, error) {
+	datasetIDs, err := s.DatasetIDs(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	for _, datasetID := range datasetIDs {
+		idx, err := s.getIndex(orgID, datasetID, false)
+		if err != nil {
+			return nil, err
+		}
+
+		if rp, ok := idx.lookUp[hubID]; ok {
+			return rp, nil
+		}
+	}
+
+	return nil, ErrRecordNotFound
+}
+
+func (s *Store) GetRecord(ctx context.Context, q *oaipmh.RequestConfig) (record oaipmh.Record, pmhErrors []oaipmh.Error, err error) {
+	if q.FirstRequest.Identifier == "" {
+		pmhErrors = append(pmhErrors, oaipmh.ErrIdDoesNotExist)
+		return record, pmhErrors, nil
+	}
+
+	rp, err := s.lookup(q.OrgID, q.FirstRequest.Identifier)
+	if err != nil {
+		if errors.Is(err, ErrRecordNotFound) {
+			pmhErrors = append(pmhErrors, oaipmh.ErrIdDoesNotExist)
+			return record, pmhErrors, nil
+		}
+
+		return record, pmhErrors, err
+	}
+
+	formats, err := s.ListMetadataFormats(ctx, &oaipmh.RequestConfig{OrgID: q.OrgID, DatasetID: rp.DatasetID})
+	if err != nil {
+		return record, pmhErrors, err
+	}
+
+	var supported bool
+
+	for _, format := range formats {
+		if strings.EqualFold(format.MetadataPrefix, q.FirstRequest.MetadataPrefix) {
+			supported = true
+		}
+	}
+
+	if !supported {
+		pmhErrors = append(pmhErrors, oaipmh.ErrCannotDisseminateFormat)
+		return record, pmhErrors, nil
+	}
+
+	record, ok, err := s.oaipmhRecord(ctx, q.OrgID, q.FirstRequest.MetadataPrefix, rp)
+	if err != nil {
+		return record, pmhErrors, err
+	}
+
+	if !ok {
+		// the record was changed after the lookup
+		return s.GetRecord(ctx, q)
+	}
+
+	return record, pmhErrors, nil
+}
+
+// ListMetadataFormats returns the metadata formats of the dataset or the
+// record in the request. Without either the formats of all datasets of the
+// organization are returned.
+func (s *Store) ListMetadataFormats(ctx context.Context, q *oaipmh.RequestConfig) (formats []oaipmh.MetadataFormat, err error) {
+	datasetIDs := []string{q.DatasetID}
+
+	switch {
+	case q.FirstRequest != nil && q.FirstRequest.Identifier != "":
+		rp, lookupErr := s.lookup(q.OrgID, q.FirstRequest.Identifier)
+		if lookupErr != nil {
+			if errors.Is(lookupErr, ErrRecordNotFound) {
+				return formats, nil
+			}
+
+			return formats, lookupErr
+		}
+
+		datasetIDs = []string{rp.DatasetID}
+	case q.DatasetID == "":
+		datasetIDs, err = s.DatasetIDs(q.OrgID)
+		if err != nil {
+			return formats, err
+		}
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	seen := map[string]bool{}
+
+	for _, datasetID := range datasetIDs {
+		idx, idxErr := s.getIndex(q.OrgID, datasetID, false)
+		if idxErr != nil {
+			if errors.Is(idxErr, ErrDatasetNotFound) {
+				continue
+			}
+
+			return formats, idxErr
+		}
+
+		for _, format := range s.metadataFormats(idx) {
+			if seen[format.MetadataPrefix] || !q.AllowsFormat(datasetID, format.MetadataPrefix) {
+				continue
+			}
+
+			seen[format.MetadataPrefix] = true
+
+			formats = append(formats, format)
+		}
+	}
+
+	return formats, nil
+}
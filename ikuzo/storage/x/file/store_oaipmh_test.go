@@ -0,0 +1,298 @@
+package file
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/delving/hub3/ikuzo/service/x/oaipmh"
+	"github.com/delving/hub3/ikuzo/service/x/sitemap"
+	"github.com/matryer/is"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	t.Cleanup(func() { store.Close() })
+
+	records := []*Record{
+		testRecord("ds1", "1", testTriple("1", "first")),
+		testRecord("ds1", "2", testTriple("2", "second")),
+		testRecord("ds1", "3", testTriple("3", "third")),
+		testRecord("ds2", "1", testTriple("1", "other")),
+	}
+
+	if err := store.Put(records...); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	if err := store.Delete("hub3", "ds1", "hub3_ds1_3"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	return store
+}
+
+func TestStore_OAIPMH(t *testing.T) {
+	store := newTestStore(t)
+	store.ResponseSize = 2
+
+	ctx := context.TODO()
+
+	t.Run("listsets", func(t *testing.T) {
+		is := is.New(t)
+
+		res, err := store.ListSets(ctx, &oaipmh.RequestConfig{OrgID: "hub3"})
+		is.NoErr(err)
+		is.Equal(res.Total, 2)
+		is.Equal(res.Sets[0].SetSpec, "ds1")
+		is.Equal(string(res.Sets[0].SetDescription.Body), "<totalRecords>2</totalRecords>")
+
+		res, err = store.ListSets(ctx, &oaipmh.RequestConfig{OrgID: "unknown"})
+		is.NoErr(err)
+		is.Equal(res.Errors, []oaipmh.Error{oaipmh.ErrNoSetHierachy})
+	})
+
+	t.Run("listidentifiers", func(t *testing.T) {
+		is := is.New(t)
+
+		q := &oaipmh.RequestConfig{
+			ID:           "harvest",
+			OrgID:        "hub3",
+			FirstRequest: &oaipmh.Request{MetadataPrefix: "ntriples"},
+		}
+
+		res, err := store.ListIdentifiers(ctx, q)
+		is.NoErr(err)
+		is.Equal(res.Total, 4)
+		is.Equal(len(res.Headers), 2)
+		is.Equal(res.Headers[0].Identifier, "hub3_ds1_1")
+		is.Equal(res.Headers[0].SetSpec, []string{"ds1"})
+
+		q.CurrentRequest = oaipmh.RawToken{HarvestID: "harvest", Cursor: 2, StorePayload: res.StorePayload}
+
+		res, err = store.ListIdentifiers(ctx, q)
+		is.NoErr(err)
+		is.Equal(len(res.Headers), 2)
+		is.Equal(res.Headers[0].Identifier, "hub3_ds1_3")
+		is.Equal(res.Headers[0].Status, "deleted")
+		is.Equal(res.Headers[1].Identifier, "hub3_ds2_1")
+	})
+
+	t.Run("listidentifiers resumes at the cursor", func(t *testing.T) {
+		is := is.New(t)
+
+		q := &oaipmh.RequestConfig{
+			ID:           "resume",
+			OrgID:        "hub3",
+			FirstRequest: &oaipmh.Request{MetadataPrefix: "ntriples"},
+		}
+
+		ids := []string{}
+
+		for i := 0; i < 4; i++ {
+			res, err := store.ListIdentifiers(ctx, q)
+			is.NoErr(err)
+
+			if len(res.Headers) == 0 {
+				break
+			}
+
+			for _, h := range res.Headers {
+				ids = append(ids, h.Identifier)
+			}
+
+			q.CurrentRequest = oaipmh.RawToken{HarvestID: "resume", Cursor: len(ids), StorePayload: res.StorePayload}
+		}
+
+		is.Equal(ids, []string{"hub3_ds1_1", "hub3_ds1_2", "hub3_ds1_3", "hub3_ds2_1"})
+	})
+
+	t.Run("listidentifiers of an invalid set", func(t *testing.T) {
+		is := is.New(t)
+
+		q := &oaipmh.RequestConfig{
+			OrgID:        "hub3",
+			DatasetID:    "../hub3/ds1",
+			FirstRequest: &oaipmh.Request{MetadataPrefix: "ntriples"},
+		}
+
+		res, err := store.ListIdentifiers(ctx, q)
+		is.NoErr(err)
+		is.Equal(res.Errors, []oaipmh.Error{oaipmh.ErrBadArgument})
+	})
+
+	t.Run("listidentifiers without the sets that disallow the format", func(t *testing.T) {
+		is := is.New(t)
+
+		q := &oaipmh.RequestConfig{
+			OrgID:        "hub3",
+			FirstRequest: &oaipmh.Request{MetadataPrefix: "ntriples"},
+			ExcludedSets: []string{"ds1"},
+		}
+
+		res, err := store.ListIdentifiers(ctx, q)
+		is.NoErr(err)
+		is.Equal(res.Total, 1)
+		is.Equal(len(res.Headers), 1)
+		is.Equal(res.Headers[0].Identifier, "hub3_ds2_1")
+
+		q.ExcludedSets = nil
+		q.IncludedSets = []string{}
+
+		res, err = store.ListIdentifiers(ctx, q)
+		is.NoErr(err)
+		is.Equal(res.Errors, []oaipmh.Error{oaipmh.ErrCannotDisseminateFormat})
+	})
+
+	t.Run("listrecords", func(t *testing.T) {
+		is := is.New(t)
+
+		q := &oaipmh.RequestConfig{
+			OrgID:        "hub3",
+			DatasetID:    "ds2",
+			FirstRequest: &oaipmh.Request{MetadataPrefix: "ntriples"},
+		}
+
+		res, err := store.ListRecords(ctx, q)
+		is.NoErr(err)
+		is.Equal(res.Total, 1)
+		is.Equal(len(res.Records), 1)
+		is.True(strings.HasPrefix(string(res.Records[0].Metadata.Body), "<![CDATA["))
+		is.True(strings.Contains(string(res.Records[0].Metadata.Body), `"other"`))
+
+		q.FirstRequest.MetadataPrefix = "edm"
+
+		res, err = store.ListRecords(ctx, q)
+		is.NoErr(err)
+		is.Equal(len(res.Records), 1)
+		is.True(strings.Contains(string(res.Records[0].Metadata.Body), "<rdf:RDF"))
+		is.True(strings.Contains(string(res.Records[0].Metadata.Body), ">other<"))
+
+		q.FirstRequest.MetadataPrefix = "ead"
+
+		res, err = store.ListRecords(ctx, q)
+		is.NoErr(err)
+		is.Equal(res.Errors, []oaipmh.Error{oaipmh.ErrCannotDisseminateFormat})
+
+		q.FirstRequest = &oaipmh.Request{MetadataPrefix: "ntriples", From: "2000-13-01"}
+
+		res, err = store.ListRecords(ctx, q)
+		is.NoErr(err)
+		is.Equal(res.Errors, []oaipmh.Error{oaipmh.ErrBadArgument})
+
+		q.FirstRequest = &oaipmh.Request{MetadataPrefix: "ntriples", Until: "2000-01-01"}
+
+		res, err = store.ListRecords(ctx, q)
+		is.NoErr(err)
+		is.Equal(len(res.Records), 0)
+	})
+
+	t.Run("getrecord", func(t *testing.T) {
+		is := is.New(t)
+
+		q := &oaipmh.RequestConfig{
+			OrgID:        "hub3",
+			FirstRequest: &oaipmh.Request{MetadataPrefix: "ntriples", Identifier: "hub3_ds1_2"},
+		}
+
+		record, pmhErrors, err := store.GetRecord(ctx, q)
+		is.NoErr(err)
+		is.Equal(len(pmhErrors), 0)
+		is.Equal(record.Header.Identifier, "hub3_ds1_2")
+		is.True(strings.Contains(string(record.Metadata.Body), `"second"`))
+
+		q.FirstRequest.Identifier = "hub3_ds1_3"
+
+		record, pmhErrors, err = store.GetRecord(ctx, q)
+		is.NoErr(err)
+		is.Equal(len(pmhErrors), 0)
+		is.Equal(record.Header.Status, "deleted")
+		is.Equal(len(record.Metadata.Body), 0)
+
+		q.FirstRequest.Identifier = "unknown"
+
+		_, pmhErrors, err = store.GetRecord(ctx, q)
+		is.NoErr(err)
+		is.Equal(pmhErrors, []oaipmh.Error{oaipmh.ErrIdDoesNotExist})
+	})
+
+	t.Run("listmetadataformats", func(t *testing.T) {
+		is := is.New(t)
+
+		formats, err := store.ListMetadataFormats(ctx, &oaipmh.RequestConfig{OrgID: "hub3"})
+		is.NoErr(err)
+		is.Equal(formats, store.Crosswalks.FormatsFor([]string{"mdr"})) // ead only applies to archives
+
+		err = store.SetMetadataFormats("hub3", "ds2", oaipmh.MetadataFormat{MetadataPrefix: "edm"})
+		is.NoErr(err)
+
+		formats, err = store.ListMetadataFormats(ctx, &oaipmh.RequestConfig{OrgID: "hub3", DatasetID: "ds2"})
+		is.NoErr(err)
+		is.Equal(len(formats), 1)
+		is.Equal(formats[0].MetadataNamespace, "http://www.europeana.eu/schemas/edm/")
+	})
+}
+
+func TestStore_OAIPMH_ead(t *testing.T) {
+	is := is.New(t)
+
+	store, err := NewStore(t.TempDir())
+	is.NoErr(err)
+
+	t.Cleanup(func() { store.Close() })
+
+	store.Format = FormatEAD
+
+	is.NoErr(store.Put(testRecord("archive", "1", `<?xml version="1.0"?><ead><eadheader/></ead>`)))
+
+	ctx := context.TODO()
+
+	formats, err := store.ListMetadataFormats(ctx, &oaipmh.RequestConfig{OrgID: "hub3"})
+	is.NoErr(err)
+	is.Equal(len(formats), 1)
+	is.Equal(formats[0].MetadataPrefix, "ead")
+
+	record, pmhErrors, err := store.GetRecord(ctx, &oaipmh.RequestConfig{
+		OrgID:        "hub3",
+		FirstRequest: &oaipmh.Request{MetadataPrefix: "ead", Identifier: "hub3_archive_1"},
+	})
+	is.NoErr(err)
+	is.Equal(len(pmhErrors), 0)
+	is.Equal(string(record.Metadata.Body), "<ead><eadheader/></ead>\n") // without the XML declaration
+}
+
+func TestStore_Sitemap(t *testing.T) {
+	is := is.New(t)
+
+	store := newTestStore(t)
+	ctx := context.TODO()
+	cfg := sitemap.Config{OrgID: "hub3"}
+
+	datasets, err := store.Datasets(ctx, cfg)
+	is.NoErr(err)
+	is.Equal(len(datasets), 2)
+	is.Equal(datasets[0].ID, "ds1")
+	is.Equal(datasets[0].RecordCount, int64(2))
+	is.True(datasets[0].LastMod != nil)
+
+	count, err := store.LocationCount(ctx, cfg)
+	is.NoErr(err)
+	is.Equal(count, 3)
+
+	locations := store.Locations(ctx, cfg, 1, 3)
+	is.Equal(len(locations), 2)
+	is.Equal(locations[0].ID, "ds1/2")
+	is.Equal(locations[1].ID, "ds2/1")
+
+	cfg.ExcludedSpecs = []string{"ds1"}
+
+	count, err = store.LocationCount(ctx, cfg)
+	is.NoErr(err)
+	is.Equal(count, 1)
+}
@@ -0,0 +1,163 @@
+package file
+
+import (
+	"context"
+	"path"
+	"time"
+
+	"github.com/delving/hub3/ikuzo/service/x/sitemap"
+)
+
+var _ sitemap.Store = (*Store)(nil)
+
+// datasetStats contains the number of current records in a dataset, excluding
+// the deleted records, and the latest modification date.
+type datasetStats struct {
+	Records      int64
+	LastModified time.Time
+}
+
+func (s *Store) stats(orgID, datasetID string) (stats datasetStats, err error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	idx, err := s.getIndex(orgID, datasetID, false)
+	if err != nil {
+		return stats, err
+	}
+
+	for _, rp := range idx.live(0) {
+		if rp.Deleted {
+			continue
+		}
+
+		stats.Records++
+
+		if rp.LastModified.After(stats.LastModified) {
+			stats.LastModified = rp.LastModified
+		}
+	}
+
+	return stats, nil
+}
+
+// sitemapDatasets returns the datasets of the organization that are not
+// excluded by the sitemap configuration.
+func (s *Store) sitemapDatasets(cfg sitemap.Config) ([]string, error) {
+	datasetIDs, err := s.DatasetIDs(cfg.OrgID)
+	if err != nil {
+		return nil, err
+	}
+
+	included := []string{}
+
+	for _, datasetID := range datasetIDs {
+		if !cfg.IsExcludedSpec(datasetID) {
+			included = append(included, datasetID)
+		}
+	}
+
+	return included, nil
+}
+
+func (s *Store) Datasets(ctx context.Context, cfg sitemap.Config) ([]sitemap.Location, error) {
+	var locations []sitemap.Location
+
+	datasetIDs, err := s.sitemapDatasets(cfg)
+	if err != nil {
+		return locations, err
+	}
+
+	for _, datasetID := range datasetIDs {
+		stats, err := s.stats(cfg.OrgID, datasetID)
+		if err != nil {
+			return locations, err
+		}
+
+		if stats.Records == 0 {
+			continue
+		}
+
+		lastMod := stats.LastModified
+
+		locations = append(locations, sitemap.Location{
+			ID:          datasetID,
+			LastMod:     &lastMod,
+			RecordCount: stats.Records,
+		})
+	}
+
+	return locations, nil
+}
+
+func (s *Store) LocationCount(ctx context.Context, cfg sitemap.Config) (int, error) {
+	datasetIDs, err := s.sitemapDatasets(cfg)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+
+	for _, datasetID := range datasetIDs {
+		stats, err := s.stats(cfg.OrgID, datasetID)
+		if err != nil {
+			return 0, err
+		}
+
+		count += stats.Records
+	}
+
+	return int(count), nil
+}
+
+// Locations returns the records from start up to end ordered by dataset and
+// revision. The ID of a location is '{datasetID}/{localID}'.
+func (s *Store) Locations(ctx context.Context, cfg sitemap.Config, start, end int) []sitemap.Location {
+	datasetIDs, err := s.sitemapDatasets(cfg)
+	if err != nil {
+		return nil
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	var (
+		locations []sitemap.Location
+		i         int
+	)
+
+	for _, datasetID := range datasetIDs {
+		idx, err := s.getIndex(cfg.OrgID, datasetID, false)
+		if err != nil {
+			return locations
+		}
+
+		for _, rp := range idx.live(0) {
+			if rp.Deleted {
+				continue
+			}
+
+			if i >= end {
+				return locations
+			}
+
+			if i >= start {
+				localID := rp.LocalID
+				if localID == "" {
+					localID = rp.HubID
+				}
+
+				lastMod := rp.LastModified
+
+				locations = append(locations, sitemap.Location{
+					ID:      path.Join(datasetID, localID),
+					LastMod: &lastMod,
+				})
+			}
+
+			i++
+		}
+	}
+
+	return locations
+}
@@ -0,0 +1,182 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func testRecord(datasetID, localID, data string) *Record {
+	return &Record{
+		RecordSeparator: RecordSeparator{
+			OrgID:     "hub3",
+			DatasetID: datasetID,
+			HubID:     fmt.Sprintf("hub3_%s_%s", datasetID, localID),
+			LocalID:   localID,
+			GraphURI:  fmt.Sprintf("http://data.hub3.org/%s/%s/graph", datasetID, localID),
+		},
+		Data: []byte(data),
+	}
+}
+
+func testTriple(localID, title string) string {
+	return fmt.Sprintf("<http://data.hub3.org/%s> <http://purl.org/dc/elements/1.1/title> %q .\n", localID, title)
+}
+
+func iterateIDs(t *testing.T, store *Store, datasetID string, fromRevision int64) []string {
+	t.Helper()
+
+	ids := []string{}
+
+	err := store.Iterate(context.TODO(), "hub3", datasetID, fromRevision, func(rec *Record) error {
+		ids = append(ids, rec.LocalID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Iterate() error = %v", err)
+	}
+
+	return ids
+}
+
+func TestStore(t *testing.T) {
+	is := is.New(t)
+
+	path := t.TempDir()
+
+	store, err := NewStore(path)
+	is.NoErr(err)
+
+	err = store.Put(
+		testRecord("ds1", "1", testTriple("1", "first")),
+		testRecord("ds1", "2", testTriple("2", "second")),
+		testRecord("ds1", "3", testTriple("3", "third")),
+	)
+	is.NoErr(err)
+
+	t.Run("get", func(t *testing.T) {
+		is := is.New(t)
+
+		rec, err := store.Get("hub3", "ds1", "hub3_ds1_2")
+		is.NoErr(err)
+		is.Equal(string(rec.Data), testTriple("2", "second"))
+		is.Equal(rec.Revision, int64(2))
+		is.Equal(rec.GraphURI, "http://data.hub3.org/ds1/2/graph")
+		is.True(rec.ContentHash != "")
+
+		_, err = store.Get("hub3", "ds1", "unknown")
+		is.True(errors.Is(err, ErrRecordNotFound))
+
+		_, err = store.Get("hub3", "unknown", "hub3_ds1_2")
+		is.True(errors.Is(err, ErrDatasetNotFound))
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		is := is.New(t)
+
+		unchanged := testRecord("ds1", "1", testTriple("1", "first"))
+		replaced := testRecord("ds1", "2", testTriple("2", "second edition"))
+
+		err := store.Put(unchanged, replaced)
+		is.NoErr(err)
+		is.Equal(unchanged.Revision, int64(1)) // unchanged content is not appended
+		is.Equal(replaced.Revision, int64(4))
+
+		rec, err := store.Get("hub3", "ds1", "hub3_ds1_2")
+		is.NoErr(err)
+		is.Equal(string(rec.Data), testTriple("2", "second edition"))
+
+		is.Equal(iterateIDs(t, store, "ds1", 0), []string{"1", "3", "2"})
+		is.Equal(iterateIDs(t, store, "ds1", 3), []string{"2"})
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		is := is.New(t)
+
+		err := store.Delete("hub3", "ds1", "hub3_ds1_3", "unknown")
+		is.NoErr(err)
+
+		rec, err := store.Get("hub3", "ds1", "hub3_ds1_3")
+		is.NoErr(err)
+		is.True(rec.Deleted)
+		is.Equal(len(rec.Data), 0)
+		is.Equal(rec.Revision, int64(5))
+
+		revision, err := store.Revision("hub3", "ds1")
+		is.NoErr(err)
+		is.Equal(revision, int64(5))
+	})
+
+	t.Run("compact", func(t *testing.T) {
+		is := is.New(t)
+
+		before, err := os.ReadDir(filepath.Join(path, "hub3", "ds1"))
+		is.NoErr(err)
+
+		err = store.Compact("hub3", "ds1")
+		is.NoErr(err)
+
+		after, err := os.ReadDir(filepath.Join(path, "hub3", "ds1"))
+		is.NoErr(err)
+		is.Equal(len(before), len(after)) // the previous data file is removed
+
+		is.Equal(iterateIDs(t, store, "ds1", 0), []string{"1", "2", "3"})
+
+		rec, err := store.Get("hub3", "ds1", "hub3_ds1_2")
+		is.NoErr(err)
+		is.Equal(string(rec.Data), testTriple("2", "second edition"))
+		is.Equal(rec.Revision, int64(4))
+
+		rec, err = store.Get("hub3", "ds1", "hub3_ds1_3")
+		is.NoErr(err)
+		is.True(rec.Deleted) // tombstones survive compaction
+	})
+
+	t.Run("reopen", func(t *testing.T) {
+		is := is.New(t)
+
+		is.NoErr(store.Close())
+
+		reopened, err := NewStore(path)
+		is.NoErr(err)
+
+		defer reopened.Close()
+
+		rec, err := reopened.Get("hub3", "ds1", "hub3_ds1_1")
+		is.NoErr(err)
+		is.Equal(string(rec.Data), testTriple("1", "first"))
+
+		err = reopened.Put(testRecord("ds1", "4", testTriple("4", "fourth")))
+		is.NoErr(err)
+
+		is.Equal(iterateIDs(t, reopened, "ds1", 4), []string{"3", "4"})
+
+		datasetIDs, err := reopened.DatasetIDs("hub3")
+		is.NoErr(err)
+		is.Equal(datasetIDs, []string{"ds1"})
+	})
+
+	t.Run("invalid record", func(t *testing.T) {
+		is := is.New(t)
+
+		err := store.Put(&Record{Data: []byte("no identifiers")})
+		is.True(errors.Is(err, ErrInvalidRecord))
+	})
+
+	t.Run("invalid dataset name", func(t *testing.T) {
+		for _, datasetID := range []string{"..", ".", "../ds1", "ds1/../..", `ds1\..`, "/tmp"} {
+			is := is.New(t)
+
+			err := store.Put(testRecord(datasetID, "1", testTriple("1", "escaped")))
+			is.True(errors.Is(err, ErrInvalidName))
+
+			_, err = store.Get("hub3", datasetID, "hub3_ds1_1")
+			is.True(errors.Is(err, ErrInvalidName))
+		}
+	})
+}
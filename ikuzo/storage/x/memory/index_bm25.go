@@ -0,0 +1,143 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"math"
+	"strings"
+
+	"github.com/delving/hub3/ikuzo/service/x/search"
+)
+
+// BM25 parameters. k1 controls the term frequency saturation and b the
+// document length normalisation.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// score adds the BM25 score of each matching document to hits.
+//
+// Each non-prohibited query term is scored separately. Wildcard and fuzzy
+// terms are scored as a single term over all their expansions, and phrases
+// by the number of phrase occurrences in the document. The score of a query
+// term is multiplied by its boost.
+//
+// Fields are scored separately with their own length normalisation, and the
+// score of each field is multiplied by the field boost.
+func (ti *TextIndex) score(query *search.QueryTerm, hits *search.Matches) {
+	for _, qt := range scoringTerms(query) {
+		boost := qt.Boost
+		if boost == 0 {
+			boost = 1
+		}
+
+		for _, p := range ti.postings(qt) {
+			ti.scorePostings(p, qt, boost*ti.fieldBoost(p.field), hits)
+		}
+	}
+}
+
+func (ti *TextIndex) scorePostings(p postings, qt *search.QueryTerm, boost float64, hits *search.Matches) {
+	if len(p.lengths) == 0 {
+		return
+	}
+
+	termHits := search.NewMatches()
+	if !p.match(qt, termHits) {
+		return
+	}
+
+	var total int
+	for _, l := range p.lengths {
+		total += l
+	}
+
+	avgLength := float64(total) / float64(len(p.lengths))
+	docCount := float64(len(p.lengths))
+
+	freqs := termFrequencies(termHits.Vectors())
+
+	if qt.Phrase {
+		size := len(strings.Fields(qt.Value))
+		for docID, freq := range freqs {
+			freqs[docID] = freq / size
+		}
+	}
+
+	df := float64(len(freqs))
+	idf := math.Log(1 + (docCount-df+0.5)/(df+0.5))
+
+	for docID, freq := range freqs {
+		if freq == 0 || !hits.HasDocID(docID) {
+			continue
+		}
+
+		tf := float64(freq)
+		norm := 1 - bm25B + bm25B*float64(p.lengths[docID])/avgLength
+
+		hits.AddScore(docID, boost*idf*(tf*(bm25K1+1))/(tf+bm25K1*norm))
+	}
+}
+
+// scoringTerms returns the required and optional leaf terms of the query.
+func scoringTerms(query *search.QueryTerm) []*search.QueryTerm {
+	terms := []*search.QueryTerm{}
+
+	if !query.IsBoolQuery() {
+		if !query.Prohibited && query.Value != "" {
+			terms = append(terms, query)
+		}
+
+		return terms
+	}
+
+	for _, clauses := range [][]*search.QueryTerm{query.Must(), query.Should()} {
+		for _, qt := range clauses {
+			terms = append(terms, scoringTerms(qt)...)
+		}
+	}
+
+	return terms
+}
+
+// termFrequencies counts the matched positions per document.
+func termFrequencies(vectors *search.Vectors) map[int]int {
+	freqs := map[int]int{}
+
+	for v := range vectors.Locations {
+		freqs[v.DocID]++
+	}
+
+	return freqs
+}
+
+// docLengths returns the number of terms per document. Indexes that were
+// encoded before DocLengths was added are counted from the term vectors.
+func (ti *TextIndex) docLengths() map[int]int {
+	if len(ti.DocLengths) != 0 || len(ti.Terms) == 0 {
+		return ti.DocLengths
+	}
+
+	lengths := map[int]int{}
+
+	for _, tv := range ti.Terms {
+		for v := range tv.Locations {
+			lengths[v.DocID]++
+		}
+	}
+
+	return lengths
+}
@@ -0,0 +1,132 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// nolint:gocritic
+package memory
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/delving/hub3/ikuzo/service/x/search"
+	"github.com/google/go-cmp/cmp"
+	"github.com/matryer/is"
+)
+
+func rankedIDs(hits *search.Matches) []int {
+	ids := []int{}
+	for _, r := range hits.Ranked() {
+		ids = append(ids, r.DocID)
+	}
+
+	return ids
+}
+
+func TestTextIndex_score(t *testing.T) {
+	docs := []string{
+		"de archieven van de compagnie en de archieven van de kamer",
+		"de archieven",
+		"een lange beschrijving van de kamer met de archieven en met veel andere woorden",
+		"de compagnie",
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []int
+	}{
+		{
+			"term frequency and length normalisation",
+			"archieven",
+			[]int{2, 1, 3},
+		},
+		{
+			"rare terms weigh more",
+			"archieven compagnie",
+			[]int{1, 4, 2, 3},
+		},
+		{
+			"boost",
+			"archieven compagnie^5",
+			[]int{4, 1, 2, 3},
+		},
+		{
+			"phrase",
+			"\"de kamer\"",
+			[]int{1, 3},
+		},
+		{
+			"prohibited terms are not scored",
+			"kamer -ontbreekt",
+			[]int{1, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+
+			ti := NewTextIndex()
+			for _, doc := range docs {
+				is.NoErr(ti.AppendString(doc))
+			}
+
+			qp, err := search.NewQueryParser()
+			is.NoErr(err)
+
+			query, err := qp.Parse(tt.query)
+			is.NoErr(err)
+
+			hits, err := ti.Search(query)
+			is.NoErr(err)
+
+			if diff := cmp.Diff(tt.want, rankedIDs(hits)); diff != "" {
+				t.Errorf("TextIndex.Search() %s ranking mismatch (-want +got):\n%s", tt.name, diff)
+			}
+
+			for _, r := range hits.Ranked() {
+				is.True(r.Score > 0) // matching documents have a positive score
+			}
+		})
+	}
+}
+
+func TestTextIndex_scoreWithoutDocLengths(t *testing.T) {
+	is := is.New(t)
+
+	ti := NewTextIndex()
+	is.NoErr(ti.AppendString("one two three"))
+	is.NoErr(ti.AppendString("one"))
+
+	var buf bytes.Buffer
+	is.NoErr(ti.Encode(&buf))
+
+	decoded, err := DecodeTextIndex(&buf)
+	is.NoErr(err)
+
+	// indexes that were written before the document lengths were stored
+	decoded.DocLengths = nil
+
+	qp, err := search.NewQueryParser()
+	is.NoErr(err)
+
+	query, err := qp.Parse("one")
+	is.NoErr(err)
+
+	hits, err := decoded.Search(query)
+	is.NoErr(err)
+	is.Equal(rankedIDs(hits), []int{2, 1})
+}
@@ -0,0 +1,153 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/delving/hub3/ikuzo/service/x/search"
+)
+
+// postings is the inverted index of a single field. The text that is added
+// without a field is stored in the postings with an empty field name.
+type postings struct {
+	field   string
+	terms   map[string]*search.Vectors
+	lengths map[int]int
+}
+
+// key returns the name of the term in the search.Matches. Terms of a field are
+// prefixed with the field name, e.g. 'unittitle:archief'.
+func (p postings) key(term string) string {
+	if p.field == "" {
+		return term
+	}
+
+	return p.field + string(search.FieldOperator) + term
+}
+
+// AppendField adds the text as the field of the document. Field names are
+// case-insensitive. The positions of the terms start at 1 for each field, so
+// each field of a document can be highlighted on its own.
+//
+// When docID is 0 a new document is created.
+func (ti *TextIndex) AppendField(field, text string, docID int) error {
+	if field == "" {
+		return fmt.Errorf("field name cannot be empty")
+	}
+
+	field = strings.ToLower(field)
+	id := ti.setDocID(docID)
+
+	if ti.Fields == nil {
+		ti.Fields = make(map[string]map[string]*search.Vectors)
+	}
+
+	if ti.FieldLengths == nil {
+		ti.FieldLengths = make(map[string]map[int]int)
+	}
+
+	if _, ok := ti.Fields[field]; !ok {
+		ti.Fields[field] = make(map[string]*search.Vectors)
+		ti.FieldLengths[field] = make(map[int]int)
+	}
+
+	for _, token := range ti.a.Tokenize(text, id).Tokens() {
+		if token.Ignored {
+			continue
+		}
+
+		if token.RawText == "" {
+			return fmt.Errorf("cannot index empty string")
+		}
+
+		analyzedTerm := ti.a.Transform(token.RawText)
+		if analyzedTerm == "" {
+			continue
+		}
+
+		tv, ok := ti.Fields[field][analyzedTerm]
+		if !ok {
+			tv = search.NewVectors()
+			ti.Fields[field][analyzedTerm] = tv
+		}
+
+		tv.Add(id, token.TermVector)
+		ti.FieldLengths[field][id]++
+	}
+
+	return nil
+}
+
+// SetDefaultFields sets the fields that are searched by query terms without a
+// field, typically the fields set with search.SetFields on the QueryParser.
+// When no default fields are set only the text without a field is searched.
+func (ti *TextIndex) SetDefaultFields(fields ...string) {
+	ti.DefaultFields = fields
+}
+
+// SetFieldBoost sets the factor by which the relevance score of matches in the
+// field is multiplied.
+func (ti *TextIndex) SetFieldBoost(field string, boost float64) {
+	if ti.FieldBoosts == nil {
+		ti.FieldBoosts = make(map[string]float64)
+	}
+
+	ti.FieldBoosts[strings.ToLower(field)] = boost
+}
+
+func (ti *TextIndex) fieldBoost(field string) float64 {
+	boost, ok := ti.FieldBoosts[field]
+	if !ok {
+		return 1
+	}
+
+	return boost
+}
+
+// fieldPostings returns the postings of the field. An empty field returns the
+// postings of the text without a field.
+func (ti *TextIndex) fieldPostings(field string) postings {
+	if field == "" {
+		return postings{terms: ti.Terms, lengths: ti.docLengths()}
+	}
+
+	field = strings.ToLower(field)
+
+	return postings{
+		field:   field,
+		terms:   ti.Fields[field],
+		lengths: ti.FieldLengths[field],
+	}
+}
+
+// postings returns the postings that the query term is matched against.
+func (ti *TextIndex) postings(qt *search.QueryTerm) []postings {
+	if qt.Field != "" {
+		return []postings{ti.fieldPostings(qt.Field)}
+	}
+
+	if len(ti.DefaultFields) == 0 {
+		return []postings{ti.fieldPostings("")}
+	}
+
+	all := make([]postings, 0, len(ti.DefaultFields))
+	for _, field := range ti.DefaultFields {
+		all = append(all, ti.fieldPostings(field))
+	}
+
+	return all
+}
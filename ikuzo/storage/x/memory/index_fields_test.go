@@ -0,0 +1,175 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// nolint:gocritic
+package memory
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/delving/hub3/ikuzo/service/x/search"
+	"github.com/google/go-cmp/cmp"
+	"github.com/matryer/is"
+)
+
+func newFieldIndex(t *testing.T) *TextIndex {
+	t.Helper()
+
+	docs := []map[string]string{
+		{"unittitle": "Archief van Jansen", "scopecontent": "brieven en notulen"},
+		{"unittitle": "Notulen van de raad", "persname": "Pieter Jansen"},
+		{"unittitle": "Kaarten", "scopecontent": "kaarten van de Jansen polder"},
+	}
+
+	ti := NewTextIndex()
+
+	for idx, doc := range docs {
+		for field, text := range doc {
+			if err := ti.AppendField(field, text, idx+1); err != nil {
+				t.Fatalf("unable to append field %s: %s", field, err)
+			}
+		}
+	}
+
+	return ti
+}
+
+func TestTextIndex_fields(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		defaultFields []string
+		boosts        map[string]float64
+		want          []int
+		wantTerms     map[string]int
+		wantErr       error
+	}{
+		{
+			"field query",
+			"persname:jansen",
+			nil,
+			nil,
+			[]int{2},
+			map[string]int{"persname:jansen": 1},
+			nil,
+		},
+		{
+			"field names are case-insensitive",
+			"UnitTitle:notulen",
+			nil,
+			nil,
+			[]int{2},
+			map[string]int{"unittitle:notulen": 1},
+			nil,
+		},
+		{
+			"unknown field",
+			"title:jansen",
+			nil,
+			nil,
+			[]int{},
+			map[string]int{},
+			ErrSearchNoMatch,
+		},
+		{
+			"without default fields only the text without a field is searched",
+			"jansen",
+			nil,
+			nil,
+			[]int{},
+			map[string]int{},
+			ErrSearchNoMatch,
+		},
+		{
+			"default fields",
+			"notulen",
+			[]string{"unittitle", "scopecontent"},
+			nil,
+			[]int{2, 1},
+			map[string]int{"unittitle:notulen": 1, "scopecontent:notulen": 1},
+			nil,
+		},
+		{
+			"field boost",
+			"jansen",
+			[]string{"unittitle", "persname", "scopecontent"},
+			map[string]float64{"persname": 10},
+			[]int{2, 1, 3},
+			map[string]int{"unittitle:jansen": 1, "persname:jansen": 1, "scopecontent:jansen": 1},
+			nil,
+		},
+		{
+			"phrase in field",
+			"scopecontent:\"jansen polder\"",
+			nil,
+			nil,
+			[]int{3},
+			map[string]int{"scopecontent:jansen polder": 1},
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+
+			ti := newFieldIndex(t)
+
+			qp, err := search.NewQueryParser(search.SetFields(tt.defaultFields...))
+			is.NoErr(err)
+
+			ti.SetDefaultFields(qp.Fields()...)
+
+			for field, boost := range tt.boosts {
+				ti.SetFieldBoost(field, boost)
+			}
+
+			query, err := qp.Parse(tt.query)
+			is.NoErr(err)
+
+			hits, err := ti.Search(query)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("TextIndex.Search() %s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+
+			if diff := cmp.Diff(tt.want, rankedIDs(hits)); diff != "" {
+				t.Errorf("TextIndex.Search() %s ranking mismatch (-want +got):\n%s", tt.name, diff)
+			}
+
+			if diff := cmp.Diff(tt.wantTerms, hits.TermFrequency()); diff != "" {
+				t.Errorf("TextIndex.Search() %s terms mismatch (-want +got):\n%s", tt.name, diff)
+			}
+		})
+	}
+}
+
+func TestTextIndex_AppendField(t *testing.T) {
+	is := is.New(t)
+
+	ti := NewTextIndex()
+
+	err := ti.AppendField("", "text", 1)
+	is.True(err != nil) // empty field names are not allowed
+
+	is.NoErr(ti.AppendField("title", "een twee", 0))
+	is.NoErr(ti.AppendField("body", "drie", 1))
+
+	is.Equal(ti.DocCount, 1)
+	is.Equal(ti.FieldLengths["title"][1], 2)
+	is.Equal(ti.FieldLengths["body"][1], 1)
+	is.Equal(ti.size(), 0) // fields are not added to the text without a field
+}
@@ -38,18 +38,52 @@ type TextIndex struct {
 	a        search.Analyzer
 	DocCount int
 	Docs     map[int]bool
+	// DocLengths contains the number of indexed terms per document.
+	DocLengths map[int]int
+	// Fields contains the postings per field of multi-field documents.
+	Fields map[string]map[string]*search.Vectors
+	// FieldLengths contains the number of indexed terms per field and document.
+	FieldLengths  map[string]map[int]int
+	FieldBoosts   map[string]float64
+	DefaultFields []string
+	// Language selects the stopwords and stemmer of the analyzer.
+	Language search.Language
 }
 
 func NewTextIndex() *TextIndex {
 	return &TextIndex{
-		Terms: make(map[string]*search.Vectors),
-		Docs:  make(map[int]bool),
+		Terms:      make(map[string]*search.Vectors),
+		Docs:       make(map[int]bool),
+		DocLengths: make(map[int]int),
 	}
 }
 
+// SetLanguage sets the language of the analyzer of the index. It must be set
+// before text is appended. See search.ParseLanguage for the supported values.
+func (ti *TextIndex) SetLanguage(lang string) error {
+	a, err := search.NewAnalyzer(search.SetLanguage(lang))
+	if err != nil {
+		return err
+	}
+
+	ti.a = *a
+	ti.Language = a.Language()
+
+	return nil
+}
+
+// Analyzer returns the analyzer of the index. Queries must be parsed with the
+// same analyzer, see search.SetAnalyzer.
+func (ti *TextIndex) Analyzer() *search.Analyzer {
+	return &ti.a
+}
+
 func (ti *TextIndex) reset() {
 	ti.Terms = make(map[string]*search.Vectors)
 	ti.Docs = make(map[int]bool)
+	ti.DocLengths = make(map[int]int)
+	ti.Fields = nil
+	ti.FieldLengths = nil
 	ti.DocCount = 0
 }
 
@@ -84,8 +118,7 @@ func (ti *TextIndex) hasDocID(docID int) bool {
 func (ti *TextIndex) AppendBytes(b []byte, docID ...int) error {
 	id := ti.setDocID(docID...)
 
-	tok := search.NewTokenizer()
-	for _, token := range tok.ParseBytes(b, id).Tokens() {
+	for _, token := range ti.a.Tokenize(string(b), id).Tokens() {
 		if !token.Ignored {
 			err := ti.addTerm(token.Normal, token.TermVector)
 			if err != nil {
@@ -101,8 +134,7 @@ func (ti *TextIndex) AppendBytes(b []byte, docID ...int) error {
 func (ti *TextIndex) AppendString(text string, docID ...int) error {
 	id := ti.setDocID(docID...)
 
-	tok := search.NewTokenizer()
-	for _, token := range tok.ParseString(text, id).Tokens() {
+	for _, token := range ti.a.Tokenize(text, id).Tokens() {
 		if !token.Ignored {
 			err := ti.addTerm(token.RawText, token.TermVector)
 			if err != nil {
@@ -126,6 +158,12 @@ func (ti *TextIndex) setTermVector(term string, pos int) {
 	}
 
 	tv.Add(ti.DocCount, pos)
+
+	if ti.DocLengths == nil {
+		ti.DocLengths = make(map[int]int)
+	}
+
+	ti.DocLengths[ti.DocCount]++
 }
 
 func (ti *TextIndex) addTerm(word string, pos int) error {
@@ -152,6 +190,8 @@ func (ti *TextIndex) match(qt *search.QueryTerm, hits *search.Matches) bool {
 		return ti.matchPhrase(qt, hits)
 	case search.FuzzyQuery:
 		return ti.matchFuzzy(qt, hits)
+	case search.RangeQuery:
+		return ti.matchRange(qt, hits)
 	default:
 		// search.TermQuery is the default
 		return ti.matchTerm(qt, hits)
@@ -159,6 +199,10 @@ func (ti *TextIndex) match(qt *search.QueryTerm, hits *search.Matches) bool {
 }
 
 func (ti *TextIndex) matchPhrase(qt *search.QueryTerm, hits *search.Matches) bool {
+	return ti.matchEach(qt, hits, postings.matchPhrase)
+}
+
+func (p postings) matchPhrase(qt *search.QueryTerm, hits *search.Matches) bool {
 	var nextVectors map[search.Vector]bool
 
 	phrasePositions := map[search.Vector]string{}
@@ -166,12 +210,12 @@ func (ti *TextIndex) matchPhrase(qt *search.QueryTerm, hits *search.Matches) boo
 	words := strings.Fields(qt.Value)
 
 	if len(words) == 1 {
-		term, ok := ti.Terms[qt.Value]
+		term, ok := p.terms[qt.Value]
 		if !ok {
 			return false
 		}
 
-		hits.AppendTerm(qt.Value, term)
+		hits.AppendTerm(p.key(qt.Value), term)
 
 		return true
 	}
@@ -179,7 +223,7 @@ func (ti *TextIndex) matchPhrase(qt *search.QueryTerm, hits *search.Matches) boo
 	var previousTerm string
 
 	for idx, word := range words {
-		term, ok := ti.Terms[word]
+		term, ok := p.terms[word]
 		if !ok {
 			return false
 		}
@@ -220,7 +264,7 @@ func (ti *TextIndex) matchPhrase(qt *search.QueryTerm, hits *search.Matches) boo
 
 	if matches != 0 {
 		for term, vectors := range sortAndCountPhrases(words, phrasePositions) {
-			hits.AppendTerm(term, vectors)
+			hits.AppendTerm(p.key(term), vectors)
 		}
 	}
 
@@ -281,14 +325,18 @@ func sortAndCountPhrases(words []string, phrases map[search.Vector]string) map[s
 }
 
 func (ti *TextIndex) matchFuzzy(qt *search.QueryTerm, hits *search.Matches) bool {
+	return ti.matchEach(qt, hits, postings.matchFuzzy)
+}
+
+func (p postings) matchFuzzy(qt *search.QueryTerm, hits *search.Matches) bool {
 	var hasMatch bool
 
-	for k, tv := range ti.Terms {
+	for k, tv := range p.terms {
 		ok, _ := search.IsFuzzyMatch(k, qt.Value, float64(qt.Fuzzy), search.Levenshtein)
 		if ok {
 			hasMatch = true
 
-			hits.AppendTerm(k, tv)
+			hits.AppendTerm(p.key(k), tv)
 		}
 	}
 
@@ -296,6 +344,10 @@ func (ti *TextIndex) matchFuzzy(qt *search.QueryTerm, hits *search.Matches) bool
 }
 
 func (ti *TextIndex) matchWildcard(qt *search.QueryTerm, hits *search.Matches) bool {
+	return ti.matchEach(qt, hits, postings.matchWildcard)
+}
+
+func (p postings) matchWildcard(qt *search.QueryTerm, hits *search.Matches) bool {
 	var matcher func(s, prefix string) bool
 
 	switch {
@@ -307,45 +359,92 @@ func (ti *TextIndex) matchWildcard(qt *search.QueryTerm, hits *search.Matches) b
 
 	var hasMatch bool
 
-	for k, tv := range ti.Terms {
+	for k, tv := range p.terms {
 		if matcher(k, qt.Value) {
 			hasMatch = true
 
-			hits.AppendTerm(k, tv)
+			hits.AppendTerm(p.key(k), tv)
 		}
 	}
 
 	return hasMatch
 }
 
+// matchTerm matches the term in all the postings of the query term. A
+// prohibited term matches when it is not found in any of them.
 func (ti *TextIndex) matchTerm(qt *search.QueryTerm, hits *search.Matches) bool {
-	term, ok := ti.Terms[qt.Value]
-	if ok && qt.Prohibited {
-		return false
+	if !qt.Prohibited {
+		return ti.matchEach(qt, hits, postings.matchTerm)
 	}
 
-	if !ok && !qt.Prohibited {
-		return false
+	for _, p := range ti.postings(qt) {
+		if _, ok := p.terms[qt.Value]; ok {
+			return false
+		}
 	}
 
-	if term == nil {
-		term = search.NewVectors()
+	return true
+}
+
+func (p postings) matchTerm(qt *search.QueryTerm, hits *search.Matches) bool {
+	term, ok := p.terms[qt.Value]
+	if !ok {
+		return false
 	}
 
-	hits.AppendTerm(qt.Value, term)
+	hits.AppendTerm(p.key(qt.Value), term)
 
 	return true
 }
 
+// match matches the query term against a single postings.
+func (p postings) match(qt *search.QueryTerm, hits *search.Matches) bool {
+	switch qt.Type() {
+	case search.WildCardQuery:
+		return p.matchWildcard(qt, hits)
+	case search.PhraseQuery:
+		return p.matchPhrase(qt, hits)
+	case search.FuzzyQuery:
+		return p.matchFuzzy(qt, hits)
+	case search.RangeQuery:
+		return p.matchRange(qt, hits)
+	default:
+		return p.matchTerm(qt, hits)
+	}
+}
+
+// matchEach applies fn to each postings of the query term. It returns true when
+// any of them matches.
+func (ti *TextIndex) matchEach(
+	qt *search.QueryTerm,
+	hits *search.Matches,
+	fn func(p postings, qt *search.QueryTerm, hits *search.Matches) bool,
+) bool {
+	var hasMatch bool
+
+	for _, p := range ti.postings(qt) {
+		if fn(p, qt, hits) {
+			hasMatch = true
+		}
+	}
+
+	return hasMatch
+}
+
 func (ti *TextIndex) Search(query *search.QueryTerm) (*search.Matches, error) {
 	hits := search.NewMatches()
-	err := ti.search(query, hits)
 
-	if errors.Is(err, ErrSearchNoMatch) {
-		hits.Reset()
+	if err := ti.search(query, hits); err != nil {
+		if errors.Is(err, ErrSearchNoMatch) {
+			hits.Reset()
+		}
+
+		return hits, err
 	}
 
-	return hits, err
+	ti.score(query, hits)
+
+	return hits, nil
 }
 
 func (ti *TextIndex) searchMustNot(query *search.QueryTerm, hits *search.Matches) error {
@@ -454,5 +553,9 @@ func DecodeTextIndex(r io.Reader) (*TextIndex, error) {
 		return nil, err
 	}
 
+	if err := ti.SetLanguage(string(ti.Language)); err != nil {
+		return nil, err
+	}
+
 	return &ti, nil
 }
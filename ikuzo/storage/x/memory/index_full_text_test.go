@@ -798,7 +798,7 @@ func TestTextIndexSerialization(t *testing.T) {
 	newTi, err := DecodeTextIndex(&buf)
 	is.NoErr(err)
 
-	if diff := cmp.Diff(ti, newTi, cmp.AllowUnexported(TextIndex{}, search.Vector{})); diff != "" {
+	if diff := cmp.Diff(ti, newTi, cmp.AllowUnexported(TextIndex{}, search.Vector{}, search.Analyzer{})); diff != "" {
 		t.Errorf("TextIndex serialization = mismatch (-want +got):\n%s", diff)
 	}
 }
@@ -0,0 +1,87 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// nolint:gocritic
+package memory
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/delving/hub3/ikuzo/service/x/search"
+	"github.com/google/go-cmp/cmp"
+	"github.com/matryer/is"
+)
+
+func TestTextIndex_SetLanguage(t *testing.T) {
+	is := is.New(t)
+
+	ti := NewTextIndex()
+
+	err := ti.SetLanguage("klingon")
+	is.True(errors.Is(err, search.ErrUnknownLanguage))
+
+	is.NoErr(ti.SetLanguage("nl"))
+	is.NoErr(ti.AppendString("De boerderijen van de familie"))
+	is.NoErr(ti.AppendString("Twee oude boerderijen"))
+	is.NoErr(ti.AppendField("unittitle", "Kaarten van boerderijen", 1))
+
+	_, ok := ti.Terms["van"]
+	is.True(!ok) // stopwords are not indexed
+
+	// the language is restored when the index is decoded
+	var buf bytes.Buffer
+	is.NoErr(ti.Encode(&buf))
+
+	decoded, err := DecodeTextIndex(&buf)
+	is.NoErr(err)
+	is.Equal(decoded.Language, search.Dutch)
+
+	tests := []struct {
+		name  string
+		query string
+		want  []int
+	}{
+		{"plural finds singular", "boerderijen", []int{1, 2}},
+		{"singular finds plural", "boerderij", []int{1, 2}},
+		{"field query", "unittitle:kaart", []int{1}},
+		{"stemmed phrase", `"oude boerderij"`, []int{2}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+
+			qp, err := search.NewQueryParser(search.SetAnalyzer(decoded.Analyzer()))
+			is.NoErr(err)
+
+			query, err := qp.Parse(tt.query)
+			is.NoErr(err)
+
+			hits, err := decoded.Search(query)
+			is.NoErr(err)
+
+			got := rankedIDs(hits)
+			sort.Ints(got)
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("TextIndex.Search() %s mismatch (-want +got):\n%s", tt.name, diff)
+			}
+		})
+	}
+}
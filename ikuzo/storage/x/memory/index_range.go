@@ -0,0 +1,115 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/delving/hub3/ikuzo/service/x/search"
+)
+
+// matchRange matches the terms that fall within the range of the query term.
+// A prohibited range matches when none of the terms fall within the range.
+func (ti *TextIndex) matchRange(qt *search.QueryTerm, hits *search.Matches) bool {
+	if !qt.Prohibited {
+		return ti.matchEach(qt, hits, postings.matchRange)
+	}
+
+	now := time.Now()
+
+	for _, p := range ti.postings(qt) {
+		for k := range p.terms {
+			if inRange(qt.Range, k, now) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func (p postings) matchRange(qt *search.QueryTerm, hits *search.Matches) bool {
+	var hasMatch bool
+
+	now := time.Now()
+
+	for k, tv := range p.terms {
+		if inRange(qt.Range, k, now) {
+			hasMatch = true
+
+			hits.AppendTerm(p.key(k), tv)
+		}
+	}
+
+	return hasMatch
+}
+
+// inRange returns true when the term falls within both bounds of the range.
+func inRange(r *search.Range, term string, now time.Time) bool {
+	if r.Lower != "" {
+		cmp, ok := compareBound(term, r.Lower, now)
+		if !ok || cmp < 0 || (cmp == 0 && !r.IncludeLower) {
+			return false
+		}
+	}
+
+	if r.Upper != "" {
+		cmp, ok := compareBound(term, r.Upper, now)
+		if !ok || cmp > 0 || (cmp == 0 && !r.IncludeUpper) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// compareBound compares the term with the bound of a range. Numbers are
+// compared numerically and dates chronologically, where the bound can be date
+// math such as 'now-10y'. Other terms are compared lexically. It returns false
+// when a number or date bound is compared with a term of another kind.
+func compareBound(term, bound string, now time.Time) (int, bool) {
+	if b, err := strconv.ParseFloat(bound, 64); err == nil {
+		t, err := strconv.ParseFloat(term, 64)
+		if err != nil {
+			return 0, false
+		}
+
+		return compareFloat(t, b), true
+	}
+
+	if b, err := search.ParseDateMath(bound, now); err == nil {
+		t, err := search.ParseDate(term)
+		if err != nil {
+			return 0, false
+		}
+
+		return compareFloat(float64(t.Sub(b)), 0), true
+	}
+
+	return strings.Compare(term, strings.ToLower(bound)), true
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
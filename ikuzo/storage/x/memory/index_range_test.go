@@ -0,0 +1,88 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// nolint:gocritic
+package memory
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/delving/hub3/ikuzo/service/x/search"
+	"github.com/google/go-cmp/cmp"
+	"github.com/matryer/is"
+)
+
+func TestTextIndex_matchRange(t *testing.T) {
+	recent := strconv.Itoa(time.Now().Year() - 2)
+
+	docs := []map[string]string{
+		{"year": "1900", "unittitle": "archief"},
+		{"year": "1950", "unittitle": "brieven"},
+		{"year": recent, "unittitle": "kaarten"},
+	}
+
+	tests := []struct {
+		name    string
+		query   string
+		want    []int
+		wantErr error
+	}{
+		{"inclusive range", "year:[1900 TO 1950]", []int{1, 2}, nil},
+		{"exclusive range", "year:{1900 TO 1950}", []int{}, ErrSearchNoMatch},
+		{"unbounded upper", "year:{1900 TO *]", []int{2, 3}, nil},
+		{"greater than or equal", "year:>=1950", []int{2, 3}, nil},
+		{"less than", "year:<1950", []int{1}, nil},
+		{"date math", "year:>=now-10y", []int{3}, nil},
+		{"lexical range", "unittitle:[b TO c]", []int{2}, nil},
+		{"range or term", "year:[1900 TO 1950] brieven", []int{2, 1}, nil},
+		{"prohibited range", "archief AND -year:>2100", []int{1}, nil},
+		{"prohibited range matches", "archief AND -year:>1900", []int{}, ErrSearchNoMatch},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+
+			ti := NewTextIndex()
+
+			for idx, doc := range docs {
+				for field, text := range doc {
+					is.NoErr(ti.AppendField(field, text, idx+1))
+				}
+			}
+
+			ti.SetDefaultFields("unittitle")
+
+			qp, err := search.NewQueryParser()
+			is.NoErr(err)
+
+			query, err := qp.Parse(tt.query)
+			is.NoErr(err)
+
+			hits, err := ti.Search(query)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("TextIndex.Search() %s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+
+			if diff := cmp.Diff(tt.want, rankedIDs(hits)); diff != "" {
+				t.Errorf("TextIndex.Search() %s mismatch (-want +got):\n%s", tt.name, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,457 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/delving/hub3/ikuzo/domain"
+	isearch "github.com/delving/hub3/ikuzo/search"
+	"github.com/delving/hub3/ikuzo/service/x/search"
+)
+
+var _ isearch.Searcher = (*Searcher)(nil)
+
+// Searcher is an in-memory implementation of the search.Searcher in the
+// ikuzo/search package. It is meant for tests and small installations that
+// run without ElasticSearch.
+//
+// Records are added as v2 search records with AddRecord. The values of the
+// resource entries are indexed per SearchLabel and as full-text, with the
+// analyzer of the language of the organization.
+type Searcher struct {
+	rw      sync.RWMutex
+	orgs    map[string]*recordIndex
+	configs domain.OrgConfigRetriever
+}
+
+// SearcherOption configures the Searcher.
+type SearcherOption func(s *Searcher)
+
+// SetOrgConfigRetriever sets the configuration of the organizations, which
+// selects the language of the analyzer per organization. Without it the
+// text is only lowercased and folded to ASCII.
+func SetOrgConfigRetriever(configs domain.OrgConfigRetriever) SearcherOption {
+	return func(s *Searcher) {
+		s.configs = configs
+	}
+}
+
+// recordIndex contains the records of a single organization.
+type recordIndex struct {
+	ti     *TextIndex
+	hits   map[int]*isearch.Hit
+	docIDs map[string]int
+	lastID int
+}
+
+func NewSearcher(options ...SearcherOption) *Searcher {
+	s := &Searcher{
+		orgs: map[string]*recordIndex{},
+	}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
+
+// AddRecord decodes the v2 search record and adds it to the index of the
+// organization.
+func (s *Searcher) AddRecord(orgID string, source []byte) error {
+	hit, err := isearch.DecodeHit(source)
+	if err != nil {
+		return err
+	}
+
+	hit.OrgID = orgID
+
+	return s.Add(hit)
+}
+
+// TrainRecord implements the index.RecordTrainer, so the Searcher can be
+// updated by the index service.
+func (s *Searcher) TrainRecord(orgID string, source []byte) error {
+	return s.AddRecord(orgID, source)
+}
+
+// Add adds the hit to the index of its organization. A hit with the same ID
+// replaces the previous version.
+func (s *Searcher) Add(hit *isearch.Hit) error {
+	if hit.OrgID == "" || hit.ID == "" {
+		return fmt.Errorf("orgID and ID are required to index a record")
+	}
+
+	s.rw.Lock()
+	defer s.rw.Unlock()
+
+	idx, ok := s.orgs[hit.OrgID]
+	if !ok {
+		ti := NewTextIndex()
+
+		if s.configs != nil {
+			if cfg, ok := s.configs.RetrieveConfig(hit.OrgID); ok {
+				if err := ti.SetLanguage(cfg.Analyzer.Language); err != nil {
+					return fmt.Errorf("unable to set the language of %s; %w", hit.OrgID, err)
+				}
+			}
+		}
+
+		idx = &recordIndex{
+			ti:     ti,
+			hits:   map[int]*isearch.Hit{},
+			docIDs: map[string]int{},
+		}
+		s.orgs[hit.OrgID] = idx
+	}
+
+	// the TextIndex cannot remove terms, so the previous version is only
+	// removed from the hits
+	idx.delete(hit.ID)
+
+	idx.lastID++
+	docID := idx.lastID
+
+	fullText := []string{}
+
+	for field, values := range hit.Fields {
+		for _, value := range values {
+			if err := idx.ti.AppendField(field, value, docID); err != nil {
+				return err
+			}
+
+			fullText = append(fullText, value)
+		}
+	}
+
+	if len(fullText) != 0 {
+		if err := idx.ti.AppendString(strings.Join(fullText, " "), docID); err != nil {
+			return err
+		}
+	}
+
+	idx.hits[docID] = hit
+	idx.docIDs[hit.ID] = docID
+
+	return nil
+}
+
+// Delete removes the record from the index of the organization.
+func (s *Searcher) Delete(orgID, id string) {
+	s.rw.Lock()
+	defer s.rw.Unlock()
+
+	if idx, ok := s.orgs[orgID]; ok {
+		idx.delete(id)
+	}
+}
+
+func (idx *recordIndex) delete(id string) {
+	if docID, ok := idx.docIDs[id]; ok {
+		delete(idx.hits, docID)
+		delete(idx.docIDs, id)
+	}
+}
+
+// Len returns the number of records of the organization.
+func (s *Searcher) Len(orgID string) int {
+	s.rw.RLock()
+	defer s.rw.RUnlock()
+
+	idx, ok := s.orgs[orgID]
+	if !ok {
+		return 0
+	}
+
+	return len(idx.hits)
+}
+
+// Search returns the records of the organization that match the query and
+// filters of the request, ordered by descending score.
+func (s *Searcher) Search(ctx context.Context, req *isearch.Request) (*isearch.Response, error) {
+	for _, ff := range req.Facets {
+		if !ff.IsMetaField() && !ff.IsLiteralField() {
+			return nil, fmt.Errorf("%w: %s", isearch.ErrUnsupportedFacet, ff.Field)
+		}
+	}
+
+	s.rw.RLock()
+	defer s.rw.RUnlock()
+
+	resp := &isearch.Response{Hits: []*isearch.Hit{}}
+
+	idx, ok := s.orgs[req.OrgID]
+	if !ok {
+		resp.Facets = facets(req, nil)
+		return resp, nil
+	}
+
+	ranked, err := idx.search(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Total = len(ranked)
+	resp.Facets = facets(req, ranked)
+
+	start := req.Start()
+	if start > len(ranked) {
+		start = len(ranked)
+	}
+
+	end := start + req.Rows
+	if req.Rows == 0 || end > len(ranked) {
+		end = len(ranked)
+	}
+
+	resp.Hits = append(resp.Hits, ranked[start:end]...)
+
+	return resp, nil
+}
+
+// search returns the matching hits ordered by descending score and ID.
+func (idx *recordIndex) search(req *isearch.Request) ([]*isearch.Hit, error) {
+	all := make(map[int]bool, len(idx.hits))
+	for docID := range idx.hits {
+		all[docID] = true
+	}
+
+	docs := all
+	scores := search.NewMatches()
+
+	if strings.TrimSpace(req.Query) != "" {
+		// the query terms are analyzed like the indexed text
+		options := append(append([]search.QueryOption{}, req.QueryOptions...), search.SetAnalyzer(idx.ti.Analyzer()))
+
+		qp, err := search.NewQueryParser(options...)
+		if err != nil {
+			return nil, err
+		}
+
+		query, err := qp.Parse(req.Query)
+		if err != nil {
+			return nil, err
+		}
+
+		docs = idx.ti.matchDocs(query, all)
+
+		for _, qt := range scoringTerms(query) {
+			idx.ti.match(unprohibited(qt), scores)
+		}
+
+		idx.ti.score(query, scores)
+	}
+
+	ranked := []*isearch.Hit{}
+
+	for docID := range docs {
+		hit, ok := idx.hits[docID]
+		if !ok || !matchFilters(hit, req.Filters) {
+			continue
+		}
+
+		scored := *hit
+		scored.Score = scores.Score(docID)
+
+		ranked = append(ranked, &scored)
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+
+		return ranked[i].ID < ranked[j].ID
+	})
+
+	return ranked, nil
+}
+
+// matchDocs returns the documents that match the query. Unlike Search, the
+// boolean clauses are evaluated per document: all must clauses must match the
+// same document and documents that match a must not clause are removed.
+func (ti *TextIndex) matchDocs(query *search.QueryTerm, all map[int]bool) map[int]bool {
+	if !query.IsBoolQuery() {
+		if query.Value == "" && query.Range == nil {
+			return all
+		}
+
+		hits := search.NewMatches()
+		ti.match(unprohibited(query), hits)
+
+		return hits.Vectors().Docs
+	}
+
+	var docs map[int]bool
+
+	switch {
+	case len(query.Must()) != 0:
+		for _, qt := range query.Must() {
+			docs = intersect(docs, ti.matchDocs(qt, all))
+		}
+	case len(query.Should()) != 0:
+		docs = map[int]bool{}
+
+		for _, qt := range query.Should() {
+			for docID := range ti.matchDocs(qt, all) {
+				docs[docID] = true
+			}
+		}
+	default:
+		docs = all
+	}
+
+	if len(query.MustNot()) == 0 {
+		return docs
+	}
+
+	remaining := map[int]bool{}
+
+	for docID := range docs {
+		remaining[docID] = true
+	}
+
+	for _, qt := range query.MustNot() {
+		for docID := range ti.matchDocs(qt, all) {
+			delete(remaining, docID)
+		}
+	}
+
+	return remaining
+}
+
+// unprohibited returns a copy of the query term that matches the documents
+// that contain the term, also when the term is prohibited.
+func unprohibited(qt *search.QueryTerm) *search.QueryTerm {
+	if !qt.Prohibited {
+		return qt
+	}
+
+	c := *qt
+	c.Prohibited = false
+
+	return &c
+}
+
+// intersect returns the documents in both a and b. A nil a returns b.
+func intersect(a, b map[int]bool) map[int]bool {
+	if a == nil {
+		return b
+	}
+
+	docs := map[int]bool{}
+
+	for docID := range a {
+		if b[docID] {
+			docs[docID] = true
+		}
+	}
+
+	return docs
+}
+
+// matchFilters returns true when the hit matches all filters. Values are
+// matched exactly, like the keyword fields in ElasticSearch.
+func matchFilters(hit *isearch.Hit, filters []*isearch.Filter) bool {
+	for _, f := range filters {
+		if hasValue(hit.Values(f.Field), f.Value) == f.Exclude {
+			return false
+		}
+	}
+
+	return true
+}
+
+func hasValue(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// facets returns the facets of the request for the matching hits.
+func facets(req *isearch.Request, hits []*isearch.Hit) []*isearch.Facet {
+	facets := []*isearch.Facet{}
+
+	for _, ff := range req.Facets {
+		field := ff.Path()
+		if ff.IsLiteralField() {
+			field = ff.Field
+		}
+
+		counts := map[string]int64{}
+
+		for _, hit := range hits {
+			// each hit is only counted once per value
+			seen := map[string]bool{}
+
+			for _, value := range hit.Values(field) {
+				if !seen[value] {
+					seen[value] = true
+					counts[value]++
+				}
+			}
+		}
+
+		links := make([]*isearch.FacetLink, 0, len(counts))
+
+		for value, count := range counts {
+			links = append(links, &isearch.FacetLink{
+				Value:         value,
+				DisplayString: value,
+				Count:         count,
+				IsSelected:    req.IsSelected(field, value),
+			})
+		}
+
+		isearch.SortFacetLinks(ff, links)
+
+		facet := &isearch.Facet{
+			Name:  ff.Field,
+			Field: field,
+		}
+
+		for i, link := range links {
+			facet.Total += link.Count
+
+			if i >= ff.Size && ff.Size != 0 {
+				facet.OtherDocs += link.Count
+				continue
+			}
+
+			facet.Links = append(facet.Links, link)
+
+			if link.IsSelected {
+				facet.IsSelected = true
+			}
+		}
+
+		if facet.Links == nil {
+			facet.Links = []*isearch.FacetLink{}
+		}
+
+		facets = append(facets, facet)
+	}
+
+	return facets
+}
@@ -0,0 +1,263 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/delving/hub3/ikuzo/domain"
+	isearch "github.com/delving/hub3/ikuzo/search"
+	"github.com/delving/hub3/ikuzo/service/x/search"
+	"github.com/google/go-cmp/cmp"
+	"github.com/matryer/is"
+)
+
+func testRecord(id, spec, title, creator string) []byte {
+	return []byte(fmt.Sprintf(`{
+		"meta": {"hubID": %q, "orgID": "demo", "spec": %q, "tags": ["narthex"]},
+		"resources": [{"entries": [
+			{"@value": %q, "searchLabel": "dc_title"},
+			{"@value": %q, "searchLabel": "dc_creator"},
+			{"@id": "http://example.org/place/1", "searchLabel": "dc_spatial"}
+		]}]
+	}`, id, spec, title, creator))
+}
+
+func newTestSearcher(t *testing.T) *Searcher {
+	is := is.New(t)
+
+	s := NewSearcher()
+	is.NoErr(s.AddRecord("demo", testRecord("demo_a_1", "a", "Amsterdam canals", "Breitner")))
+	is.NoErr(s.AddRecord("demo", testRecord("demo_a_2", "a", "Canals of Utrecht", "Witsen")))
+	is.NoErr(s.AddRecord("demo", testRecord("demo_b_1", "b", "Amsterdam harbour", "Witsen")))
+	is.NoErr(s.AddRecord("demo", testRecord("demo_b_2", "b", "Rotterdam harbour", "Breitner")))
+
+	return s
+}
+
+func hitIDs(resp *isearch.Response) []string {
+	ids := []string{}
+	for _, hit := range resp.Hits {
+		ids = append(ids, hit.ID)
+	}
+
+	return ids
+}
+
+func TestSearcher_Search(t *testing.T) {
+	s := newTestSearcher(t)
+
+	tests := []struct {
+		name    string
+		query   string
+		filters []string
+		want    []string
+	}{
+		{"match all", "", nil, []string{"demo_a_1", "demo_a_2", "demo_b_1", "demo_b_2"}},
+		{"single term", "amsterdam", nil, []string{"demo_a_1", "demo_b_1"}},
+		{"must clauses on the same record", "amsterdam AND canals", nil, []string{"demo_a_1"}},
+		{"should clauses", "utrecht OR rotterdam", nil, []string{"demo_a_2", "demo_b_2"}},
+		{"prohibited term", "harbour -rotterdam", nil, []string{"demo_b_1"}},
+		{"fielded term", "dc_creator:witsen", nil, []string{"demo_a_2", "demo_b_1"}},
+		{"no match", "haarlem", nil, []string{}},
+		{"meta filter", "", []string{"meta.spec:b"}, []string{"demo_b_1", "demo_b_2"}},
+		{"exclude filter", "", []string{"-dc_creator:Breitner"}, []string{"demo_a_2", "demo_b_1"}},
+		{"query and filter", "harbour", []string{"dc_creator:Witsen"}, []string{"demo_b_1"}},
+		{"resource filter", "", []string{"dc_spatial:http://example.org/place/1", "meta.spec:a"}, []string{"demo_a_1", "demo_a_2"}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+
+			req := &isearch.Request{OrgID: "demo", Query: tt.query, Page: 1, Rows: 10}
+
+			for _, qf := range tt.filters {
+				f, err := isearch.ParseFilter(qf)
+				is.NoErr(err)
+
+				req.Filters = append(req.Filters, f)
+			}
+
+			resp, err := s.Search(context.TODO(), req)
+			is.NoErr(err)
+			is.Equal(resp.Total, len(tt.want))
+
+			got := hitIDs(resp)
+			if tt.query == "" {
+				// all scores are equal, so the hits are sorted by ID
+				is.Equal(got, tt.want)
+				return
+			}
+
+			opt := cmp.Transformer("sort", func(ids []string) map[string]bool {
+				m := map[string]bool{}
+				for _, id := range ids {
+					m[id] = true
+				}
+
+				return m
+			})
+			if diff := cmp.Diff(tt.want, got, opt); diff != "" {
+				t.Errorf("Searcher.Search() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSearcher_Ranking(t *testing.T) {
+	is := is.New(t)
+
+	s := NewSearcher()
+	is.NoErr(s.AddRecord("demo", testRecord("demo_a_1", "a", "Harbour", "Witsen")))
+	is.NoErr(s.AddRecord("demo", testRecord("demo_a_2", "a", "Harbour harbour", "Witsen")))
+
+	resp, err := s.Search(context.TODO(), &isearch.Request{OrgID: "demo", Query: "harbour", Rows: 10})
+	is.NoErr(err)
+	is.Equal(hitIDs(resp), []string{"demo_a_2", "demo_a_1"})
+	is.True(resp.Hits[0].Score > resp.Hits[1].Score)
+}
+
+func TestSearcher_Paging(t *testing.T) {
+	is := is.New(t)
+
+	s := newTestSearcher(t)
+
+	resp, err := s.Search(context.TODO(), &isearch.Request{OrgID: "demo", Page: 2, Rows: 3})
+	is.NoErr(err)
+	is.Equal(resp.Total, 4)
+	is.Equal(hitIDs(resp), []string{"demo_b_2"})
+
+	resp, err = s.Search(context.TODO(), &isearch.Request{OrgID: "unknown", Page: 1, Rows: 3})
+	is.NoErr(err)
+	is.Equal(resp.Total, 0)
+	is.Equal(len(resp.Hits), 0)
+}
+
+func TestSearcher_Replace(t *testing.T) {
+	is := is.New(t)
+
+	s := newTestSearcher(t)
+	is.NoErr(s.AddRecord("demo", testRecord("demo_a_1", "a", "Haarlem", "Breitner")))
+	is.Equal(s.Len("demo"), 4)
+
+	resp, err := s.Search(context.TODO(), &isearch.Request{OrgID: "demo", Query: "haarlem OR canals", Rows: 10})
+	is.NoErr(err)
+	is.Equal(hitIDs(resp), []string{"demo_a_1", "demo_a_2"})
+	is.Equal(resp.Hits[0].Fields["dc_title"], []string{"Haarlem"})
+
+	s.Delete("demo", "demo_a_1")
+	is.Equal(s.Len("demo"), 3)
+}
+
+func TestSearcher_Facets(t *testing.T) {
+	is := is.New(t)
+
+	s := newTestSearcher(t)
+
+	spec, err := isearch.NewFacetField("meta.spec")
+	is.NoErr(err)
+
+	creator, err := isearch.NewFacetField("^dc_creator~1")
+	is.NoErr(err)
+
+	filter, err := isearch.ParseFilter("meta.spec:a")
+	is.NoErr(err)
+
+	req := &isearch.Request{
+		OrgID:   "demo",
+		Query:   "amsterdam OR canals OR rotterdam",
+		Filters: []*isearch.Filter{filter},
+		Facets:  []*isearch.FacetField{spec, creator},
+		Rows:    10,
+	}
+
+	resp, err := s.Search(context.TODO(), req)
+	is.NoErr(err)
+
+	want := []*isearch.Facet{
+		{
+			Name:       "meta.spec",
+			Field:      "meta.spec",
+			IsSelected: true,
+			Total:      2,
+			Links: []*isearch.FacetLink{
+				{Value: "a", DisplayString: "a", Count: 2, IsSelected: true},
+			},
+		},
+		{
+			Name:      "dc_creator",
+			Field:     "dc_creator",
+			Total:     2,
+			OtherDocs: 1,
+			Links: []*isearch.FacetLink{
+				{Value: "Breitner", DisplayString: "Breitner", Count: 1},
+			},
+		},
+	}
+
+	if diff := cmp.Diff(want, resp.Facets); diff != "" {
+		t.Errorf("Searcher.Search() facets mismatch (-want +got):\n%s", diff)
+	}
+
+	date, err := isearch.NewFacetField("datehistogram.dc_date")
+	is.NoErr(err)
+
+	req.Facets = []*isearch.FacetField{date}
+
+	_, err = s.Search(context.TODO(), req)
+	is.True(errors.Is(err, isearch.ErrUnsupportedFacet))
+}
+
+// orgConfigs implements domain.OrgConfigRetriever for the tests.
+type orgConfigs map[string]domain.OrganizationConfig
+
+func (oc orgConfigs) RetrieveConfig(orgID string) (domain.OrganizationConfig, bool) {
+	cfg, ok := oc[orgID]
+	return cfg, ok
+}
+
+func TestSearcher_Language(t *testing.T) {
+	is := is.New(t)
+
+	nl := domain.OrganizationConfig{}
+	nl.Analyzer.Language = "nl"
+
+	s := NewSearcher(SetOrgConfigRetriever(orgConfigs{"demo": nl}))
+	is.NoErr(s.AddRecord("demo", testRecord("demo_a_1", "a", "Boerderijen in de Betuwe", "Witsen")))
+	is.NoErr(s.AddRecord("other", testRecord("other_a_1", "a", "Boerderijen in de Betuwe", "Witsen")))
+
+	find := func(orgID, query string) []string {
+		resp, err := s.Search(context.TODO(), &isearch.Request{OrgID: orgID, Query: query, Rows: 10})
+		is.NoErr(err)
+
+		return hitIDs(resp)
+	}
+
+	is.Equal(find("demo", "boerderij"), []string{"demo_a_1"}) // stemmed with the dutch analyzer
+	is.Equal(find("other", "boerderij"), []string{})          // no language configured
+	is.Equal(find("other", "boerderijen"), []string{"other_a_1"})
+
+	nl.Analyzer.Language = "klingon"
+	s = NewSearcher(SetOrgConfigRetriever(orgConfigs{"demo": nl}))
+
+	err := s.AddRecord("demo", testRecord("demo_a_1", "a", "Boerderijen", "Witsen"))
+	is.True(errors.Is(err, search.ErrUnknownLanguage))
+}
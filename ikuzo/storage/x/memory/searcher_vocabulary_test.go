@@ -0,0 +1,90 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/delving/hub3/ikuzo/domain"
+	isearch "github.com/delving/hub3/ikuzo/search"
+	xsearch "github.com/delving/hub3/ikuzo/service/x/search"
+	"github.com/delving/hub3/ikuzo/service/x/vocabulary"
+	"github.com/delving/hub3/ikuzo/storage/x/memory"
+	"github.com/matryer/is"
+)
+
+func record(id, spec, title, creator string) []byte {
+	return []byte(fmt.Sprintf(`{
+		"meta": {"hubID": %q, "orgID": "demo", "spec": %q, "tags": ["narthex"]},
+		"resources": [{"entries": [
+			{"@value": %q, "searchLabel": "dc_title"},
+			{"@value": %q, "searchLabel": "dc_creator"}
+		]}]
+	}`, id, spec, title, creator))
+}
+
+func TestSearcher_VocabularyExpansion(t *testing.T) {
+	is := is.New(t)
+
+	vocab, err := vocabulary.NewService()
+	is.NoErr(err)
+
+	is.NoErr(vocab.Load(strings.NewReader(`
+		@prefix skos: <http://www.w3.org/2004/02/skos/core#> .
+		@prefix ex: <http://example.org/thesaurus/> .
+
+		ex:landbouw a skos:Concept ;
+			skos:prefLabel "Landbouw"@nl ;
+			skos:narrower ex:akkerbouw .
+
+		ex:akkerbouw a skos:Concept ;
+			skos:prefLabel "Akkerbouw"@nl .
+	`), vocabulary.Turtle))
+
+	s := memory.NewSearcher()
+	is.NoErr(s.AddRecord("demo", record("demo_a_1", "a", "Akkerbouw in de Betuwe", "Witsen")))
+	is.NoErr(s.AddRecord("demo", record("demo_a_2", "a", "Veeteelt in Friesland", "Witsen")))
+
+	svc, err := isearch.NewService(
+		isearch.SetSearcher(s),
+		isearch.SetQueryOptions(func(cfg *domain.OrganizationConfig) ([]xsearch.QueryOption, error) {
+			return []xsearch.QueryOption{xsearch.SetExpander(vocab)}, nil
+		}),
+	)
+	is.NoErr(err)
+
+	orgID, err := domain.NewOrganizationID("demo")
+	is.NoErr(err)
+
+	org := domain.Organization{ID: orgID}
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v3/search?q=landbouw", nil)
+	r = domain.SetOrganization(r, &org)
+
+	w := httptest.NewRecorder()
+	svc.ServeHTTP(w, r)
+
+	is.Equal(w.Code, http.StatusOK)
+
+	var got isearch.Response
+	is.NoErr(json.NewDecoder(w.Body).Decode(&got))
+	is.Equal(len(got.Hits), 1)
+	is.Equal(got.Hits[0].ID, "demo_a_1") // the narrower term akkerbouw is matched
+}
@@ -96,8 +96,7 @@ func (tq *TextQuery) hightlightWithVectors(text string, docID int, vectors *sear
 		return text
 	}
 
-	tok := search.NewTokenizer()
-	tokens := tok.ParseString(text, docID)
+	tokens := tq.ti.a.Tokenize(text, docID)
 
 	return tokens.Highlight(vectors, tq.EmStartTag, tq.EmStyleClass)
 }
@@ -0,0 +1,187 @@
+package quadstore
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/delving/hub3/ikuzo/domain"
+	"github.com/delving/hub3/ikuzo/rdf"
+)
+
+const (
+	naveDatasetSpec  = "http://schemas.delving.eu/nave/terms/datasetSpec"
+	naveSpecRevision = "http://schemas.delving.eu/nave/terms/specRevision"
+)
+
+// DataSetStore manages the named graphs of a domain.DataSet in the Store.
+//
+// It supports the same operations as the sparql.DataSetStore.
+type DataSetStore struct {
+	store     *Store
+	datasetID string
+}
+
+// NewDataSetStore returns a DataSetStore for the dataset.
+func NewDataSetStore(store *Store, datasetID string) (*DataSetStore, error) {
+	if store == nil {
+		return nil, fmt.Errorf("quadstore: store cannot be nil")
+	}
+
+	ds := &DataSetStore{
+		store:     store,
+		datasetID: datasetID,
+	}
+
+	return ds, nil
+}
+
+// specRecord is a subject of the dataset with its named graph and revision.
+type specRecord struct {
+	graph       rdf.Context
+	revision    int
+	hasRevision bool
+}
+
+// records returns the subjects that have the dataset spec as nave:datasetSpec.
+func (ds *DataSetStore) records(ctx context.Context) ([]specRecord, error) {
+	specPred, err := rdf.NewIRI(naveDatasetSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	revisionPred, err := rdf.NewIRI(naveSpecRevision)
+	if err != nil {
+		return nil, err
+	}
+
+	spec, err := rdf.NewLiteral(ds.datasetID)
+	if err != nil {
+		return nil, err
+	}
+
+	quads, err := ds.store.Match(ctx, nil, specPred, spec, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	records := []specRecord{}
+
+	for _, q := range quads {
+		revisions, err := ds.store.match(ctx, q.Subject, revisionPred, nil, q.Context(), true)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(revisions) == 0 {
+			records = append(records, specRecord{graph: q.Context()})
+			continue
+		}
+
+		for _, r := range revisions {
+			lit, ok := r.Object.(rdf.Literal)
+			if !ok {
+				return nil, fmt.Errorf("quadstore: revision %s is not a literal", r.Object)
+			}
+
+			revision, err := strconv.Atoi(lit.RawValue())
+			if err != nil {
+				return nil, fmt.Errorf("quadstore: unable to convert revision %s to integer", lit.RawValue())
+			}
+
+			records = append(records, specRecord{graph: q.Context(), revision: revision, hasRevision: true})
+		}
+	}
+
+	return records, nil
+}
+
+// deleteGraphs deletes the named graphs of the records that are selected by fn.
+func (ds *DataSetStore) deleteGraphs(ctx context.Context, fn func(r specRecord) bool) (bool, error) {
+	records, err := ds.records(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	seen := map[string]bool{}
+
+	for _, r := range records {
+		if r.graph == nil || !fn(r) || seen[r.graph.String()] {
+			continue
+		}
+
+		seen[r.graph.String()] = true
+
+		if err := ds.store.DeleteGraph(ctx, r.graph); err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// DeleteAllGraphsBySpec deletes all the named graphs of the dataset.
+func (ds *DataSetStore) DeleteAllGraphsBySpec(ctx context.Context) (bool, error) {
+	return ds.deleteGraphs(ctx, func(r specRecord) bool {
+		return true
+	})
+}
+
+// DeleteGraphsOrphansBySpec deletes the named graphs of the dataset with a
+// revision other than the given revision.
+func (ds *DataSetStore) DeleteGraphsOrphansBySpec(ctx context.Context, revision int) (bool, error) {
+	return ds.deleteGraphs(ctx, func(r specRecord) bool {
+		return r.hasRevision && r.revision != revision
+	})
+}
+
+// CountGraphsBySpec counts all the subjects of the dataset.
+func (ds *DataSetStore) CountGraphsBySpec() (int, error) {
+	specPred, err := rdf.NewIRI(naveDatasetSpec)
+	if err != nil {
+		return 0, err
+	}
+
+	spec, err := rdf.NewLiteral(ds.datasetID)
+	if err != nil {
+		return 0, err
+	}
+
+	quads, err := ds.store.Match(context.Background(), nil, specPred, spec, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(quads), nil
+}
+
+// CountRevisionsBySpec counts the subjects of each revision of the dataset.
+func (ds *DataSetStore) CountRevisionsBySpec() ([]domain.DataSetRevisions, error) {
+	records, err := ds.records(context.Background())
+	if err != nil {
+		return []domain.DataSetRevisions{}, err
+	}
+
+	counts := map[int]int{}
+
+	for _, r := range records {
+		if r.hasRevision {
+			counts[r.revision]++
+		}
+	}
+
+	revisions := make([]domain.DataSetRevisions, 0, len(counts))
+	for number, count := range counts {
+		revisions = append(revisions, domain.DataSetRevisions{
+			Number:      number,
+			RecordCount: count,
+		})
+	}
+
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Number < revisions[j].Number
+	})
+
+	return revisions, nil
+}
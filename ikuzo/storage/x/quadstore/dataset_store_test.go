@@ -0,0 +1,96 @@
+package quadstore
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/delving/hub3/ikuzo/domain"
+	"github.com/delving/hub3/ikuzo/rdf"
+	"github.com/google/go-cmp/cmp"
+	"github.com/matryer/is"
+)
+
+func addRecord(t *testing.T, store *Store, spec string, id, revision int) {
+	t.Helper()
+
+	subj := mustIRI(t, fmt.Sprintf("urn:%s/%d", spec, id))
+
+	specLit, err := rdf.NewLiteral(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	revisionLit, err := rdf.NewLiteralWithType(
+		strconv.Itoa(revision),
+		mustIRI(t, "http://www.w3.org/2001/XMLSchema#integer"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := rdf.NewGraph()
+	g.AddTriple(subj, mustIRI(t, naveDatasetSpec), specLit)
+	g.AddTriple(subj, mustIRI(t, naveSpecRevision), revisionLit)
+
+	graph := mustIRI(t, fmt.Sprintf("urn:%s/%d/graph", spec, id))
+
+	if err := store.ReplaceGraph(context.Background(), graph, g); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDataSetStore(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+
+	store := newTestStore(t)
+
+	addRecord(t, store, "spec1", 1, 1)
+	addRecord(t, store, "spec1", 2, 1)
+	addRecord(t, store, "spec1", 3, 2)
+	addRecord(t, store, "spec2", 1, 1)
+
+	ds, err := NewDataSetStore(store, "spec1")
+	is.NoErr(err)
+
+	count, err := ds.CountGraphsBySpec()
+	is.NoErr(err)
+	is.Equal(count, 3)
+
+	revisions, err := ds.CountRevisionsBySpec()
+	is.NoErr(err)
+
+	want := []domain.DataSetRevisions{
+		{Number: 1, RecordCount: 2},
+		{Number: 2, RecordCount: 1},
+	}
+	if diff := cmp.Diff(want, revisions); diff != "" {
+		t.Errorf("CountRevisionsBySpec() mismatch (-want +got):\n%s", diff)
+	}
+
+	ok, err := ds.DeleteGraphsOrphansBySpec(ctx, 2)
+	is.NoErr(err)
+	is.True(ok)
+
+	count, err = ds.CountGraphsBySpec()
+	is.NoErr(err)
+	is.Equal(count, 1)
+
+	ok, err = ds.DeleteAllGraphsBySpec(ctx)
+	is.NoErr(err)
+	is.True(ok)
+
+	count, err = ds.CountGraphsBySpec()
+	is.NoErr(err)
+	is.Equal(count, 0)
+
+	// other datasets are not affected
+	other, err := NewDataSetStore(store, "spec2")
+	is.NoErr(err)
+
+	count, err = other.CountGraphsBySpec()
+	is.NoErr(err)
+	is.Equal(count, 1)
+}
@@ -0,0 +1,24 @@
+// Copyright 2020 Delving B.V.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package quadstore is an embedded named-graph store backed by bbolt.
+//
+// Each RDF term is stored once in a term dictionary and replaced by a numeric
+// ID. The quads are stored in four indexes (SPOG, POSG, OSPG and GSPO), so that
+// every triple pattern can be answered with a prefix scan.
+//
+// The DataSetStore supports the same dataset operations as the sparql.DataSetStore,
+// where the dataset spec and revision of a named graph are stored as
+// nave:datasetSpec and nave:specRevision triples in the graph.
+package quadstore
@@ -0,0 +1,595 @@
+package quadstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/delving/hub3/ikuzo/rdf"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	bucketTerms = []byte("terms")
+	bucketIDs   = []byte("ids")
+)
+
+// position of the term in a quad
+const (
+	posS = iota
+	posP
+	posO
+	posG
+)
+
+// defaultGraph is the ID of the unnamed graph. Term IDs start at 1.
+const defaultGraph uint64 = 0
+
+const keyLen = 4 * 8
+
+// index is a permutation of the quad positions. The key of a quad in the index
+// is the concatenation of the term IDs in the order of the permutation.
+type index struct {
+	name   []byte
+	layout [4]int
+}
+
+var indexes = []index{
+	{name: []byte("spog"), layout: [4]int{posS, posP, posO, posG}},
+	{name: []byte("posg"), layout: [4]int{posP, posO, posS, posG}},
+	{name: []byte("ospg"), layout: [4]int{posO, posS, posP, posG}},
+	{name: []byte("gspo"), layout: [4]int{posG, posS, posP, posO}},
+}
+
+func (idx index) key(q [4]uint64) []byte {
+	key := make([]byte, keyLen)
+	for i, pos := range idx.layout {
+		binary.BigEndian.PutUint64(key[i*8:], q[pos])
+	}
+
+	return key
+}
+
+func (idx index) quad(key []byte) [4]uint64 {
+	var q [4]uint64
+	for i, pos := range idx.layout {
+		q[pos] = binary.BigEndian.Uint64(key[i*8:])
+	}
+
+	return q
+}
+
+// prefix returns the number of leading positions of the layout that are bound.
+func (idx index) prefix(bound [4]bool) int {
+	n := 0
+
+	for _, pos := range idx.layout {
+		if !bound[pos] {
+			break
+		}
+
+		n++
+	}
+
+	return n
+}
+
+// Store is a persistent quad store. Quads without a context are stored in the
+// default graph.
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens or creates the Store at path.
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("quadstore: unable to open %s; %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		buckets := [][]byte{bucketTerms, bucketIDs}
+		for _, idx := range indexes {
+			buckets = append(buckets, idx.name)
+		}
+
+		for _, name := range buckets {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close releases the underlying database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Add stores the quads. Quads that are already present are ignored.
+func (s *Store) Add(ctx context.Context, quads ...*rdf.Quad) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		for _, q := range quads {
+			if err := addQuad(tx, q.Triple, q.Context()); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// AddGraph adds the triples of g to the named graph. When graph is nil the
+// triples are added to the default graph.
+func (s *Store) AddGraph(ctx context.Context, graph rdf.Context, g *rdf.Graph) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		for _, t := range g.Triples() {
+			if err := addQuad(tx, t, graph); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// ReplaceGraph replaces the content of the named graph with the triples of g
+// in a single transaction.
+func (s *Store) ReplaceGraph(ctx context.Context, graph rdf.Context, g *rdf.Graph) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if err := deleteGraph(tx, graph); err != nil {
+			return err
+		}
+
+		for _, t := range g.Triples() {
+			if err := addQuad(tx, t, graph); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// DeleteGraph removes all the quads of the named graph. When graph is nil the
+// default graph is emptied.
+func (s *Store) DeleteGraph(ctx context.Context, graph rdf.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return deleteGraph(tx, graph)
+	})
+}
+
+// Remove deletes the quads from the store.
+func (s *Store) Remove(ctx context.Context, quads ...*rdf.Quad) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		for _, q := range quads {
+			ids, ok, err := lookupQuad(tx, q.Triple, q.Context())
+			if err != nil {
+				return err
+			}
+
+			if !ok {
+				continue
+			}
+
+			if err := deleteIDs(tx, ids); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Graph returns the triples of the named graph. When graph is nil the default
+// graph is returned.
+func (s *Store) Graph(ctx context.Context, graph rdf.Context) (*rdf.Graph, error) {
+	quads, err := s.match(ctx, nil, nil, nil, graph, true)
+	if err != nil {
+		return nil, err
+	}
+
+	g := rdf.NewGraph()
+	for _, q := range quads {
+		g.Add(q.Triple)
+	}
+
+	return g, nil
+}
+
+// Graphs returns the names of all the named graphs in the store.
+func (s *Store) Graphs(ctx context.Context) ([]rdf.Context, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	names := []rdf.Context{}
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(indexes[3].name).Cursor()
+
+		for k, _ := c.First(); k != nil; {
+			id := binary.BigEndian.Uint64(k)
+			if id != defaultGraph {
+				term, err := getTerm(tx, id)
+				if err != nil {
+					return err
+				}
+
+				name, ok := term.(rdf.Context)
+				if !ok {
+					return fmt.Errorf("quadstore: term %s is not a valid graph name", term)
+				}
+
+				names = append(names, name)
+			}
+
+			// skip to the next graph
+			next := make([]byte, 8)
+			binary.BigEndian.PutUint64(next, id+1)
+
+			k, _ = c.Seek(next)
+		}
+
+		return nil
+	})
+
+	return names, err
+}
+
+// Match returns the quads that match the pattern. A nil subject, predicate,
+// object or graph matches any term.
+func (s *Store) Match(ctx context.Context, subj rdf.Subject, pred rdf.Predicate, obj rdf.Object, graph rdf.Context) ([]*rdf.Quad, error) {
+	return s.match(ctx, subj, pred, obj, graph, false)
+}
+
+// match returns the quads that match the pattern. When defaultOnly is true a nil
+// graph only matches the default graph.
+func (s *Store) match(ctx context.Context, subj rdf.Subject, pred rdf.Predicate, obj rdf.Object, graph rdf.Context, defaultOnly bool) ([]*rdf.Quad, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var (
+		pattern [4]uint64
+		bound   [4]bool
+		terms   = [4]rdf.Term{}
+	)
+
+	// assigning a nil interface value keeps the array element nil
+	if subj != nil {
+		terms[posS] = subj
+	}
+
+	if pred != nil {
+		terms[posP] = pred
+	}
+
+	if obj != nil {
+		terms[posO] = obj
+	}
+
+	if graph != nil {
+		terms[posG] = graph
+	}
+
+	quads := []*rdf.Quad{}
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		for pos, term := range terms {
+			if term == nil {
+				continue
+			}
+
+			id, ok, err := lookupTerm(tx, term)
+			if err != nil {
+				return err
+			}
+
+			if !ok {
+				// an unknown term cannot match
+				return nil
+			}
+
+			pattern[pos] = id
+			bound[pos] = true
+		}
+
+		if graph == nil && defaultOnly {
+			bound[posG] = true
+		}
+
+		return scan(tx, pattern, bound, func(ids [4]uint64) error {
+			q, err := decodeQuad(tx, ids)
+			if err != nil {
+				return err
+			}
+
+			quads = append(quads, q)
+
+			return nil
+		})
+	})
+
+	return quads, err
+}
+
+// Len returns the number of quads in the store.
+func (s *Store) Len() (int, error) {
+	var n int
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		n = tx.Bucket(indexes[0].name).Stats().KeyN
+		return nil
+	})
+
+	return n, err
+}
+
+// scan calls fn for each quad that matches the bound positions of the pattern.
+// The index with the longest bound prefix is used.
+func scan(tx *bolt.Tx, pattern [4]uint64, bound [4]bool, fn func(ids [4]uint64) error) error {
+	best := indexes[0]
+	for _, idx := range indexes[1:] {
+		if idx.prefix(bound) > best.prefix(bound) {
+			best = idx
+		}
+	}
+
+	prefix := best.key(pattern)[:best.prefix(bound)*8]
+	c := tx.Bucket(best.name).Cursor()
+
+	for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+		ids := best.quad(k)
+
+		matches := true
+
+		for pos := range ids {
+			if bound[pos] && ids[pos] != pattern[pos] {
+				matches = false
+				break
+			}
+		}
+
+		if !matches {
+			continue
+		}
+
+		if err := fn(ids); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func addQuad(tx *bolt.Tx, t *rdf.Triple, graph rdf.Context) error {
+	var ids [4]uint64
+
+	terms := []rdf.Term{t.Subject, t.Predicate, t.Object}
+	if graph != nil {
+		terms = append(terms, graph)
+	}
+
+	for pos, term := range terms {
+		id, err := termID(tx, term)
+		if err != nil {
+			return err
+		}
+
+		ids[pos] = id
+	}
+
+	for _, idx := range indexes {
+		if err := tx.Bucket(idx.name).Put(idx.key(ids), nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func lookupQuad(tx *bolt.Tx, t *rdf.Triple, graph rdf.Context) (ids [4]uint64, ok bool, err error) {
+	terms := []rdf.Term{t.Subject, t.Predicate, t.Object}
+	if graph != nil {
+		terms = append(terms, graph)
+	}
+
+	for pos, term := range terms {
+		id, found, err := lookupTerm(tx, term)
+		if err != nil || !found {
+			return ids, false, err
+		}
+
+		ids[pos] = id
+	}
+
+	return ids, true, nil
+}
+
+func deleteIDs(tx *bolt.Tx, ids [4]uint64) error {
+	for _, idx := range indexes {
+		if err := tx.Bucket(idx.name).Delete(idx.key(ids)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func deleteGraph(tx *bolt.Tx, graph rdf.Context) error {
+	var (
+		pattern [4]uint64
+		bound   = [4]bool{posG: true}
+	)
+
+	if graph != nil {
+		id, ok, err := lookupTerm(tx, graph)
+		if err != nil || !ok {
+			return err
+		}
+
+		pattern[posG] = id
+	}
+
+	matches := [][4]uint64{}
+
+	// keys cannot be deleted while the cursor iterates over the bucket
+	err := scan(tx, pattern, bound, func(ids [4]uint64) error {
+		matches = append(matches, ids)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, ids := range matches {
+		if err := deleteIDs(tx, ids); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func decodeQuad(tx *bolt.Tx, ids [4]uint64) (*rdf.Quad, error) {
+	var terms [4]rdf.Term
+
+	for pos, id := range ids {
+		if pos == posG && id == defaultGraph {
+			continue
+		}
+
+		term, err := getTerm(tx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		terms[pos] = term
+	}
+
+	subj, ok := terms[posS].(rdf.Subject)
+	if !ok {
+		return nil, fmt.Errorf("quadstore: term %s is not a valid subject", terms[posS])
+	}
+
+	pred, ok := terms[posP].(rdf.Predicate)
+	if !ok {
+		return nil, fmt.Errorf("quadstore: term %s is not a valid predicate", terms[posP])
+	}
+
+	obj, ok := terms[posO].(rdf.Object)
+	if !ok {
+		return nil, fmt.Errorf("quadstore: term %s is not a valid object", terms[posO])
+	}
+
+	var graph rdf.Context
+
+	if terms[posG] != nil {
+		graph, ok = terms[posG].(rdf.Context)
+		if !ok {
+			return nil, fmt.Errorf("quadstore: term %s is not a valid graph name", terms[posG])
+		}
+	}
+
+	return rdf.NewQuad(rdf.NewTriple(subj, pred, obj), graph)
+}
+
+// termID returns the ID of the term. A new ID is assigned when the term is not
+// in the dictionary yet.
+func termID(tx *bolt.Tx, term rdf.Term) (uint64, error) {
+	id, ok, err := lookupTerm(tx, term)
+	if err != nil || ok {
+		return id, err
+	}
+
+	key, err := encodeTerm(term)
+	if err != nil {
+		return 0, err
+	}
+
+	ids := tx.Bucket(bucketIDs)
+
+	id, err = ids.NextSequence()
+	if err != nil {
+		return 0, err
+	}
+
+	idKey := make([]byte, 8)
+	binary.BigEndian.PutUint64(idKey, id)
+
+	if err := ids.Put(idKey, key); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Bucket(bucketTerms).Put(key, idKey); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func lookupTerm(tx *bolt.Tx, term rdf.Term) (id uint64, ok bool, err error) {
+	key, err := encodeTerm(term)
+	if err != nil {
+		return 0, false, err
+	}
+
+	v := tx.Bucket(bucketTerms).Get(key)
+	if v == nil {
+		return 0, false, nil
+	}
+
+	return binary.BigEndian.Uint64(v), true, nil
+}
+
+var errUnknownTerm = errors.New("quadstore: unknown term id")
+
+func getTerm(tx *bolt.Tx, id uint64) (rdf.Term, error) {
+	idKey := make([]byte, 8)
+	binary.BigEndian.PutUint64(idKey, id)
+
+	v := tx.Bucket(bucketIDs).Get(idKey)
+	if v == nil {
+		return nil, fmt.Errorf("%w: %d", errUnknownTerm, id)
+	}
+
+	return decodeTerm(v)
+}
@@ -0,0 +1,188 @@
+package quadstore
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/delving/hub3/ikuzo/rdf"
+	"github.com/matryer/is"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+
+	store, err := Open(filepath.Join(t.TempDir(), "quads.db"))
+	if err != nil {
+		t.Fatalf("unable to open store: %s", err)
+	}
+
+	t.Cleanup(func() { store.Close() })
+
+	return store
+}
+
+func mustIRI(t *testing.T, iri string) rdf.IRI {
+	t.Helper()
+
+	i, err := rdf.NewIRI(iri)
+	if err != nil {
+		t.Fatalf("invalid iri %s: %s", iri, err)
+	}
+
+	return i
+}
+
+func TestTermEncoding(t *testing.T) {
+	is := is.New(t)
+
+	iri := mustIRI(t, "urn:s/1")
+
+	bnode, err := rdf.NewBlankNode("b1")
+	is.NoErr(err)
+
+	plain, err := rdf.NewLiteral("plain")
+	is.NoErr(err)
+
+	lang, err := rdf.NewLiteralWithLang("tekst", "nl")
+	is.NoErr(err)
+
+	typed, err := rdf.NewLiteralWithType("42", mustIRI(t, "http://www.w3.org/2001/XMLSchema#integer"))
+	is.NoErr(err)
+
+	for _, term := range []rdf.Term{iri, bnode, plain, lang, typed} {
+		b, err := encodeTerm(term)
+		is.NoErr(err)
+
+		decoded, err := decodeTerm(b)
+		is.NoErr(err)
+		is.True(decoded.Equal(term)) // decoded term should equal the original
+	}
+}
+
+func TestStore(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+
+	store := newTestStore(t)
+
+	s1, s2 := mustIRI(t, "urn:s/1"), mustIRI(t, "urn:s/2")
+	p := mustIRI(t, "urn:p/title")
+	g1, g2 := mustIRI(t, "urn:graph/1"), mustIRI(t, "urn:graph/2")
+
+	o1, err := rdf.NewLiteral("first")
+	is.NoErr(err)
+
+	o2, err := rdf.NewLiteralWithLang("tweede", "nl")
+	is.NoErr(err)
+
+	quad := func(s rdf.Subject, o rdf.Object, g rdf.Context) *rdf.Quad {
+		q, err := rdf.NewQuad(rdf.NewTriple(s, p, o), g)
+		is.NoErr(err)
+
+		return q
+	}
+
+	err = store.Add(ctx,
+		quad(s1, o1, g1),
+		quad(s2, o2, g1),
+		quad(s1, o1, g2),
+		quad(s1, o2, nil),
+		quad(s1, o1, g1), // duplicate
+	)
+	is.NoErr(err)
+
+	n, err := store.Len()
+	is.NoErr(err)
+	is.Equal(n, 4)
+
+	t.Run("match", func(t *testing.T) {
+		is := is.New(t)
+
+		quads, err := store.Match(ctx, s1, nil, nil, nil)
+		is.NoErr(err)
+		is.Equal(len(quads), 3)
+
+		quads, err = store.Match(ctx, nil, nil, o1, g2)
+		is.NoErr(err)
+		is.Equal(len(quads), 1)
+		is.True(quads[0].Equal(quad(s1, o1, g2)))
+
+		quads, err = store.Match(ctx, nil, p, o2, nil)
+		is.NoErr(err)
+		is.Equal(len(quads), 2)
+
+		quads, err = store.Match(ctx, mustIRI(t, "urn:unknown"), nil, nil, nil)
+		is.NoErr(err)
+		is.Equal(len(quads), 0)
+	})
+
+	t.Run("graphs", func(t *testing.T) {
+		is := is.New(t)
+
+		names, err := store.Graphs(ctx)
+		is.NoErr(err)
+		is.Equal(len(names), 2)
+
+		g, err := store.Graph(ctx, g1)
+		is.NoErr(err)
+		is.Equal(g.Len(), 2)
+
+		g, err = store.Graph(ctx, nil)
+		is.NoErr(err)
+		is.Equal(g.Len(), 1)
+	})
+
+	t.Run("replace and delete", func(t *testing.T) {
+		is := is.New(t)
+
+		replacement := rdf.NewGraph()
+		replacement.AddTriple(s2, p, o1)
+
+		err := store.ReplaceGraph(ctx, g1, replacement)
+		is.NoErr(err)
+
+		g, err := store.Graph(ctx, g1)
+		is.NoErr(err)
+		is.Equal(g.Len(), 1)
+
+		err = store.DeleteGraph(ctx, g2)
+		is.NoErr(err)
+
+		names, err := store.Graphs(ctx)
+		is.NoErr(err)
+		is.Equal(len(names), 1)
+
+		err = store.Remove(ctx, quad(s1, o2, nil))
+		is.NoErr(err)
+
+		n, err := store.Len()
+		is.NoErr(err)
+		is.Equal(n, 1)
+	})
+}
+
+func TestStorePersistence(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "quads.db")
+
+	store, err := Open(path)
+	is.NoErr(err)
+
+	g := rdf.NewGraph()
+	g.AddTriple(mustIRI(t, "urn:s/1"), mustIRI(t, "urn:p/1"), mustIRI(t, "urn:o/1"))
+
+	is.NoErr(store.AddGraph(ctx, mustIRI(t, "urn:graph/1"), g))
+	is.NoErr(store.Close())
+
+	store, err = Open(path)
+	is.NoErr(err)
+
+	defer store.Close()
+
+	stored, err := store.Graph(ctx, mustIRI(t, "urn:graph/1"))
+	is.NoErr(err)
+	is.Equal(stored.Len(), 1)
+}
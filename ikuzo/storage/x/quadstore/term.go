@@ -0,0 +1,76 @@
+package quadstore
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/delving/hub3/ikuzo/rdf"
+)
+
+const (
+	kindIRI       = 'I'
+	kindBlankNode = 'B'
+	kindLiteral   = 'L'
+	kindLangLit   = '@'
+
+	xsdString = "http://www.w3.org/2001/XMLSchema#string"
+)
+
+// encodeTerm returns the dictionary key of the term.
+//
+// IRIs and blank nodes are prefixed with their kind. Literals are encoded as the
+// kind, followed by the datatype or language, a zero byte and the lexical value.
+func encodeTerm(term rdf.Term) ([]byte, error) {
+	switch t := term.(type) {
+	case rdf.IRI:
+		return append([]byte{kindIRI}, t.RawValue()...), nil
+	case rdf.BlankNode:
+		return append([]byte{kindBlankNode}, t.RawValue()...), nil
+	case rdf.Literal:
+		if lang := strings.TrimPrefix(t.Lang(), "@"); lang != "" {
+			return []byte(string(kindLangLit) + lang + "\x00" + t.RawValue()), nil
+		}
+
+		dt := t.DataType.RawValue()
+		if dt == "" {
+			dt = xsdString
+		}
+
+		return []byte(string(kindLiteral) + dt + "\x00" + t.RawValue()), nil
+	}
+
+	return nil, fmt.Errorf("quadstore: unsupported term type %T", term)
+}
+
+func decodeTerm(b []byte) (rdf.Term, error) {
+	if len(b) == 0 {
+		return nil, fmt.Errorf("quadstore: empty term")
+	}
+
+	value := string(b[1:])
+
+	switch b[0] {
+	case kindIRI:
+		return rdf.NewIRI(value)
+	case kindBlankNode:
+		return rdf.NewBlankNode(value)
+	case kindLiteral, kindLangLit:
+		parts := strings.SplitN(value, "\x00", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("quadstore: invalid literal encoding %q", value)
+		}
+
+		if b[0] == kindLangLit {
+			return rdf.NewLiteralWithLang(parts[1], parts[0])
+		}
+
+		dt, err := rdf.NewIRI(parts[0])
+		if err != nil {
+			return nil, err
+		}
+
+		return rdf.NewLiteralWithType(parts[1], dt)
+	}
+
+	return nil, fmt.Errorf("quadstore: unknown term kind %q", b[0])
+}